@@ -8,19 +8,54 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
+	"http/internal/clock"
+	"http/internal/eventlog"
+	"http/internal/fee"
+	"http/internal/fx"
+	"http/internal/idempotency"
+	"http/internal/lock"
+	"http/internal/rateplan"
+	"http/internal/repository"
 	"http/internal/repository/memory"
+	"http/internal/repository/sqlite"
 	"http/internal/service/account"
+	"http/internal/service/batch"
+	"http/internal/service/interest"
+	"http/internal/service/ledger"
+	"http/internal/service/overdraft"
+	"http/internal/service/standingorder"
 	"http/internal/service/transaction"
 	"http/internal/service/user"
 	"http/internal/tbhttp"
+	"http/internal/worker"
 
 	"github.com/Netflix/go-env"
 )
 
 type Config struct {
-	LogLevel string `env:"LOG_LEVEL,default=debug"`
+	LogLevel   string `env:"LOG_LEVEL,default=debug"`
+	Storage    string `env:"STORAGE,default=memory"`
+	SQLitePath string `env:"SQLITE_PATH,default=tiny_bank.db"`
+
+	EventLogDir          string        `env:"EVENT_LOG_DIR,default=tiny_bank_events"`
+	EventLogSync         string        `env:"EVENT_LOG_SYNC,default=always"`
+	EventLogSyncInterval time.Duration `env:"EVENT_LOG_SYNC_INTERVAL,default=1s"`
+	SnapshotInterval     time.Duration `env:"EVENT_LOG_SNAPSHOT_INTERVAL,default=5m"`
+
+	IdempotencyTTL  time.Duration `env:"IDEMPOTENCY_TTL,default=24h"`
+	FXRatesPath     string        `env:"FX_RATES_PATH"`
+	RatePlansPath   string        `env:"INTEREST_RATE_PLANS_PATH"`
+	FeeSchedulePath string        `env:"FEE_SCHEDULE_PATH"`
+
+	StandingOrderInterval     time.Duration `env:"STANDING_ORDER_INTERVAL,default=1m"`
+	OverdraftInterestInterval time.Duration `env:"OVERDRAFT_INTEREST_INTERVAL,default=1h"`
+	SavingsInterestInterval   time.Duration `env:"SAVINGS_INTEREST_INTERVAL,default=1h"`
+	HoldSweepInterval         time.Duration `env:"HOLD_SWEEP_INTERVAL,default=1m"`
+
+	AdminToken string `env:"ADMIN_TOKEN"`
 }
 
 func main() {
@@ -42,18 +77,66 @@ func run() error {
 
 	logger := initLogger(config.LogLevel)
 
-	userRepo := memory.NewUserRepository()
-	accountRepo := memory.NewAccountRepository()
-	transactionRepo := memory.NewTransactionRepository()
-	accountService := account.NewService(accountRepo)
-	userSvc := user.NewService(userRepo, accountService)
-	transactionSvc := transaction.NewService(accountService, transactionRepo)
+	repos, err := initRepositories(ctx, logger, config)
+	if err != nil {
+		return err
+	}
+	defer repos.close()
+
+	exchangeRates, err := fx.NewTable(config.FXRatesPath)
+	if err != nil {
+		return err
+	}
+	ratePlans, err := rateplan.NewTable(config.RatePlansPath)
+	if err != nil {
+		return err
+	}
+	feeSchedule, err := fee.NewSchedule(config.FeeSchedulePath)
+	if err != nil {
+		return err
+	}
+	go reloadFilesOnHangup(ctx, logger, exchangeRates, ratePlans, feeSchedule)
+
+	accountLocker := lock.NewManager()
+	accountService := account.NewService(repos.accounts, repos.users, repos.unitOfWorkFactory, accountLocker, clock.System{})
+	userSvc := user.NewService(repos.users, accountService, repos.audit, clock.System{})
+	transactionSvc := transaction.NewService(repos.unitOfWorkFactory, repos.accounts, repos.transactions, repos.holds, accountLocker, exchangeRates, feeSchedule, clock.System{})
+	ledgerSvc := ledger.NewService(repos.ledger, repos.accounts, accountLocker)
+	batchSvc := batch.NewService(repos.batches, repos.accounts, transactionSvc, clock.System{})
+	standingOrderSvc := standingorder.NewService(repos.unitOfWorkFactory, repos.standingOrders, repos.accounts, transactionSvc, lock.NewManager(), clock.System{})
+	overdraftSvc := overdraft.NewService(repos.unitOfWorkFactory, repos.accounts, transactionSvc, accountLocker, clock.System{})
+	interestSvc := interest.NewService(repos.unitOfWorkFactory, repos.accounts, transactionSvc, ratePlans, accountLocker, clock.System{})
+	idempotencyStore := idempotency.NewMemoryStore(config.IdempotencyTTL)
 
 	server := &http.Server{
 		Addr:    ":8080",
-		Handler: tbhttp.NewServer(ctx, logger, userSvc, accountService, transactionSvc),
+		Handler: tbhttp.NewServer(ctx, logger, userSvc, accountService, transactionSvc, ledgerSvc, batchSvc, standingOrderSvc, interestSvc, idempotencyStore, exchangeRates, ratePlans, config.AdminToken),
 	}
 
+	workerStopped := make(chan struct{})
+	go func() {
+		defer close(workerStopped)
+		worker.Run(ctx, logger, "execute standing orders", config.StandingOrderInterval, standingOrderSvc.ExecuteDue)
+	}()
+
+	overdraftStopped := make(chan struct{})
+	go func() {
+		defer close(overdraftStopped)
+		worker.Run(ctx, logger, "accrue overdraft interest", config.OverdraftInterestInterval, overdraftSvc.AccrueInterest)
+	}()
+
+	savingsStopped := make(chan struct{})
+	go func() {
+		defer close(savingsStopped)
+		worker.Run(ctx, logger, "accrue savings interest", config.SavingsInterestInterval, interestSvc.AccrueInterest)
+	}()
+
+	holdsStopped := make(chan struct{})
+	go func() {
+		defer close(holdsStopped)
+		worker.Run(ctx, logger, "release expired holds", config.HoldSweepInterval, transactionSvc.ReleaseExpiredHolds)
+	}()
+
 	go func() {
 		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 			logger.ErrorContext(ctx, "HTTP server error", "error", err)
@@ -70,12 +153,173 @@ func run() error {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.ErrorContext(ctx, "HTTP shutdown error", "error", err)
 	}
+	// the repositories are closed on return, the workers must be done with them
+	<-workerStopped
+	<-overdraftStopped
+	<-savingsStopped
+	<-holdsStopped
 
 	logger.InfoContext(ctx, "Graceful shutdown complete.")
 
 	return nil
 }
 
+type repositories struct {
+	users             repository.UserRepository
+	accounts          repository.AccountRepository
+	transactions      repository.TransactionRepository
+	ledger            repository.LedgerRepository
+	audit             repository.AuditRepository
+	batches           repository.BatchRepository
+	standingOrders    repository.StandingOrderRepository
+	holds             repository.HoldRepository
+	unitOfWorkFactory repository.UnitOfWorkFactory
+	close             func() error
+}
+
+func initRepositories(ctx context.Context, logger *slog.Logger, config Config) (*repositories, error) {
+	switch config.Storage {
+	case "memory":
+		return storeRepositories(memory.NewStore(nil), func() error { return nil }), nil
+	case "eventlog":
+		return initEventLog(ctx, logger, config)
+	case "sqlite":
+		db, err := sqlite.Open(config.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+
+		return &repositories{
+			users:             sqlite.NewUserRepository(db),
+			accounts:          sqlite.NewAccountRepository(db),
+			transactions:      sqlite.NewTransactionRepository(db),
+			ledger:            sqlite.NewLedgerRepository(db),
+			audit:             sqlite.NewAuditRepository(db),
+			batches:           sqlite.NewBatchRepository(db),
+			standingOrders:    sqlite.NewStandingOrderRepository(db),
+			holds:             sqlite.NewHoldRepository(db),
+			unitOfWorkFactory: sqlite.NewUnitOfWorkFactory(db),
+			close:             db.Close,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q, expected memory, eventlog or sqlite", config.Storage)
+	}
+}
+
+func storeRepositories(store *memory.Store, close func() error) *repositories {
+	return &repositories{
+		users:             store.Users,
+		accounts:          store.Accounts,
+		transactions:      store.Transactions,
+		ledger:            store.Ledger,
+		audit:             store.Audit,
+		batches:           store.Batches,
+		standingOrders:    store.StandingOrders,
+		holds:             store.Holds,
+		unitOfWorkFactory: store.UnitOfWorkFactory,
+		close:             close,
+	}
+}
+
+// initEventLog rebuilds the in memory repositories from the event log and journals every change to it from then on
+func initEventLog(ctx context.Context, logger *slog.Logger, config Config) (*repositories, error) {
+	syncPolicy, err := eventlog.ParseSyncPolicy(config.EventLogSync)
+	if err != nil {
+		return nil, err
+	}
+
+	eventLog, err := eventlog.Open(config.EventLogDir, eventlog.Options{
+		Sync:         syncPolicy,
+		SyncInterval: config.EventLogSyncInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if truncated := eventLog.Truncated(); truncated > 0 {
+		logger.WarnContext(ctx, "dropped a torn record at the end of the event log", "bytes", truncated)
+	}
+
+	store := memory.NewStore(eventLog)
+	if err := eventLog.Replay(store.Apply); err != nil {
+		return nil, errors.Join(err, eventLog.Close())
+	}
+
+	snapshotCtx, stopSnapshots := context.WithCancel(ctx)
+	snapshotsStopped := make(chan struct{})
+	go func() {
+		defer close(snapshotsStopped)
+		snapshotPeriodically(snapshotCtx, logger, store, eventLog, config.SnapshotInterval)
+	}()
+
+	return storeRepositories(store, func() error {
+		stopSnapshots()
+		<-snapshotsStopped
+		return eventLog.Close()
+	}), nil
+}
+
+// snapshotPeriodically snapshots the store every interval, as long as something was appended since the last one
+func snapshotPeriodically(ctx context.Context, logger *slog.Logger, store *memory.Store, eventLog *eventlog.Log, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if eventLog.Pending() == 0 {
+				continue
+			}
+
+			if err := store.Snapshot(eventLog); err != nil {
+				logger.ErrorContext(ctx, "failed to snapshot the event log", "error", err)
+				continue
+			}
+
+			logger.InfoContext(ctx, "snapshotted the event log")
+		}
+	}
+}
+
+// reloadFilesOnHangup reloads the exchange rate, interest rate plan and fee schedule files on SIGHUP, a broken file
+// keeps what was loaded from it before
+func reloadFilesOnHangup(ctx context.Context, logger *slog.Logger, exchangeRates *fx.Table, ratePlans *rateplan.Table, feeSchedule *fee.Schedule) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			if err := exchangeRates.Reload(); err != nil {
+				logger.ErrorContext(ctx, "failed to reload exchange rates", "error", err)
+			} else {
+				logger.InfoContext(ctx, "reloaded exchange rates")
+			}
+
+			if err := ratePlans.Reload(); err != nil {
+				logger.ErrorContext(ctx, "failed to reload interest rate plans", "error", err)
+			} else {
+				logger.InfoContext(ctx, "reloaded interest rate plans")
+			}
+
+			if err := feeSchedule.Reload(); err != nil {
+				logger.ErrorContext(ctx, "failed to reload fee schedule", "error", err)
+			} else {
+				logger.InfoContext(ctx, "reloaded fee schedule")
+			}
+		}
+	}
+}
+
 func initLogger(logLevelEnv string) *slog.Logger {
 	logLevel := slog.LevelDebug
 
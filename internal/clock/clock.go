@@ -0,0 +1,52 @@
+// Package clock tells the time to the parts of the bank that act on their own, such as background workers, so tests
+// can decide what time it is.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the wall clock
+type System struct{}
+
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// Manual only moves when told to, it's safe to use from several goroutines
+type Manual struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+func NewManual(now time.Time) *Manual {
+	return &Manual{now: now}
+}
+
+func (clock *Manual) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	return clock.now
+}
+
+// Set moves the clock to now, backwards too
+func (clock *Manual) Set(now time.Time) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	clock.now = now
+}
+
+// Advance moves the clock forward by d
+func (clock *Manual) Advance(d time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	clock.now = clock.now.Add(d)
+}
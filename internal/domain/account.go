@@ -2,47 +2,168 @@ package domain
 
 import (
 	"errors"
+	"math"
 	"time"
+	"unicode/utf8"
 
 	"github.com/lithammer/shortuuid/v4"
 	"http/internal/tberrors"
 )
 
 type Account struct {
-	ID        string
-	UserID    string
-	Balance   int
+	ID       string
+	UserID   string
+	Balance  Money
+	Type     AccountType
+	Nickname string
+	// DeletedAt is set once the account is closed, either on its own or together with its user
 	DeletedAt *time.Time
+	Overdraft Overdraft
+	Interest  SavingsInterest
+	// Held is what the active holds on the account reserve, it has no currency until the first hold is placed
+	Held Money
 }
 
-func NewAccount(userID string) (*Account, error) {
+type AccountType string
+
+const (
+	Checking AccountType = "checking"
+	Savings  AccountType = "savings"
+)
+
+func (t AccountType) String() string {
+	return string(t)
+}
+
+var invalidAccountTypeError = tberrors.NewValidationError("invalid_account_type", "account type must be checking or savings", "type")
+
+// ParseAccountType reads an account type from a request, an empty type is a checking account
+func ParseAccountType(accountType string) (AccountType, error) {
+	switch t := AccountType(accountType); t {
+	case "":
+		return Checking, nil
+	case Checking, Savings:
+		return t, nil
+	default:
+		return "", invalidAccountTypeError
+	}
+}
+
+// maxNicknameLength is counted in characters, not bytes
+const maxNicknameLength = 64
+
+func NewAccount(userID string, currency Currency, accountType AccountType, nickname string) (*Account, error) {
 	acc := Account{
-		ID:      shortuuid.New(),
-		UserID:  userID,
-		Balance: 0,
+		ID:       shortuuid.New(),
+		UserID:   userID,
+		Balance:  NewMoney(0, currency),
+		Type:     accountType,
+		Nickname: nickname,
 	}
 
 	return acc.validate()
 }
 
-var emptyAccountUserIDError = tberrors.NewValidationError("invalid empty account user id", "UserID")
+var emptyAccountUserIDError = tberrors.NewValidationError("missing_user_id", "invalid empty account user id", "user_id")
+var nicknameTooLongError = tberrors.NewValidationError("nickname_too_long", "nickname must have at most 64 characters", "nickname")
 
 func (acc *Account) validate() (*Account, error) {
+	var errs []error
 	if acc.UserID == "" {
-		return nil, emptyAccountUserIDError
+		errs = append(errs, emptyAccountUserIDError)
+	}
+	if !acc.Balance.Currency.Valid() {
+		errs = append(errs, invalidCurrencyError)
+	}
+	if acc.Type != Checking && acc.Type != Savings {
+		errs = append(errs, invalidAccountTypeError)
+	}
+	if utf8.RuneCountInString(acc.Nickname) > maxNicknameLength {
+		errs = append(errs, nicknameTooLongError)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
 	}
 
 	return acc, nil
 }
 
-var negativeBalanceError = errors.New("adding balance results in negative balance")
+// Closed tells whether the account was closed, closed accounts are kept for their history
+func (acc *Account) Closed() bool {
+	return acc.DeletedAt != nil
+}
+
+var accountClosedError = tberrors.NewForbiddenError("account_closed", "account is closed", "account_id")
+var balanceNotZeroError = tberrors.NewConflictError("balance_not_zero", "account balance must be zero or swept to another account before closing", "sweep_account")
+var activeHoldsError = tberrors.NewConflictError("active_holds", "holds on the account must be captured or voided before closing", "account_id")
+
+// Close marks the account as closed at closedAt, only accounts without money or held funds can be closed
+func (acc *Account) Close(closedAt time.Time) error {
+	if acc.Closed() {
+		return accountClosedError
+	}
+
+	if acc.Held.Amount != 0 {
+		return activeHoldsError
+	}
+
+	if !acc.Balance.IsZero() {
+		return balanceNotZeroError
+	}
+
+	acc.DeletedAt = &closedAt
+
+	return nil
+}
+
+// Reopen undoes Close, it's used when a deleted user is restored
+func (acc *Account) Reopen() {
+	acc.DeletedAt = nil
+}
 
-func (acc *Account) AddBalance(balance int) error {
-	if acc.Balance+balance < 0 {
-		return negativeBalanceError
+var negativeBalanceError = tberrors.NewInsufficientFundsError("insufficient_funds", "adding balance results in negative balance", "amount")
+var overdraftLimitExceededError = tberrors.NewInsufficientFundsError("insufficient_funds", "adding balance exceeds the overdraft limit", "amount")
+var fundsHeldError = tberrors.NewInsufficientFundsError("insufficient_funds", "adding balance takes funds reserved by holds", "amount")
+
+// AddBalance adds a signed amount, which must be in the account currency, to the balance. The balance never goes
+// below zero by more than the overdraft limit, debits don't take funds reserved by holds and closed accounts don't
+// move money.
+func (acc *Account) AddBalance(amount Money) error {
+	if acc.Closed() {
+		return accountClosedError
+	}
+
+	balance, err := acc.Balance.Add(amount)
+	if err != nil {
+		return err
+	}
+
+	// a balance already beyond the limit, because of posted interest, can still receive money
+	limit := acc.Overdraft.Limit.Amount
+	if balance.Amount < acc.Held.Amount-limit && amount.IsNegative() {
+		switch {
+		case balance.Amount >= -limit:
+			return fundsHeldError
+		case limit == 0:
+			return negativeBalanceError
+		default:
+			return overdraftLimitExceededError
+		}
 	}
 
-	acc.Balance += balance
+	acc.Balance = balance
 
 	return nil
 }
+
+// AvailableBalance is what the account can spend, its balance plus its overdraft limit less what holds reserve
+func (acc *Account) AvailableBalance() Money {
+	available, err := acc.Balance.Add(NewMoney(acc.Overdraft.Limit.Amount-acc.Held.Amount, acc.Balance.Currency))
+	if err != nil {
+		// only balances next to the largest amount overflow, they can spend as much as can be represented
+		return NewMoney(math.MaxInt64, acc.Balance.Currency)
+	}
+
+	return available
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"errors"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,7 +14,9 @@ func TestAccount_validate(t *testing.T) {
 	type fields struct {
 		ID        string
 		UserID    string
-		Balance   int
+		Balance   Money
+		Type      AccountType
+		Nickname  string
 		DeletedAt *time.Time
 	}
 	tests := []struct {
@@ -24,14 +28,18 @@ func TestAccount_validate(t *testing.T) {
 		{
 			name: "valid account",
 			fields: fields{
-				ID:      "1",
-				UserID:  "1",
-				Balance: 0,
+				ID:       "1",
+				UserID:   "1",
+				Balance:  eur(0),
+				Type:     Savings,
+				Nickname: "Holidays",
 			},
 			want: &Account{
-				ID:      "1",
-				UserID:  "1",
-				Balance: 0,
+				ID:       "1",
+				UserID:   "1",
+				Balance:  eur(0),
+				Type:     Savings,
+				Nickname: "Holidays",
 			},
 		},
 		{
@@ -39,10 +47,32 @@ func TestAccount_validate(t *testing.T) {
 			fields: fields{
 				ID:      "1",
 				UserID:  "",
-				Balance: 0,
+				Balance: eur(0),
+				Type:    Checking,
 			},
 			wantErr: emptyAccountUserIDError,
 		},
+		{
+			name: "unknown account type, want invalidAccountTypeError",
+			fields: fields{
+				ID:      "1",
+				UserID:  "1",
+				Balance: eur(0),
+				Type:    "brokerage",
+			},
+			wantErr: invalidAccountTypeError,
+		},
+		{
+			name: "nickname longer than 64 characters, want nicknameTooLongError",
+			fields: fields{
+				ID:       "1",
+				UserID:   "1",
+				Balance:  eur(0),
+				Type:     Checking,
+				Nickname: strings.Repeat("é", 65),
+			},
+			wantErr: nicknameTooLongError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -50,6 +80,8 @@ func TestAccount_validate(t *testing.T) {
 				ID:        tt.fields.ID,
 				UserID:    tt.fields.UserID,
 				Balance:   tt.fields.Balance,
+				Type:      tt.fields.Type,
+				Nickname:  tt.fields.Nickname,
 				DeletedAt: tt.fields.DeletedAt,
 			}
 
@@ -67,20 +99,24 @@ func TestAccount_validate(t *testing.T) {
 }
 
 func TestAccount_AddBalance(t *testing.T) {
+	closedAt := time.Now()
+
 	type fields struct {
 		ID        string
 		UserID    string
-		Balance   int
+		Balance   Money
 		DeletedAt *time.Time
+		Overdraft Overdraft
+		Held      Money
 	}
 	type args struct {
-		balance int
+		balance Money
 	}
 	tests := []struct {
 		name        string
 		fields      fields
 		args        args
-		wantBalance int
+		wantBalance Money
 		wantErr     error
 	}{
 		{
@@ -88,36 +124,159 @@ func TestAccount_AddBalance(t *testing.T) {
 			fields: fields{
 				ID:      "1",
 				UserID:  "1",
-				Balance: 0,
+				Balance: eur(0),
 			},
 			args: args{
-				balance: 1,
+				balance: eur(1),
 			},
-			wantBalance: 1,
+			wantBalance: eur(1),
 		},
 		{
 			name: "subtract balance",
 			fields: fields{
 				ID:      "1",
 				UserID:  "1",
-				Balance: 1,
+				Balance: eur(1),
 			},
 			args: args{
-				balance: -1,
+				balance: eur(-1),
 			},
-			wantBalance: 0,
+			wantBalance: eur(0),
 		},
 		{
 			name: "subtract balance from account without sufficient balance",
 			fields: fields{
 				ID:      "1",
 				UserID:  "1",
-				Balance: 0,
+				Balance: eur(0),
+			},
+			args: args{
+				balance: eur(-1),
+			},
+			wantBalance: eur(0),
+			wantErr:     negativeBalanceError,
+		},
+		{
+			name: "subtract balance within the overdraft limit",
+			fields: fields{
+				ID:        "1",
+				UserID:    "1",
+				Balance:   eur(100),
+				Overdraft: Overdraft{Limit: eur(500)},
+			},
+			args: args{
+				balance: eur(-600),
+			},
+			wantBalance: eur(-500),
+		},
+		{
+			name: "subtract balance beyond the overdraft limit, want overdraftLimitExceededError",
+			fields: fields{
+				ID:        "1",
+				UserID:    "1",
+				Balance:   eur(100),
+				Overdraft: Overdraft{Limit: eur(500)},
+			},
+			args: args{
+				balance: eur(-601),
+			},
+			wantBalance: eur(100),
+			wantErr:     overdraftLimitExceededError,
+		},
+		{
+			name: "add balance to an account overdrawn beyond its limit",
+			fields: fields{
+				ID:        "1",
+				UserID:    "1",
+				Balance:   eur(-520),
+				Overdraft: Overdraft{Limit: eur(500)},
+			},
+			args: args{
+				balance: eur(10),
+			},
+			wantBalance: eur(-510),
+		},
+		{
+			name: "subtract balance that isn't held",
+			fields: fields{
+				ID:      "1",
+				UserID:  "1",
+				Balance: eur(100),
+				Held:    eur(40),
+			},
+			args: args{
+				balance: eur(-60),
+			},
+			wantBalance: eur(40),
+		},
+		{
+			name: "subtract held balance, want fundsHeldError",
+			fields: fields{
+				ID:      "1",
+				UserID:  "1",
+				Balance: eur(100),
+				Held:    eur(40),
 			},
 			args: args{
-				balance: -1,
+				balance: eur(-61),
 			},
-			wantErr: negativeBalanceError,
+			wantBalance: eur(100),
+			wantErr:     fundsHeldError,
+		},
+		{
+			name: "subtract balance held within the overdraft limit, want fundsHeldError",
+			fields: fields{
+				ID:        "1",
+				UserID:    "1",
+				Balance:   eur(100),
+				Overdraft: Overdraft{Limit: eur(500)},
+				Held:      eur(200),
+			},
+			args: args{
+				balance: eur(-401),
+			},
+			wantBalance: eur(100),
+			wantErr:     fundsHeldError,
+		},
+		{
+			name: "add balance to closed account, want accountClosedError",
+			fields: fields{
+				ID:        "1",
+				UserID:    "1",
+				Balance:   eur(0),
+				DeletedAt: &closedAt,
+			},
+			args: args{
+				balance: eur(1),
+			},
+			wantBalance: eur(0),
+			wantErr:     accountClosedError,
+		},
+		{
+			name: "add balance in another currency, want currencyMismatchError",
+			fields: fields{
+				ID:      "1",
+				UserID:  "1",
+				Balance: eur(100),
+			},
+			args: args{
+				balance: NewMoney(1, USD),
+			},
+			wantBalance: eur(100),
+			wantErr:     currencyMismatchError,
+		},
+		{
+			name: "add balance overflowing int64, want amountOutOfRangeError",
+			fields: fields{
+				ID:      "1",
+				UserID:  "1",
+				Balance: eur(math.MaxInt64),
+			},
+			args: args{
+				balance: eur(1),
+			},
+			wantBalance: eur(math.MaxInt64),
+			wantErr:     amountOutOfRangeError,
 		},
 	}
 	for _, tt := range tests {
@@ -127,6 +286,8 @@ func TestAccount_AddBalance(t *testing.T) {
 				UserID:    tt.fields.UserID,
 				Balance:   tt.fields.Balance,
 				DeletedAt: tt.fields.DeletedAt,
+				Overdraft: tt.fields.Overdraft,
+				Held:      tt.fields.Held,
 			}
 			if err := acc.AddBalance(tt.args.balance); !errors.Is(err, tt.wantErr) {
 				t.Errorf("AddBalance() error = %v, wantErr %v", err, tt.wantErr)
@@ -138,3 +299,77 @@ func TestAccount_AddBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestAccount_Close(t *testing.T) {
+	closedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		balance       Money
+		held          Money
+		deletedAt     *time.Time
+		wantDeletedAt *time.Time
+		wantErr       error
+	}{
+		{
+			name:          "close empty account",
+			balance:       eur(0),
+			wantDeletedAt: &closedAt,
+		},
+		{
+			name:    "close account with money, want balanceNotZeroError",
+			balance: eur(1),
+			wantErr: balanceNotZeroError,
+		},
+		{
+			name:    "close account with active holds, want activeHoldsError",
+			balance: eur(0),
+			held:    eur(10),
+			wantErr: activeHoldsError,
+		},
+		{
+			name:          "close closed account, want accountClosedError",
+			balance:       eur(0),
+			deletedAt:     &closedAt,
+			wantDeletedAt: &closedAt,
+			wantErr:       accountClosedError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := &Account{ID: "1", UserID: "1", Balance: tt.balance, Type: Checking, DeletedAt: tt.deletedAt, Held: tt.held}
+
+			if err := acc.Close(closedAt); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Close() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.wantDeletedAt, acc.DeletedAt); diff != "" {
+				t.Errorf("Close() deleted at (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseAccountType(t *testing.T) {
+	tests := []struct {
+		accountType string
+		want        AccountType
+		wantErr     error
+	}{
+		{accountType: "", want: Checking},
+		{accountType: "checking", want: Checking},
+		{accountType: "savings", want: Savings},
+		{accountType: "Savings", wantErr: invalidAccountTypeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accountType, func(t *testing.T) {
+			got, err := ParseAccountType(tt.accountType)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseAccountType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAccountType() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/lithammer/shortuuid/v4"
+	"http/internal/tberrors"
+)
+
+// AuditEvent records a change to a user's lifecycle together with the accounts it touched
+type AuditEvent struct {
+	ID         string
+	UserID     string
+	Action     AuditAction
+	AccountIDs []string
+	OccurredAt time.Time
+}
+
+type AuditAction string
+
+const (
+	UserDeleted  AuditAction = "user_deleted"
+	UserRestored AuditAction = "user_restored"
+)
+
+func (a AuditAction) String() string {
+	return string(a)
+}
+
+func NewAuditEvent(userID string, action AuditAction, accountIDs []string, occurredAt time.Time) (*AuditEvent, error) {
+	event := &AuditEvent{
+		ID:         shortuuid.New(),
+		UserID:     userID,
+		Action:     action,
+		AccountIDs: accountIDs,
+		OccurredAt: occurredAt,
+	}
+
+	return event.validate()
+}
+
+var emptyAuditUserIDError = tberrors.NewValidationError("missing_user_id", "invalid empty audit event user id", "user_id")
+var invalidAuditActionError = tberrors.NewValidationError("invalid_audit_action", "invalid audit action", "action")
+
+func (event *AuditEvent) validate() (*AuditEvent, error) {
+	if event.UserID == "" {
+		return nil, emptyAuditUserIDError
+	}
+
+	if event.Action != UserDeleted && event.Action != UserRestored {
+		return nil, invalidAuditActionError
+	}
+
+	return event, nil
+}
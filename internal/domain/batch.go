@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lithammer/shortuuid/v4"
+	"http/internal/tberrors"
+)
+
+// MaxBatchLines bounds how many transfers a single batch holds
+const MaxBatchLines = 5000
+
+// Batch is a file of transfers out of one account, its lines are made according to its mode
+type Batch struct {
+	ID        string
+	AccountID string
+	Mode      BatchMode
+	Status    BatchStatus
+	Lines     []BatchLine
+	CreatedAt time.Time
+	// CompletedAt is set once every line was made or failed
+	CompletedAt *time.Time
+}
+
+type BatchMode string
+
+const (
+	// AllOrNothing makes every line together, a single failing line fails the whole batch
+	AllOrNothing BatchMode = "all-or-nothing"
+	// BestEffort makes every line on its own, failing lines don't stop the others
+	BestEffort BatchMode = "best-effort"
+)
+
+func (m BatchMode) String() string {
+	return string(m)
+}
+
+// ParseBatchMode reads a batch mode from a request
+func ParseBatchMode(mode string) (BatchMode, error) {
+	switch m := BatchMode(mode); m {
+	case AllOrNothing, BestEffort:
+		return m, nil
+	default:
+		return "", invalidBatchModeError
+	}
+}
+
+type BatchStatus string
+
+const (
+	BatchProcessing         BatchStatus = "processing"
+	BatchCompleted          BatchStatus = "completed"
+	BatchPartiallyCompleted BatchStatus = "partially_completed"
+	BatchFailed             BatchStatus = "failed"
+)
+
+func (s BatchStatus) String() string {
+	return string(s)
+}
+
+// BatchLine is a transfer of a batch, Number is its position in the file starting at 1
+type BatchLine struct {
+	Number      int
+	ToAccountID string
+	Amount      Money
+	// Reference is how the payer identifies the line, such as the end to end id of a pain.001 file
+	Reference     string
+	Status        BatchLineStatus
+	TransactionID string
+	// FailureCode and FailureReason tell why a line wasn't made
+	FailureCode   string
+	FailureReason string
+}
+
+// BatchTransfer is a line of a batch file as it was read, its amount is in the currency of the batch account unless
+// the file named one
+type BatchTransfer struct {
+	ToAccountID string
+	Amount      Amount
+	Reference   string
+}
+
+type BatchLineStatus string
+
+const (
+	BatchLinePending   BatchLineStatus = "pending"
+	BatchLineCompleted BatchLineStatus = "completed"
+	BatchLineFailed    BatchLineStatus = "failed"
+	// BatchLineCancelled lines were fine but weren't made because another line of an all or nothing batch failed
+	BatchLineCancelled BatchLineStatus = "cancelled"
+)
+
+func (s BatchLineStatus) String() string {
+	return string(s)
+}
+
+// NewBatch numbers the transfers in the order given as the lines of the batch submitted at now, every line starts
+// pending. Amounts that name no currency are read in currency, the one of the account.
+func NewBatch(accountID string, currency Currency, mode BatchMode, transfers []BatchTransfer, now time.Time) (*Batch, error) {
+	batch := &Batch{
+		ID:        shortuuid.New(),
+		AccountID: accountID,
+		Mode:      mode,
+		Status:    BatchProcessing,
+		Lines:     make([]BatchLine, len(transfers)),
+		CreatedAt: now,
+	}
+
+	errs := batch.validate()
+	for i, transfer := range transfers {
+		line := BatchLine{Number: i + 1, ToAccountID: transfer.ToAccountID, Reference: transfer.Reference, Status: BatchLinePending}
+		if err := line.read(batch.AccountID, transfer.Amount, currency); err != nil {
+			errs = append(errs, tberrors.Nested(LineField(line.Number), err))
+		}
+		batch.Lines[i] = line
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// LineField names a field of the line with number, as a request field
+func LineField(number int) string {
+	return fmt.Sprintf("lines[%d]", number)
+}
+
+// Complete marks the line at index as made by the transaction with transactionID
+func (batch *Batch) Complete(index int, transactionID string) {
+	batch.Lines[index].Status = BatchLineCompleted
+	batch.Lines[index].TransactionID = transactionID
+}
+
+// Fail marks the line at index as failed for reason
+func (batch *Batch) Fail(index int, code, reason string) {
+	batch.Lines[index].Status = BatchLineFailed
+	batch.Lines[index].FailureCode = code
+	batch.Lines[index].FailureReason = reason
+}
+
+// Cancel marks the line at index as not made because the line with failedNumber failed
+func (batch *Batch) Cancel(index int, failedNumber int) {
+	batch.Lines[index].Status = BatchLineCancelled
+	batch.Lines[index].FailureCode = "batch_failed"
+	batch.Lines[index].FailureReason = fmt.Sprintf("line %d failed", failedNumber)
+}
+
+// Finish settles the status of the batch from the status of its lines
+func (batch *Batch) Finish(at time.Time) {
+	completed := 0
+	for _, line := range batch.Lines {
+		if line.Status == BatchLineCompleted {
+			completed++
+		}
+	}
+
+	switch completed {
+	case len(batch.Lines):
+		batch.Status = BatchCompleted
+	case 0:
+		batch.Status = BatchFailed
+	default:
+		batch.Status = BatchPartiallyCompleted
+	}
+	batch.CompletedAt = &at
+}
+
+var emptyBatchAccountIDError = tberrors.NewValidationError("missing_account_id", "invalid empty batch account id", "account_id")
+var invalidBatchModeError = tberrors.NewValidationError("invalid_batch_mode", "mode must be all-or-nothing or best-effort", "mode")
+var emptyBatchError = tberrors.NewValidationError("empty_batch", "batch has no lines", "lines")
+var tooManyBatchLinesError = tberrors.NewValidationError("too_many_lines", fmt.Sprintf("batch has more than %d lines", MaxBatchLines), "lines")
+
+func (batch *Batch) validate() []error {
+	var errs []error
+	if batch.AccountID == "" {
+		errs = append(errs, emptyBatchAccountIDError)
+	}
+	if batch.Mode != AllOrNothing && batch.Mode != BestEffort {
+		errs = append(errs, invalidBatchModeError)
+	}
+
+	switch {
+	case len(batch.Lines) == 0:
+		errs = append(errs, emptyBatchError)
+	case len(batch.Lines) > MaxBatchLines:
+		errs = append(errs, tooManyBatchLinesError)
+	}
+
+	return errs
+}
+
+// read sets the amount of the line and checks it as the transfer out of accountID it becomes
+func (line *BatchLine) read(accountID string, amount Amount, currency Currency) error {
+	if amount == nil {
+		return invalidAmountError
+	}
+
+	var err error
+	if line.Amount, err = amount.In(currency); err != nil {
+		return err
+	}
+
+	transfer := Transaction{FromAccountID: &accountID, Amount: line.Amount, Type: Transfer}
+	if line.ToAccountID != "" {
+		transfer.ToAccountID = &line.ToAccountID
+	}
+
+	_, err = transfer.validate()
+	return err
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/tberrors"
+)
+
+func TestNewBatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		accountID  string
+		mode       BatchMode
+		transfers  []BatchTransfer
+		want       []BatchLine
+		wantFields []string
+	}{
+		{
+			name:      "lines are numbered and pending",
+			accountID: "1",
+			mode:      BestEffort,
+			transfers: []BatchTransfer{
+				{ToAccountID: "2", Amount: eur(100), Reference: "salary-2"},
+				{ToAccountID: "3", Amount: DecimalAmount("2.50")},
+			},
+			want: []BatchLine{
+				{Number: 1, ToAccountID: "2", Amount: eur(100), Reference: "salary-2", Status: BatchLinePending},
+				{Number: 2, ToAccountID: "3", Amount: eur(250), Status: BatchLinePending},
+			},
+		},
+		{
+			name:       "no lines and an unknown mode",
+			accountID:  "1",
+			mode:       "sometimes",
+			wantFields: []string{"mode", "lines"},
+		},
+		{
+			name:      "every invalid line is reported under its number",
+			accountID: "1",
+			mode:      AllOrNothing,
+			transfers: []BatchTransfer{
+				{ToAccountID: "2", Amount: eur(100)},
+				{Amount: eur(0)},
+				{ToAccountID: "3", Amount: DecimalAmount("0.001")},
+				{ToAccountID: "1", Amount: eur(1)},
+			},
+			wantFields: []string{"lines[2].amount", "lines[2].to_account", "lines[3].amount", "lines[4].to_account"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBatch(tt.accountID, EUR, tt.mode, tt.transfers, time.Now())
+
+			if diff := cmp.Diff(tt.wantFields, validationFields(err)); diff != "" {
+				t.Fatalf("NewBatch() error = %v, fields (-want +got):\n%s", err, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Status != BatchProcessing {
+				t.Errorf("NewBatch() status = %s, want %s", got.Status, BatchProcessing)
+			}
+			if diff := cmp.Diff(tt.want, got.Lines); diff != "" {
+				t.Errorf("NewBatch() lines (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBatch_Finish(t *testing.T) {
+	tests := []struct {
+		name   string
+		settle func(batch *Batch)
+		want   BatchStatus
+	}{
+		{
+			name: "every line completed",
+			settle: func(batch *Batch) {
+				batch.Complete(0, "tx-1")
+				batch.Complete(1, "tx-2")
+			},
+			want: BatchCompleted,
+		},
+		{
+			name: "some lines failed",
+			settle: func(batch *Batch) {
+				batch.Complete(0, "tx-1")
+				batch.Fail(1, "account_closed", "account is closed")
+			},
+			want: BatchPartiallyCompleted,
+		},
+		{
+			name: "a line failed and the other was cancelled",
+			settle: func(batch *Batch) {
+				batch.Cancel(0, 2)
+				batch.Fail(1, "insufficient_funds", "insufficient funds")
+			},
+			want: BatchFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch, err := NewBatch("1", EUR, AllOrNothing, []BatchTransfer{{ToAccountID: "2", Amount: eur(1)}, {ToAccountID: "3", Amount: eur(1)}}, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			completedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			tt.settle(batch)
+			batch.Finish(completedAt)
+
+			if batch.Status != tt.want {
+				t.Errorf("Finish() status = %s, want %s", batch.Status, tt.want)
+			}
+			if diff := cmp.Diff(&completedAt, batch.CompletedAt); diff != "" {
+				t.Errorf("Finish() completed at (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// validationFields lists the field of every validation error joined in err
+func validationFields(err error) []string {
+	var fields []string
+	switch e := err.(type) {
+	case tberrors.ValidationError:
+		fields = append(fields, e.Field())
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			fields = append(fields, validationFields(inner)...)
+		}
+	}
+
+	return fields
+}
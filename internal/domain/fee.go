@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"math/big"
+	"strings"
+
+	"http/internal/tberrors"
+)
+
+// FeeRule is what the bank charges for the transactions of one type made from accounts in one currency, either a flat
+// amount or a percentage of the amount moved kept between optional caps.
+type FeeRule struct {
+	Type     TransactionType
+	Currency Currency
+	// Flat is charged on every transaction, it's zero for percentage fees
+	Flat Money
+	// Percentage of the amount moved, such as "0.5" for 0.5%. It's empty for flat fees.
+	Percentage string
+	// Min and Max keep percentage fees between them, a zero Max leaves them uncapped
+	Min Money
+	Max Money
+}
+
+var invalidFeeTypeError = tberrors.NewValidationError("invalid_fee_type", "fees are only charged on deposits, withdrawals and transfers", "type")
+var invalidFeeError = tberrors.NewValidationError("invalid_fee", "a fee is either a positive flat amount or a percentage", "flat")
+var invalidFeePercentageError = tberrors.NewValidationError("invalid_fee_percentage", "fee percentage must be a decimal between 0 and 100 such as 0.5", "percentage")
+var invalidFeeCapsError = tberrors.NewValidationError("invalid_fee_caps", "fee caps must not be negative and min must not be above max", "max")
+
+func NewFeeRule(transactionType TransactionType, currency Currency, flat Money, percentage string, min, max Money) (FeeRule, error) {
+	switch transactionType {
+	case Deposit, Withdrawal, Transfer:
+	default:
+		return FeeRule{}, invalidFeeTypeError
+	}
+	if !currency.Valid() {
+		return FeeRule{}, invalidCurrencyError
+	}
+	for _, amount := range []Money{flat, min, max} {
+		if amount.Currency != currency {
+			return FeeRule{}, currencyMismatchError
+		}
+	}
+
+	if flat.IsNegative() || flat.IsPositive() == (percentage != "") {
+		return FeeRule{}, invalidFeeError
+	}
+	if percentage != "" {
+		if err := validatePercentage(percentage); err != nil {
+			return FeeRule{}, err
+		}
+	}
+
+	if min.IsNegative() || max.IsNegative() || (!max.IsZero() && min.Amount > max.Amount) {
+		return FeeRule{}, invalidFeeCapsError
+	}
+
+	return FeeRule{Type: transactionType, Currency: currency, Flat: flat, Percentage: percentage, Min: min, Max: max}, nil
+}
+
+func validatePercentage(percentage string) error {
+	whole, fraction, hasFraction := strings.Cut(percentage, ".")
+	if !isDigits(whole) || (hasFraction && !isDigits(fraction)) {
+		return invalidFeePercentageError
+	}
+
+	value, ok := new(big.Rat).SetString(percentage)
+	if !ok || value.Cmp(big.NewRat(100, 1)) > 0 {
+		return invalidFeePercentageError
+	}
+
+	return nil
+}
+
+// Charge is the fee on a transaction moving amount, percentages are rounded half to even to minor units before the
+// caps apply
+func (rule FeeRule) Charge(amount Money) (Money, error) {
+	if amount.Currency != rule.Currency {
+		return Money{}, currencyMismatchError
+	}
+	if rule.Percentage == "" {
+		return rule.Flat, nil
+	}
+
+	percentage, ok := new(big.Rat).SetString(rule.Percentage)
+	if !ok {
+		return Money{}, invalidFeePercentageError
+	}
+
+	fee := new(big.Rat).Mul(new(big.Rat).SetInt64(amount.Amount), percentage)
+	rounded := roundHalfEven(fee.Quo(fee, big.NewRat(100, 1)))
+	if !rounded.IsInt64() {
+		return Money{}, amountOutOfRangeError
+	}
+
+	charge := max(rounded.Int64(), rule.Min.Amount)
+	if rule.Max.IsPositive() {
+		charge = min(charge, rule.Max.Amount)
+	}
+
+	return NewMoney(charge, rule.Currency), nil
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewFeeRule(t *testing.T) {
+	tests := []struct {
+		name            string
+		transactionType TransactionType
+		flat            Money
+		percentage      string
+		min, max        Money
+		wantErr         error
+	}{
+		{name: "flat", transactionType: Withdrawal, flat: eur(100), min: eur(0), max: eur(0)},
+		{name: "capped percentage", transactionType: Transfer, flat: eur(0), percentage: "0.5", min: eur(50), max: eur(1000)},
+		{
+			name: "fee on a fee, want invalidFeeTypeError", transactionType: Fee, flat: eur(100), min: eur(0), max: eur(0),
+			wantErr: invalidFeeTypeError,
+		},
+		{
+			name: "flat and percentage, want invalidFeeError", transactionType: Transfer, flat: eur(100), percentage: "1", min: eur(0), max: eur(0),
+			wantErr: invalidFeeError,
+		},
+		{
+			name: "neither flat nor percentage, want invalidFeeError", transactionType: Transfer, flat: eur(0), min: eur(0), max: eur(0),
+			wantErr: invalidFeeError,
+		},
+		{
+			name: "percentage above 100, want invalidFeePercentageError", transactionType: Transfer, flat: eur(0), percentage: "120", min: eur(0), max: eur(0),
+			wantErr: invalidFeePercentageError,
+		},
+		{
+			name: "min above max, want invalidFeeCapsError", transactionType: Transfer, flat: eur(0), percentage: "1", min: eur(500), max: eur(100),
+			wantErr: invalidFeeCapsError,
+		},
+		{
+			name: "cap in another currency, want currencyMismatchError", transactionType: Transfer, flat: eur(0), percentage: "1", min: NewMoney(0, USD), max: eur(0),
+			wantErr: currencyMismatchError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFeeRule(tt.transactionType, EUR, tt.flat, tt.percentage, tt.min, tt.max); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewFeeRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFeeRule_Charge(t *testing.T) {
+	flat := FeeRule{Type: Withdrawal, Currency: EUR, Flat: eur(100)}
+	capped := FeeRule{Type: Transfer, Currency: EUR, Flat: eur(0), Percentage: "0.5", Min: eur(50), Max: eur(1000)}
+	uncapped := FeeRule{Type: Transfer, Currency: EUR, Flat: eur(0), Percentage: "0.5"}
+
+	tests := []struct {
+		name    string
+		rule    FeeRule
+		amount  Money
+		want    Money
+		wantErr error
+	}{
+		{name: "flat whatever the amount", rule: flat, amount: eur(123456), want: eur(100)},
+		{name: "percentage between the caps", rule: capped, amount: eur(50000), want: eur(250)},
+		{name: "percentage below min", rule: capped, amount: eur(1000), want: eur(50)},
+		{name: "percentage above max", rule: capped, amount: eur(1000000), want: eur(1000)},
+		{name: "half a cent rounds to even", rule: uncapped, amount: eur(300), want: eur(2)},
+		{name: "tiny amount, nothing charged", rule: uncapped, amount: eur(50), want: eur(0)},
+		{name: "amount in another currency, want currencyMismatchError", rule: flat, amount: NewMoney(100, USD), wantErr: currencyMismatchError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Charge(tt.amount)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Charge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Charge() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"http/internal/tberrors"
+)
+
+// ExchangeRate converts From into To, one unit of From buys Rate units of To. Rate is kept as the decimal string it
+// was published with so conversions never go through floats.
+type ExchangeRate struct {
+	From      Currency
+	To        Currency
+	Rate      string
+	UpdatedAt time.Time
+}
+
+var invalidExchangeRateError = tberrors.NewValidationError("invalid_exchange_rate", "exchange rate needs two different currencies and a positive decimal rate", "rate")
+var convertedAmountTooSmallError = tberrors.NewValidationError("amount_too_small", "amount is too small to convert", "amount")
+
+func NewExchangeRate(from, to Currency, rate string, updatedAt time.Time) (ExchangeRate, error) {
+	exchangeRate := ExchangeRate{
+		From:      from,
+		To:        to,
+		Rate:      rate,
+		UpdatedAt: updatedAt,
+	}
+
+	return exchangeRate.validate()
+}
+
+func (rate ExchangeRate) validate() (ExchangeRate, error) {
+	if !rate.From.Valid() || !rate.To.Valid() || rate.From == rate.To {
+		return ExchangeRate{}, invalidExchangeRateError
+	}
+
+	// big.Rat also reads fractions and exponents, a rate must be a plain decimal such as 1.0845
+	whole, fraction, hasFraction := strings.Cut(rate.Rate, ".")
+	if !isDigits(whole) || (hasFraction && !isDigits(fraction)) {
+		return ExchangeRate{}, invalidExchangeRateError
+	}
+
+	value, ok := new(big.Rat).SetString(rate.Rate)
+	if !ok || value.Sign() <= 0 {
+		return ExchangeRate{}, invalidExchangeRateError
+	}
+
+	return rate, nil
+}
+
+// Convert turns amount, which must be in the From currency, into the To currency rounding half to even to its
+// minor units.
+func (rate ExchangeRate) Convert(amount Money) (Money, error) {
+	if amount.Currency != rate.From {
+		return Money{}, currencyMismatchError
+	}
+
+	value, ok := new(big.Rat).SetString(rate.Rate)
+	if !ok {
+		return Money{}, invalidExchangeRateError
+	}
+
+	// minor units of To = minor units of From * rate * 10^(exponent of To - exponent of From)
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount.Amount), value)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(rate.To.Exponent()-rate.From.Exponent()))), nil))
+	if rate.To.Exponent() >= rate.From.Exponent() {
+		converted.Mul(converted, scale)
+	} else {
+		converted.Quo(converted, scale)
+	}
+
+	rounded := roundHalfEven(converted)
+	if !rounded.IsInt64() {
+		return Money{}, amountOutOfRangeError
+	}
+
+	if rounded.Sign() == 0 && amount.Amount != 0 {
+		return Money{}, convertedAmountTooSmallError
+	}
+
+	return NewMoney(rounded.Int64(), rate.To), nil
+}
+
+func roundHalfEven(value *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+
+	// compare twice the remainder with the denominator to find out which side of the half value lies on
+	twiceRemainder := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+	cmp := twiceRemainder.Cmp(value.Denom())
+	if cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1) {
+		if value.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// FXConversion records how a transfer between accounts in different currencies was converted.
+type FXConversion struct {
+	DestinationAmount Money
+	Rate              string
+	RateTimestamp     time.Time
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewExchangeRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    Currency
+		to      Currency
+		rate    string
+		wantErr error
+	}{
+		{name: "valid rate", from: EUR, to: USD, rate: "1.0845"},
+		{name: "whole rate", from: USD, to: JPY, rate: "150"},
+		{name: "same currency, want invalidExchangeRateError", from: EUR, to: EUR, rate: "1", wantErr: invalidExchangeRateError},
+		{name: "unknown currency, want invalidExchangeRateError", from: EUR, to: "XXX", rate: "1", wantErr: invalidExchangeRateError},
+		{name: "zero rate, want invalidExchangeRateError", from: EUR, to: USD, rate: "0.00", wantErr: invalidExchangeRateError},
+		{name: "negative rate, want invalidExchangeRateError", from: EUR, to: USD, rate: "-1.2", wantErr: invalidExchangeRateError},
+		{name: "fraction rate, want invalidExchangeRateError", from: EUR, to: USD, rate: "11/10", wantErr: invalidExchangeRateError},
+		{name: "exponent rate, want invalidExchangeRateError", from: EUR, to: USD, rate: "1e2", wantErr: invalidExchangeRateError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExchangeRate(tt.from, tt.to, tt.rate, time.Time{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewExchangeRate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExchangeRate_Convert(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    ExchangeRate
+		amount  Money
+		want    Money
+		wantErr error
+	}{
+		{
+			name:   "converts minor units",
+			rate:   ExchangeRate{From: EUR, To: USD, Rate: "1.0845"},
+			amount: eur(10000),
+			want:   NewMoney(10845, USD),
+		},
+		{
+			name:   "half rounds to even, down",
+			rate:   ExchangeRate{From: EUR, To: USD, Rate: "0.5"},
+			amount: eur(5),
+			want:   NewMoney(2, USD),
+		},
+		{
+			name:   "half rounds to even, up",
+			rate:   ExchangeRate{From: EUR, To: USD, Rate: "0.5"},
+			amount: eur(3),
+			want:   NewMoney(2, USD),
+		},
+		{
+			name:   "more than half rounds up",
+			rate:   ExchangeRate{From: EUR, To: GBP, Rate: "0.8567"},
+			amount: eur(1000),
+			want:   NewMoney(857, GBP),
+		},
+		{
+			name:   "into a currency without minor units",
+			rate:   ExchangeRate{From: USD, To: JPY, Rate: "150.25"},
+			amount: NewMoney(1050, USD),
+			want:   NewMoney(1578, JPY),
+		},
+		{
+			name:   "from a currency without minor units",
+			rate:   ExchangeRate{From: JPY, To: EUR, Rate: "0.0061"},
+			amount: NewMoney(1000, JPY),
+			want:   eur(610),
+		},
+		{
+			name:    "amount in another currency, want currencyMismatchError",
+			rate:    ExchangeRate{From: EUR, To: USD, Rate: "1.0845"},
+			amount:  NewMoney(100, GBP),
+			wantErr: currencyMismatchError,
+		},
+		{
+			name:    "converted amount rounds to zero, want convertedAmountTooSmallError",
+			rate:    ExchangeRate{From: EUR, To: USD, Rate: "0.001"},
+			amount:  eur(1),
+			wantErr: convertedAmountTooSmallError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rate.Convert(tt.amount)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Convert() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
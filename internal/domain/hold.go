@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/lithammer/shortuuid/v4"
+	"http/internal/tberrors"
+)
+
+// MaxHoldDuration is the longest funds can be reserved for, like a card authorization
+const MaxHoldDuration = 30 * 24 * time.Hour
+
+// Hold reserves Amount of what an account can spend until it's captured, voided or expires. Capturing pays up to
+// Amount out of the account, into ToAccountID when it's set and as a withdrawal otherwise, and releases the rest.
+type Hold struct {
+	ID          string
+	AccountID   string
+	ToAccountID string
+	Amount      Money
+	Status      HoldStatus
+	// Captured is what was paid when the hold was captured, it has no currency before
+	Captured Money
+	// TransactionID is the transaction the hold was captured with
+	TransactionID string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	// ReleasedAt is when the hold stopped reserving funds, nil while it's active
+	ReleasedAt *time.Time
+}
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldVoided   HoldStatus = "voided"
+	// HoldExpired holds were released because they weren't captured in time
+	HoldExpired HoldStatus = "expired"
+)
+
+func (s HoldStatus) String() string {
+	return string(s)
+}
+
+// Expired tells whether the hold is still active past its expiry, such holds are released by ExpireHold
+func (hold *Hold) Expired(now time.Time) bool {
+	return hold.Status == HoldActive && !now.Before(hold.ExpiresAt)
+}
+
+var invalidHoldAmountError = tberrors.NewValidationError("invalid_amount", "held amount must be positive", "amount")
+var invalidHoldExpiryError = tberrors.NewValidationError("invalid_expires_at", "hold must expire in the future and within 30 days", "expires_at")
+var sameHoldAccountError = tberrors.NewValidationError("same_account", "captures can't be paid into the held account", "to_account")
+var holdFundsError = tberrors.NewInsufficientFundsError("insufficient_funds", "held amount exceeds the available balance", "amount")
+var invalidCaptureAmountError = tberrors.NewValidationError("invalid_capture_amount", "captured amount must be positive and at most the held amount", "amount")
+var holdNotActiveError = tberrors.NewConflictError("hold_not_active", "hold was already captured, voided or expired", "hold_id")
+var holdExpiredError = tberrors.NewConflictError("hold_expired", "hold expired and its funds are being released", "hold_id")
+var holdNotExpiredError = tberrors.NewConflictError("hold_not_expired", "hold has not expired yet", "hold_id")
+var holdAccountMismatchError = tberrors.NewInternalError("hold_account_mismatch", "hold is not on the account")
+
+// PlaceHold reserves amount, in the account currency, of what the account can spend until expiresAt. Holds expire
+// at most MaxHoldDuration after now.
+func (acc *Account) PlaceHold(amount Money, toAccountID string, expiresAt, now time.Time) (*Hold, error) {
+	if acc.Closed() {
+		return nil, accountClosedError
+	}
+	if amount.Currency != acc.Balance.Currency {
+		return nil, currencyMismatchError
+	}
+	if !amount.IsPositive() {
+		return nil, invalidHoldAmountError
+	}
+	if !expiresAt.After(now) || expiresAt.Sub(now) > MaxHoldDuration {
+		return nil, invalidHoldExpiryError
+	}
+	if toAccountID == acc.ID {
+		return nil, sameHoldAccountError
+	}
+
+	if acc.AvailableBalance().Amount < amount.Amount {
+		return nil, holdFundsError
+	}
+
+	held, err := NewMoney(acc.Held.Amount, acc.Balance.Currency).Add(amount)
+	if err != nil {
+		return nil, err
+	}
+	acc.Held = held
+
+	return &Hold{
+		ID:          shortuuid.New(),
+		AccountID:   acc.ID,
+		ToAccountID: toAccountID,
+		Amount:      amount,
+		Status:      HoldActive,
+		CreatedAt:   now,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// CaptureHold releases hold and settles amount of it, at most the whole held amount, with the transaction
+// transactionID that pays it. The transaction is made separately once the funds are released.
+func (acc *Account) CaptureHold(hold *Hold, amount Money, transactionID string, now time.Time) error {
+	if hold.Expired(now) {
+		return holdExpiredError
+	}
+	if amount.Currency != hold.Amount.Currency || !amount.IsPositive() || amount.Amount > hold.Amount.Amount {
+		return invalidCaptureAmountError
+	}
+
+	if err := acc.releaseHold(hold, HoldCaptured, now); err != nil {
+		return err
+	}
+	hold.Captured = amount
+	hold.TransactionID = transactionID
+
+	return nil
+}
+
+// VoidHold releases hold without paying anything
+func (acc *Account) VoidHold(hold *Hold, now time.Time) error {
+	if hold.Expired(now) {
+		return holdExpiredError
+	}
+
+	return acc.releaseHold(hold, HoldVoided, now)
+}
+
+// ExpireHold releases a hold that wasn't captured or voided before it expired
+func (acc *Account) ExpireHold(hold *Hold, now time.Time) error {
+	if hold.Status == HoldActive && !hold.Expired(now) {
+		return holdNotExpiredError
+	}
+
+	return acc.releaseHold(hold, HoldExpired, now)
+}
+
+// releaseHold gives the funds reserved by an active hold back to the account, closed accounts included
+func (acc *Account) releaseHold(hold *Hold, status HoldStatus, now time.Time) error {
+	if hold.AccountID != acc.ID {
+		return holdAccountMismatchError
+	}
+	if hold.Status != HoldActive {
+		return holdNotActiveError
+	}
+
+	held, err := NewMoney(acc.Held.Amount, acc.Balance.Currency).Sub(hold.Amount)
+	if err != nil {
+		return err
+	}
+	if held.IsNegative() {
+		return holdAccountMismatchError
+	}
+
+	acc.Held = held
+	hold.Status = status
+	hold.ReleasedAt = &now
+
+	return nil
+}
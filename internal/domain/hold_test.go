@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestAccount_PlaceHold(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	closedAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		account     Account
+		amount      Money
+		toAccountID string
+		expiresAt   time.Time
+		want        *Hold
+		wantHeld    Money
+		wantErr     error
+	}{
+		{
+			name:      "first hold",
+			account:   Account{ID: "1", Balance: eur(100)},
+			amount:    eur(60),
+			expiresAt: now.Add(time.Hour),
+			want:      &Hold{AccountID: "1", Amount: eur(60), Status: HoldActive, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+			wantHeld:  eur(60),
+		},
+		{
+			name:        "hold paid into another account, within the overdraft",
+			account:     Account{ID: "1", Balance: eur(100), Overdraft: Overdraft{Limit: eur(500)}, Held: eur(100)},
+			amount:      eur(500),
+			toAccountID: "2",
+			expiresAt:   now.Add(MaxHoldDuration),
+			want:        &Hold{AccountID: "1", ToAccountID: "2", Amount: eur(500), Status: HoldActive, CreatedAt: now, ExpiresAt: now.Add(MaxHoldDuration)},
+			wantHeld:    eur(600),
+		},
+		{
+			name:      "more than what isn't held yet, want holdFundsError",
+			account:   Account{ID: "1", Balance: eur(100), Held: eur(50)},
+			amount:    eur(51),
+			expiresAt: now.Add(time.Hour),
+			wantHeld:  eur(50),
+			wantErr:   holdFundsError,
+		},
+		{
+			name:      "already expired, want invalidHoldExpiryError",
+			account:   Account{ID: "1", Balance: eur(100)},
+			amount:    eur(10),
+			expiresAt: now,
+			wantErr:   invalidHoldExpiryError,
+		},
+		{
+			name:      "expiring too late, want invalidHoldExpiryError",
+			account:   Account{ID: "1", Balance: eur(100)},
+			amount:    eur(10),
+			expiresAt: now.Add(MaxHoldDuration + time.Second),
+			wantErr:   invalidHoldExpiryError,
+		},
+		{
+			name:      "zero amount, want invalidHoldAmountError",
+			account:   Account{ID: "1", Balance: eur(100)},
+			amount:    eur(0),
+			expiresAt: now.Add(time.Hour),
+			wantErr:   invalidHoldAmountError,
+		},
+		{
+			name:      "amount in another currency, want currencyMismatchError",
+			account:   Account{ID: "1", Balance: eur(100)},
+			amount:    NewMoney(10, USD),
+			expiresAt: now.Add(time.Hour),
+			wantErr:   currencyMismatchError,
+		},
+		{
+			name:        "paid into the held account, want sameHoldAccountError",
+			account:     Account{ID: "1", Balance: eur(100)},
+			amount:      eur(10),
+			toAccountID: "1",
+			expiresAt:   now.Add(time.Hour),
+			wantErr:     sameHoldAccountError,
+		},
+		{
+			name:      "closed account, want accountClosedError",
+			account:   Account{ID: "1", Balance: eur(100), DeletedAt: &closedAt},
+			amount:    eur(10),
+			expiresAt: now.Add(time.Hour),
+			wantErr:   accountClosedError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := tt.account
+
+			got, err := acc.PlaceHold(tt.amount, tt.toAccountID, tt.expiresAt, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PlaceHold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(Hold{}, "ID")); diff != "" {
+				t.Errorf("PlaceHold() (-want +got):\n%s", diff)
+			}
+			if acc.Held != tt.wantHeld {
+				t.Errorf("PlaceHold() held = %v, want %v", acc.Held, tt.wantHeld)
+			}
+		})
+	}
+}
+
+func TestAccount_ReleaseHold(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(24 * time.Hour)
+	beforeExpiry := expiresAt.Add(-time.Second)
+
+	tests := []struct {
+		name       string
+		status     HoldStatus
+		release    func(acc *Account, hold *Hold) error
+		wantStatus HoldStatus
+		wantErr    error
+	}{
+		{
+			name:   "partial capture releases the rest",
+			status: HoldActive,
+			release: func(acc *Account, hold *Hold) error {
+				return acc.CaptureHold(hold, eur(30), "t1", beforeExpiry)
+			},
+			wantStatus: HoldCaptured,
+		},
+		{
+			name:   "capture above the held amount, want invalidCaptureAmountError",
+			status: HoldActive,
+			release: func(acc *Account, hold *Hold) error {
+				return acc.CaptureHold(hold, eur(41), "t1", beforeExpiry)
+			},
+			wantStatus: HoldActive,
+			wantErr:    invalidCaptureAmountError,
+		},
+		{
+			name:   "capture once expired, want holdExpiredError",
+			status: HoldActive,
+			release: func(acc *Account, hold *Hold) error {
+				return acc.CaptureHold(hold, eur(40), "t1", expiresAt)
+			},
+			wantStatus: HoldActive,
+			wantErr:    holdExpiredError,
+		},
+		{
+			name:   "void",
+			status: HoldActive,
+			release: func(acc *Account, hold *Hold) error {
+				return acc.VoidHold(hold, beforeExpiry)
+			},
+			wantStatus: HoldVoided,
+		},
+		{
+			name:   "void captured hold, want holdNotActiveError",
+			status: HoldCaptured,
+			release: func(acc *Account, hold *Hold) error {
+				return acc.VoidHold(hold, beforeExpiry)
+			},
+			wantStatus: HoldCaptured,
+			wantErr:    holdNotActiveError,
+		},
+		{
+			name:   "expire",
+			status: HoldActive,
+			release: func(acc *Account, hold *Hold) error {
+				return acc.ExpireHold(hold, expiresAt)
+			},
+			wantStatus: HoldExpired,
+		},
+		{
+			name:   "expire before the expiry, want holdNotExpiredError",
+			status: HoldActive,
+			release: func(acc *Account, hold *Hold) error {
+				return acc.ExpireHold(hold, beforeExpiry)
+			},
+			wantStatus: HoldActive,
+			wantErr:    holdNotExpiredError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := &Account{ID: "1", Balance: eur(100), Held: eur(50)}
+			hold := &Hold{ID: "h1", AccountID: "1", Amount: eur(40), Status: tt.status, CreatedAt: createdAt, ExpiresAt: expiresAt}
+
+			if err := tt.release(acc, hold); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("release error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if hold.Status != tt.wantStatus {
+				t.Errorf("hold status = %v, want %v", hold.Status, tt.wantStatus)
+			}
+
+			wantHeld, wantReleased := eur(10), true
+			if tt.wantErr != nil {
+				wantHeld, wantReleased = eur(50), false
+			}
+			if acc.Held != wantHeld || (hold.ReleasedAt != nil) != wantReleased {
+				t.Errorf("held = %v and released at %v, want %v held and released %v", acc.Held, hold.ReleasedAt, wantHeld, wantReleased)
+			}
+			if acc.Balance != eur(100) {
+				t.Errorf("balance = %v, want it untouched", acc.Balance)
+			}
+		})
+	}
+
+	t.Run("capture records what was paid", func(t *testing.T) {
+		acc := &Account{ID: "1", Balance: eur(100), Held: eur(40)}
+		hold := &Hold{ID: "h1", AccountID: "1", Amount: eur(40), Status: HoldActive, CreatedAt: createdAt, ExpiresAt: expiresAt}
+
+		if err := acc.CaptureHold(hold, eur(25), "t1", beforeExpiry); err != nil {
+			t.Fatalf("CaptureHold() error = %v", err)
+		}
+
+		want := &Hold{
+			ID:            "h1",
+			AccountID:     "1",
+			Amount:        eur(40),
+			Status:        HoldCaptured,
+			Captured:      eur(25),
+			TransactionID: "t1",
+			CreatedAt:     createdAt,
+			ExpiresAt:     expiresAt,
+			ReleasedAt:    &beforeExpiry,
+		}
+		if diff := cmp.Diff(want, hold); diff != "" {
+			t.Errorf("CaptureHold() (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"math/big"
+	"slices"
+	"time"
+
+	"http/internal/tberrors"
+)
+
+// RatePlan is a named set of yearly interest rates paid on savings balances in one currency. Balances are split in
+// bands at the start of every tier and each band earns the rate of its tier, a flat plan has a single tier from zero.
+type RatePlan struct {
+	Name     string
+	Currency Currency
+	// Tiers are sorted by From, the first one starts at zero
+	Tiers []RateTier
+}
+
+// RateTier pays Rate, a yearly decimal fraction such as "0.02", on the part of the balance above From up to the start
+// of the next tier
+type RateTier struct {
+	From Money
+	Rate string
+}
+
+var missingRatePlanNameError = tberrors.NewValidationError("missing_plan_name", "rate plan name is required", "name")
+var missingRateTiersError = tberrors.NewValidationError("missing_tiers", "rate plan needs at least one tier", "tiers")
+var invalidRateTiersError = tberrors.NewValidationError("invalid_tiers", "tiers must start at zero and be sorted by increasing balance", "tiers")
+
+func NewRatePlan(name string, currency Currency, tiers []RateTier) (RatePlan, error) {
+	if name == "" {
+		return RatePlan{}, missingRatePlanNameError
+	}
+	if !currency.Valid() {
+		return RatePlan{}, invalidCurrencyError
+	}
+	if len(tiers) == 0 {
+		return RatePlan{}, missingRateTiersError
+	}
+
+	tiers = slices.Clone(tiers)
+	for i, tier := range tiers {
+		if tier.From.Currency != currency {
+			return RatePlan{}, currencyMismatchError
+		}
+		if (i == 0 && !tier.From.IsZero()) || (i > 0 && tier.From.Amount <= tiers[i-1].From.Amount) {
+			return RatePlan{}, invalidRateTiersError
+		}
+
+		rate, err := ParseInterestRate(tier.Rate)
+		if err != nil {
+			return RatePlan{}, err
+		}
+		tiers[i].Rate = rate
+	}
+
+	return RatePlan{Name: name, Currency: currency, Tiers: tiers}, nil
+}
+
+// dailyInterest is the interest a day ending with balance earns in minor units, nothing for balances below zero
+func (plan RatePlan) dailyInterest(balance Money) *big.Rat {
+	interest := new(big.Rat)
+	for i, tier := range plan.Tiers {
+		if balance.Amount <= tier.From.Amount {
+			break
+		}
+
+		upTo := balance.Amount
+		if i+1 < len(plan.Tiers) && plan.Tiers[i+1].From.Amount < upTo {
+			upTo = plan.Tiers[i+1].From.Amount
+		}
+
+		rate, ok := new(big.Rat).SetString(tier.Rate)
+		if !ok {
+			continue
+		}
+		band := new(big.Rat).SetInt64(upTo - tier.From.Amount)
+		interest.Add(interest, band.Mul(band, rate))
+	}
+
+	return interest.Quo(interest, big.NewRat(daysPerYear, 1))
+}
+
+// SavingsInterest is the rate plan of a savings account together with the interest it earned, the zero value earns
+// nothing.
+type SavingsInterest struct {
+	// Plan is the name of the RatePlan the account earns interest with, empty once it's taken off its plan
+	Plan string
+	// Accrued is the interest earned and not paid yet, a decimal number of minor units of the account currency
+	Accrued string
+	// AccruedThrough is the last day interest accrued for, as midnight UTC. It's zero until the account is first put
+	// on a plan and from then on the account accrues every day.
+	AccruedThrough time.Time
+}
+
+var notSavingsAccountError = tberrors.NewConflictError("not_savings_account", "only savings accounts earn interest", "account_id")
+var notEarningInterestError = tberrors.NewConflictError("not_earning_interest", "account is not on a rate plan", "account_id")
+var ratePlanMismatchError = tberrors.NewInternalError("rate_plan_mismatch", "rate plan is not the plan of the account")
+
+// SetRatePlan puts a savings account on plan, a plan without a name takes it off its plan. Interest starts accruing
+// on the day of now the first time the account is put on a plan, interest accrued before is kept and paid as usual.
+func (acc *Account) SetRatePlan(plan RatePlan, now time.Time) error {
+	if acc.Closed() {
+		return accountClosedError
+	}
+	if acc.Type != Savings {
+		return notSavingsAccountError
+	}
+	if plan.Name != "" && plan.Currency != acc.Balance.Currency {
+		return currencyMismatchError
+	}
+
+	acc.Interest.Plan = plan.Name
+	if acc.Interest.AccruedThrough.IsZero() {
+		acc.Interest.AccruedThrough = startOfDay(now).AddDate(0, 0, -1)
+	}
+
+	return nil
+}
+
+// EarnsInterest tells whether the account accrues savings interest, it does from the day it was first put on a rate
+// plan until it's closed
+func (acc *Account) EarnsInterest() bool {
+	return !acc.Closed() && !acc.Interest.AccruedThrough.IsZero()
+}
+
+// AccrueSavingsInterest accrues the interest of the day after AccruedThrough with plan, the plan of the account or
+// the zero RatePlan when it has none. endOfDay is the balance the account ended that day with.
+func (acc *Account) AccrueSavingsInterest(plan RatePlan, endOfDay Money) error {
+	if !acc.EarnsInterest() {
+		return notEarningInterestError
+	}
+	if plan.Name != acc.Interest.Plan {
+		return ratePlanMismatchError
+	}
+	if endOfDay.Currency != acc.Balance.Currency {
+		return currencyMismatchError
+	}
+
+	accrued, err := parseAccrued(acc.Interest.Accrued)
+	if err != nil {
+		return err
+	}
+
+	acc.Interest.Accrued = formatAccrued(accrued.Add(accrued, plan.dailyInterest(endOfDay)))
+	acc.Interest.AccruedThrough = acc.Interest.AccruedThrough.AddDate(0, 0, 1)
+
+	return nil
+}
+
+// PostSavingsInterest capitalizes the accrued interest rounded half to even to minor units. It returns the
+// transaction made at postedAt with AccruedThrough as its value date, or nil when the accrued interest rounds to nothing, and the accrual starts again from
+// zero either way.
+func (acc *Account) PostSavingsInterest(postedAt time.Time) (*Transaction, error) {
+	if !acc.EarnsInterest() {
+		return nil, notEarningInterestError
+	}
+
+	accrued, err := parseAccrued(acc.Interest.Accrued)
+	if err != nil {
+		return nil, err
+	}
+
+	rounded := roundHalfEven(accrued)
+	if !rounded.IsInt64() {
+		return nil, amountOutOfRangeError
+	}
+
+	acc.Interest.Accrued = ""
+	if rounded.Sign() == 0 {
+		return nil, nil
+	}
+
+	amount := NewMoney(rounded.Int64(), acc.Balance.Currency)
+	balance, err := acc.Balance.Add(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := NewInterest(acc.ID, amount, acc.Interest.AccruedThrough, postedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	acc.Balance = balance
+
+	return transaction, nil
+}
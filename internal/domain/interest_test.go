@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestNewRatePlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		tiers   []RateTier
+		wantErr error
+	}{
+		{
+			name:  "tiered",
+			tiers: []RateTier{{From: eur(0), Rate: "0.01"}, {From: eur(1000000), Rate: "0.02"}},
+		},
+		{
+			name:    "first tier above zero, want invalidRateTiersError",
+			tiers:   []RateTier{{From: eur(100), Rate: "0.01"}},
+			wantErr: invalidRateTiersError,
+		},
+		{
+			name:    "tiers out of order, want invalidRateTiersError",
+			tiers:   []RateTier{{From: eur(0), Rate: "0.01"}, {From: eur(500), Rate: "0.02"}, {From: eur(500), Rate: "0.03"}},
+			wantErr: invalidRateTiersError,
+		},
+		{
+			name:    "tier in another currency, want currencyMismatchError",
+			tiers:   []RateTier{{From: NewMoney(0, USD), Rate: "0.01"}},
+			wantErr: currencyMismatchError,
+		},
+		{
+			name:    "rate as a percentage, want invalidInterestRateError",
+			tiers:   []RateTier{{From: eur(0), Rate: "2"}},
+			wantErr: invalidInterestRateError,
+		},
+		{
+			name:    "no tiers, want missingRateTiersError",
+			wantErr: missingRateTiersError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRatePlan("easy-saver", EUR, tt.tiers); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewRatePlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccount_SetRatePlan(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
+	plan := RatePlan{Name: "easy-saver", Currency: EUR, Tiers: []RateTier{{From: eur(0), Rate: "0.02"}}}
+
+	tests := []struct {
+		name    string
+		account Account
+		plan    RatePlan
+		want    SavingsInterest
+		wantErr error
+	}{
+		{
+			name:    "first plan, accrues from today",
+			account: Account{Balance: eur(100), Type: Savings},
+			plan:    plan,
+			want:    SavingsInterest{Plan: "easy-saver", AccruedThrough: time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "off its plan, keeps accruing what's earned",
+			account: Account{Balance: eur(100), Type: Savings, Interest: SavingsInterest{Plan: "easy-saver", Accrued: "1.5", AccruedThrough: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}},
+			want:    SavingsInterest{Accrued: "1.5", AccruedThrough: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "checking account, want notSavingsAccountError",
+			account: Account{Balance: eur(100), Type: Checking},
+			plan:    plan,
+			wantErr: notSavingsAccountError,
+		},
+		{
+			name:    "plan in another currency, want currencyMismatchError",
+			account: Account{Balance: NewMoney(100, USD), Type: Savings},
+			plan:    plan,
+			wantErr: currencyMismatchError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := tt.account
+
+			if err := acc.SetRatePlan(tt.plan, now); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetRatePlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, acc.Interest); diff != "" {
+				t.Errorf("SetRatePlan() interest (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAccount_SavingsInterest(t *testing.T) {
+	accruedThrough := time.Date(2025, 1, 28, 0, 0, 0, 0, time.UTC)
+	// interest is made when it's posted, some days after the last one it was accrued for, which is its value date
+	valueDate := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	postedAt := time.Date(2025, 2, 3, 9, 30, 0, 0, time.UTC)
+	// 3.65% a year is a hundredth of a percent a day, 7.3% two hundredths
+	flat := RatePlan{Name: "flat", Currency: EUR, Tiers: []RateTier{{From: eur(0), Rate: "0.0365"}}}
+	tiered := RatePlan{Name: "tiered", Currency: EUR, Tiers: []RateTier{{From: eur(0), Rate: "0.0365"}, {From: eur(100000), Rate: "0.073"}}}
+
+	tests := []struct {
+		name         string
+		plan         RatePlan
+		endOfDays    []Money
+		wantAccrued  string
+		wantInterest *Transaction
+		wantBalance  Money
+	}{
+		{
+			name:         "every band of a tiered plan earns its own rate, nothing below zero",
+			plan:         tiered,
+			endOfDays:    []Money{eur(150000), eur(50000), eur(-100)},
+			wantAccrued:  "25.000000000",
+			wantInterest: &Transaction{CreatedAt: postedAt, ToAccountID: ptr("1"), Amount: eur(25), Type: Interest, ValueDate: &valueDate},
+			wantBalance:  eur(10025),
+		},
+		{
+			name:         "half a cent rounds to the even cent below",
+			plan:         flat,
+			endOfDays:    []Money{eur(12500), eur(12500), eur(0)},
+			wantAccrued:  "2.500000000",
+			wantInterest: &Transaction{CreatedAt: postedAt, ToAccountID: ptr("1"), Amount: eur(2), Type: Interest, ValueDate: &valueDate},
+			wantBalance:  eur(10002),
+		},
+		{
+			name:         "half a cent rounds to the even cent above",
+			plan:         flat,
+			endOfDays:    []Money{eur(12500), eur(12500), eur(10000)},
+			wantAccrued:  "3.500000000",
+			wantInterest: &Transaction{CreatedAt: postedAt, ToAccountID: ptr("1"), Amount: eur(4), Type: Interest, ValueDate: &valueDate},
+			wantBalance:  eur(10004),
+		},
+		{
+			name:        "off its plan, nothing earned",
+			endOfDays:   []Money{eur(10000), eur(10000), eur(10000)},
+			wantAccrued: "",
+			wantBalance: eur(10000),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := Account{ID: "1", Balance: eur(10000), Type: Savings, Interest: SavingsInterest{Plan: tt.plan.Name, AccruedThrough: accruedThrough}}
+
+			for _, endOfDay := range tt.endOfDays {
+				if err := acc.AccrueSavingsInterest(tt.plan, endOfDay); err != nil {
+					t.Fatalf("AccrueSavingsInterest() error = %v", err)
+				}
+			}
+			if acc.Interest.Accrued != tt.wantAccrued {
+				t.Errorf("AccrueSavingsInterest() accrued = %q, want %q", acc.Interest.Accrued, tt.wantAccrued)
+			}
+
+			interest, err := acc.PostSavingsInterest(postedAt)
+			if err != nil {
+				t.Fatalf("PostSavingsInterest() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantInterest, interest, cmpopts.IgnoreFields(Transaction{}, "ID")); diff != "" {
+				t.Errorf("PostSavingsInterest() (-want +got):\n%s", diff)
+			}
+			if acc.Balance != tt.wantBalance || acc.Interest.Accrued != "" {
+				t.Errorf("PostSavingsInterest() balance = %v and accrued = %q, want %v and nothing", acc.Balance, acc.Interest.Accrued, tt.wantBalance)
+			}
+		})
+	}
+
+	t.Run("another plan than the account's, want ratePlanMismatchError", func(t *testing.T) {
+		acc := Account{ID: "1", Balance: eur(10000), Type: Savings, Interest: SavingsInterest{Plan: "flat", AccruedThrough: accruedThrough}}
+
+		if err := acc.AccrueSavingsInterest(tiered, eur(10000)); !errors.Is(err, ratePlanMismatchError) {
+			t.Errorf("AccrueSavingsInterest() error = %v, wantErr %v", err, ratePlanMismatchError)
+		}
+	})
+}
@@ -0,0 +1,274 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lithammer/shortuuid/v4"
+	"http/internal/tberrors"
+)
+
+// System ledger accounts are the bank's own side of every movement, they never hold customer money.
+const (
+	systemAccountPrefix = "system:"
+
+	// CashInAccountID is debited with every deposit
+	CashInAccountID = systemAccountPrefix + "cash-in"
+	// CashOutAccountID is credited with every withdrawal
+	CashOutAccountID = systemAccountPrefix + "cash-out"
+	// FXPositionAccountID takes the source currency and gives the destination currency of converted transfers
+	FXPositionAccountID = systemAccountPrefix + "fx-position"
+	// InterestIncomeAccountID is credited with the interest charged on overdrawn accounts
+	InterestIncomeAccountID = systemAccountPrefix + "interest-income"
+	// InterestExpenseAccountID is debited with the interest paid on savings accounts
+	InterestExpenseAccountID = systemAccountPrefix + "interest-expense"
+	// FeeRevenueAccountID is credited with the fees charged on transactions
+	FeeRevenueAccountID = systemAccountPrefix + "fee-revenue"
+)
+
+func IsSystemAccount(accountID string) bool {
+	return strings.HasPrefix(accountID, systemAccountPrefix)
+}
+
+type EntrySide string
+
+const (
+	Debit  EntrySide = "debit"
+	Credit EntrySide = "credit"
+)
+
+func (s EntrySide) String() string {
+	return string(s)
+}
+
+// JournalLine moves Amount in or out of a ledger account. Customer accounts are credited when they receive money
+// and debited when money leaves them.
+type JournalLine struct {
+	AccountID string
+	Side      EntrySide
+	Amount    Money
+}
+
+// JournalEntry is the ledger record of a Transaction, its debits always equal its credits.
+type JournalEntry struct {
+	ID            string
+	TransactionID string
+	CreatedAt     time.Time
+	Lines         []JournalLine
+}
+
+func NewJournalEntry(transaction *Transaction) (*JournalEntry, error) {
+	entry := &JournalEntry{
+		ID:            shortuuid.New(),
+		TransactionID: transaction.ID,
+		CreatedAt:     transaction.CreatedAt,
+	}
+
+	switch transaction.Type {
+	case Deposit:
+		if transaction.ToAccountID == nil {
+			return nil, invalidToAccountError
+		}
+		entry.Lines = []JournalLine{
+			{AccountID: CashInAccountID, Side: Debit, Amount: transaction.Amount},
+			{AccountID: *transaction.ToAccountID, Side: Credit, Amount: transaction.Amount},
+		}
+	case Withdrawal:
+		if transaction.FromAccountID == nil {
+			return nil, invalidFromAccountError
+		}
+		entry.Lines = []JournalLine{
+			{AccountID: *transaction.FromAccountID, Side: Debit, Amount: transaction.Amount},
+			{AccountID: CashOutAccountID, Side: Credit, Amount: transaction.Amount},
+		}
+	case OverdraftInterest:
+		if transaction.FromAccountID == nil {
+			return nil, invalidFromAccountError
+		}
+		entry.Lines = []JournalLine{
+			{AccountID: *transaction.FromAccountID, Side: Debit, Amount: transaction.Amount},
+			{AccountID: InterestIncomeAccountID, Side: Credit, Amount: transaction.Amount},
+		}
+	case Fee:
+		if transaction.FromAccountID == nil {
+			return nil, invalidFromAccountError
+		}
+		entry.Lines = []JournalLine{
+			{AccountID: *transaction.FromAccountID, Side: Debit, Amount: transaction.Amount},
+			{AccountID: FeeRevenueAccountID, Side: Credit, Amount: transaction.Amount},
+		}
+	case Interest:
+		if transaction.ToAccountID == nil {
+			return nil, invalidToAccountError
+		}
+		entry.Lines = []JournalLine{
+			{AccountID: InterestExpenseAccountID, Side: Debit, Amount: transaction.Amount},
+			{AccountID: *transaction.ToAccountID, Side: Credit, Amount: transaction.Amount},
+		}
+	case Transfer:
+		if transaction.FromAccountID == nil {
+			return nil, invalidFromAccountError
+		}
+		if transaction.ToAccountID == nil {
+			return nil, invalidToAccountError
+		}
+		entry.Lines = []JournalLine{
+			{AccountID: *transaction.FromAccountID, Side: Debit, Amount: transaction.Amount},
+			{AccountID: *transaction.ToAccountID, Side: Credit, Amount: transaction.Amount},
+		}
+		// a converted transfer balances each currency through the fx position account
+		if transaction.Conversion != nil {
+			entry.Lines = []JournalLine{
+				{AccountID: *transaction.FromAccountID, Side: Debit, Amount: transaction.Amount},
+				{AccountID: FXPositionAccountID, Side: Credit, Amount: transaction.Amount},
+				{AccountID: FXPositionAccountID, Side: Debit, Amount: transaction.Conversion.DestinationAmount},
+				{AccountID: *transaction.ToAccountID, Side: Credit, Amount: transaction.Conversion.DestinationAmount},
+			}
+		}
+	case Reversal:
+		if transaction.FromAccountID == nil && transaction.ToAccountID == nil {
+			return nil, invalidFromAccountError
+		}
+		entry.Lines = reversalLines(transaction)
+	default:
+		return nil, invalidTransactionType
+	}
+
+	return entry.validate()
+}
+
+// reversalLines undo the lines of the parent of a reversal, the system account it went through takes the money back
+func reversalLines(transaction *Transaction) []JournalLine {
+	from, to := CashOutAccountID, CashInAccountID
+	if transaction.FromAccountID != nil {
+		from = *transaction.FromAccountID
+	}
+	if transaction.ToAccountID != nil {
+		to = *transaction.ToAccountID
+	}
+	if transaction.Conversion != nil {
+		return []JournalLine{
+			{AccountID: from, Side: Debit, Amount: transaction.Amount},
+			{AccountID: FXPositionAccountID, Side: Credit, Amount: transaction.Amount},
+			{AccountID: FXPositionAccountID, Side: Debit, Amount: transaction.Conversion.DestinationAmount},
+			{AccountID: to, Side: Credit, Amount: transaction.Conversion.DestinationAmount},
+		}
+	}
+
+	return []JournalLine{
+		{AccountID: from, Side: Debit, Amount: transaction.Amount},
+		{AccountID: to, Side: Credit, Amount: transaction.Amount},
+	}
+}
+
+var tooFewJournalLinesError = tberrors.NewInternalError("invalid_journal_entry", "journal entry needs at least two lines")
+var invalidJournalLineError = tberrors.NewInternalError("invalid_journal_entry", "journal line needs an account, a side and a positive amount")
+var unbalancedJournalEntryError = tberrors.NewInternalError("unbalanced_journal_entry", "journal entry debits do not equal its credits")
+
+func (entry *JournalEntry) validate() (*JournalEntry, error) {
+	if len(entry.Lines) < 2 {
+		return nil, tooFewJournalLinesError
+	}
+
+	// an entry balances in every currency it touches
+	balances := make(map[Currency]Money)
+	for _, line := range entry.Lines {
+		if line.AccountID == "" || !line.Amount.IsPositive() || !line.Amount.Currency.Valid() {
+			return nil, invalidJournalLineError
+		}
+
+		balance, ok := balances[line.Amount.Currency]
+		if !ok {
+			balance = NewMoney(0, line.Amount.Currency)
+		}
+
+		var err error
+		switch line.Side {
+		case Debit:
+			balance, err = balance.Add(line.Amount)
+		case Credit:
+			balance, err = balance.Sub(line.Amount)
+		default:
+			return nil, invalidJournalLineError
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		balances[line.Amount.Currency] = balance
+	}
+
+	for _, balance := range balances {
+		if !balance.IsZero() {
+			return nil, unbalancedJournalEntryError
+		}
+	}
+
+	return entry, nil
+}
+
+// LedgerAccountTotal sums every journal line posted to a ledger account in one currency. System accounts have one
+// total per currency they have seen, customer accounts only ever hold their own currency.
+type LedgerAccountTotal struct {
+	AccountID string
+	Currency  Currency
+	Debits    Money
+	Credits   Money
+}
+
+func NewLedgerAccountTotal(accountID string, currency Currency) LedgerAccountTotal {
+	return LedgerAccountTotal{
+		AccountID: accountID,
+		Currency:  currency,
+		Debits:    NewMoney(0, currency),
+		Credits:   NewMoney(0, currency),
+	}
+}
+
+// Balance is the money held by a customer account according to the ledger.
+func (total LedgerAccountTotal) Balance() (Money, error) {
+	return total.Credits.Sub(total.Debits)
+}
+
+func (total *LedgerAccountTotal) Post(line JournalLine) error {
+	var err error
+	if line.Side == Debit {
+		total.Debits, err = total.Debits.Add(line.Amount)
+	} else {
+		total.Credits, err = total.Credits.Add(line.Amount)
+	}
+
+	return err
+}
+
+// BalanceMismatch reports a customer account whose stored balance disagrees with the ledger.
+type BalanceMismatch struct {
+	AccountID      string
+	LedgerBalance  Money
+	AccountBalance Money
+}
+
+// CurrencyTotal sums every ledger account in one currency.
+type CurrencyTotal struct {
+	Currency Currency
+	Debits   Money
+	Credits  Money
+}
+
+type TrialBalance struct {
+	Accounts   []LedgerAccountTotal
+	Totals     []CurrencyTotal
+	Mismatches []BalanceMismatch
+}
+
+// Balanced is true when the ledger debits equal its credits in every currency and every account agrees with the
+// ledger.
+func (tb TrialBalance) Balanced() bool {
+	for _, total := range tb.Totals {
+		if total.Debits != total.Credits {
+			return false
+		}
+	}
+
+	return len(tb.Mismatches) == 0
+}
@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestNewJournalEntry(t *testing.T) {
+	fromAccountID := "1"
+	toAccountID := "2"
+
+	tests := []struct {
+		name        string
+		transaction *Transaction
+		want        []JournalLine
+		wantErr     error
+	}{
+		{
+			name: "deposit debits cash in",
+			transaction: &Transaction{
+				ToAccountID: &toAccountID,
+				Amount:      eur(100),
+				Type:        Deposit,
+			},
+			want: []JournalLine{
+				{AccountID: CashInAccountID, Side: Debit, Amount: eur(100)},
+				{AccountID: toAccountID, Side: Credit, Amount: eur(100)},
+			},
+		},
+		{
+			name: "withdrawal credits cash out",
+			transaction: &Transaction{
+				FromAccountID: &fromAccountID,
+				Amount:        eur(100),
+				Type:          Withdrawal,
+			},
+			want: []JournalLine{
+				{AccountID: fromAccountID, Side: Debit, Amount: eur(100)},
+				{AccountID: CashOutAccountID, Side: Credit, Amount: eur(100)},
+			},
+		},
+		{
+			name: "interest debits interest expense",
+			transaction: &Transaction{
+				ToAccountID: &toAccountID,
+				Amount:      eur(100),
+				Type:        Interest,
+			},
+			want: []JournalLine{
+				{AccountID: InterestExpenseAccountID, Side: Debit, Amount: eur(100)},
+				{AccountID: toAccountID, Side: Credit, Amount: eur(100)},
+			},
+		},
+		{
+			name: "transfer moves between customer accounts",
+			transaction: &Transaction{
+				FromAccountID: &fromAccountID,
+				ToAccountID:   &toAccountID,
+				Amount:        eur(100),
+				Type:          Transfer,
+			},
+			want: []JournalLine{
+				{AccountID: fromAccountID, Side: Debit, Amount: eur(100)},
+				{AccountID: toAccountID, Side: Credit, Amount: eur(100)},
+			},
+		},
+		{
+			name: "converted transfer balances each currency through the fx position",
+			transaction: &Transaction{
+				FromAccountID: &fromAccountID,
+				ToAccountID:   &toAccountID,
+				Amount:        eur(100),
+				Type:          Transfer,
+				Conversion:    &FXConversion{DestinationAmount: NewMoney(108, USD), Rate: "1.08"},
+			},
+			want: []JournalLine{
+				{AccountID: fromAccountID, Side: Debit, Amount: eur(100)},
+				{AccountID: FXPositionAccountID, Side: Credit, Amount: eur(100)},
+				{AccountID: FXPositionAccountID, Side: Debit, Amount: NewMoney(108, USD)},
+				{AccountID: toAccountID, Side: Credit, Amount: NewMoney(108, USD)},
+			},
+		},
+		{
+			name: "reversed deposit credits cash in back",
+			transaction: &Transaction{
+				FromAccountID: &toAccountID,
+				Amount:        eur(40),
+				Type:          Reversal,
+			},
+			want: []JournalLine{
+				{AccountID: toAccountID, Side: Debit, Amount: eur(40)},
+				{AccountID: CashInAccountID, Side: Credit, Amount: eur(40)},
+			},
+		},
+		{
+			name: "reversed withdrawal debits cash out back",
+			transaction: &Transaction{
+				ToAccountID: &fromAccountID,
+				Amount:      eur(40),
+				Type:        Reversal,
+			},
+			want: []JournalLine{
+				{AccountID: CashOutAccountID, Side: Debit, Amount: eur(40)},
+				{AccountID: fromAccountID, Side: Credit, Amount: eur(40)},
+			},
+		},
+		{
+			name: "reversed converted transfer goes back through the fx position",
+			transaction: &Transaction{
+				FromAccountID: &toAccountID,
+				ToAccountID:   &fromAccountID,
+				Amount:        NewMoney(108, USD),
+				Type:          Reversal,
+				Conversion:    &FXConversion{DestinationAmount: eur(100), Rate: "1.08"},
+			},
+			want: []JournalLine{
+				{AccountID: toAccountID, Side: Debit, Amount: NewMoney(108, USD)},
+				{AccountID: FXPositionAccountID, Side: Credit, Amount: NewMoney(108, USD)},
+				{AccountID: FXPositionAccountID, Side: Debit, Amount: eur(100)},
+				{AccountID: fromAccountID, Side: Credit, Amount: eur(100)},
+			},
+		},
+		{
+			name: "reversal without accounts, want invalidFromAccountError",
+			transaction: &Transaction{
+				Amount: eur(100),
+				Type:   Reversal,
+			},
+			wantErr: invalidFromAccountError,
+		},
+		{
+			name: "deposit without to account, want invalidToAccountError",
+			transaction: &Transaction{
+				Amount: eur(100),
+				Type:   Deposit,
+			},
+			wantErr: invalidToAccountError,
+		},
+		{
+			name: "zero amount, want invalidJournalLineError",
+			transaction: &Transaction{
+				ToAccountID: &toAccountID,
+				Amount:      eur(0),
+				Type:        Deposit,
+			},
+			wantErr: invalidJournalLineError,
+		},
+		{
+			name: "unknown type, want invalidTransactionType",
+			transaction: &Transaction{
+				Amount: eur(100),
+				Type:   "unknown",
+			},
+			wantErr: invalidTransactionType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewJournalEntry(tt.transaction)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewJournalEntry() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got == nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got.Lines, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("NewJournalEntry() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJournalEntry_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []JournalLine
+		wantErr error
+	}{
+		{
+			name: "balanced entry",
+			lines: []JournalLine{
+				{AccountID: "1", Side: Debit, Amount: eur(100)},
+				{AccountID: "2", Side: Credit, Amount: eur(60)},
+				{AccountID: "3", Side: Credit, Amount: eur(40)},
+			},
+		},
+		{
+			name: "single line, want tooFewJournalLinesError",
+			lines: []JournalLine{
+				{AccountID: "1", Side: Debit, Amount: eur(100)},
+			},
+			wantErr: tooFewJournalLinesError,
+		},
+		{
+			name: "debits differ from credits, want unbalancedJournalEntryError",
+			lines: []JournalLine{
+				{AccountID: "1", Side: Debit, Amount: eur(100)},
+				{AccountID: "2", Side: Credit, Amount: eur(99)},
+			},
+			wantErr: unbalancedJournalEntryError,
+		},
+		{
+			name: "same amounts in different currencies, want unbalancedJournalEntryError",
+			lines: []JournalLine{
+				{AccountID: "1", Side: Debit, Amount: eur(100)},
+				{AccountID: "2", Side: Credit, Amount: NewMoney(100, USD)},
+			},
+			wantErr: unbalancedJournalEntryError,
+		},
+		{
+			name: "unknown side, want invalidJournalLineError",
+			lines: []JournalLine{
+				{AccountID: "1", Side: "sideways", Amount: eur(100)},
+				{AccountID: "2", Side: Credit, Amount: eur(100)},
+			},
+			wantErr: invalidJournalLineError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &JournalEntry{ID: "1", Lines: tt.lines}
+
+			if _, err := entry.validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
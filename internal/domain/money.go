@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"http/internal/tberrors"
+)
+
+// Currency is an ISO 4217 currency code.
+type Currency string
+
+const (
+	EUR Currency = "EUR"
+	USD Currency = "USD"
+	GBP Currency = "GBP"
+	CHF Currency = "CHF"
+	JPY Currency = "JPY"
+
+	// DefaultCurrency is used for accounts opened without naming one
+	DefaultCurrency = EUR
+)
+
+// currencyExponents holds the number of minor unit digits of every supported currency
+var currencyExponents = map[Currency]int{
+	EUR: 2,
+	USD: 2,
+	GBP: 2,
+	CHF: 2,
+	JPY: 0,
+}
+
+var invalidCurrencyError = tberrors.NewValidationError("invalid_currency", "unsupported currency", "currency")
+
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(code))
+	if !currency.Valid() {
+		return "", invalidCurrencyError
+	}
+
+	return currency, nil
+}
+
+func (c Currency) Valid() bool {
+	_, ok := currencyExponents[c]
+	return ok
+}
+
+// Exponent is the number of minor unit digits, 2 for EUR where 1 euro is 100 cents
+func (c Currency) Exponent() int {
+	return currencyExponents[c]
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// Money is an amount in minor units of a currency, 12.34 EUR is Money{Amount: 1234, Currency: EUR}. Arithmetic is
+// checked, mixing currencies or overflowing int64 returns an error instead of a wrong amount.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+func NewMoney(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+var invalidAmountFormatError = tberrors.NewValidationError("invalid_amount", "amount must be a decimal number such as 12.34", "amount")
+var amountPrecisionError = tberrors.NewValidationError("invalid_amount", "amount has more decimal places than its currency allows", "amount")
+var amountOutOfRangeError = tberrors.NewValidationError("amount_out_of_range", "amount is out of range", "amount")
+var currencyMismatchError = tberrors.NewValidationError("currency_mismatch", "amounts are in different currencies", "currency")
+
+// ParseMoney reads a decimal amount such as "12.34" or "-5" in the given currency, it never goes through floats.
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, invalidCurrencyError
+	}
+
+	whole, fraction, negative, err := splitDecimal(amount)
+	if err != nil {
+		return Money{}, err
+	}
+
+	exponent := currency.Exponent()
+	if len(fraction) > exponent {
+		return Money{}, amountPrecisionError
+	}
+
+	minorUnits, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", exponent-len(fraction)), 10, 64)
+	if err != nil {
+		return Money{}, amountOutOfRangeError
+	}
+
+	if negative {
+		minorUnits = -minorUnits
+	}
+
+	return Money{Amount: minorUnits, Currency: currency}, nil
+}
+
+// Amount is an amount of money a request moves. Requests may leave its currency out, it's then the currency of the
+// account the amount is taken from or paid into.
+type Amount interface {
+	// In reads the amount in currency unless it's in a currency of its own
+	In(currency Currency) (Money, error)
+}
+
+// In is m, it's already in a currency
+func (m Money) In(Currency) (Money, error) {
+	return m, nil
+}
+
+// DecimalAmount is an amount a request wrote without a currency, such as "12.34". It can only be read once the
+// currency is known, as that tells how many decimal places it may have.
+type DecimalAmount string
+
+// ParseDecimalAmount checks amount is a decimal number, its decimal places are checked once its currency is known
+func ParseDecimalAmount(amount string) (DecimalAmount, error) {
+	if _, _, _, err := splitDecimal(amount); err != nil {
+		return "", err
+	}
+
+	return DecimalAmount(amount), nil
+}
+
+func (a DecimalAmount) In(currency Currency) (Money, error) {
+	return ParseMoney(string(a), currency)
+}
+
+// splitDecimal splits a decimal such as "-12.34" into its whole and fractional digits
+func splitDecimal(amount string) (whole, fraction string, negative bool, err error) {
+	digits, negative := strings.CutPrefix(amount, "-")
+	whole, fraction, hasFraction := strings.Cut(digits, ".")
+	if !isDigits(whole) || (hasFraction && !isDigits(fraction)) {
+		return "", "", false, invalidAmountFormatError
+	}
+
+	return whole, fraction, negative, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, currencyMismatchError
+	}
+
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, amountOutOfRangeError
+	}
+
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, currencyMismatchError
+	}
+
+	if (other.Amount < 0 && m.Amount > math.MaxInt64+other.Amount) ||
+		(other.Amount > 0 && m.Amount < math.MinInt64+other.Amount) {
+		return Money{}, amountOutOfRangeError
+	}
+
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Neg() (Money, error) {
+	return Money{Currency: m.Currency}.Sub(m)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// String formats the amount as a decimal with the currency's minor unit digits, "12.34" or "-0.50"
+func (m Money) String() string {
+	var sign string
+	magnitude := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		magnitude = uint64(-(m.Amount + 1)) + 1
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	exponent := m.Currency.Exponent()
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// MarshalJSON encodes the amount as a decimal string, the currency is carried by a sibling field
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number, read in the currency already set on m or DefaultCurrency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	amount := string(bytes.Trim(data, `"`))
+
+	currency := m.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	money, err := ParseMoney(amount, currency)
+	if err != nil {
+		return err
+	}
+
+	*m = money
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func eur(amount int64) Money {
+	return NewMoney(amount, EUR)
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency Currency
+		want     Money
+		wantErr  error
+	}{
+		{name: "cents", amount: "12.34", currency: EUR, want: eur(1234)},
+		{name: "whole units", amount: "12", currency: EUR, want: eur(1200)},
+		{name: "fewer decimal places", amount: "0.5", currency: EUR, want: eur(50)},
+		{name: "negative", amount: "-0.05", currency: EUR, want: eur(-5)},
+		{name: "currency without minor units", amount: "1000", currency: JPY, want: NewMoney(1000, JPY)},
+		{name: "too many decimal places, want amountPrecisionError", amount: "1.234", currency: EUR, wantErr: amountPrecisionError},
+		{name: "decimals on currency without minor units, want amountPrecisionError", amount: "1.5", currency: JPY, wantErr: amountPrecisionError},
+		{name: "empty, want invalidAmountFormatError", amount: "", currency: EUR, wantErr: invalidAmountFormatError},
+		{name: "missing whole part, want invalidAmountFormatError", amount: ".5", currency: EUR, wantErr: invalidAmountFormatError},
+		{name: "trailing dot, want invalidAmountFormatError", amount: "5.", currency: EUR, wantErr: invalidAmountFormatError},
+		{name: "exponent, want invalidAmountFormatError", amount: "1e3", currency: EUR, wantErr: invalidAmountFormatError},
+		{name: "plus sign, want invalidAmountFormatError", amount: "+1", currency: EUR, wantErr: invalidAmountFormatError},
+		{name: "overflow, want amountOutOfRangeError", amount: "92233720368547758.08", currency: EUR, wantErr: amountOutOfRangeError},
+		{name: "unknown currency, want invalidCurrencyError", amount: "1", currency: "XXX", wantErr: invalidCurrencyError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMoney(tt.amount, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseMoney() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseMoney() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecimalAmount_In(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency Currency
+		want     Money
+		wantErr  error
+	}{
+		{name: "cents", amount: "12.34", currency: USD, want: NewMoney(1234, USD)},
+		{name: "currency without minor units", amount: "1000", currency: JPY, want: NewMoney(1000, JPY)},
+		{name: "decimals on currency without minor units, want amountPrecisionError", amount: "1.5", currency: JPY, wantErr: amountPrecisionError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := ParseDecimalAmount(tt.amount)
+			if err != nil {
+				t.Fatalf("ParseDecimalAmount() error = %v", err)
+			}
+
+			got, err := amount.In(tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("In() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("In() (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := ParseDecimalAmount("1e3"); !errors.Is(err, invalidAmountFormatError) {
+		t.Errorf("ParseDecimalAmount() error = %v, wantErr %v", err, invalidAmountFormatError)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: eur(1234), want: "12.34"},
+		{money: eur(5), want: "0.05"},
+		{money: eur(0), want: "0.00"},
+		{money: eur(-50), want: "-0.50"},
+		{money: NewMoney(1000, JPY), want: "1000"},
+		{money: eur(math.MinInt64), want: "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      func(a, b Money) (Money, error)
+		a, b    Money
+		want    Money
+		wantErr error
+	}{
+		{name: "add", op: Money.Add, a: eur(150), b: eur(50), want: eur(200)},
+		{name: "sub below zero", op: Money.Sub, a: eur(50), b: eur(150), want: eur(-100)},
+		{name: "add overflow, want amountOutOfRangeError", op: Money.Add, a: eur(math.MaxInt64), b: eur(1), wantErr: amountOutOfRangeError},
+		{name: "add underflow, want amountOutOfRangeError", op: Money.Add, a: eur(math.MinInt64), b: eur(-1), wantErr: amountOutOfRangeError},
+		{name: "sub overflow, want amountOutOfRangeError", op: Money.Sub, a: eur(math.MaxInt64), b: eur(-1), wantErr: amountOutOfRangeError},
+		{name: "sub underflow, want amountOutOfRangeError", op: Money.Sub, a: eur(math.MinInt64), b: eur(1), wantErr: amountOutOfRangeError},
+		{name: "mixed currencies, want currencyMismatchError", op: Money.Add, a: eur(1), b: NewMoney(1, USD), wantErr: currencyMismatchError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op(tt.a, tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := eur(math.MinInt64).Neg(); !errors.Is(err, amountOutOfRangeError) {
+		t.Errorf("Neg() error = %v, wantErr %v", err, amountOutOfRangeError)
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{Amount: eur(1234)})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if got, want := string(data), `{"amount":"12.34"}`; got != want {
+		t.Errorf("Marshal() = %v, want %v", got, want)
+	}
+
+	for _, input := range []string{`"12.34"`, `12.34`} {
+		var got Money
+		if err := json.Unmarshal([]byte(input), &got); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", input, err)
+		}
+		if got != eur(1234) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", input, got, eur(1234))
+		}
+	}
+
+	var got Money
+	if err := json.Unmarshal([]byte(`"12.345"`), &got); !errors.Is(err, amountPrecisionError) {
+		t.Errorf("Unmarshal() error = %v, wantErr %v", err, amountPrecisionError)
+	}
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"http/internal/tberrors"
+)
+
+// daysPerYear turns yearly interest rates into daily ones, every year counts 365 days
+const daysPerYear = 365
+
+// accruedDecimals is how many decimal places of a minor unit accrued interest keeps until it's posted
+const accruedDecimals = 9
+
+// Overdraft is the arranged overdraft of an account together with the debit interest it accrued, the zero value
+// arranges none.
+type Overdraft struct {
+	// Limit is how far below zero the balance may go, in the account currency
+	Limit Money
+	// Rate is the yearly interest charged on the overdrawn balance as a decimal fraction, "0.12" for 12%
+	Rate string
+	// Accrued is the interest accrued and not posted yet, a negative decimal number of minor units of the account
+	// currency. Fractions of a unit are kept so only the posted amount is ever rounded.
+	Accrued string
+	// AccruedThrough is the last day interest accrued for, as midnight UTC. It's zero until an overdraft is arranged
+	// and from then on the account accrues every day, whether it's overdrawn or not.
+	AccruedThrough time.Time
+}
+
+var invalidInterestRateError = tberrors.NewValidationError("invalid_interest_rate", "interest rate must be a decimal fraction between 0 and 1 such as 0.12", "interest_rate")
+var invalidOverdraftLimitError = tberrors.NewValidationError("invalid_limit", "overdraft limit must not be negative", "limit")
+var overdraftInUseError = tberrors.NewConflictError("overdraft_in_use", "balance is overdrawn beyond the new limit", "limit")
+
+// ParseInterestRate reads a yearly interest rate, a plain decimal fraction between 0 and 1. An empty rate is 0.
+func ParseInterestRate(rate string) (string, error) {
+	if rate == "" {
+		return "0", nil
+	}
+
+	whole, fraction, hasFraction := strings.Cut(rate, ".")
+	if !isDigits(whole) || (hasFraction && !isDigits(fraction)) {
+		return "", invalidInterestRateError
+	}
+
+	value, ok := new(big.Rat).SetString(rate)
+	if !ok || value.Cmp(big.NewRat(1, 1)) > 0 {
+		return "", invalidInterestRateError
+	}
+
+	return rate, nil
+}
+
+// SetOverdraft arranges an overdraft of limit charging rate, a zero limit removes it. The balance must already be
+// within the new limit. Interest starts accruing on the day of now the first time an overdraft is arranged, interest
+// accrued before is kept and posted as usual.
+func (acc *Account) SetOverdraft(limit Money, rate string, now time.Time) error {
+	if acc.Closed() {
+		return accountClosedError
+	}
+
+	if limit.Currency != acc.Balance.Currency {
+		return currencyMismatchError
+	}
+	if limit.IsNegative() {
+		return invalidOverdraftLimitError
+	}
+
+	rate, err := ParseInterestRate(rate)
+	if err != nil {
+		return err
+	}
+
+	if acc.Balance.Amount < -limit.Amount {
+		return overdraftInUseError
+	}
+
+	acc.Overdraft.Limit = limit
+	acc.Overdraft.Rate = rate
+	if acc.Overdraft.AccruedThrough.IsZero() {
+		acc.Overdraft.AccruedThrough = startOfDay(now).AddDate(0, 0, -1)
+	}
+
+	return nil
+}
+
+// AccruesInterest tells whether the account accrues overdraft interest, it does from the day an overdraft was first
+// arranged until it's closed
+func (acc *Account) AccruesInterest() bool {
+	return !acc.Closed() && !acc.Overdraft.AccruedThrough.IsZero()
+}
+
+// AccrueOverdraftInterest accrues the interest of the day after AccruedThrough, endOfDay is the balance the account
+// ended that day with. Only an overdrawn balance accrues interest.
+func (acc *Account) AccrueOverdraftInterest(endOfDay Money) error {
+	if !acc.AccruesInterest() {
+		return noOverdraftError
+	}
+	if endOfDay.Currency != acc.Balance.Currency {
+		return currencyMismatchError
+	}
+
+	accrued, err := parseAccrued(acc.Overdraft.Accrued)
+	if err != nil {
+		return err
+	}
+
+	rate, ok := new(big.Rat).SetString(acc.Overdraft.Rate)
+	if endOfDay.IsNegative() && ok {
+		interest := new(big.Rat).Mul(new(big.Rat).SetInt64(endOfDay.Amount), rate)
+		accrued.Add(accrued, interest.Quo(interest, big.NewRat(daysPerYear, 1)))
+	}
+
+	acc.Overdraft.Accrued = formatAccrued(accrued)
+	acc.Overdraft.AccruedThrough = acc.Overdraft.AccruedThrough.AddDate(0, 0, 1)
+
+	return nil
+}
+
+// PostOverdraftInterest charges the accrued interest rounded half to even to minor units, the charge may take the
+// balance beyond the overdraft limit. It returns the transaction made at postedAt with AccruedThrough as its value
+// date, or nil when the accrued interest rounds to nothing, and the accrual starts again from zero either way.
+func (acc *Account) PostOverdraftInterest(postedAt time.Time) (*Transaction, error) {
+	if !acc.AccruesInterest() {
+		return nil, noOverdraftError
+	}
+
+	accrued, err := parseAccrued(acc.Overdraft.Accrued)
+	if err != nil {
+		return nil, err
+	}
+
+	rounded := roundHalfEven(accrued)
+	if !rounded.IsInt64() {
+		return nil, amountOutOfRangeError
+	}
+
+	acc.Overdraft.Accrued = ""
+	if rounded.Sign() == 0 {
+		return nil, nil
+	}
+
+	charge := NewMoney(rounded.Int64(), acc.Balance.Currency)
+	balance, err := acc.Balance.Add(charge)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := charge.Neg()
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := NewOverdraftInterest(acc.ID, amount, acc.Overdraft.AccruedThrough, postedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	acc.Balance = balance
+
+	return transaction, nil
+}
+
+var noOverdraftError = tberrors.NewConflictError("no_overdraft", "account has no overdraft accruing interest", "account_id")
+var invalidAccruedInterestError = tberrors.NewInternalError("invalid_accrued_interest", "accrued interest is not a decimal number")
+
+func parseAccrued(accrued string) (*big.Rat, error) {
+	if accrued == "" {
+		return new(big.Rat), nil
+	}
+
+	value, ok := new(big.Rat).SetString(accrued)
+	if !ok {
+		return nil, invalidAccruedInterestError
+	}
+
+	return value, nil
+}
+
+func formatAccrued(accrued *big.Rat) string {
+	if accrued.Sign() == 0 {
+		return ""
+	}
+
+	return accrued.FloatString(accruedDecimals)
+}
+
+// startOfDay is midnight UTC of the day t falls on in UTC
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestAccount_SetOverdraft(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
+	closedAt := now.Add(-time.Hour)
+	accruing := Overdraft{Limit: eur(1000), Rate: "0.1", Accrued: "-3.5", AccruedThrough: time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name    string
+		account Account
+		limit   Money
+		rate    string
+		want    Overdraft
+		wantErr error
+	}{
+		{
+			name:    "first overdraft, accrues from today",
+			account: Account{Balance: eur(100)},
+			limit:   eur(50000),
+			rate:    "0.12",
+			want:    Overdraft{Limit: eur(50000), Rate: "0.12", AccruedThrough: time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "removed overdraft, keeps accruing what's owed",
+			account: Account{Balance: eur(0), Overdraft: accruing},
+			limit:   eur(0),
+			want:    Overdraft{Limit: eur(0), Rate: "0", Accrued: "-3.5", AccruedThrough: accruing.AccruedThrough},
+		},
+		{
+			name:    "limit below the overdrawn balance, want overdraftInUseError",
+			account: Account{Balance: eur(-600), Overdraft: accruing},
+			limit:   eur(500),
+			rate:    "0.1",
+			want:    accruing,
+			wantErr: overdraftInUseError,
+		},
+		{
+			name:    "rate as a percentage, want invalidInterestRateError",
+			account: Account{Balance: eur(0)},
+			limit:   eur(500),
+			rate:    "12",
+			wantErr: invalidInterestRateError,
+		},
+		{
+			name:    "rate in another notation, want invalidInterestRateError",
+			account: Account{Balance: eur(0)},
+			limit:   eur(500),
+			rate:    "1e-2",
+			wantErr: invalidInterestRateError,
+		},
+		{
+			name:    "negative limit, want invalidOverdraftLimitError",
+			account: Account{Balance: eur(0)},
+			limit:   eur(-1),
+			wantErr: invalidOverdraftLimitError,
+		},
+		{
+			name:    "limit in another currency, want currencyMismatchError",
+			account: Account{Balance: eur(0)},
+			limit:   NewMoney(500, USD),
+			wantErr: currencyMismatchError,
+		},
+		{
+			name:    "closed account, want accountClosedError",
+			account: Account{Balance: eur(0), DeletedAt: &closedAt},
+			limit:   eur(500),
+			wantErr: accountClosedError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := tt.account
+
+			if err := acc.SetOverdraft(tt.limit, tt.rate, now); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetOverdraft() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, acc.Overdraft); diff != "" {
+				t.Errorf("SetOverdraft() overdraft (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAccount_AvailableBalance(t *testing.T) {
+	acc := Account{Balance: eur(-250), Overdraft: Overdraft{Limit: eur(1000)}}
+
+	if got := acc.AvailableBalance(); got != eur(750) {
+		t.Errorf("AvailableBalance() = %v, want %v", got, eur(750))
+	}
+}
+
+func TestAccount_OverdraftInterest(t *testing.T) {
+	accruedThrough := time.Date(2025, 1, 28, 0, 0, 0, 0, time.UTC)
+	// interest is made when it's posted, some days after the last one it was accrued for, which is its value date
+	valueDate := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	postedAt := time.Date(2025, 2, 3, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		rate        string
+		endOfDays   []Money
+		wantAccrued string
+		wantCharge  *Transaction
+		wantBalance Money
+	}{
+		{
+			name:        "only overdrawn days accrue, the total is rounded half to even when posted",
+			rate:        "0.0365",
+			endOfDays:   []Money{eur(-12500), eur(40), eur(-12500)},
+			wantAccrued: "-2.500000000",
+			wantCharge:  &Transaction{CreatedAt: postedAt, FromAccountID: ptr("1"), Amount: eur(2), Type: OverdraftInterest, ValueDate: &valueDate},
+			wantBalance: eur(-10002),
+		},
+		{
+			name:        "fractions of a cent are kept until posted",
+			rate:        "0.12",
+			endOfDays:   []Money{eur(-1000), eur(-1000), eur(-1000)},
+			wantAccrued: "-0.986301369",
+			wantCharge:  &Transaction{CreatedAt: postedAt, FromAccountID: ptr("1"), Amount: eur(1), Type: OverdraftInterest, ValueDate: &valueDate},
+			wantBalance: eur(-10001),
+		},
+		{
+			name:        "nothing owed, nothing charged",
+			rate:        "0.12",
+			endOfDays:   []Money{eur(0), eur(10), eur(5)},
+			wantAccrued: "",
+			wantBalance: eur(-10000),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := Account{ID: "1", Balance: eur(-10000), Overdraft: Overdraft{Limit: eur(10000), Rate: tt.rate, AccruedThrough: accruedThrough}}
+
+			for _, endOfDay := range tt.endOfDays {
+				if err := acc.AccrueOverdraftInterest(endOfDay); err != nil {
+					t.Fatalf("AccrueOverdraftInterest() error = %v", err)
+				}
+			}
+			if acc.Overdraft.Accrued != tt.wantAccrued {
+				t.Errorf("AccrueOverdraftInterest() accrued = %q, want %q", acc.Overdraft.Accrued, tt.wantAccrued)
+			}
+			if !acc.Overdraft.AccruedThrough.Equal(valueDate) {
+				t.Errorf("AccrueOverdraftInterest() accrued through = %v, want %v", acc.Overdraft.AccruedThrough, postedAt.AddDate(0, 0, -1))
+			}
+
+			charge, err := acc.PostOverdraftInterest(postedAt)
+			if err != nil {
+				t.Fatalf("PostOverdraftInterest() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantCharge, charge, cmpopts.IgnoreFields(Transaction{}, "ID")); diff != "" {
+				t.Errorf("PostOverdraftInterest() (-want +got):\n%s", diff)
+			}
+			if acc.Balance != tt.wantBalance || acc.Overdraft.Accrued != "" {
+				t.Errorf("PostOverdraftInterest() balance = %v and accrued = %q, want %v and nothing", acc.Balance, acc.Overdraft.Accrued, tt.wantBalance)
+			}
+		})
+	}
+
+	t.Run("account without an overdraft, want noOverdraftError", func(t *testing.T) {
+		acc := Account{ID: "1", Balance: eur(-10)}
+
+		if err := acc.AccrueOverdraftInterest(eur(-10)); !errors.Is(err, noOverdraftError) {
+			t.Errorf("AccrueOverdraftInterest() error = %v, wantErr %v", err, noOverdraftError)
+		}
+	})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/lithammer/shortuuid/v4"
+	"http/internal/tberrors"
+)
+
+var notReversibleError = tberrors.NewValidationError("not_reversible", "only transfers can be reversed", "transaction_id")
+var refundExceedsTransactionError = tberrors.NewValidationError("refund_exceeds_transaction", "amount is more than what is left to refund of the transaction", "amount")
+var alreadyRefundedError = tberrors.NewConflictError("already_refunded", "transaction was already refunded in full", "transaction_id")
+
+// Reversible tells whether the transaction can be refunded with reversals. Only transfers between accounts are,
+// reversing a deposit or a withdrawal would move cash the bank can't take back, such as crediting a withdrawal that
+// was already paid out.
+func (t *Transaction) Reversible() bool {
+	return t.Type == Transfer
+}
+
+// Refundable is what is left to give back of the transaction once reversals are, in the currency it credited
+func (t *Transaction) Refundable(reversals []Transaction) (Money, error) {
+	refundable := t.DestinationAmount()
+	for _, reversal := range reversals {
+		var err error
+		if refundable, err = refundable.Sub(reversal.Amount); err != nil {
+			return Money{}, err
+		}
+	}
+
+	return refundable, nil
+}
+
+// NewReversal gives back amount of original, which reversals were already made of. The money moves the other way
+// round: amount is taken from the account original credited, in its currency, and the account it debited gets it
+// back at now. Together the reversals can never give back more than original moved.
+//
+// A reversal of a converted transfer keeps the rate of the transfer, the source account gets back the share of what
+// it paid that amount is of what's left to refund. The last refund gives back exactly what's left of both amounts.
+func NewReversal(original *Transaction, reversals []Transaction, amount Money, now time.Time) (*Transaction, error) {
+	if !original.Reversible() {
+		return nil, notReversibleError
+	}
+
+	refundable, err := original.Refundable(reversals)
+	if err != nil {
+		return nil, err
+	}
+	if refundable.IsZero() {
+		return nil, alreadyRefundedError
+	}
+	if amount.Currency != refundable.Currency {
+		return nil, currencyMismatchError
+	}
+	if amount.Amount > refundable.Amount {
+		return nil, refundExceedsTransactionError
+	}
+
+	t := &Transaction{
+		ID:            shortuuid.New(),
+		CreatedAt:     now,
+		FromAccountID: original.ToAccountID,
+		ToAccountID:   original.FromAccountID,
+		Amount:        amount,
+		Type:          Reversal,
+		ParentID:      original.ID,
+	}
+
+	if original.Conversion != nil && amount.IsPositive() {
+		if t.Conversion, err = reverseConversion(original, reversals, amount, refundable); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.validate()
+}
+
+// reverseConversion shares what's left of the source amount of original out in proportion to amount
+func reverseConversion(original *Transaction, reversals []Transaction, amount, refundable Money) (*FXConversion, error) {
+	remaining := original.Amount
+	for _, reversal := range reversals {
+		var err error
+		if remaining, err = remaining.Sub(reversal.DestinationAmount()); err != nil {
+			return nil, err
+		}
+	}
+
+	share := new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(remaining.Amount), big.NewInt(amount.Amount)),
+		big.NewInt(refundable.Amount),
+	)
+	rounded := roundHalfEven(share)
+	if !rounded.IsInt64() {
+		return nil, amountOutOfRangeError
+	}
+	if rounded.Sign() == 0 {
+		return nil, convertedAmountTooSmallError
+	}
+
+	return &FXConversion{
+		DestinationAmount: NewMoney(rounded.Int64(), remaining.Currency),
+		Rate:              original.Conversion.Rate,
+		RateTimestamp:     original.Conversion.RateTimestamp,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestNewReversal(t *testing.T) {
+	account, other := "1", "2"
+	transfer := &Transaction{ID: "t", FromAccountID: &account, ToAccountID: &other, Amount: eur(100), Type: Transfer}
+	converted := &Transaction{ID: "c", FromAccountID: &account, ToAccountID: &other, Amount: eur(100), Type: Transfer,
+		Conversion: &FXConversion{DestinationAmount: NewMoney(108, USD), Rate: "1.08"}}
+	deposit := &Transaction{ID: "d", ToAccountID: &account, Amount: eur(100), Type: Deposit}
+	withdrawal := &Transaction{ID: "w", FromAccountID: &account, Amount: eur(100), Type: Withdrawal}
+
+	tests := []struct {
+		name      string
+		original  *Transaction
+		reversals []Transaction
+		amount    Money
+		want      *Transaction
+		wantErr   error
+	}{
+		{
+			name:     "whole transfer",
+			original: transfer,
+			amount:   eur(100),
+			want:     &Transaction{FromAccountID: &other, ToAccountID: &account, Amount: eur(100), Type: Reversal, ParentID: "t"},
+		},
+		{
+			name:      "rest of a partly refunded transfer",
+			original:  transfer,
+			reversals: []Transaction{{Amount: eur(30)}, {Amount: eur(30)}},
+			amount:    eur(40),
+			want:      &Transaction{FromAccountID: &other, ToAccountID: &account, Amount: eur(40), Type: Reversal, ParentID: "t"},
+		},
+		{
+			name:     "part of a converted transfer at its rate",
+			original: converted,
+			amount:   NewMoney(54, USD),
+			want: &Transaction{FromAccountID: &other, ToAccountID: &account, Amount: NewMoney(54, USD), Type: Reversal, ParentID: "c",
+				Conversion: &FXConversion{DestinationAmount: eur(50), Rate: "1.08"}},
+		},
+		{
+			name:     "rest of a converted transfer gives back what's left of the source amount",
+			original: converted,
+			reversals: []Transaction{
+				{Amount: NewMoney(1, USD), Conversion: &FXConversion{DestinationAmount: eur(1)}},
+				{Amount: NewMoney(1, USD), Conversion: &FXConversion{DestinationAmount: eur(1)}},
+			},
+			amount: NewMoney(106, USD),
+			want: &Transaction{FromAccountID: &other, ToAccountID: &account, Amount: NewMoney(106, USD), Type: Reversal, ParentID: "c",
+				Conversion: &FXConversion{DestinationAmount: eur(98), Rate: "1.08"}},
+		},
+		{
+			name:      "more than what's left to refund, want refundExceedsTransactionError",
+			original:  transfer,
+			reversals: []Transaction{{Amount: eur(60)}},
+			amount:    eur(41),
+			wantErr:   refundExceedsTransactionError,
+		},
+		{
+			name:      "refunded in full, want alreadyRefundedError",
+			original:  transfer,
+			reversals: []Transaction{{Amount: eur(100)}},
+			amount:    eur(1),
+			wantErr:   alreadyRefundedError,
+		},
+		{
+			name:     "converted transfer refunded in the source currency, want currencyMismatchError",
+			original: converted,
+			amount:   eur(50),
+			wantErr:  currencyMismatchError,
+		},
+		{
+			name:     "zero amount, want invalidAmountError",
+			original: transfer,
+			amount:   eur(0),
+			wantErr:  invalidAmountError,
+		},
+		{
+			name:     "deposit, want notReversibleError",
+			original: deposit,
+			amount:   eur(25),
+			wantErr:  notReversibleError,
+		},
+		{
+			name:     "withdrawal, want notReversibleError",
+			original: withdrawal,
+			amount:   eur(25),
+			wantErr:  notReversibleError,
+		},
+		{
+			name:     "fee, want notReversibleError",
+			original: &Transaction{ID: "f", FromAccountID: &account, Amount: eur(1), Type: Fee, ParentID: "t"},
+			amount:   eur(1),
+			wantErr:  notReversibleError,
+		},
+		{
+			name:     "reversal, want notReversibleError",
+			original: &Transaction{ID: "r", FromAccountID: &account, Amount: eur(1), Type: Reversal, ParentID: "d"},
+			amount:   eur(1),
+			wantErr:  notReversibleError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewReversal(tt.original, tt.reversals, tt.amount, time.Now())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewReversal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(Transaction{}, "ID", "CreatedAt")); diff != "" {
+				t.Errorf("NewReversal() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
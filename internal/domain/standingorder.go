@@ -0,0 +1,377 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lithammer/shortuuid/v4"
+	"http/internal/schedule"
+	"http/internal/tberrors"
+)
+
+// MaxStandingOrderRetries bounds how many times a payment that failed for lack of funds is attempted again
+const MaxStandingOrderRetries = 10
+
+// MinRetryInterval is the shortest wait between two attempts at the same payment
+const MinRetryInterval = time.Minute
+
+// DefaultRetryInterval is the wait between two attempts at the same payment when the policy doesn't say
+const DefaultRetryInterval = time.Hour
+
+// StandingOrder transfers Amount from AccountID to ToAccountID at every occurrence of Schedule
+type StandingOrder struct {
+	ID          string
+	AccountID   string
+	ToAccountID string
+	Amount      Money
+	Reference   string
+	// Schedule is a cron expression or an RRULE, occurrences are computed in TimeZone from StartAt
+	Schedule string
+	TimeZone string
+	StartAt  time.Time
+	// EndAt is the last time an occurrence may happen at, orders without one run until cancelled
+	EndAt  *time.Time
+	Policy FailurePolicy
+	Status StandingOrderStatus
+	// NextRunAt is the occurrence paid next, nil once the order is over
+	NextRunAt *time.Time
+	// DueAt is when the next attempt at paying NextRunAt is made, later than it while retrying
+	DueAt *time.Time
+	// Attempts counts the failed attempts at paying NextRunAt
+	Attempts  int
+	CreatedAt time.Time
+}
+
+type StandingOrderStatus string
+
+const (
+	StandingOrderActive StandingOrderStatus = "active"
+	// StandingOrderSuspended orders stopped after a payment failed, they run again once resumed
+	StandingOrderSuspended StandingOrderStatus = "suspended"
+	// StandingOrderCompleted orders have no occurrence left
+	StandingOrderCompleted StandingOrderStatus = "completed"
+	StandingOrderCancelled StandingOrderStatus = "cancelled"
+)
+
+func (s StandingOrderStatus) String() string {
+	return string(s)
+}
+
+// FailurePolicy tells what happens when a payment fails. Payments failing for lack of funds are attempted Retries
+// more times, RetryInterval apart, before OnFailure applies. Other failures apply OnFailure right away.
+type FailurePolicy struct {
+	Retries       int
+	RetryInterval time.Duration
+	OnFailure     FailureAction
+}
+
+type FailureAction string
+
+const (
+	// SkipPayment gives up on the occurrence and waits for the next one
+	SkipPayment FailureAction = "skip"
+	// SuspendOrder stops the order until it's resumed
+	SuspendOrder FailureAction = "suspend"
+)
+
+func (a FailureAction) String() string {
+	return string(a)
+}
+
+// ParseFailureAction reads a failure action from a request
+func ParseFailureAction(action string) (FailureAction, error) {
+	switch a := FailureAction(action); a {
+	case SkipPayment, SuspendOrder:
+		return a, nil
+	default:
+		return "", invalidFailureActionError
+	}
+}
+
+// StandingOrderTerms are what the owner of an account decides about a standing order
+type StandingOrderTerms struct {
+	ToAccountID string
+	// Amount is in the currency of the account paying unless it names one
+	Amount    Amount
+	Reference string
+	Schedule  string
+	// TimeZone is an IANA time zone such as Europe/Lisbon, UTC when empty
+	TimeZone string
+	// StartAt is when the order starts, now when zero
+	StartAt time.Time
+	EndAt   *time.Time
+	Policy  FailurePolicy
+}
+
+// StandingOrderExecution is an attempt at paying an occurrence of a standing order
+type StandingOrderExecution struct {
+	ID      string
+	OrderID string
+	// ScheduledFor is the occurrence paid
+	ScheduledFor time.Time
+	ExecutedAt   time.Time
+	// Attempt is 1 for the first attempt at ScheduledFor
+	Attempt       int
+	Status        ExecutionStatus
+	TransactionID string
+	// FailureCode and FailureReason tell why the payment wasn't made
+	FailureCode   string
+	FailureReason string
+}
+
+type ExecutionStatus string
+
+const (
+	// ExecutionPending payments are being made, executions are stored once their payment was made or failed
+	ExecutionPending   ExecutionStatus = "pending"
+	ExecutionCompleted ExecutionStatus = "completed"
+	// ExecutionRetrying payments failed and will be attempted again
+	ExecutionRetrying ExecutionStatus = "retrying"
+	// ExecutionSkipped payments failed and their occurrence was given up on
+	ExecutionSkipped ExecutionStatus = "skipped"
+	// ExecutionSuspended payments failed and suspended their order
+	ExecutionSuspended ExecutionStatus = "suspended"
+)
+
+func (s ExecutionStatus) String() string {
+	return string(s)
+}
+
+// NewStandingOrder creates an order out of accountID, whose balance is in currency, paying the first occurrence at or
+// after both its start and now, occurrences before now are never paid
+func NewStandingOrder(accountID string, currency Currency, terms StandingOrderTerms, now time.Time) (*StandingOrder, error) {
+	if terms.Amount == nil {
+		return nil, invalidAmountError
+	}
+	amount, err := terms.Amount.In(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &StandingOrder{
+		ID:          shortuuid.New(),
+		AccountID:   accountID,
+		ToAccountID: terms.ToAccountID,
+		Amount:      amount,
+		Reference:   terms.Reference,
+		Schedule:    terms.Schedule,
+		TimeZone:    terms.TimeZone,
+		StartAt:     terms.StartAt,
+		EndAt:       terms.EndAt,
+		Policy:      terms.Policy,
+		Status:      StandingOrderActive,
+		CreatedAt:   now,
+	}
+	if order.TimeZone == "" {
+		order.TimeZone = "UTC"
+	}
+	if order.StartAt.IsZero() {
+		// orders starting now are due right away when their schedule allows it, an RRULE always does as it takes
+		// its time of day from the start
+		order.StartAt = now.Truncate(time.Second)
+		now = order.StartAt
+	}
+	if order.Policy.OnFailure == "" {
+		order.Policy.OnFailure = SkipPayment
+	}
+	if order.Policy.Retries > 0 && order.Policy.RetryInterval == 0 {
+		order.Policy.RetryInterval = DefaultRetryInterval
+	}
+
+	if _, err := order.validate(); err != nil {
+		return nil, err
+	}
+
+	order.schedule(latest(order.StartAt, now).Add(-time.Nanosecond))
+	if order.Status == StandingOrderCompleted {
+		return nil, scheduleNeverRunsError
+	}
+
+	return order, nil
+}
+
+// Due tells whether a payment of an active order is to be attempted at
+func (order *StandingOrder) Due(at time.Time) bool {
+	return order.Status == StandingOrderActive && order.DueAt != nil && !order.DueAt.After(at)
+}
+
+// Attempt starts paying NextRunAt and moves the order on as if the payment was made, the order is to be stored moved
+// on along with the payment. The execution is pending until Succeed, Fail or Interrupt records how the payment went.
+func (order *StandingOrder) Attempt(at time.Time) *StandingOrderExecution {
+	execution := order.execution(ExecutionPending, at)
+	order.schedule(*order.NextRunAt)
+
+	return execution
+}
+
+// Succeed records the payment of execution by the transaction with transactionID
+func (execution *StandingOrderExecution) Succeed(transactionID string) {
+	execution.Status = ExecutionCompleted
+	execution.TransactionID = transactionID
+}
+
+// Fail records that the payment of execution failed. Payments that are worth retrying are attempted again while the
+// policy allows it, otherwise the order skips the occurrence or is suspended.
+func (order *StandingOrder) Fail(execution *StandingOrderExecution, code, reason string, retryable bool, at time.Time) {
+	order.restore(execution)
+
+	switch {
+	case retryable && order.Attempts < order.Policy.Retries:
+		execution.Status = ExecutionRetrying
+		order.Attempts++
+		retryAt := at.Add(order.Policy.RetryInterval)
+		order.DueAt = &retryAt
+	case order.Policy.OnFailure == SuspendOrder:
+		execution.Status = ExecutionSuspended
+		order.Status = StandingOrderSuspended
+		order.DueAt = nil
+	default:
+		execution.Status = ExecutionSkipped
+		order.schedule(*order.NextRunAt)
+	}
+
+	execution.FailureCode = code
+	execution.FailureReason = reason
+}
+
+// Interrupt records that the payment of execution failed for a reason that may go away on its own, such as a storage
+// failure. The order is due again right away and the attempt doesn't count against its retries.
+func (order *StandingOrder) Interrupt(execution *StandingOrderExecution, code, reason string) {
+	order.restore(execution)
+
+	execution.Status = ExecutionRetrying
+	execution.FailureCode = code
+	execution.FailureReason = reason
+}
+
+// restore puts the order back on the occurrence and attempt of execution, which Attempt moved it past
+func (order *StandingOrder) restore(execution *StandingOrderExecution) {
+	next, due := execution.ScheduledFor, execution.ExecutedAt
+	order.Status = StandingOrderActive
+	order.NextRunAt = &next
+	order.DueAt = &due
+	order.Attempts = execution.Attempt - 1
+}
+
+// Cancel stops the order for good
+func (order *StandingOrder) Cancel() error {
+	if order.Status == StandingOrderCancelled || order.Status == StandingOrderCompleted {
+		return standingOrderEndedError
+	}
+
+	order.Status = StandingOrderCancelled
+	order.NextRunAt = nil
+	order.DueAt = nil
+
+	return nil
+}
+
+// Resume runs a suspended order again from the first occurrence after now, occurrences missed meanwhile aren't paid
+func (order *StandingOrder) Resume(now time.Time) error {
+	if order.Status != StandingOrderSuspended {
+		return standingOrderNotSuspendedError
+	}
+
+	order.Status = StandingOrderActive
+	order.schedule(latest(order.StartAt, now).Add(-time.Nanosecond))
+
+	return nil
+}
+
+func (order *StandingOrder) execution(status ExecutionStatus, at time.Time) *StandingOrderExecution {
+	return &StandingOrderExecution{
+		ID:           shortuuid.New(),
+		OrderID:      order.ID,
+		ScheduledFor: *order.NextRunAt,
+		ExecutedAt:   at,
+		Attempt:      order.Attempts + 1,
+		Status:       status,
+	}
+}
+
+// schedule moves the order to its first occurrence after after, the order is completed when there's none left
+func (order *StandingOrder) schedule(after time.Time) {
+	order.Attempts = 0
+
+	recurrence, err := order.recurrence()
+	var next time.Time
+	ok := err == nil
+	if ok {
+		next, ok = recurrence.Next(after)
+	}
+	if !ok || order.EndAt != nil && next.After(*order.EndAt) {
+		order.Status = StandingOrderCompleted
+		order.NextRunAt = nil
+		order.DueAt = nil
+		return
+	}
+
+	order.NextRunAt = &next
+	order.DueAt = &next
+}
+
+func (order *StandingOrder) recurrence() (schedule.Schedule, error) {
+	location, err := time.LoadLocation(order.TimeZone)
+	if err != nil {
+		return nil, invalidTimeZoneError
+	}
+
+	return schedule.Parse(order.Schedule, order.StartAt.In(location))
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+var invalidTimeZoneError = tberrors.NewValidationError("invalid_time_zone", "time zone must be an IANA time zone such as Europe/Lisbon", "time_zone")
+var invalidEndError = tberrors.NewValidationError("invalid_end", "end must be after start", "end_at")
+var invalidRetriesError = tberrors.NewValidationError("invalid_retries", "retries must be from 0 to 10", "retries")
+var invalidRetryIntervalError = tberrors.NewValidationError("invalid_retry_interval", "retry interval must be at least a minute", "retry_interval")
+var invalidFailureActionError = tberrors.NewValidationError("invalid_failure_action", "on failure must be skip or suspend", "on_failure")
+var scheduleNeverRunsError = tberrors.NewValidationError("schedule_never_runs", "schedule has no occurrence between start and end", "schedule")
+var standingOrderEndedError = tberrors.NewConflictError("standing_order_ended", "standing order is already cancelled or completed", "")
+var standingOrderNotSuspendedError = tberrors.NewConflictError("standing_order_not_suspended", "only suspended standing orders can be resumed", "")
+
+func (order *StandingOrder) validate() (*StandingOrder, error) {
+	var errs []error
+	if order.AccountID == "" {
+		errs = append(errs, invalidFromAccountError)
+	}
+
+	// the order is checked as the transfers it makes
+	transfer := Transaction{FromAccountID: &order.AccountID, Amount: order.Amount, Type: Transfer}
+	if order.ToAccountID != "" {
+		transfer.ToAccountID = &order.ToAccountID
+	}
+	if _, err := transfer.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := order.recurrence(); err != nil {
+		errs = append(errs, err)
+	}
+	if order.EndAt != nil && !order.EndAt.After(order.StartAt) {
+		errs = append(errs, invalidEndError)
+	}
+
+	policy := order.Policy
+	if policy.Retries < 0 || policy.Retries > MaxStandingOrderRetries {
+		errs = append(errs, invalidRetriesError)
+	}
+	if policy.Retries > 0 && policy.RetryInterval < MinRetryInterval {
+		errs = append(errs, invalidRetryIntervalError)
+	}
+	if policy.OnFailure != SkipPayment && policy.OnFailure != SuspendOrder {
+		errs = append(errs, invalidFailureActionError)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
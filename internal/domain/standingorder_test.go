@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewStandingOrder(t *testing.T) {
+	now := time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC)
+	endAt := time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour int) *time.Time {
+		t := time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		name          string
+		terms         StandingOrderTerms
+		wantNextRunAt *time.Time
+		wantFields    []string
+	}{
+		{
+			name:          "first occurrence after now",
+			terms:         StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "0 9 1 * *"},
+			wantNextRunAt: at(time.February, 1, 9),
+		},
+		{
+			name:          "first occurrence after a later start, in its time zone",
+			terms:         StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "0 9 * * *", TimeZone: "America/New_York", StartAt: *at(time.March, 1, 0)},
+			wantNextRunAt: at(time.March, 1, 14),
+		},
+		{
+			name:       "no occurrence before the end",
+			terms:      StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "0 9 1 * *", EndAt: &endAt},
+			wantFields: []string{"schedule"},
+		},
+		{
+			name: "every invalid term is reported",
+			terms: StandingOrderTerms{
+				ToAccountID: "1",
+				Amount:      eur(0),
+				Schedule:    "FREQ=HOURLY",
+				TimeZone:    "Mars/Olympus",
+				StartAt:     now,
+				EndAt:       &now,
+				Policy:      FailurePolicy{Retries: 11, RetryInterval: time.Second, OnFailure: "retry"},
+			},
+			wantFields: []string{"amount", "to_account", "time_zone", "end_at", "retries", "retry_interval", "on_failure"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewStandingOrder("1", EUR, tt.terms, now)
+
+			if diff := cmp.Diff(tt.wantFields, validationFields(err)); diff != "" {
+				t.Fatalf("NewStandingOrder() error = %v, fields (-want +got):\n%s", err, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Status != StandingOrderActive {
+				t.Errorf("NewStandingOrder() status = %s, want %s", got.Status, StandingOrderActive)
+			}
+			if diff := cmp.Diff(tt.wantNextRunAt, got.NextRunAt); diff != "" {
+				t.Errorf("NewStandingOrder() next run (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(got.NextRunAt, got.DueAt); diff != "" {
+				t.Errorf("NewStandingOrder() due (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStandingOrder_Payments(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endAt := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+	first := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	newOrder := func(policy FailurePolicy) *StandingOrder {
+		order, err := NewStandingOrder("1", EUR, StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "0 9 1 * *", EndAt: &endAt, Policy: policy}, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return order
+	}
+
+	t.Run("payments move on to the next occurrence until the end", func(t *testing.T) {
+		order := newOrder(FailurePolicy{})
+
+		var scheduled []time.Time
+		for i := 0; order.Status == StandingOrderActive; i++ {
+			if i > 3 {
+				t.Fatal("order never completed")
+			}
+			execution := order.Attempt(*order.DueAt)
+			execution.Succeed("tx")
+			if execution.Status != ExecutionCompleted || execution.TransactionID != "tx" || execution.Attempt != 1 {
+				t.Errorf("Succeed() = %+v", execution)
+			}
+			scheduled = append(scheduled, execution.ScheduledFor)
+		}
+
+		want := []time.Time{first, first.AddDate(0, 1, 0), first.AddDate(0, 2, 0)}
+		if diff := cmp.Diff(want, scheduled); diff != "" {
+			t.Errorf("Succeed() scheduled for (-want +got):\n%s", diff)
+		}
+		if order.Status != StandingOrderCompleted || order.NextRunAt != nil || order.Due(endAt.AddDate(1, 0, 0)) {
+			t.Errorf("Succeed() left %s order due at %v", order.Status, order.DueAt)
+		}
+	})
+
+	t.Run("retries then skips", func(t *testing.T) {
+		order := newOrder(FailurePolicy{Retries: 2, RetryInterval: time.Hour, OnFailure: SkipPayment})
+
+		var statuses []ExecutionStatus
+		var attempts []int
+		for order.NextRunAt.Equal(first) {
+			execution := order.Attempt(*order.DueAt)
+			order.Fail(execution, "insufficient_funds", "insufficient funds", true, execution.ExecutedAt)
+			statuses = append(statuses, execution.Status)
+			attempts = append(attempts, execution.Attempt)
+		}
+
+		if diff := cmp.Diff([]ExecutionStatus{ExecutionRetrying, ExecutionRetrying, ExecutionSkipped}, statuses); diff != "" {
+			t.Errorf("Fail() statuses (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]int{1, 2, 3}, attempts); diff != "" {
+			t.Errorf("Fail() attempts (-want +got):\n%s", diff)
+		}
+		if next := first.AddDate(0, 1, 0); !order.DueAt.Equal(next) || order.Attempts != 0 {
+			t.Errorf("Fail() due at %v after %d attempts, want %v after none", order.DueAt, order.Attempts, next)
+		}
+	})
+
+	t.Run("failures not worth retrying suspend right away", func(t *testing.T) {
+		order := newOrder(FailurePolicy{Retries: 2, RetryInterval: time.Hour, OnFailure: SuspendOrder})
+
+		execution := order.Attempt(first)
+		order.Fail(execution, "account_closed", "account is closed", false, first)
+
+		if execution.Status != ExecutionSuspended || execution.FailureCode != "account_closed" {
+			t.Errorf("Fail() = %+v", execution)
+		}
+		if order.Status != StandingOrderSuspended || order.Due(endAt) {
+			t.Errorf("Fail() left %s order due at %v", order.Status, order.DueAt)
+		}
+
+		resumedAt := first.AddDate(0, 1, 1)
+		if err := order.Resume(resumedAt); err != nil {
+			t.Fatalf("Resume() error = %v", err)
+		}
+		if next := first.AddDate(0, 2, 0); order.Status != StandingOrderActive || !order.NextRunAt.Equal(next) {
+			t.Errorf("Resume() left %s order next run at %v, want active at %v", order.Status, order.NextRunAt, next)
+		}
+		if err := order.Resume(resumedAt); !errors.Is(err, standingOrderNotSuspendedError) {
+			t.Errorf("Resume() error = %v, wantErr %v", err, standingOrderNotSuspendedError)
+		}
+	})
+
+	t.Run("interrupted payments are due again without using up a retry", func(t *testing.T) {
+		order := newOrder(FailurePolicy{Retries: 1, RetryInterval: time.Hour})
+
+		execution := order.Attempt(first)
+		if execution.Status != ExecutionPending || order.Due(first) {
+			t.Errorf("Attempt() = %+v, left the order due at %v", execution, order.DueAt)
+		}
+
+		order.Interrupt(execution, "storage_failure", "failed to commit transaction")
+
+		if execution.Status != ExecutionRetrying || execution.FailureCode != "storage_failure" {
+			t.Errorf("Interrupt() = %+v", execution)
+		}
+		if !order.Due(first) || !order.NextRunAt.Equal(first) || order.Attempts != 0 {
+			t.Errorf("Interrupt() left the order due at %v for %v after %d attempts", order.DueAt, order.NextRunAt, order.Attempts)
+		}
+	})
+
+	t.Run("cancelled orders are never due", func(t *testing.T) {
+		order := newOrder(FailurePolicy{})
+
+		if err := order.Cancel(); err != nil {
+			t.Fatalf("Cancel() error = %v", err)
+		}
+		if order.Due(endAt) {
+			t.Error("Cancel() left the order due")
+		}
+		if err := order.Cancel(); !errors.Is(err, standingOrderEndedError) {
+			t.Errorf("Cancel() error = %v, wantErr %v", err, standingOrderEndedError)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Statement describes an account over a period, the entries in between take it from the opening balance to the
+// closing balance
+type Statement struct {
+	ID          string
+	AccountID   string
+	AccountType AccountType
+	// From and To bound the period, both are included
+	From time.Time
+	To   time.Time
+	// OpeningBalance is the balance right before From and ClosingBalance the balance at To
+	OpeningBalance Money
+	ClosingBalance Money
+	CreatedAt      time.Time
+}
+
+// StatementEntry is a transaction as seen by the account of a statement
+type StatementEntry struct {
+	Transaction Transaction
+	// Amount is what the transaction added to the balance, negative when money left the account
+	Amount       Money
+	BalanceAfter Money
+	// Counterparty is the other account of a transfer
+	Counterparty string
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/lithammer/shortuuid/v4"
+	"http/internal/tberrors"
 )
 
 type Transaction struct {
@@ -12,8 +13,21 @@ type Transaction struct {
 	CreatedAt     time.Time
 	FromAccountID *string
 	ToAccountID   *string
-	Amount        int
-	Type          TransactionType
+	// Amount is taken from the source account and in its currency
+	Amount Money
+	Type   TransactionType
+	// Conversion is set on transfers between accounts in different currencies and on their reversals, which keep the
+	// rate of the transfer
+	Conversion *FXConversion
+	// ParentID is the transaction this one was made for, such as the transaction a fee was charged on or the one a
+	// reversal refunds
+	ParentID string
+	// Fee is the fee charged on the transaction as it was made. The fee is a transaction of its own pointing back at
+	// this one with its ParentID, it isn't stored with it.
+	Fee *Transaction
+	// ValueDate is the last day of the period interest was accrued for, nil on transactions that take effect when
+	// they're made
+	ValueDate *time.Time
 }
 
 type TransactionType string
@@ -22,16 +36,36 @@ const (
 	Withdrawal TransactionType = "withdrawal"
 	Deposit    TransactionType = "deposit"
 	Transfer   TransactionType = "transfer"
+	// OverdraftInterest charges the interest an overdrawn account accrued, it only has a from account
+	OverdraftInterest TransactionType = "overdraft_interest"
+	// Interest pays the interest a savings account earned, it only has a to account
+	Interest TransactionType = "interest"
+	// Fee charges the fee of its parent transaction, it only has a from account
+	Fee TransactionType = "fee"
+	// Reversal refunds part or all of its parent transaction, it moves the money back between the accounts of the
+	// parent so it only has a from account when it reverses a deposit and only a to account when it reverses a
+	// withdrawal
+	Reversal TransactionType = "reversal"
 )
 
 func (t TransactionType) String() string {
 	return string(t)
 }
 
-func NewTransfer(fromAccountID, toAccountID string, amount int) (*Transaction, error) {
+// ParseTransactionType reads a transaction type from a request
+func ParseTransactionType(transactionType string) (TransactionType, error) {
+	switch t := TransactionType(transactionType); t {
+	case Withdrawal, Deposit, Transfer, OverdraftInterest, Interest, Fee, Reversal:
+		return t, nil
+	default:
+		return "", invalidTransactionType
+	}
+}
+
+func NewTransfer(fromAccountID, toAccountID string, amount Money, now time.Time) (*Transaction, error) {
 	t := &Transaction{
 		ID:            shortuuid.New(),
-		CreatedAt:     time.Now(),
+		CreatedAt:     now,
 		FromAccountID: &fromAccountID,
 		ToAccountID:   &toAccountID,
 		Amount:        amount,
@@ -41,10 +75,10 @@ func NewTransfer(fromAccountID, toAccountID string, amount int) (*Transaction, e
 	return t.validate()
 }
 
-func NewDeposit(toAccountID string, amount int) (*Transaction, error) {
+func NewDeposit(toAccountID string, amount Money, now time.Time) (*Transaction, error) {
 	t := &Transaction{
 		ID:          shortuuid.New(),
-		CreatedAt:   time.Now(),
+		CreatedAt:   now,
 		ToAccountID: &toAccountID,
 		Amount:      amount,
 		Type:        Deposit,
@@ -53,10 +87,10 @@ func NewDeposit(toAccountID string, amount int) (*Transaction, error) {
 	return t.validate()
 }
 
-func NewWithdrawal(fromAccountID string, amount int) (*Transaction, error) {
+func NewWithdrawal(fromAccountID string, amount Money, now time.Time) (*Transaction, error) {
 	t := &Transaction{
 		ID:            shortuuid.New(),
-		CreatedAt:     time.Now(),
+		CreatedAt:     now,
 		FromAccountID: &fromAccountID,
 		Amount:        amount,
 		Type:          Withdrawal,
@@ -65,35 +99,185 @@ func NewWithdrawal(fromAccountID string, amount int) (*Transaction, error) {
 	return t.validate()
 }
 
-var invalidFromAccountError = errors.New("from account ID is required")
-var invalidToAccountError = errors.New("to account ID is required")
-var invalidAmountError = errors.New("amount must be greater than zero")
-var invalidTransactionType = errors.New("invalid transaction type")
+// NewOverdraftInterest charges amount of interest accrued through valueDate to accountID, it's made at postedAt as the
+// bank posts interest for periods that are already over
+func NewOverdraftInterest(accountID string, amount Money, valueDate, postedAt time.Time) (*Transaction, error) {
+	t := &Transaction{
+		ID:            shortuuid.New(),
+		CreatedAt:     postedAt,
+		FromAccountID: &accountID,
+		Amount:        amount,
+		Type:          OverdraftInterest,
+		ValueDate:     &valueDate,
+	}
+
+	return t.validate()
+}
+
+// NewInterest pays amount of interest to accountID, it's made at postedAt like NewOverdraftInterest
+func NewInterest(accountID string, amount Money, valueDate, postedAt time.Time) (*Transaction, error) {
+	t := &Transaction{
+		ID:          shortuuid.New(),
+		CreatedAt:   postedAt,
+		ToAccountID: &accountID,
+		Amount:      amount,
+		Type:        Interest,
+		ValueDate:   &valueDate,
+	}
+
+	return t.validate()
+}
+
+// NewFee charges amount to accountID for parent, it's made at the same time as parent
+func NewFee(accountID string, amount Money, parent *Transaction) (*Transaction, error) {
+	t := &Transaction{
+		ID:            shortuuid.New(),
+		CreatedAt:     parent.CreatedAt,
+		FromAccountID: &accountID,
+		Amount:        amount,
+		Type:          Fee,
+		ParentID:      parent.ID,
+	}
+
+	return t.validate()
+}
+
+var conversionNotAllowedError = tberrors.NewValidationError("invalid_transaction_type", "only transfers can be converted", "type")
+
+// ApplyRate converts a transfer into the destination currency, the destination account is credited with the
+// converted amount.
+func (t *Transaction) ApplyRate(rate ExchangeRate) error {
+	if t.Type != Transfer {
+		return conversionNotAllowedError
+	}
+
+	destinationAmount, err := rate.Convert(t.Amount)
+	if err != nil {
+		return err
+	}
+
+	t.Conversion = &FXConversion{
+		DestinationAmount: destinationAmount,
+		Rate:              rate.Rate,
+		RateTimestamp:     rate.UpdatedAt,
+	}
+
+	return nil
+}
+
+// DestinationAmount is the money credited to the destination account
+func (t *Transaction) DestinationAmount() Money {
+	if t.Conversion != nil {
+		return t.Conversion.DestinationAmount
+	}
+
+	return t.Amount
+}
+
+// AccountAmount is the money that moved in or out of accountID, in the account's currency
+func (t *Transaction) AccountAmount(accountID string) Money {
+	if t.ToAccountID != nil && *t.ToAccountID == accountID {
+		return t.DestinationAmount()
+	}
+
+	return t.Amount
+}
+
+// BalanceChange is what the transaction added to the balance of accountID, negative when money left the account. A
+// transfer to the same account changes nothing.
+func (t *Transaction) BalanceChange(accountID string) (Money, error) {
+	change := Money{Currency: t.AccountAmount(accountID).Currency}
+	if t.ToAccountID != nil && *t.ToAccountID == accountID {
+		change = t.DestinationAmount()
+	}
+	if t.FromAccountID != nil && *t.FromAccountID == accountID {
+		return change.Sub(t.Amount)
+	}
+
+	return change, nil
+}
+
+// ValuedAt is when the transaction counts in the balance of a day, its value date when it has one and otherwise when
+// it was made
+func (t *Transaction) ValuedAt() time.Time {
+	if t.ValueDate != nil {
+		return *t.ValueDate
+	}
+
+	return t.CreatedAt
+}
+
+// Counterparty is the other account of a transfer, deposits and withdrawals have none
+func (t *Transaction) Counterparty(accountID string) string {
+	if t.FromAccountID == nil || t.ToAccountID == nil {
+		return ""
+	}
+	if *t.FromAccountID == accountID {
+		return *t.ToAccountID
+	}
+
+	return *t.FromAccountID
+}
+
+var invalidFromAccountError = tberrors.NewValidationError("missing_from_account", "from account ID is required", "from_account")
+var invalidToAccountError = tberrors.NewValidationError("missing_to_account", "to account ID is required", "to_account")
+var invalidAmountError = tberrors.NewValidationError("invalid_amount", "amount must be greater than zero", "amount")
+var invalidTransactionType = tberrors.NewValidationError("invalid_transaction_type", "invalid transaction type", "type")
+var sameAccountTransferError = tberrors.NewValidationError("same_account", "cannot transfer to the same account", "to_account")
+var missingParentError = tberrors.NewValidationError("missing_parent", "parent transaction ID is required", "parent_id")
 
 func (t *Transaction) validate() (*Transaction, error) {
-	if t.Amount <= 0 {
-		return nil, invalidAmountError
+	var errs []error
+	if !t.Amount.Currency.Valid() {
+		errs = append(errs, invalidCurrencyError)
+	}
+	if !t.Amount.IsPositive() {
+		errs = append(errs, invalidAmountError)
 	}
 
 	switch {
-	case t.Type == Deposit:
+	case t.Type == Deposit, t.Type == Interest:
 		if t.ToAccountID == nil {
-			return nil, invalidToAccountError
+			errs = append(errs, invalidToAccountError)
 		}
-	case t.Type == Withdrawal:
+	case t.Type == Withdrawal, t.Type == OverdraftInterest:
 		if t.FromAccountID == nil {
-			return nil, invalidFromAccountError
+			errs = append(errs, invalidFromAccountError)
+		}
+	case t.Type == Fee:
+		if t.FromAccountID == nil {
+			errs = append(errs, invalidFromAccountError)
+		}
+		if t.ParentID == "" {
+			errs = append(errs, missingParentError)
 		}
 	case t.Type == Transfer:
 		if t.FromAccountID == nil {
-			return nil, invalidFromAccountError
+			errs = append(errs, invalidFromAccountError)
 		}
 		if t.ToAccountID == nil {
-			return nil, invalidToAccountError
+			errs = append(errs, invalidToAccountError)
+		}
+		if t.FromAccountID != nil && t.ToAccountID != nil && *t.FromAccountID == *t.ToAccountID {
+			errs = append(errs, sameAccountTransferError)
+		}
+	case t.Type == Reversal:
+		if t.FromAccountID == nil && t.ToAccountID == nil {
+			errs = append(errs, invalidFromAccountError)
+		}
+		if t.FromAccountID != nil && t.ToAccountID != nil && *t.FromAccountID == *t.ToAccountID {
+			errs = append(errs, sameAccountTransferError)
+		}
+		if t.ParentID == "" {
+			errs = append(errs, missingParentError)
 		}
 	default:
-		return nil, invalidTransactionType
+		errs = append(errs, invalidTransactionType)
+	}
 
+	// every invalid field is reported at once so clients can fix them in one go
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
 	}
 
 	return t, nil
@@ -17,8 +17,9 @@ func TestTransaction_validate(t *testing.T) {
 		CreatedAt     time.Time
 		FromAccountID *string
 		ToAccountID   *string
-		Amount        int
+		Amount        Money
 		Type          TransactionType
+		ParentID      string
 	}
 	tests := []struct {
 		name    string
@@ -32,14 +33,14 @@ func TestTransaction_validate(t *testing.T) {
 				ID:            "1",
 				FromAccountID: &fromAccountID,
 				ToAccountID:   &toAccountID,
-				Amount:        100,
+				Amount:        eur(100),
 				Type:          Transfer,
 			},
 			want: &Transaction{
 				ID:            "1",
 				FromAccountID: &fromAccountID,
 				ToAccountID:   &toAccountID,
-				Amount:        100,
+				Amount:        eur(100),
 				Type:          Transfer,
 			},
 		},
@@ -49,7 +50,7 @@ func TestTransaction_validate(t *testing.T) {
 				ID:            "1",
 				FromAccountID: &fromAccountID,
 				ToAccountID:   &toAccountID,
-				Amount:        -1,
+				Amount:        eur(-1),
 				Type:          Transfer,
 			},
 			wantErr: invalidAmountError,
@@ -59,7 +60,7 @@ func TestTransaction_validate(t *testing.T) {
 			fields: fields{
 				ID:          "1",
 				ToAccountID: &toAccountID,
-				Amount:      100,
+				Amount:      eur(100),
 				Type:        Transfer,
 			},
 			wantErr: invalidFromAccountError,
@@ -69,7 +70,7 @@ func TestTransaction_validate(t *testing.T) {
 			fields: fields{
 				ID:            "1",
 				FromAccountID: &fromAccountID,
-				Amount:        100,
+				Amount:        eur(100),
 				Type:          Transfer,
 			},
 			wantErr: invalidToAccountError,
@@ -79,13 +80,13 @@ func TestTransaction_validate(t *testing.T) {
 			fields: fields{
 				ID:          "1",
 				ToAccountID: &toAccountID,
-				Amount:      100,
+				Amount:      eur(100),
 				Type:        Deposit,
 			},
 			want: &Transaction{
 				ID:          "1",
 				ToAccountID: &toAccountID,
-				Amount:      100,
+				Amount:      eur(100),
 				Type:        Deposit,
 			},
 		},
@@ -93,7 +94,7 @@ func TestTransaction_validate(t *testing.T) {
 			name: "deposit type, invalid to account, want invalidToAccountError",
 			fields: fields{
 				ID:     "1",
-				Amount: 100,
+				Amount: eur(100),
 				Type:   Deposit,
 			},
 			wantErr: invalidToAccountError,
@@ -102,11 +103,69 @@ func TestTransaction_validate(t *testing.T) {
 			name: "withdrawal type, invalid from account, want invalidFromAccountError",
 			fields: fields{
 				ID:     "1",
-				Amount: 100,
+				Amount: eur(100),
 				Type:   Withdrawal,
 			},
 			wantErr: invalidFromAccountError,
 		},
+		{
+			name: "transfer type, invalid amount and to account, want invalidToAccountError reported too",
+			fields: fields{
+				ID:            "1",
+				FromAccountID: &fromAccountID,
+				Type:          Transfer,
+			},
+			wantErr: invalidToAccountError,
+		},
+		{
+			name: "transfer type, same from and to account, want sameAccountTransferError",
+			fields: fields{
+				ID:            "1",
+				FromAccountID: &fromAccountID,
+				ToAccountID:   &fromAccountID,
+				Amount:        eur(100),
+				Type:          Transfer,
+			},
+			wantErr: sameAccountTransferError,
+		},
+		{
+			name: "reversal type of a deposit, valid",
+			fields: fields{
+				ID:            "2",
+				FromAccountID: &toAccountID,
+				Amount:        eur(40),
+				Type:          Reversal,
+				ParentID:      "1",
+			},
+			want: &Transaction{
+				ID:            "2",
+				FromAccountID: &toAccountID,
+				Amount:        eur(40),
+				Type:          Reversal,
+				ParentID:      "1",
+			},
+		},
+		{
+			name: "reversal type, no account, want invalidFromAccountError",
+			fields: fields{
+				ID:       "2",
+				Amount:   eur(40),
+				Type:     Reversal,
+				ParentID: "1",
+			},
+			wantErr: invalidFromAccountError,
+		},
+		{
+			name: "reversal type, no parent, want missingParentError",
+			fields: fields{
+				ID:            "2",
+				FromAccountID: &toAccountID,
+				ToAccountID:   &fromAccountID,
+				Amount:        eur(40),
+				Type:          Reversal,
+			},
+			wantErr: missingParentError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t1 *testing.T) {
@@ -117,6 +176,7 @@ func TestTransaction_validate(t *testing.T) {
 				ToAccountID:   tt.fields.ToAccountID,
 				Amount:        tt.fields.Amount,
 				Type:          tt.fields.Type,
+				ParentID:      tt.fields.ParentID,
 			}
 			got, err := tr.validate()
 			if !errors.Is(err, tt.wantErr) {
@@ -130,3 +190,34 @@ func TestTransaction_validate(t *testing.T) {
 		})
 	}
 }
+
+func TestTransaction_BalanceChange(t *testing.T) {
+	account, other := "1", "2"
+	converted := &Transaction{FromAccountID: &other, ToAccountID: &account, Amount: eur(100), Type: Transfer}
+	converted.Conversion = &FXConversion{DestinationAmount: NewMoney(108, USD), Rate: "1.08"}
+
+	tests := []struct {
+		name        string
+		transaction *Transaction
+		want        Money
+	}{
+		{name: "deposit", transaction: &Transaction{ToAccountID: &account, Amount: eur(100), Type: Deposit}, want: eur(100)},
+		{name: "withdrawal", transaction: &Transaction{FromAccountID: &account, Amount: eur(100), Type: Withdrawal}, want: eur(-100)},
+		{name: "transfer out", transaction: &Transaction{FromAccountID: &account, ToAccountID: &other, Amount: eur(100), Type: Transfer}, want: eur(-100)},
+		{name: "converted transfer in", transaction: converted, want: NewMoney(108, USD)},
+		{name: "transfer to itself", transaction: &Transaction{FromAccountID: &account, ToAccountID: &account, Amount: eur(100), Type: Transfer}, want: eur(0)},
+		{name: "other account", transaction: &Transaction{ToAccountID: &other, Amount: eur(100), Type: Deposit}, want: eur(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.transaction.BalanceChange(account)
+			if err != nil {
+				t.Fatalf("BalanceChange() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("BalanceChange() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
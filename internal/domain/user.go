@@ -22,7 +22,7 @@ func NewUser(name string) (*User, error) {
 	return u.validate()
 }
 
-var invalidEmptyNameError = tberrors.NewValidationError("invalid empty name", "name")
+var invalidEmptyNameError = tberrors.NewValidationError("missing_name", "invalid empty name", "name")
 
 func (u *User) validate() (*User, error) {
 	if u.Name == "" {
@@ -31,3 +31,16 @@ func (u *User) validate() (*User, error) {
 
 	return u, nil
 }
+
+var userNotDeletedError = tberrors.NewConflictError("user_not_deleted", "user is not deleted", "user_id")
+
+// Restore reactivates a deleted user
+func (u *User) Restore() error {
+	if u.DeletedAt == nil {
+		return userNotDeletedError
+	}
+
+	u.DeletedAt = nil
+
+	return nil
+}
@@ -58,3 +58,35 @@ func TestUser_validate(t *testing.T) {
 		})
 	}
 }
+
+func TestUser_Restore(t *testing.T) {
+	deletedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		deletedAt *time.Time
+		wantErr   error
+	}{
+		{
+			name:      "restore deleted user",
+			deletedAt: &deletedAt,
+		},
+		{
+			name:    "restore active user, want userNotDeletedError",
+			wantErr: userNotDeletedError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &User{ID: "id", Name: "name", DeletedAt: tt.deletedAt}
+
+			if err := u.Restore(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Restore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if u.DeletedAt != nil {
+				t.Errorf("Restore() deleted at got = %v, want nil", u.DeletedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package eventlog
+
+import "errors"
+
+var failedToOpenLog = errors.New("failed to open event log")
+var failedToReplay = errors.New("failed to replay event log")
+var failedToAppend = errors.New("failed to append to event log")
+var failedToSnapshot = errors.New("failed to snapshot event log")
+var corruptedLog = errors.New("event log is corrupted")
+var logFailed = errors.New("event log failed and refuses writes until restarted")
+var invalidRecord = errors.New("invalid record")
+var recordTooLarge = errors.New("record is too large")
+var unknownEventType = errors.New("unknown event type")
+var invalidEvent = errors.New("invalid event")
+var invalidSyncPolicy = errors.New("invalid sync policy")
+var invalidSyncInterval = errors.New("sync interval must be positive")
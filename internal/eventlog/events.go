@@ -0,0 +1,759 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"http/internal/domain"
+)
+
+// Event is a single state change, every record holds the events of one write so they are replayed all together
+// or not at all.
+type Event interface {
+	eventType() string
+}
+
+type UserCreated struct {
+	User domain.User
+}
+
+type UserDeleted struct {
+	UserID    string
+	DeletedAt time.Time
+}
+
+type UserRestored struct {
+	UserID string
+}
+
+// UserUpdated carries the whole user for changes other than a deletion or a restoration
+type UserUpdated struct {
+	User domain.User
+}
+
+type AccountOpened struct {
+	Account domain.Account
+}
+
+type BalanceChanged struct {
+	AccountID string
+	Balance   domain.Money
+}
+
+type AccountClosed struct {
+	AccountID string
+	ClosedAt  time.Time
+}
+
+type AccountReopened struct {
+	AccountID string
+}
+
+// AccountUpdated carries the whole account for changes other than its balance or closing
+type AccountUpdated struct {
+	Account domain.Account
+}
+
+type TransactionRecorded struct {
+	Transaction domain.Transaction
+}
+
+type JournalEntryPosted struct {
+	Entry domain.JournalEntry
+}
+
+type AuditRecorded struct {
+	Event domain.AuditEvent
+}
+
+type BatchCreated struct {
+	Batch domain.Batch
+}
+
+// BatchUpdated carries the whole batch with the status of every line
+type BatchUpdated struct {
+	Batch domain.Batch
+}
+
+// BatchLineUpdated carries a line of a batch once it was made or failed
+type BatchLineUpdated struct {
+	BatchID string
+	Line    domain.BatchLine
+}
+
+type StandingOrderCreated struct {
+	Order domain.StandingOrder
+}
+
+// StandingOrderUpdated carries the whole standing order after it ran, was cancelled or resumed
+type StandingOrderUpdated struct {
+	Order domain.StandingOrder
+}
+
+type StandingOrderExecuted struct {
+	Execution domain.StandingOrderExecution
+}
+
+// StandingOrderExecutionUpdated carries the whole execution once its payment was made or failed
+type StandingOrderExecutionUpdated struct {
+	Execution domain.StandingOrderExecution
+}
+
+type HoldPlaced struct {
+	Hold domain.Hold
+}
+
+// HoldUpdated carries the whole hold after it was captured, voided or expired
+type HoldUpdated struct {
+	Hold domain.Hold
+}
+
+func (UserCreated) eventType() string                   { return "UserCreated" }
+func (UserDeleted) eventType() string                   { return "UserDeleted" }
+func (UserRestored) eventType() string                  { return "UserRestored" }
+func (UserUpdated) eventType() string                   { return "UserUpdated" }
+func (AccountOpened) eventType() string                 { return "AccountOpened" }
+func (BalanceChanged) eventType() string                { return "BalanceChanged" }
+func (AccountClosed) eventType() string                 { return "AccountClosed" }
+func (AccountReopened) eventType() string               { return "AccountReopened" }
+func (AccountUpdated) eventType() string                { return "AccountUpdated" }
+func (TransactionRecorded) eventType() string           { return "TransactionRecorded" }
+func (JournalEntryPosted) eventType() string            { return "JournalEntryPosted" }
+func (AuditRecorded) eventType() string                 { return "AuditRecorded" }
+func (BatchCreated) eventType() string                  { return "BatchCreated" }
+func (BatchUpdated) eventType() string                  { return "BatchUpdated" }
+func (BatchLineUpdated) eventType() string              { return "BatchLineUpdated" }
+func (StandingOrderCreated) eventType() string          { return "StandingOrderCreated" }
+func (StandingOrderUpdated) eventType() string          { return "StandingOrderUpdated" }
+func (StandingOrderExecuted) eventType() string         { return "StandingOrderExecuted" }
+func (StandingOrderExecutionUpdated) eventType() string { return "StandingOrderExecutionUpdated" }
+func (HoldPlaced) eventType() string                    { return "HoldPlaced" }
+func (HoldUpdated) eventType() string                   { return "HoldUpdated" }
+
+// envelope is how an event is stored, data holds one of the record types below
+type envelope struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// the record types spell out every field with a stable name, domain types are free to change their JSON form for
+// the API without breaking logs already written
+
+type money struct {
+	Amount   int64           `json:"amount"`
+	Currency domain.Currency `json:"currency"`
+}
+
+type userRecord struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type accountRecord struct {
+	ID        string             `json:"id"`
+	UserID    string             `json:"user_id"`
+	Balance   money              `json:"balance"`
+	Type      domain.AccountType `json:"type"`
+	Nickname  string             `json:"nickname,omitempty"`
+	DeletedAt *time.Time         `json:"deleted_at,omitempty"`
+	Overdraft *overdraftRecord   `json:"overdraft,omitempty"`
+	Interest  *interestRecord    `json:"interest,omitempty"`
+	Held      *money             `json:"held,omitempty"`
+}
+
+type overdraftRecord struct {
+	Limit          money     `json:"limit"`
+	Rate           string    `json:"rate"`
+	Accrued        string    `json:"accrued,omitempty"`
+	AccruedThrough time.Time `json:"accrued_through"`
+}
+
+type interestRecord struct {
+	Plan           string    `json:"plan,omitempty"`
+	Accrued        string    `json:"accrued,omitempty"`
+	AccruedThrough time.Time `json:"accrued_through"`
+}
+
+type conversionRecord struct {
+	DestinationAmount money     `json:"destination_amount"`
+	Rate              string    `json:"rate"`
+	RateTimestamp     time.Time `json:"rate_timestamp"`
+}
+
+type transactionRecord struct {
+	ID            string                 `json:"id"`
+	CreatedAt     time.Time              `json:"created_at"`
+	FromAccountID *string                `json:"from_account_id,omitempty"`
+	ToAccountID   *string                `json:"to_account_id,omitempty"`
+	Amount        money                  `json:"amount"`
+	Type          domain.TransactionType `json:"type"`
+	Conversion    *conversionRecord      `json:"conversion,omitempty"`
+	ParentID      string                 `json:"parent_id,omitempty"`
+	ValueDate     *time.Time             `json:"value_date,omitempty"`
+}
+
+type journalLineRecord struct {
+	AccountID string           `json:"account_id"`
+	Side      domain.EntrySide `json:"side"`
+	Amount    money            `json:"amount"`
+}
+
+type journalEntryRecord struct {
+	ID            string              `json:"id"`
+	TransactionID string              `json:"transaction_id"`
+	CreatedAt     time.Time           `json:"created_at"`
+	Lines         []journalLineRecord `json:"lines"`
+}
+
+type auditRecord struct {
+	ID         string             `json:"id"`
+	UserID     string             `json:"user_id"`
+	Action     domain.AuditAction `json:"action"`
+	AccountIDs []string           `json:"account_ids"`
+	OccurredAt time.Time          `json:"occurred_at"`
+}
+
+type batchLineRecord struct {
+	Number        int                    `json:"number"`
+	ToAccountID   string                 `json:"to_account_id"`
+	Amount        money                  `json:"amount"`
+	Reference     string                 `json:"reference,omitempty"`
+	Status        domain.BatchLineStatus `json:"status"`
+	TransactionID string                 `json:"transaction_id,omitempty"`
+	FailureCode   string                 `json:"failure_code,omitempty"`
+	FailureReason string                 `json:"failure_reason,omitempty"`
+}
+
+type batchRecord struct {
+	ID          string             `json:"id"`
+	AccountID   string             `json:"account_id"`
+	Mode        domain.BatchMode   `json:"mode"`
+	Status      domain.BatchStatus `json:"status"`
+	Lines       []batchLineRecord  `json:"lines"`
+	CreatedAt   time.Time          `json:"created_at"`
+	CompletedAt *time.Time         `json:"completed_at,omitempty"`
+}
+
+type batchLineUpdateRecord struct {
+	BatchID string          `json:"batch_id"`
+	Line    batchLineRecord `json:"line"`
+}
+
+type standingOrderRecord struct {
+	ID          string                     `json:"id"`
+	AccountID   string                     `json:"account_id"`
+	ToAccountID string                     `json:"to_account_id"`
+	Amount      money                      `json:"amount"`
+	Reference   string                     `json:"reference,omitempty"`
+	Schedule    string                     `json:"schedule"`
+	TimeZone    string                     `json:"time_zone"`
+	StartAt     time.Time                  `json:"start_at"`
+	EndAt       *time.Time                 `json:"end_at,omitempty"`
+	Retries     int                        `json:"retries"`
+	RetryEvery  time.Duration              `json:"retry_interval_ns"`
+	OnFailure   domain.FailureAction       `json:"on_failure"`
+	Status      domain.StandingOrderStatus `json:"status"`
+	NextRunAt   *time.Time                 `json:"next_run_at,omitempty"`
+	DueAt       *time.Time                 `json:"due_at,omitempty"`
+	Attempts    int                        `json:"attempts"`
+	CreatedAt   time.Time                  `json:"created_at"`
+}
+
+type executionRecord struct {
+	ID            string                 `json:"id"`
+	OrderID       string                 `json:"order_id"`
+	ScheduledFor  time.Time              `json:"scheduled_for"`
+	ExecutedAt    time.Time              `json:"executed_at"`
+	Attempt       int                    `json:"attempt"`
+	Status        domain.ExecutionStatus `json:"status"`
+	TransactionID string                 `json:"transaction_id,omitempty"`
+	FailureCode   string                 `json:"failure_code,omitempty"`
+	FailureReason string                 `json:"failure_reason,omitempty"`
+}
+
+type holdRecord struct {
+	ID            string            `json:"id"`
+	AccountID     string            `json:"account_id"`
+	ToAccountID   string            `json:"to_account_id,omitempty"`
+	Amount        money             `json:"amount"`
+	Status        domain.HoldStatus `json:"status"`
+	Captured      *money            `json:"captured,omitempty"`
+	TransactionID string            `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	ReleasedAt    *time.Time        `json:"released_at,omitempty"`
+}
+
+type userIDRecord struct {
+	UserID    string     `json:"user_id"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type balanceRecord struct {
+	AccountID string `json:"account_id"`
+	Balance   money  `json:"balance"`
+}
+
+type accountIDRecord struct {
+	AccountID string     `json:"account_id"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+}
+
+func encodeEvent(event Event) (envelope, error) {
+	var data any
+	switch e := event.(type) {
+	case UserCreated:
+		data = fromUser(e.User)
+	case UserDeleted:
+		data = userIDRecord{UserID: e.UserID, DeletedAt: &e.DeletedAt}
+	case UserRestored:
+		data = userIDRecord{UserID: e.UserID}
+	case UserUpdated:
+		data = fromUser(e.User)
+	case AccountOpened:
+		data = fromAccount(e.Account)
+	case BalanceChanged:
+		data = balanceRecord{AccountID: e.AccountID, Balance: fromMoney(e.Balance)}
+	case AccountClosed:
+		data = accountIDRecord{AccountID: e.AccountID, ClosedAt: &e.ClosedAt}
+	case AccountReopened:
+		data = accountIDRecord{AccountID: e.AccountID}
+	case AccountUpdated:
+		data = fromAccount(e.Account)
+	case TransactionRecorded:
+		data = fromTransaction(e.Transaction)
+	case JournalEntryPosted:
+		data = fromJournalEntry(e.Entry)
+	case AuditRecorded:
+		data = auditRecord{
+			ID:         e.Event.ID,
+			UserID:     e.Event.UserID,
+			Action:     e.Event.Action,
+			AccountIDs: e.Event.AccountIDs,
+			OccurredAt: e.Event.OccurredAt,
+		}
+	case BatchCreated:
+		data = fromBatch(e.Batch)
+	case BatchUpdated:
+		data = fromBatch(e.Batch)
+	case BatchLineUpdated:
+		data = batchLineUpdateRecord{BatchID: e.BatchID, Line: fromBatchLine(e.Line)}
+	case StandingOrderCreated:
+		data = fromStandingOrder(e.Order)
+	case StandingOrderUpdated:
+		data = fromStandingOrder(e.Order)
+	case StandingOrderExecuted:
+		data = executionRecord(e.Execution)
+	case StandingOrderExecutionUpdated:
+		data = executionRecord(e.Execution)
+	case HoldPlaced:
+		data = fromHold(e.Hold)
+	case HoldUpdated:
+		data = fromHold(e.Hold)
+	default:
+		return envelope{}, fmt.Errorf("%w: %T", unknownEventType, event)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return envelope{}, err
+	}
+
+	return envelope{Type: event.eventType(), Data: raw}, nil
+}
+
+func decodeEvent(env envelope) (Event, error) {
+	switch env.Type {
+	case UserCreated{}.eventType():
+		user, err := decode[userRecord](env.Data)
+		return UserCreated{User: user.toDomain()}, err
+	case UserDeleted{}.eventType():
+		record, err := decode[userIDRecord](env.Data)
+		if err == nil && record.DeletedAt == nil {
+			err = fmt.Errorf("%w: %s without deleted_at", invalidEvent, env.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return UserDeleted{UserID: record.UserID, DeletedAt: *record.DeletedAt}, nil
+	case UserRestored{}.eventType():
+		record, err := decode[userIDRecord](env.Data)
+		return UserRestored{UserID: record.UserID}, err
+	case UserUpdated{}.eventType():
+		user, err := decode[userRecord](env.Data)
+		return UserUpdated{User: user.toDomain()}, err
+	case AccountOpened{}.eventType():
+		account, err := decode[accountRecord](env.Data)
+		return AccountOpened{Account: account.toDomain()}, err
+	case BalanceChanged{}.eventType():
+		record, err := decode[balanceRecord](env.Data)
+		return BalanceChanged{AccountID: record.AccountID, Balance: record.Balance.toDomain()}, err
+	case AccountClosed{}.eventType():
+		record, err := decode[accountIDRecord](env.Data)
+		if err == nil && record.ClosedAt == nil {
+			err = fmt.Errorf("%w: %s without closed_at", invalidEvent, env.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return AccountClosed{AccountID: record.AccountID, ClosedAt: *record.ClosedAt}, nil
+	case AccountReopened{}.eventType():
+		record, err := decode[accountIDRecord](env.Data)
+		return AccountReopened{AccountID: record.AccountID}, err
+	case AccountUpdated{}.eventType():
+		account, err := decode[accountRecord](env.Data)
+		return AccountUpdated{Account: account.toDomain()}, err
+	case TransactionRecorded{}.eventType():
+		transaction, err := decode[transactionRecord](env.Data)
+		return TransactionRecorded{Transaction: transaction.toDomain()}, err
+	case JournalEntryPosted{}.eventType():
+		entry, err := decode[journalEntryRecord](env.Data)
+		return JournalEntryPosted{Entry: entry.toDomain()}, err
+	case AuditRecorded{}.eventType():
+		record, err := decode[auditRecord](env.Data)
+		return AuditRecorded{Event: domain.AuditEvent{
+			ID:         record.ID,
+			UserID:     record.UserID,
+			Action:     record.Action,
+			AccountIDs: record.AccountIDs,
+			OccurredAt: record.OccurredAt,
+		}}, err
+	case BatchCreated{}.eventType():
+		batch, err := decode[batchRecord](env.Data)
+		return BatchCreated{Batch: batch.toDomain()}, err
+	case BatchUpdated{}.eventType():
+		batch, err := decode[batchRecord](env.Data)
+		return BatchUpdated{Batch: batch.toDomain()}, err
+	case BatchLineUpdated{}.eventType():
+		record, err := decode[batchLineUpdateRecord](env.Data)
+		return BatchLineUpdated{BatchID: record.BatchID, Line: record.Line.toDomain()}, err
+	case StandingOrderCreated{}.eventType():
+		order, err := decode[standingOrderRecord](env.Data)
+		return StandingOrderCreated{Order: order.toDomain()}, err
+	case StandingOrderUpdated{}.eventType():
+		order, err := decode[standingOrderRecord](env.Data)
+		return StandingOrderUpdated{Order: order.toDomain()}, err
+	case StandingOrderExecuted{}.eventType():
+		execution, err := decode[executionRecord](env.Data)
+		return StandingOrderExecuted{Execution: domain.StandingOrderExecution(execution)}, err
+	case StandingOrderExecutionUpdated{}.eventType():
+		execution, err := decode[executionRecord](env.Data)
+		return StandingOrderExecutionUpdated{Execution: domain.StandingOrderExecution(execution)}, err
+	case HoldPlaced{}.eventType():
+		hold, err := decode[holdRecord](env.Data)
+		return HoldPlaced{Hold: hold.toDomain()}, err
+	case HoldUpdated{}.eventType():
+		hold, err := decode[holdRecord](env.Data)
+		return HoldUpdated{Hold: hold.toDomain()}, err
+	default:
+		return nil, fmt.Errorf("%w: %q", unknownEventType, env.Type)
+	}
+}
+
+func decode[T any](data json.RawMessage) (T, error) {
+	var record T
+	err := json.Unmarshal(data, &record)
+	return record, err
+}
+
+func fromMoney(m domain.Money) money {
+	return money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func (m money) toDomain() domain.Money {
+	return domain.NewMoney(m.Amount, m.Currency)
+}
+
+func fromUser(user domain.User) userRecord {
+	return userRecord{ID: user.ID, Name: user.Name, DeletedAt: user.DeletedAt}
+}
+
+func (record userRecord) toDomain() domain.User {
+	return domain.User{ID: record.ID, Name: record.Name, DeletedAt: record.DeletedAt}
+}
+
+func fromAccount(account domain.Account) accountRecord {
+	record := accountRecord{
+		ID:        account.ID,
+		UserID:    account.UserID,
+		Balance:   fromMoney(account.Balance),
+		Type:      account.Type,
+		Nickname:  account.Nickname,
+		DeletedAt: account.DeletedAt,
+	}
+
+	// accounts that never had an overdraft are written as before overdrafts existed
+	if overdraft := account.Overdraft; overdraft != (domain.Overdraft{}) {
+		record.Overdraft = &overdraftRecord{
+			Limit:          fromMoney(overdraft.Limit),
+			Rate:           overdraft.Rate,
+			Accrued:        overdraft.Accrued,
+			AccruedThrough: overdraft.AccruedThrough,
+		}
+	}
+	if interest := account.Interest; interest != (domain.SavingsInterest{}) {
+		record.Interest = &interestRecord{
+			Plan:           interest.Plan,
+			Accrued:        interest.Accrued,
+			AccruedThrough: interest.AccruedThrough,
+		}
+	}
+	// accounts that never had a hold have no currency for it
+	if held := account.Held; held != (domain.Money{}) {
+		record.Held = &money{Amount: held.Amount, Currency: held.Currency}
+	}
+
+	return record
+}
+
+func (record accountRecord) toDomain() domain.Account {
+	account := domain.Account{
+		ID:        record.ID,
+		UserID:    record.UserID,
+		Balance:   record.Balance.toDomain(),
+		Type:      record.Type,
+		Nickname:  record.Nickname,
+		DeletedAt: record.DeletedAt,
+	}
+
+	if overdraft := record.Overdraft; overdraft != nil {
+		account.Overdraft = domain.Overdraft{
+			Limit:          overdraft.Limit.toDomain(),
+			Rate:           overdraft.Rate,
+			Accrued:        overdraft.Accrued,
+			AccruedThrough: overdraft.AccruedThrough,
+		}
+	}
+	if interest := record.Interest; interest != nil {
+		account.Interest = domain.SavingsInterest{
+			Plan:           interest.Plan,
+			Accrued:        interest.Accrued,
+			AccruedThrough: interest.AccruedThrough,
+		}
+	}
+	if held := record.Held; held != nil {
+		account.Held = held.toDomain()
+	}
+
+	return account
+}
+
+func fromTransaction(transaction domain.Transaction) transactionRecord {
+	record := transactionRecord{
+		ID:            transaction.ID,
+		CreatedAt:     transaction.CreatedAt,
+		FromAccountID: transaction.FromAccountID,
+		ToAccountID:   transaction.ToAccountID,
+		Amount:        fromMoney(transaction.Amount),
+		Type:          transaction.Type,
+		ParentID:      transaction.ParentID,
+		ValueDate:     transaction.ValueDate,
+	}
+
+	if conversion := transaction.Conversion; conversion != nil {
+		record.Conversion = &conversionRecord{
+			DestinationAmount: fromMoney(conversion.DestinationAmount),
+			Rate:              conversion.Rate,
+			RateTimestamp:     conversion.RateTimestamp,
+		}
+	}
+
+	return record
+}
+
+func (record transactionRecord) toDomain() domain.Transaction {
+	transaction := domain.Transaction{
+		ID:            record.ID,
+		CreatedAt:     record.CreatedAt,
+		FromAccountID: record.FromAccountID,
+		ToAccountID:   record.ToAccountID,
+		Amount:        record.Amount.toDomain(),
+		Type:          record.Type,
+		ParentID:      record.ParentID,
+		ValueDate:     record.ValueDate,
+	}
+
+	if conversion := record.Conversion; conversion != nil {
+		transaction.Conversion = &domain.FXConversion{
+			DestinationAmount: conversion.DestinationAmount.toDomain(),
+			Rate:              conversion.Rate,
+			RateTimestamp:     conversion.RateTimestamp,
+		}
+	}
+
+	return transaction
+}
+
+func fromJournalEntry(entry domain.JournalEntry) journalEntryRecord {
+	lines := make([]journalLineRecord, len(entry.Lines))
+	for i, line := range entry.Lines {
+		lines[i] = journalLineRecord{AccountID: line.AccountID, Side: line.Side, Amount: fromMoney(line.Amount)}
+	}
+
+	return journalEntryRecord{
+		ID:            entry.ID,
+		TransactionID: entry.TransactionID,
+		CreatedAt:     entry.CreatedAt,
+		Lines:         lines,
+	}
+}
+
+func (record journalEntryRecord) toDomain() domain.JournalEntry {
+	lines := make([]domain.JournalLine, len(record.Lines))
+	for i, line := range record.Lines {
+		lines[i] = domain.JournalLine{AccountID: line.AccountID, Side: line.Side, Amount: line.Amount.toDomain()}
+	}
+
+	return domain.JournalEntry{
+		ID:            record.ID,
+		TransactionID: record.TransactionID,
+		CreatedAt:     record.CreatedAt,
+		Lines:         lines,
+	}
+}
+
+func fromBatch(batch domain.Batch) batchRecord {
+	lines := make([]batchLineRecord, len(batch.Lines))
+	for i, line := range batch.Lines {
+		lines[i] = fromBatchLine(line)
+	}
+
+	return batchRecord{
+		ID:          batch.ID,
+		AccountID:   batch.AccountID,
+		Mode:        batch.Mode,
+		Status:      batch.Status,
+		Lines:       lines,
+		CreatedAt:   batch.CreatedAt,
+		CompletedAt: batch.CompletedAt,
+	}
+}
+
+func (record batchRecord) toDomain() domain.Batch {
+	lines := make([]domain.BatchLine, len(record.Lines))
+	for i, line := range record.Lines {
+		lines[i] = line.toDomain()
+	}
+
+	return domain.Batch{
+		ID:          record.ID,
+		AccountID:   record.AccountID,
+		Mode:        record.Mode,
+		Status:      record.Status,
+		Lines:       lines,
+		CreatedAt:   record.CreatedAt,
+		CompletedAt: record.CompletedAt,
+	}
+}
+
+func fromBatchLine(line domain.BatchLine) batchLineRecord {
+	return batchLineRecord{
+		Number:        line.Number,
+		ToAccountID:   line.ToAccountID,
+		Amount:        fromMoney(line.Amount),
+		Reference:     line.Reference,
+		Status:        line.Status,
+		TransactionID: line.TransactionID,
+		FailureCode:   line.FailureCode,
+		FailureReason: line.FailureReason,
+	}
+}
+
+func (record batchLineRecord) toDomain() domain.BatchLine {
+	return domain.BatchLine{
+		Number:        record.Number,
+		ToAccountID:   record.ToAccountID,
+		Amount:        record.Amount.toDomain(),
+		Reference:     record.Reference,
+		Status:        record.Status,
+		TransactionID: record.TransactionID,
+		FailureCode:   record.FailureCode,
+		FailureReason: record.FailureReason,
+	}
+}
+
+func fromStandingOrder(order domain.StandingOrder) standingOrderRecord {
+	return standingOrderRecord{
+		ID:          order.ID,
+		AccountID:   order.AccountID,
+		ToAccountID: order.ToAccountID,
+		Amount:      fromMoney(order.Amount),
+		Reference:   order.Reference,
+		Schedule:    order.Schedule,
+		TimeZone:    order.TimeZone,
+		StartAt:     order.StartAt,
+		EndAt:       order.EndAt,
+		Retries:     order.Policy.Retries,
+		RetryEvery:  order.Policy.RetryInterval,
+		OnFailure:   order.Policy.OnFailure,
+		Status:      order.Status,
+		NextRunAt:   order.NextRunAt,
+		DueAt:       order.DueAt,
+		Attempts:    order.Attempts,
+		CreatedAt:   order.CreatedAt,
+	}
+}
+
+func (record standingOrderRecord) toDomain() domain.StandingOrder {
+	return domain.StandingOrder{
+		ID:          record.ID,
+		AccountID:   record.AccountID,
+		ToAccountID: record.ToAccountID,
+		Amount:      record.Amount.toDomain(),
+		Reference:   record.Reference,
+		Schedule:    record.Schedule,
+		TimeZone:    record.TimeZone,
+		StartAt:     record.StartAt,
+		EndAt:       record.EndAt,
+		Policy: domain.FailurePolicy{
+			Retries:       record.Retries,
+			RetryInterval: record.RetryEvery,
+			OnFailure:     record.OnFailure,
+		},
+		Status:    record.Status,
+		NextRunAt: record.NextRunAt,
+		DueAt:     record.DueAt,
+		Attempts:  record.Attempts,
+		CreatedAt: record.CreatedAt,
+	}
+}
+
+func fromHold(hold domain.Hold) holdRecord {
+	record := holdRecord{
+		ID:            hold.ID,
+		AccountID:     hold.AccountID,
+		ToAccountID:   hold.ToAccountID,
+		Amount:        fromMoney(hold.Amount),
+		Status:        hold.Status,
+		TransactionID: hold.TransactionID,
+		CreatedAt:     hold.CreatedAt,
+		ExpiresAt:     hold.ExpiresAt,
+		ReleasedAt:    hold.ReleasedAt,
+	}
+	if captured := hold.Captured; captured != (domain.Money{}) {
+		record.Captured = &money{Amount: captured.Amount, Currency: captured.Currency}
+	}
+
+	return record
+}
+
+func (record holdRecord) toDomain() domain.Hold {
+	hold := domain.Hold{
+		ID:            record.ID,
+		AccountID:     record.AccountID,
+		ToAccountID:   record.ToAccountID,
+		Amount:        record.Amount.toDomain(),
+		Status:        record.Status,
+		TransactionID: record.TransactionID,
+		CreatedAt:     record.CreatedAt,
+		ExpiresAt:     record.ExpiresAt,
+		ReleasedAt:    record.ReleasedAt,
+	}
+	if captured := record.Captured; captured != nil {
+		hold.Captured = captured.toDomain()
+	}
+
+	return hold
+}
@@ -0,0 +1,475 @@
+// Package eventlog is a file backed write-ahead log of state changes. Every write appends one checksummed record
+// holding its events, replaying the snapshot and then the records appended after it rebuilds the state.
+package eventlog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	logFileName      = "events.log"
+	snapshotFileName = "snapshot.log"
+)
+
+// snapshotBatchSize bounds how many events a single snapshot record holds
+const snapshotBatchSize = 1000
+
+// SyncPolicy decides when appended records are flushed to disk. Records that were not flushed can be lost on a
+// power failure but never on a process crash.
+type SyncPolicy string
+
+const (
+	// SyncAlways flushes every record before Append returns
+	SyncAlways SyncPolicy = "always"
+	// SyncInterval flushes pending records every Options.SyncInterval
+	SyncInterval SyncPolicy = "interval"
+	// SyncNever leaves flushing to the operating system
+	SyncNever SyncPolicy = "never"
+)
+
+func ParseSyncPolicy(policy string) (SyncPolicy, error) {
+	switch p := SyncPolicy(policy); p {
+	case SyncAlways, SyncInterval, SyncNever:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w %q, expected always, interval or never", invalidSyncPolicy, policy)
+	}
+}
+
+type Options struct {
+	Sync SyncPolicy
+	// SyncInterval is only used by SyncInterval
+	SyncInterval time.Duration
+}
+
+type Log struct {
+	dir     string
+	options Options
+	file    *os.File
+	// size is the end of the last record, where the next one is written
+	size int64
+	// seq is the sequence of the last record appended or covered by the snapshot
+	seq         uint64
+	snapshotSeq uint64
+	// truncated counts the bytes of a torn final record dropped when the log was opened
+	truncated int64
+	dirty     bool
+	// failed is set once the file may no longer match what was acknowledged, every later append is refused
+	failed error
+	mutex  sync.Mutex
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Open opens the log kept in dir, creating it when needed. A torn final record left by a crash is truncated, any
+// other damage is reported as corruptedLog.
+func Open(dir string, options Options) (*Log, error) {
+	if options.Sync == SyncInterval && options.SyncInterval <= 0 {
+		return nil, errors.Join(failedToOpenLog, invalidSyncInterval)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Join(failedToOpenLog, err)
+	}
+
+	snapshotSeq, err := readSnapshot(filepath.Join(dir, snapshotFileName), nil)
+	if err != nil {
+		return nil, errors.Join(failedToOpenLog, err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, errors.Join(failedToOpenLog, err)
+	}
+
+	log := &Log{
+		dir:         dir,
+		options:     options,
+		file:        file,
+		snapshotSeq: snapshotSeq,
+		stop:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+
+	if err := log.recover(); err != nil {
+		file.Close()
+		return nil, errors.Join(failedToOpenLog, err)
+	}
+
+	go log.syncPeriodically()
+
+	return log, nil
+}
+
+// recover finds the end of the last valid record and drops a torn record after it
+func (log *Log) recover() error {
+	info, err := log.file.Stat()
+	if err != nil {
+		return err
+	}
+	fileSize := info.Size()
+
+	reader := newRecordReader(io.NewSectionReader(log.file, 0, fileSize))
+	var lastSeq uint64
+	for {
+		b, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, errIncompleteRecord) || errors.Is(err, errChecksumMismatch) {
+			torn, tornErr := log.isTorn(reader, fileSize, err)
+			if tornErr != nil {
+				return tornErr
+			}
+			if !torn {
+				return fmt.Errorf("%w: bad record at offset %d: %w", corruptedLog, reader.offset, err)
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: record at offset %d: %w", corruptedLog, reader.offset, err)
+		}
+
+		if lastSeq != 0 && b.Seq != lastSeq+1 {
+			return fmt.Errorf("%w: record at offset %d has sequence %d after %d", corruptedLog, reader.offset, b.Seq, lastSeq)
+		}
+		lastSeq = b.Seq
+	}
+
+	if reader.offset < fileSize {
+		if err := log.file.Truncate(reader.offset); err != nil {
+			return err
+		}
+		if err := log.file.Sync(); err != nil {
+			return err
+		}
+		log.truncated = fileSize - reader.offset
+	}
+
+	log.size = reader.offset
+	log.seq = max(lastSeq, log.snapshotSeq)
+
+	return nil
+}
+
+// isTorn tells a record cut short by a crash, which can only be the last one, from damage in the middle of the log.
+// A bad record is torn when it runs up to the end of the file or only zeroes follow it.
+func (log *Log) isTorn(reader *recordReader, fileSize int64, err error) (bool, error) {
+	if errors.Is(err, errIncompleteRecord) || reader.badRecordEnd >= fileSize {
+		return true, nil
+	}
+
+	rest := bufio.NewReader(io.NewSectionReader(log.file, reader.offset, fileSize-reader.offset))
+	for {
+		b, err := rest.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if b != 0 {
+			return false, nil
+		}
+	}
+}
+
+// Truncated returns how many bytes of a torn final record were dropped when the log was opened
+func (log *Log) Truncated() int64 {
+	return log.truncated
+}
+
+// Pending returns how many records were appended since the last snapshot
+func (log *Log) Pending() uint64 {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	return log.seq - log.snapshotSeq
+}
+
+// Replay hands every event of the snapshot and of the records appended after it to apply, in order. It's meant
+// to run once, right after Open and before the first Append.
+func (log *Log) Replay(apply func(Event) error) error {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	applyBatch := func(b batch) error {
+		for _, env := range b.Events {
+			event, err := decodeEvent(env)
+			if err != nil {
+				return fmt.Errorf("%w: record %d: %w", corruptedLog, b.Seq, err)
+			}
+
+			if err := apply(event); err != nil {
+				return fmt.Errorf("record %d: %w", b.Seq, err)
+			}
+		}
+
+		return nil
+	}
+
+	if _, err := readSnapshot(filepath.Join(log.dir, snapshotFileName), applyBatch); err != nil {
+		return errors.Join(failedToReplay, err)
+	}
+
+	reader := newRecordReader(io.NewSectionReader(log.file, 0, log.size))
+	for {
+		b, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.Join(failedToReplay, err)
+		}
+
+		// records left behind by a crash between writing a snapshot and emptying the log
+		if b.Seq <= log.snapshotSeq {
+			continue
+		}
+
+		if err := applyBatch(b); err != nil {
+			return errors.Join(failedToReplay, err)
+		}
+	}
+}
+
+// Append writes the events as a single record, they are replayed all together or not at all
+func (log *Log) Append(events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	envelopes := make([]envelope, len(events))
+	for i, event := range events {
+		env, err := encodeEvent(event)
+		if err != nil {
+			return errors.Join(failedToAppend, err)
+		}
+		envelopes[i] = env
+	}
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	if log.failed != nil {
+		return errors.Join(failedToAppend, log.failed)
+	}
+
+	record, err := encodeRecord(batch{Seq: log.seq + 1, Events: envelopes})
+	if err != nil {
+		return errors.Join(failedToAppend, err)
+	}
+
+	if _, err := log.file.WriteAt(record, log.size); err != nil {
+		log.discardFrom(log.size, err)
+		return errors.Join(failedToAppend, err)
+	}
+
+	if log.options.Sync == SyncAlways {
+		if err := log.file.Sync(); err != nil {
+			// the record may or may not be on disk, it must not come back on replay once the write was refused
+			log.discardFrom(log.size, err)
+			return errors.Join(failedToAppend, err)
+		}
+	} else {
+		log.dirty = true
+	}
+
+	log.size += int64(len(record))
+	log.seq++
+
+	return nil
+}
+
+// discardFrom cuts off a record that failed to be written, the log is failed when even that is impossible. It
+// expects the caller to hold the lock.
+func (log *Log) discardFrom(offset int64, cause error) {
+	if err := log.file.Truncate(offset); err != nil {
+		log.failed = errors.Join(logFailed, cause, err)
+		return
+	}
+
+	if err := log.file.Sync(); err != nil {
+		log.failed = errors.Join(logFailed, cause, err)
+	}
+}
+
+// Snapshot replaces the snapshot with events, which must hold the whole state as of the last append, and empties
+// the log. The caller must keep writes from happening until it returns.
+func (log *Log) Snapshot(events []Event) error {
+	envelopes := make([]envelope, len(events))
+	for i, event := range events {
+		env, err := encodeEvent(event)
+		if err != nil {
+			return errors.Join(failedToSnapshot, err)
+		}
+		envelopes[i] = env
+	}
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	if log.failed != nil {
+		return errors.Join(failedToSnapshot, log.failed)
+	}
+
+	path := filepath.Join(log.dir, snapshotFileName)
+	if err := writeSnapshot(path, log.seq, envelopes); err != nil {
+		return errors.Join(failedToSnapshot, err)
+	}
+	log.snapshotSeq = log.seq
+
+	// a crash before the log is emptied is harmless, replay skips the records the snapshot covers
+	if err := log.file.Truncate(0); err != nil {
+		return errors.Join(failedToSnapshot, err)
+	}
+	if err := log.file.Sync(); err != nil {
+		return errors.Join(failedToSnapshot, err)
+	}
+	log.size = 0
+	log.dirty = false
+
+	return nil
+}
+
+// Close flushes pending records and closes the file
+func (log *Log) Close() error {
+	close(log.stop)
+	<-log.stopped
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	var syncErr error
+	if log.dirty && log.failed == nil {
+		syncErr = log.file.Sync()
+	}
+
+	return errors.Join(syncErr, log.file.Close())
+}
+
+func (log *Log) syncPeriodically() {
+	defer close(log.stopped)
+
+	if log.options.Sync != SyncInterval {
+		<-log.stop
+		return
+	}
+
+	ticker := time.NewTicker(log.options.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-log.stop:
+			return
+		case <-ticker.C:
+			log.mutex.Lock()
+			if log.dirty && log.failed == nil {
+				if err := log.file.Sync(); err != nil {
+					// records already acknowledged may be lost, nothing appended after them can be trusted
+					log.failed = errors.Join(logFailed, err)
+				}
+				log.dirty = false
+			}
+			log.mutex.Unlock()
+		}
+	}
+}
+
+// writeSnapshot writes the snapshot next to its final path and renames it over, a crash leaves either the old
+// or the new snapshot in place
+func writeSnapshot(path string, seq uint64, envelopes []envelope) error {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	// an empty snapshot still needs a record to keep its sequence
+	for start := 0; start == 0 || start < len(envelopes); start += snapshotBatchSize {
+		end := min(start+snapshotBatchSize, len(envelopes))
+
+		record, err := encodeRecord(batch{Seq: seq, Events: envelopes[start:end]})
+		if err != nil {
+			return err
+		}
+
+		if _, err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// readSnapshot reads the snapshot at path, if there is one, handing its records to apply when set. It returns the
+// sequence the snapshot was taken at. Snapshots are written whole so any damage is corruption.
+func readSnapshot(path string, apply func(batch) error) (uint64, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := newRecordReader(file)
+	var seq uint64
+	for i := 0; ; i++ {
+		b, err := reader.next()
+		if errors.Is(err, io.EOF) && i > 0 {
+			return seq, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: snapshot record %d: %w", corruptedLog, i, err)
+		}
+
+		if i > 0 && b.Seq != seq {
+			return 0, fmt.Errorf("%w: snapshot record %d has sequence %d, want %d", corruptedLog, i, b.Seq, seq)
+		}
+		seq = b.Seq
+
+		if apply != nil {
+			if err := apply(b); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,509 @@
+package eventlog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+)
+
+func allEvents() []Event {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	from, to := "acc-1", "acc-2"
+
+	return []Event{
+		UserCreated{User: domain.User{ID: "user-1", Name: "Ada"}},
+		UserDeleted{UserID: "user-1", DeletedAt: at},
+		UserRestored{UserID: "user-1"},
+		UserUpdated{User: domain.User{ID: "user-1", Name: "Ada Lovelace", DeletedAt: &at}},
+		AccountOpened{Account: domain.Account{ID: from, UserID: "user-1", Balance: domain.NewMoney(0, domain.EUR), Type: domain.Checking}},
+		BalanceChanged{AccountID: from, Balance: domain.NewMoney(-1250, domain.EUR)},
+		AccountClosed{AccountID: from, ClosedAt: at},
+		AccountReopened{AccountID: from},
+		AccountUpdated{Account: domain.Account{ID: to, UserID: "user-1", Balance: domain.NewMoney(5, domain.JPY), Type: domain.Savings, Nickname: "rainy day", DeletedAt: &at}},
+		AccountUpdated{Account: domain.Account{ID: to, UserID: "user-1", Balance: domain.NewMoney(5, domain.JPY), Type: domain.Savings,
+			Interest: domain.SavingsInterest{Plan: "easy-saver", Accrued: "0.027397260", AccruedThrough: at.Truncate(24 * time.Hour)}}},
+		AccountUpdated{Account: domain.Account{ID: from, UserID: "user-1", Balance: domain.NewMoney(-1250, domain.EUR), Type: domain.Checking,
+			Overdraft: domain.Overdraft{Limit: domain.NewMoney(50000, domain.EUR), Rate: "0.12", Accrued: "-4.109589041", AccruedThrough: at.Truncate(24 * time.Hour)}}},
+		TransactionRecorded{Transaction: domain.Transaction{
+			ID:            "tx-1",
+			CreatedAt:     at,
+			FromAccountID: &from,
+			ToAccountID:   &to,
+			Amount:        domain.NewMoney(1000, domain.EUR),
+			Type:          domain.Transfer,
+			Conversion: &domain.FXConversion{
+				DestinationAmount: domain.NewMoney(1627, domain.JPY),
+				Rate:              "162.7",
+				RateTimestamp:     at,
+			},
+		}},
+		TransactionRecorded{Transaction: domain.Transaction{
+			ID:            "fee-1",
+			CreatedAt:     at,
+			FromAccountID: &from,
+			Amount:        domain.NewMoney(25, domain.EUR),
+			Type:          domain.Fee,
+			ParentID:      "tx-1",
+		}},
+		JournalEntryPosted{Entry: domain.JournalEntry{
+			ID:            "entry-1",
+			TransactionID: "tx-1",
+			CreatedAt:     at,
+			Lines: []domain.JournalLine{
+				{AccountID: domain.CashInAccountID, Side: domain.Debit, Amount: domain.NewMoney(1000, domain.EUR)},
+				{AccountID: to, Side: domain.Credit, Amount: domain.NewMoney(1000, domain.EUR)},
+			},
+		}},
+		AuditRecorded{Event: domain.AuditEvent{ID: "audit-1", UserID: "user-1", Action: domain.UserDeleted, AccountIDs: []string{from, to}, OccurredAt: at}},
+		BatchCreated{Batch: domain.Batch{
+			ID:        "batch-1",
+			AccountID: from,
+			Mode:      domain.AllOrNothing,
+			Status:    domain.BatchProcessing,
+			Lines: []domain.BatchLine{
+				{Number: 1, ToAccountID: to, Amount: domain.NewMoney(1000, domain.EUR), Reference: "salary-1", Status: domain.BatchLinePending},
+			},
+			CreatedAt: at,
+		}},
+		BatchLineUpdated{BatchID: "batch-1", Line: domain.BatchLine{
+			Number: 1, ToAccountID: to, Amount: domain.NewMoney(1000, domain.EUR), Reference: "salary-1", Status: domain.BatchLineFailed,
+			FailureCode: "insufficient_funds", FailureReason: "insufficient funds",
+		}},
+		BatchUpdated{Batch: domain.Batch{
+			ID:        "batch-1",
+			AccountID: from,
+			Mode:      domain.AllOrNothing,
+			Status:    domain.BatchFailed,
+			Lines: []domain.BatchLine{
+				{Number: 1, ToAccountID: to, Amount: domain.NewMoney(1000, domain.EUR), Reference: "salary-1", Status: domain.BatchLineFailed, FailureCode: "insufficient_funds", FailureReason: "insufficient funds"},
+			},
+			CreatedAt:   at,
+			CompletedAt: &at,
+		}},
+		StandingOrderCreated{Order: domain.StandingOrder{
+			ID:          "order-1",
+			AccountID:   from,
+			ToAccountID: to,
+			Amount:      domain.NewMoney(80000, domain.EUR),
+			Reference:   "rent",
+			Schedule:    "FREQ=MONTHLY;BYMONTHDAY=1",
+			TimeZone:    "Europe/Lisbon",
+			StartAt:     at,
+			EndAt:       &at,
+			Policy:      domain.FailurePolicy{Retries: 3, RetryInterval: time.Hour, OnFailure: domain.SuspendOrder},
+			Status:      domain.StandingOrderActive,
+			NextRunAt:   &at,
+			DueAt:       &at,
+			CreatedAt:   at,
+		}},
+		StandingOrderUpdated{Order: domain.StandingOrder{
+			ID:          "order-1",
+			AccountID:   from,
+			ToAccountID: to,
+			Amount:      domain.NewMoney(80000, domain.EUR),
+			Schedule:    "0 9 1 * *",
+			TimeZone:    "UTC",
+			StartAt:     at,
+			Policy:      domain.FailurePolicy{OnFailure: domain.SkipPayment},
+			Status:      domain.StandingOrderSuspended,
+			NextRunAt:   &at,
+			Attempts:    2,
+			CreatedAt:   at,
+		}},
+		StandingOrderExecuted{Execution: domain.StandingOrderExecution{
+			ID:           "execution-1",
+			OrderID:      "order-1",
+			ScheduledFor: at,
+			ExecutedAt:   at,
+			Attempt:      3,
+			Status:       domain.ExecutionPending,
+		}},
+		StandingOrderExecutionUpdated{Execution: domain.StandingOrderExecution{
+			ID:            "execution-1",
+			OrderID:       "order-1",
+			ScheduledFor:  at,
+			ExecutedAt:    at,
+			Attempt:       3,
+			Status:        domain.ExecutionSuspended,
+			FailureCode:   "insufficient_funds",
+			FailureReason: "insufficient funds",
+		}},
+		AccountUpdated{Account: domain.Account{ID: from, UserID: "user-1", Balance: domain.NewMoney(-1250, domain.EUR), Type: domain.Checking,
+			Held: domain.NewMoney(4000, domain.EUR)}},
+		HoldPlaced{Hold: domain.Hold{
+			ID:        "hold-1",
+			AccountID: from,
+			Amount:    domain.NewMoney(4000, domain.EUR),
+			Status:    domain.HoldActive,
+			CreatedAt: at,
+			ExpiresAt: at.Add(24 * time.Hour),
+		}},
+		HoldUpdated{Hold: domain.Hold{
+			ID:            "hold-1",
+			AccountID:     from,
+			ToAccountID:   to,
+			Amount:        domain.NewMoney(4000, domain.EUR),
+			Status:        domain.HoldCaptured,
+			Captured:      domain.NewMoney(2500, domain.EUR),
+			TransactionID: "tx-1",
+			CreatedAt:     at,
+			ExpiresAt:     at.Add(24 * time.Hour),
+			ReleasedAt:    &at,
+		}},
+	}
+}
+
+func openLog(t *testing.T, dir string) *Log {
+	t.Helper()
+
+	log, err := Open(dir, Options{Sync: SyncAlways})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	return log
+}
+
+func replay(t *testing.T, log *Log) []Event {
+	t.Helper()
+
+	var events []Event
+	err := log.Replay(func(event Event) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	return events
+}
+
+func appendEvents(t *testing.T, log *Log, events ...Event) {
+	t.Helper()
+
+	if err := log.Append(events...); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+}
+
+func TestLog_Replay(t *testing.T) {
+	dir := t.TempDir()
+	events := allEvents()
+
+	log := openLog(t, dir)
+	appendEvents(t, log, events[:4]...)
+	for _, event := range events[4:] {
+		appendEvents(t, log, event)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	log = openLog(t, dir)
+	defer log.Close()
+
+	if diff := cmp.Diff(events, replay(t, log)); diff != "" {
+		t.Errorf("Replay() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := log.Pending(), uint64(len(events)-3); got != want {
+		t.Errorf("Pending() = %d, want %d", got, want)
+	}
+}
+
+func TestLog_TornTail(t *testing.T) {
+	tests := []struct {
+		name string
+		// damage changes the log file, which holds two records of size bytes each
+		damage func(t *testing.T, path string, size int64)
+		// wantRecords is how many records survive
+		wantRecords int
+	}{
+		{
+			name: "partial header",
+			damage: func(t *testing.T, path string, size int64) {
+				appendBytes(t, path, []byte{0, 0, 1})
+			},
+			wantRecords: 2,
+		},
+		{
+			name: "partial payload",
+			damage: func(t *testing.T, path string, size int64) {
+				if err := os.Truncate(path, 2*size-5); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantRecords: 1,
+		},
+		{
+			name: "zero filled tail",
+			damage: func(t *testing.T, path string, size int64) {
+				appendBytes(t, path, make([]byte, 4096))
+			},
+			wantRecords: 2,
+		},
+		{
+			name: "last record checksum mismatch",
+			damage: func(t *testing.T, path string, size int64) {
+				flipByte(t, path, 2*size-2)
+			},
+			wantRecords: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, logFileName)
+			event := UserCreated{User: domain.User{ID: "user-1", Name: "Ada"}}
+
+			log := openLog(t, dir)
+			appendEvents(t, log, event)
+			size := fileSize(t, path)
+			appendEvents(t, log, event)
+			if err := log.Close(); err != nil {
+				t.Fatal(err)
+			}
+			tt.damage(t, path, size)
+
+			log = openLog(t, dir)
+			if log.Truncated() == 0 {
+				t.Errorf("Truncated() = 0, want the torn bytes")
+			}
+
+			got := replay(t, log)
+			if len(got) != tt.wantRecords {
+				t.Errorf("Replay() returned %d events, want %d", len(got), tt.wantRecords)
+			}
+
+			// the log keeps working after the torn record
+			appendEvents(t, log, UserRestored{UserID: "user-1"})
+			if err := log.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			log = openLog(t, dir)
+			defer log.Close()
+			if diff := cmp.Diff(append(got, UserRestored{UserID: "user-1"}), replay(t, log)); diff != "" {
+				t.Errorf("Replay() after appending mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLog_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, logFileName)
+	event := UserCreated{User: domain.User{ID: "user-1", Name: "Ada"}}
+
+	log := openLog(t, dir)
+	appendEvents(t, log, event)
+	size := fileSize(t, path)
+	appendEvents(t, log, event)
+	appendEvents(t, log, event)
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a bad record followed by a good one is not a torn write
+	flipByte(t, path, size+headerSize+2)
+
+	_, err := Open(dir, Options{Sync: SyncAlways})
+	if !errors.Is(err, corruptedLog) {
+		t.Errorf("Open() error = %v, want %v", err, corruptedLog)
+	}
+}
+
+func TestLog_Snapshot(t *testing.T) {
+	dir := t.TempDir()
+	events := allEvents()
+	state := events[:5]
+
+	log := openLog(t, dir)
+	appendEvents(t, log, state...)
+	// a snapshot replaces whatever the log held with the state
+	if err := log.Snapshot(state); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if got := log.Pending(); got != 0 {
+		t.Errorf("Pending() after Snapshot() = %d, want 0", got)
+	}
+	appendEvents(t, log, events[5:]...)
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	log = openLog(t, dir)
+	if diff := cmp.Diff(events, replay(t, log)); diff != "" {
+		t.Errorf("Replay() mismatch (-want +got):\n%s", diff)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLog_SnapshotBeforeTruncate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, logFileName)
+	events := allEvents()
+
+	log := openLog(t, dir)
+	appendEvents(t, log, events[:2]...)
+	appendEvents(t, log, events[2:4]...)
+	stale, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Snapshot(events[:4]); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a crash right after the snapshot was renamed leaves the records it covers in the log
+	if err := os.WriteFile(path, stale, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	log = openLog(t, dir)
+	if diff := cmp.Diff(events[:4], replay(t, log)); diff != "" {
+		t.Errorf("Replay() mismatch (-want +got):\n%s", diff)
+	}
+	appendEvents(t, log, events[4])
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	log = openLog(t, dir)
+	defer log.Close()
+	if diff := cmp.Diff(events[:5], replay(t, log)); diff != "" {
+		t.Errorf("Replay() after appending mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLog_LargeSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	state := make([]Event, 2*snapshotBatchSize+1)
+	for i := range state {
+		state[i] = BalanceChanged{AccountID: "acc-1", Balance: domain.NewMoney(int64(i), domain.EUR)}
+	}
+
+	log := openLog(t, dir)
+	if err := log.Snapshot(state); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	log = openLog(t, dir)
+	defer log.Close()
+	if diff := cmp.Diff(state, replay(t, log)); diff != "" {
+		t.Errorf("Replay() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLog_SyncPolicies(t *testing.T) {
+	for _, options := range []Options{
+		{Sync: SyncAlways},
+		{Sync: SyncInterval, SyncInterval: time.Millisecond},
+		{Sync: SyncNever},
+	} {
+		t.Run(string(options.Sync), func(t *testing.T) {
+			dir := t.TempDir()
+			event := UserCreated{User: domain.User{ID: "user-1", Name: "Ada"}}
+
+			log, err := Open(dir, options)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			appendEvents(t, log, event)
+			time.Sleep(5 * time.Millisecond)
+			if err := log.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			log = openLog(t, dir)
+			defer log.Close()
+			if diff := cmp.Diff([]Event{event}, replay(t, log)); diff != "" {
+				t.Errorf("Replay() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := Open(t.TempDir(), Options{Sync: SyncInterval}); !errors.Is(err, invalidSyncInterval) {
+		t.Errorf("Open() without an interval error = %v, want %v", err, invalidSyncInterval)
+	}
+}
+
+func TestParseSyncPolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		want    SyncPolicy
+		wantErr error
+	}{
+		{policy: "always", want: SyncAlways},
+		{policy: "interval", want: SyncInterval},
+		{policy: "never", want: SyncNever},
+		{policy: "sometimes", wantErr: invalidSyncPolicy},
+		{policy: "", wantErr: invalidSyncPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			got, err := ParseSyncPolicy(tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseSyncPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSyncPolicy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return info.Size()
+}
+
+func appendBytes(t *testing.T, path string, b []byte) {
+	t.Helper()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func flipByte(t *testing.T, path string, offset int64) {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[offset] ^= 0xff
+
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,105 @@
+package eventlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// A record is framed as a 4 byte big endian payload length, the 4 byte CRC-32C of the payload and the payload
+// itself, a JSON batch.
+const headerSize = 8
+
+// maxPayloadSize guards against allocating whatever a corrupted length asks for
+const maxPayloadSize = 64 << 20
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// batch is the payload of a record, Seq grows by one with every record appended to the log
+type batch struct {
+	Seq    uint64     `json:"seq"`
+	Events []envelope `json:"events"`
+}
+
+func encodeRecord(b batch) ([]byte, error) {
+	payload, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) > maxPayloadSize {
+		return nil, recordTooLarge
+	}
+
+	record := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(payload, castagnoli))
+	copy(record[headerSize:], payload)
+
+	return record, nil
+}
+
+// errIncompleteRecord is returned when the input ends inside a record and errChecksumMismatch when a complete
+// record doesn't match its checksum. Whether either is a torn write or corruption depends on where the record lies.
+var errIncompleteRecord = errors.New("incomplete record")
+var errChecksumMismatch = errors.New("record checksum mismatch")
+
+// recordReader reads records one after the other keeping track of where the last valid one ended
+type recordReader struct {
+	reader *bufio.Reader
+	// offset is the end of the last record read successfully
+	offset int64
+	// badRecordEnd is where the record after offset claims to end when it failed its checksum
+	badRecordEnd int64
+}
+
+func newRecordReader(r io.Reader) *recordReader {
+	return &recordReader{reader: bufio.NewReader(r)}
+}
+
+// next returns io.EOF once the input ends exactly after a record
+func (r *recordReader) next() (batch, error) {
+	var header [headerSize]byte
+	if n, err := io.ReadFull(r.reader, header[:]); err != nil {
+		if errors.Is(err, io.EOF) && n == 0 {
+			return batch{}, io.EOF
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return batch{}, errIncompleteRecord
+		}
+		return batch{}, err
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	r.badRecordEnd = r.offset + headerSize + int64(length)
+	// empty payloads are never written, a zeroed header is space the file system allocated before a crash
+	if length == 0 || length > maxPayloadSize {
+		return batch{}, errChecksumMismatch
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r.reader, payload); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return batch{}, errIncompleteRecord
+		}
+		return batch{}, err
+	}
+
+	if crc32.Checksum(payload, castagnoli) != checksum {
+		return batch{}, errChecksumMismatch
+	}
+
+	var b batch
+	if err := json.Unmarshal(payload, &b); err != nil {
+		// the checksum matched so these bytes were written like this, it's not a torn write
+		return batch{}, errors.Join(invalidRecord, err)
+	}
+
+	r.offset += headerSize + int64(length)
+
+	return b, nil
+}
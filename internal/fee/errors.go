@@ -0,0 +1,8 @@
+package fee
+
+import (
+	"errors"
+)
+
+var failedToReadSchedule = errors.New("failed to read fee schedule")
+var duplicateFee = errors.New("duplicate fee")
@@ -0,0 +1,116 @@
+// Package fee holds the fee schedule charged on customer transactions.
+package fee
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"http/internal/domain"
+	"http/internal/reloadable"
+)
+
+type key struct {
+	transactionType domain.TransactionType
+	currency        domain.Currency
+}
+
+// Schedule is loaded from a JSON file and can be reloaded while serving like the exchange rate table, a failed reload
+// keeps the fees that were loaded before. Transactions without a fee for their type and currency are free.
+type Schedule struct {
+	rules *reloadable.File[map[key]domain.FeeRule]
+}
+
+// NewSchedule loads the fees at path, an empty path gives a schedule where everything is free.
+func NewSchedule(path string) (*Schedule, error) {
+	rules, err := reloadable.NewFile(path, make(map[key]domain.FeeRule), readRules)
+	if err != nil {
+		return nil, errors.Join(failedToReadSchedule, err)
+	}
+
+	return &Schedule{rules: rules}, nil
+}
+
+// Reload reads the file again and swaps the whole schedule at once.
+func (schedule *Schedule) Reload() error {
+	if err := schedule.rules.Reload(); err != nil {
+		return errors.Join(failedToReadSchedule, err)
+	}
+
+	return nil
+}
+
+// Rule is the fee charged on transactions of transactionType from accounts in currency, if there's one
+func (schedule *Schedule) Rule(transactionType domain.TransactionType, currency domain.Currency) (domain.FeeRule, bool) {
+	rule, ok := schedule.rules.Get()[key{transactionType: transactionType, currency: currency}]
+	return rule, ok
+}
+
+// ruleRecord is a fee as written in the file, amounts are decimal strings in its currency and min and max are optional
+type ruleRecord struct {
+	Type       string `json:"type"`
+	Currency   string `json:"currency"`
+	Flat       string `json:"flat"`
+	Percentage string `json:"percentage"`
+	Min        string `json:"min"`
+	Max        string `json:"max"`
+}
+
+// readRules reads {"fees": [{"type": "transfer", "currency": "EUR", "percentage": "0.5", "min": "0.50", "max": "10.00"}]}
+func readRules(path string) (map[key]domain.FeeRule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var content struct {
+		Fees []ruleRecord `json:"fees"`
+	}
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&content); err != nil {
+		return nil, err
+	}
+
+	rules := make(map[key]domain.FeeRule, len(content.Fees))
+	for i, record := range content.Fees {
+		rule, err := record.toDomain()
+		if err != nil {
+			return nil, fmt.Errorf("fee %d: %w", i+1, err)
+		}
+
+		k := key{transactionType: rule.Type, currency: rule.Currency}
+		if _, ok := rules[k]; ok {
+			return nil, fmt.Errorf("fee %d %s in %s: %w", i+1, rule.Type, rule.Currency, duplicateFee)
+		}
+		rules[k] = rule
+	}
+
+	return rules, nil
+}
+
+func (record ruleRecord) toDomain() (domain.FeeRule, error) {
+	transactionType, err := domain.ParseTransactionType(record.Type)
+	if err != nil {
+		return domain.FeeRule{}, err
+	}
+
+	currency, err := domain.ParseCurrency(record.Currency)
+	if err != nil {
+		return domain.FeeRule{}, err
+	}
+
+	var amounts [3]domain.Money
+	for i, amount := range []string{record.Flat, record.Min, record.Max} {
+		if amount == "" {
+			amount = "0"
+		}
+		if amounts[i], err = domain.ParseMoney(amount, currency); err != nil {
+			return domain.FeeRule{}, err
+		}
+	}
+
+	return domain.NewFeeRule(transactionType, currency, amounts[0], record.Percentage, amounts[1], amounts[2])
+}
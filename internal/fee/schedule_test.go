@@ -0,0 +1,81 @@
+package fee
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+)
+
+func writeSchedule(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fees.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write fees: %v", err)
+	}
+
+	return path
+}
+
+func TestNewSchedule(t *testing.T) {
+	eur := func(amount int64) domain.Money { return domain.NewMoney(amount, domain.EUR) }
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[key]domain.FeeRule
+		wantErr error
+	}{
+		{
+			name: "flat and capped percentage fees",
+			content: `{"fees": [
+				{"type": "withdrawal", "currency": "EUR", "flat": "1.00"},
+				{"type": "transfer", "currency": "EUR", "percentage": "0.5", "min": "0.50", "max": "10.00"}
+			]}`,
+			want: map[key]domain.FeeRule{
+				{transactionType: domain.Withdrawal, currency: domain.EUR}: {Type: domain.Withdrawal, Currency: domain.EUR, Flat: eur(100), Min: eur(0), Max: eur(0)},
+				{transactionType: domain.Transfer, currency: domain.EUR}:   {Type: domain.Transfer, Currency: domain.EUR, Flat: eur(0), Percentage: "0.5", Min: eur(50), Max: eur(1000)},
+			},
+		},
+		{
+			name: "same type and currency twice, want duplicateFee",
+			content: `{"fees": [
+				{"type": "withdrawal", "currency": "EUR", "flat": "1.00"},
+				{"type": "withdrawal", "currency": "EUR", "flat": "2.00"}
+			]}`,
+			wantErr: duplicateFee,
+		},
+		{
+			name:    "flat and percentage, want failedToReadSchedule",
+			content: `{"fees": [{"type": "withdrawal", "currency": "EUR", "flat": "1.00", "percentage": "1"}]}`,
+			wantErr: failedToReadSchedule,
+		},
+		{
+			name:    "fee on interest, want failedToReadSchedule",
+			content: `{"fees": [{"type": "interest", "currency": "EUR", "flat": "1.00"}]}`,
+			wantErr: failedToReadSchedule,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := NewSchedule(writeSchedule(t, tt.content))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, schedule.rules.Get(), cmp.AllowUnexported(key{})); diff != "" {
+				t.Errorf("NewSchedule() rules (-want +got):\n%s", diff)
+			}
+			if _, ok := schedule.Rule(domain.Deposit, domain.EUR); ok {
+				t.Errorf("Rule() found a deposit fee, want deposits free")
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package fx
+
+import (
+	"errors"
+
+	"http/internal/tberrors"
+)
+
+var failedToReadRates = errors.New("failed to read exchange rates")
+var unsupportedRatesFormat = errors.New("unsupported exchange rates file, expected .json or .csv")
+var duplicateRate = errors.New("duplicate exchange rate")
+var rateNotFound = tberrors.NewValidationError("fx_rate_not_found", "no exchange rate between the account currencies", "to_account")
@@ -0,0 +1,176 @@
+// Package fx holds the exchange rate table used to convert transfers between accounts in different currencies.
+package fx
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/reloadable"
+)
+
+type pair struct {
+	from domain.Currency
+	to   domain.Currency
+}
+
+// Table is loaded from a JSON or CSV file and can be reloaded while serving, a failed reload keeps the rates that
+// were loaded before. Only the pairs listed in the file exist, inverse rates are never derived.
+type Table struct {
+	rates *reloadable.File[map[pair]domain.ExchangeRate]
+}
+
+// NewTable loads the rates at path, an empty path gives a table without rates.
+func NewTable(path string) (*Table, error) {
+	rates, err := reloadable.NewFile(path, make(map[pair]domain.ExchangeRate), readRates)
+	if err != nil {
+		return nil, errors.Join(failedToReadRates, err)
+	}
+
+	return &Table{rates: rates}, nil
+}
+
+// Reload reads the file again and swaps the whole table at once.
+func (table *Table) Reload() error {
+	if err := table.rates.Reload(); err != nil {
+		return errors.Join(failedToReadRates, err)
+	}
+
+	return nil
+}
+
+func (table *Table) Rate(from, to domain.Currency) (domain.ExchangeRate, error) {
+	rate, ok := table.rates.Get()[pair{from: from, to: to}]
+	if !ok {
+		return domain.ExchangeRate{}, rateNotFound
+	}
+
+	return rate, nil
+}
+
+// Rates returns every rate sorted by currency pair
+func (table *Table) Rates() []domain.ExchangeRate {
+	rates := slices.Collect(maps.Values(table.rates.Get()))
+	slices.SortFunc(rates, func(a, b domain.ExchangeRate) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To))
+	})
+
+	return rates
+}
+
+// rateRecord is a rate as written in the file, updated_at is optional and defaults to the time the file was read
+type rateRecord struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func readRates(path string) (map[pair]domain.ExchangeRate, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []rateRecord
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		records, err = decodeJSON(file)
+	case ".csv":
+		records, err = decodeCSV(file)
+	default:
+		return nil, unsupportedRatesFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	loadedAt := time.Now()
+	rates := make(map[pair]domain.ExchangeRate, len(records))
+	for i, record := range records {
+		from, err := domain.ParseCurrency(record.From)
+		if err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+		to, err := domain.ParseCurrency(record.To)
+		if err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+
+		updatedAt := record.UpdatedAt
+		if updatedAt.IsZero() {
+			updatedAt = loadedAt
+		}
+
+		rate, err := domain.NewExchangeRate(from, to, record.Rate, updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+
+		key := pair{from: from, to: to}
+		if _, ok := rates[key]; ok {
+			return nil, fmt.Errorf("rate %d %s/%s: %w", i+1, from, to, duplicateRate)
+		}
+		rates[key] = rate
+	}
+
+	return rates, nil
+}
+
+// decodeJSON reads {"rates": [{"from": "EUR", "to": "USD", "rate": "1.0845", "updated_at": "..."}]}
+func decodeJSON(r io.Reader) ([]rateRecord, error) {
+	var file struct {
+		Rates []rateRecord `json:"rates"`
+	}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, err
+	}
+
+	return file.Rates, nil
+}
+
+// decodeCSV reads a from,to,rate,updated_at header followed by one rate per line
+func decodeCSV(r io.Reader) ([]rateRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	lines, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	header := strings.Join(lines[0], ",")
+	if header != "from,to,rate,updated_at" && header != "from,to,rate" {
+		return nil, fmt.Errorf("unexpected csv header %q, expected from,to,rate,updated_at", header)
+	}
+
+	records := make([]rateRecord, 0, len(lines)-1)
+	for i, line := range lines[1:] {
+		record := rateRecord{From: line[0], To: line[1], Rate: line[2]}
+		if len(line) > 3 && line[3] != "" {
+			if record.UpdatedAt, err = time.Parse(time.RFC3339, line[3]); err != nil {
+				return nil, fmt.Errorf("rate %d: %w", i+1, err)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,137 @@
+package fx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+)
+
+func writeRates(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write rates: %v", err)
+	}
+
+	return path
+}
+
+func TestNewTable(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []domain.ExchangeRate
+		wantErr error
+	}{
+		{
+			name: "json",
+			file: "rates.json",
+			content: `{"rates": [
+				{"from": "USD", "to": "EUR", "rate": "0.9221", "updated_at": "2026-10-01T12:00:00Z"},
+				{"from": "EUR", "to": "USD", "rate": "1.0845", "updated_at": "2026-10-01T12:00:00Z"}
+			]}`,
+			want: []domain.ExchangeRate{
+				{From: domain.EUR, To: domain.USD, Rate: "1.0845", UpdatedAt: updatedAt},
+				{From: domain.USD, To: domain.EUR, Rate: "0.9221", UpdatedAt: updatedAt},
+			},
+		},
+		{
+			name:    "csv",
+			file:    "rates.csv",
+			content: "from,to,rate,updated_at\nEUR,GBP,0.8567,2026-10-01T12:00:00Z\n",
+			want: []domain.ExchangeRate{
+				{From: domain.EUR, To: domain.GBP, Rate: "0.8567", UpdatedAt: updatedAt},
+			},
+		},
+		{
+			name:    "unsupported extension, want unsupportedRatesFormat",
+			file:    "rates.txt",
+			content: "EUR USD 1.0845",
+			wantErr: unsupportedRatesFormat,
+		},
+		{
+			name:    "duplicate pair, want duplicateRate",
+			file:    "rates.csv",
+			content: "from,to,rate\nEUR,USD,1.08\nEUR,USD,1.09\n",
+			wantErr: duplicateRate,
+		},
+		{
+			name:    "invalid rate, want failedToReadRates",
+			file:    "rates.csv",
+			content: "from,to,rate\nEUR,USD,abc\n",
+			wantErr: failedToReadRates,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := NewTable(writeRates(t, tt.file, tt.content))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, table.Rates()); diff != "" {
+				t.Errorf("Rates() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTable_Rate(t *testing.T) {
+	table, err := NewTable(writeRates(t, "rates.csv", "from,to,rate\nEUR,USD,1.0845\n"))
+	if err != nil {
+		t.Fatalf("NewTable() error = %v", err)
+	}
+
+	rate, err := table.Rate(domain.EUR, domain.USD)
+	if err != nil {
+		t.Fatalf("Rate() error = %v", err)
+	}
+	if rate.Rate != "1.0845" || rate.UpdatedAt.IsZero() {
+		t.Errorf("Rate() got = %+v, want 1.0845 stamped with the load time", rate)
+	}
+
+	// inverse rates are never derived
+	if _, err := table.Rate(domain.USD, domain.EUR); !errors.Is(err, rateNotFound) {
+		t.Errorf("Rate() error = %v, wantErr %v", err, rateNotFound)
+	}
+}
+
+func TestTable_Reload(t *testing.T) {
+	path := writeRates(t, "rates.csv", "from,to,rate\nEUR,USD,1.08\n")
+	table, err := NewTable(path)
+	if err != nil {
+		t.Fatalf("NewTable() error = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("from,to,rate\nEUR,USD,1.09\n"), 0o600); err != nil {
+		t.Fatalf("failed to write rates: %v", err)
+	}
+	if err := table.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if rate, _ := table.Rate(domain.EUR, domain.USD); rate.Rate != "1.09" {
+		t.Errorf("Rate() after reload = %v, want 1.09", rate.Rate)
+	}
+
+	if err := os.WriteFile(path, []byte("from,to,rate\nEUR,USD,broken\n"), 0o600); err != nil {
+		t.Fatalf("failed to write rates: %v", err)
+	}
+	if err := table.Reload(); !errors.Is(err, failedToReadRates) {
+		t.Fatalf("Reload() error = %v, wantErr %v", err, failedToReadRates)
+	}
+	if rate, _ := table.Rate(domain.EUR, domain.USD); rate.Rate != "1.09" {
+		t.Errorf("Rate() after failed reload = %v, want previous rate 1.09", rate.Rate)
+	}
+}
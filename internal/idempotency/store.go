@@ -0,0 +1,130 @@
+// Package idempotency remembers the outcome of requests sent with an Idempotency-Key so retries are replayed
+// instead of executed twice.
+package idempotency
+
+import (
+	"sync"
+	"time"
+
+	"http/internal/tberrors"
+)
+
+var ErrInFlight = tberrors.NewConflictError(
+	"request_in_flight", "a request with the same idempotency key is still being processed", "Idempotency-Key",
+)
+var ErrKeyReused = tberrors.NewValidationError(
+	"idempotency_key_reused", "idempotency key was already used with a different request", "Idempotency-Key",
+)
+
+// Record is the response stored for a key.
+type Record struct {
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// Store keeps one Record per key until it expires.
+type Store interface {
+	// Begin claims key for a request. It returns the stored record when the key already completed with the same
+	// request hash, ErrKeyReused when the hash differs and ErrInFlight when another request holds the key. A nil
+	// record and error means the caller owns the key and must call Complete or Abort.
+	Begin(key, requestHash string) (*Record, error)
+	Complete(key string, record Record)
+	Abort(key string)
+}
+
+// sweepInterval is how often MemoryStore scans for expired keys, a single key is always checked when accessed
+const sweepInterval = time.Minute
+
+type MemoryStore struct {
+	ttl       time.Duration
+	now       func() time.Time
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+type memoryEntry struct {
+	requestHash string
+	// record stays nil while the request is in flight
+	record    *Record
+	expiresAt time.Time
+}
+
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*memoryEntry),
+		mutex:   sync.Mutex{},
+	}
+}
+
+func (store *MemoryStore) Begin(key, requestHash string) (*Record, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := store.now()
+	if now.Sub(store.lastSweep) >= sweepInterval {
+		store.evictExpired(now)
+		store.lastSweep = now
+	}
+
+	entry, ok := store.entries[key]
+	if ok && !now.Before(entry.expiresAt) {
+		delete(store.entries, key)
+		ok = false
+	}
+
+	if !ok {
+		store.entries[key] = &memoryEntry{
+			requestHash: requestHash,
+			expiresAt:   now.Add(store.ttl),
+		}
+		return nil, nil
+	}
+
+	if entry.requestHash != requestHash {
+		return nil, ErrKeyReused
+	}
+
+	if entry.record == nil {
+		return nil, ErrInFlight
+	}
+
+	record := *entry.record
+	return &record, nil
+}
+
+func (store *MemoryStore) Complete(key string, record Record) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := store.now()
+	record.CreatedAt = now
+	store.entries[key] = &memoryEntry{
+		requestHash: record.RequestHash,
+		record:      &record,
+		expiresAt:   now.Add(store.ttl),
+	}
+}
+
+func (store *MemoryStore) Abort(key string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if entry, ok := store.entries[key]; ok && entry.record == nil {
+		delete(store.entries, key)
+	}
+}
+
+// evictExpired expects the caller to hold the lock
+func (store *MemoryStore) evictExpired(now time.Time) {
+	for key, entry := range store.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(store.entries, key)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMemoryStore_Begin(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	completed := Record{RequestHash: "hash", StatusCode: 201, ContentType: "application/json", Body: []byte("{}")}
+
+	tests := []struct {
+		name        string
+		setup       func(store *MemoryStore)
+		elapsed     time.Duration
+		requestHash string
+		want        *Record
+		wantErr     error
+	}{
+		{
+			name:        "unknown key is claimed",
+			setup:       func(store *MemoryStore) {},
+			requestHash: "hash",
+		},
+		{
+			name: "completed key with same hash is replayed",
+			setup: func(store *MemoryStore) {
+				store.Begin("key", "hash")
+				store.Complete("key", completed)
+			},
+			requestHash: "hash",
+			want: &Record{
+				RequestHash: "hash",
+				StatusCode:  201,
+				ContentType: "application/json",
+				Body:        []byte("{}"),
+				CreatedAt:   now,
+			},
+		},
+		{
+			name: "completed key with different hash, want ErrKeyReused",
+			setup: func(store *MemoryStore) {
+				store.Begin("key", "hash")
+				store.Complete("key", completed)
+			},
+			requestHash: "other",
+			wantErr:     ErrKeyReused,
+		},
+		{
+			name: "key in flight, want ErrInFlight",
+			setup: func(store *MemoryStore) {
+				store.Begin("key", "hash")
+			},
+			requestHash: "hash",
+			wantErr:     ErrInFlight,
+		},
+		{
+			name: "aborted key is claimed again",
+			setup: func(store *MemoryStore) {
+				store.Begin("key", "hash")
+				store.Abort("key")
+			},
+			requestHash: "hash",
+		},
+		{
+			name: "expired key is claimed again",
+			setup: func(store *MemoryStore) {
+				store.Begin("key", "hash")
+				store.Complete("key", completed)
+			},
+			elapsed:     time.Hour,
+			requestHash: "other",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := now
+			store := NewMemoryStore(time.Hour)
+			store.now = func() time.Time { return clock }
+
+			tt.setup(store)
+			clock = clock.Add(tt.elapsed)
+
+			got, err := store.Begin("key", tt.requestHash)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Begin() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Begin() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
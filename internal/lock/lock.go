@@ -0,0 +1,145 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var failedToAcquireLock = errors.New("failed to acquire lock")
+
+// Manager hands out per-key exclusive locks. Keys passed to the same Lock call are always acquired in
+// sorted order, so two callers locking overlapping key sets can never wait on each other in a cycle.
+// Locks are reference counted and dropped from the manager as soon as no caller holds or waits on them.
+type Manager struct {
+	mutex sync.Mutex
+	locks map[string]*entry
+
+	acquisitions atomic.Uint64
+	contentions  atomic.Uint64
+	timeouts     atomic.Uint64
+	waitTime     atomic.Int64
+}
+
+type entry struct {
+	// token holds a value while the lock is taken
+	token chan struct{}
+	// refs counts callers holding or waiting for the lock
+	refs int
+}
+
+// Stats is a snapshot of the manager contention metrics.
+type Stats struct {
+	// Acquisitions is the number of successful Lock calls
+	Acquisitions uint64
+	// Contentions is the number of keys that were already taken when requested
+	Contentions uint64
+	// Timeouts is the number of Lock calls abandoned because their context ended
+	Timeouts uint64
+	// WaitTime is the total time spent waiting on contended keys
+	WaitTime time.Duration
+	// Active is the number of keys currently held or waited on
+	Active int
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		mutex: sync.Mutex{},
+		locks: make(map[string]*entry),
+	}
+}
+
+// Lock blocks until every key is held by the caller or ctx is done. On success it returns a function releasing
+// all the keys, which is safe to call more than once. Duplicate keys are only locked once.
+func (manager *Manager) Lock(ctx context.Context, keys ...string) (func(), error) {
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	for i, key := range sorted {
+		if err := manager.acquire(ctx, key); err != nil {
+			manager.release(sorted[:i])
+			manager.timeouts.Add(1)
+			return nil, errors.Join(failedToAcquireLock, err)
+		}
+	}
+
+	manager.acquisitions.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			manager.release(sorted)
+		})
+	}, nil
+}
+
+func (manager *Manager) Stats() Stats {
+	manager.mutex.Lock()
+	active := len(manager.locks)
+	manager.mutex.Unlock()
+
+	return Stats{
+		Acquisitions: manager.acquisitions.Load(),
+		Contentions:  manager.contentions.Load(),
+		Timeouts:     manager.timeouts.Load(),
+		WaitTime:     time.Duration(manager.waitTime.Load()),
+		Active:       active,
+	}
+}
+
+func (manager *Manager) acquire(ctx context.Context, key string) error {
+	manager.mutex.Lock()
+	e := manager.locks[key]
+	if e == nil {
+		e = &entry{token: make(chan struct{}, 1)}
+		manager.locks[key] = e
+	}
+	e.refs++
+	manager.mutex.Unlock()
+
+	select {
+	case e.token <- struct{}{}:
+		return nil
+	default:
+	}
+
+	manager.contentions.Add(1)
+	start := time.Now()
+	defer func() {
+		manager.waitTime.Add(int64(time.Since(start)))
+	}()
+
+	select {
+	case e.token <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		manager.unref(key, e)
+		return ctx.Err()
+	}
+}
+
+// release unlocks keys in the reverse order they were acquired
+func (manager *Manager) release(keys []string) {
+	for i := len(keys) - 1; i >= 0; i-- {
+		manager.mutex.Lock()
+		e := manager.locks[keys[i]]
+		manager.mutex.Unlock()
+
+		<-e.token
+		manager.unref(keys[i], e)
+	}
+}
+
+func (manager *Manager) unref(key string, e *entry) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	e.refs--
+	if e.refs == 0 {
+		delete(manager.locks, key)
+	}
+}
@@ -0,0 +1,129 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManager_Lock(t *testing.T) {
+	tests := []struct {
+		name     string
+		held     []string
+		keys     []string
+		timeout  time.Duration
+		wantErr  error
+		wantHeld int
+	}{
+		{
+			name:     "lock free keys",
+			keys:     []string{"b", "a"},
+			timeout:  time.Second,
+			wantHeld: 2,
+		},
+		{
+			name:     "duplicated keys are locked once",
+			keys:     []string{"a", "a"},
+			timeout:  time.Second,
+			wantHeld: 1,
+		},
+		{
+			name:     "key held by someone else, want context.DeadlineExceeded",
+			held:     []string{"b"},
+			keys:     []string{"a", "b"},
+			timeout:  10 * time.Millisecond,
+			wantErr:  context.DeadlineExceeded,
+			wantHeld: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager()
+
+			if tt.held != nil {
+				unlock, err := manager.Lock(context.Background(), tt.held...)
+				if err != nil {
+					t.Fatalf("Lock() unexpected error = %v", err)
+				}
+				defer unlock()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			unlock, err := manager.Lock(ctx, tt.keys...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Lock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := manager.Stats().Active; got != tt.wantHeld {
+				t.Errorf("Stats().Active got = %v, want %v", got, tt.wantHeld)
+			}
+
+			if unlock != nil {
+				unlock()
+				unlock()
+			}
+		})
+	}
+}
+
+func TestManager_LockEvictsReleasedKeys(t *testing.T) {
+	manager := NewManager()
+
+	unlock, err := manager.Lock(context.Background(), "a", "b", "c")
+	if err != nil {
+		t.Fatalf("Lock() unexpected error = %v", err)
+	}
+	unlock()
+
+	stats := manager.Stats()
+	if stats.Active != 0 {
+		t.Errorf("Stats().Active got = %v, want 0", stats.Active)
+	}
+	if stats.Acquisitions != 1 {
+		t.Errorf("Stats().Acquisitions got = %v, want 1", stats.Acquisitions)
+	}
+}
+
+func TestManager_LockOpposingOrder(t *testing.T) {
+	manager := NewManager()
+	counters := map[string]int{"a": 0, "b": 0}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		keys := []string{"a", "b"}
+		if i%2 == 0 {
+			keys = []string{"b", "a"}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			unlock, err := manager.Lock(ctx, keys...)
+			if err != nil {
+				t.Errorf("Lock() unexpected error = %v", err)
+				return
+			}
+			defer unlock()
+
+			counters["a"]++
+			counters["b"]++
+		}()
+	}
+	wg.Wait()
+
+	if counters["a"] != 100 || counters["b"] != 100 {
+		t.Errorf("counters got = %v, want 100 each", counters)
+	}
+
+	if active := manager.Stats().Active; active != 0 {
+		t.Errorf("Stats().Active got = %v, want 0", active)
+	}
+}
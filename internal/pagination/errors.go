@@ -0,0 +1,10 @@
+package pagination
+
+import (
+	"fmt"
+
+	"http/internal/tberrors"
+)
+
+var invalidCursor = tberrors.NewValidationError("invalid_cursor", "invalid cursor", "cursor")
+var invalidLimit = tberrors.NewValidationError("invalid_limit", fmt.Sprintf("limit must be between 1 and %d", MaxLimit), "limit")
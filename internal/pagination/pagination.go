@@ -0,0 +1,94 @@
+// Package pagination turns repository positions into the opaque cursors clients use to ask for the next page of a
+// listing.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// Request asks for at most Limit items following Cursor, an empty cursor starts with the first item
+type Request struct {
+	Cursor string
+	Limit  int
+}
+
+// FetchLimit is the limit to fetch items with, one above the page's so NewPage can tell whether there is a next
+// page. Zero fetches every item.
+func (request Request) FetchLimit() int {
+	if request.Limit <= 0 {
+		return 0
+	}
+
+	return request.Limit + 1
+}
+
+// NewRequest defaults a zero limit to DefaultLimit
+func NewRequest(cursor string, limit int) (Request, error) {
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	if limit < 1 || limit > MaxLimit {
+		return Request{}, invalidLimit
+	}
+
+	return Request{Cursor: cursor, Limit: limit}, nil
+}
+
+// Page holds the items of a page, NextCursor is empty on the last one
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// Encode returns the cursor of a position, the position must marshal to JSON
+func Encode(position any) (string, error) {
+	raw, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Decode reads the position from a cursor returned by Encode, a nil position is returned for an empty cursor
+func Decode[P any](cursor string) (*P, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.Join(invalidCursor, err)
+	}
+
+	var position P
+	if err := json.Unmarshal(raw, &position); err != nil {
+		return nil, errors.Join(invalidCursor, err)
+	}
+
+	return &position, nil
+}
+
+// NewPage builds a page of at most limit items out of items fetched with FetchLimit, position returns the position
+// of an item
+func NewPage[T, P any](items []T, limit int, position func(T) P) (Page[T], error) {
+	if limit <= 0 || len(items) <= limit {
+		return Page[T]{Items: items}, nil
+	}
+
+	items = items[:limit]
+	next, err := Encode(position(items[limit-1]))
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	return Page[T]{Items: items, NextCursor: next}, nil
+}
@@ -0,0 +1,107 @@
+package pagination
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		want    Request
+		wantErr error
+	}{
+		{name: "default limit", limit: 0, want: Request{Cursor: "cursor", Limit: DefaultLimit}},
+		{name: "max limit", limit: MaxLimit, want: Request{Cursor: "cursor", Limit: MaxLimit}},
+		{name: "above max limit, want invalidLimit", limit: MaxLimit + 1, wantErr: invalidLimit},
+		{name: "negative limit, want invalidLimit", limit: -1, wantErr: invalidLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRequest("cursor", tt.limit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("NewRequest() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type position struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+func TestDecode(t *testing.T) {
+	want := position{CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 1, time.UTC), ID: "id"}
+	cursor, err := Encode(want)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cursor  string
+		want    *position
+		wantErr error
+	}{
+		{name: "encoded position", cursor: cursor, want: &want},
+		{name: "empty cursor, want nil position", cursor: ""},
+		{name: "not base64, want invalidCursor", cursor: "!!", wantErr: invalidCursor},
+		{name: "not a position, want invalidCursor", cursor: "bm90IGpzb24", wantErr: invalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode[position](tt.cursor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Decode() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	cursorAfter := func(item int) string {
+		cursor, _ := Encode(strconv.Itoa(item))
+		return cursor
+	}
+
+	tests := []struct {
+		name  string
+		items []int
+		limit int
+		want  Page[int]
+	}{
+		{name: "extra item, want next cursor", items: []int{1, 2, 3}, limit: 2, want: Page[int]{Items: []int{1, 2}, NextCursor: cursorAfter(2)}},
+		{name: "full last page", items: []int{1, 2}, limit: 2, want: Page[int]{Items: []int{1, 2}}},
+		{name: "short last page", items: []int{1}, limit: 2, want: Page[int]{Items: []int{1}}},
+		{name: "no limit", items: []int{1, 2, 3}, limit: 0, want: Page[int]{Items: []int{1, 2, 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPage(tt.items, tt.limit, strconv.Itoa)
+			if err != nil {
+				t.Fatalf("NewPage() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("NewPage() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
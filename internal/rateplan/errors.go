@@ -0,0 +1,11 @@
+package rateplan
+
+import (
+	"errors"
+
+	"http/internal/tberrors"
+)
+
+var failedToReadPlans = errors.New("failed to read rate plans")
+var duplicatePlan = errors.New("duplicate rate plan")
+var planNotFound = tberrors.NewValidationError("rate_plan_not_found", "no rate plan with that name", "plan")
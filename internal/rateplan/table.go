@@ -0,0 +1,117 @@
+// Package rateplan holds the interest rate plans savings accounts can be put on.
+package rateplan
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"http/internal/domain"
+	"http/internal/reloadable"
+)
+
+// Table is loaded from a JSON file and can be reloaded while serving like the exchange rate table, a failed reload
+// keeps the plans that were loaded before. Accounts name their plan, so a reload changes the rates of every account on
+// a plan from the next day that accrues.
+type Table struct {
+	plans *reloadable.File[map[string]domain.RatePlan]
+}
+
+// NewTable loads the plans at path, an empty path gives a table without plans.
+func NewTable(path string) (*Table, error) {
+	plans, err := reloadable.NewFile(path, make(map[string]domain.RatePlan), readPlans)
+	if err != nil {
+		return nil, errors.Join(failedToReadPlans, err)
+	}
+
+	return &Table{plans: plans}, nil
+}
+
+// Reload reads the file again and swaps the whole table at once.
+func (table *Table) Reload() error {
+	if err := table.plans.Reload(); err != nil {
+		return errors.Join(failedToReadPlans, err)
+	}
+
+	return nil
+}
+
+func (table *Table) Plan(name string) (domain.RatePlan, error) {
+	plan, ok := table.plans.Get()[name]
+	if !ok {
+		return domain.RatePlan{}, planNotFound
+	}
+
+	return plan, nil
+}
+
+// Plans returns every plan sorted by name
+func (table *Table) Plans() []domain.RatePlan {
+	plans := slices.Collect(maps.Values(table.plans.Get()))
+	slices.SortFunc(plans, func(a, b domain.RatePlan) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return plans
+}
+
+// planRecord is a plan as written in the file, amounts are decimal strings in the plan currency
+type planRecord struct {
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Tiers    []struct {
+		From string `json:"from"`
+		Rate string `json:"rate"`
+	} `json:"tiers"`
+}
+
+// readPlans reads {"plans": [{"name": "easy-saver", "currency": "EUR", "tiers": [{"from": "0.00", "rate": "0.02"}]}]}
+func readPlans(path string) (map[string]domain.RatePlan, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var content struct {
+		Plans []planRecord `json:"plans"`
+	}
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&content); err != nil {
+		return nil, err
+	}
+
+	plans := make(map[string]domain.RatePlan, len(content.Plans))
+	for i, record := range content.Plans {
+		currency, err := domain.ParseCurrency(record.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("plan %d: %w", i+1, err)
+		}
+
+		tiers := make([]domain.RateTier, 0, len(record.Tiers))
+		for j, tier := range record.Tiers {
+			from, err := domain.ParseMoney(tier.From, currency)
+			if err != nil {
+				return nil, fmt.Errorf("plan %d tier %d: %w", i+1, j+1, err)
+			}
+			tiers = append(tiers, domain.RateTier{From: from, Rate: tier.Rate})
+		}
+
+		plan, err := domain.NewRatePlan(record.Name, currency, tiers)
+		if err != nil {
+			return nil, fmt.Errorf("plan %d: %w", i+1, err)
+		}
+
+		if _, ok := plans[plan.Name]; ok {
+			return nil, fmt.Errorf("plan %d %s: %w", i+1, plan.Name, duplicatePlan)
+		}
+		plans[plan.Name] = plan
+	}
+
+	return plans, nil
+}
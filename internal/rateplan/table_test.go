@@ -0,0 +1,93 @@
+package rateplan
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+)
+
+func writePlans(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "plans.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write plans: %v", err)
+	}
+
+	return path
+}
+
+func TestNewTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []domain.RatePlan
+		wantErr error
+	}{
+		{
+			name: "flat and tiered plans",
+			content: `{"plans": [
+				{"name": "premium", "currency": "EUR", "tiers": [{"from": "0", "rate": "0.01"}, {"from": "10000.00", "rate": "0.025"}]},
+				{"name": "easy-saver", "currency": "USD", "tiers": [{"from": "0.00", "rate": "0.02"}]}
+			]}`,
+			want: []domain.RatePlan{
+				{Name: "easy-saver", Currency: domain.USD, Tiers: []domain.RateTier{{From: domain.NewMoney(0, domain.USD), Rate: "0.02"}}},
+				{Name: "premium", Currency: domain.EUR, Tiers: []domain.RateTier{
+					{From: domain.NewMoney(0, domain.EUR), Rate: "0.01"},
+					{From: domain.NewMoney(1000000, domain.EUR), Rate: "0.025"},
+				}},
+			},
+		},
+		{
+			name: "duplicate name, want duplicatePlan",
+			content: `{"plans": [
+				{"name": "easy-saver", "currency": "EUR", "tiers": [{"from": "0", "rate": "0.02"}]},
+				{"name": "easy-saver", "currency": "EUR", "tiers": [{"from": "0", "rate": "0.03"}]}
+			]}`,
+			wantErr: duplicatePlan,
+		},
+		{
+			name:    "tiers out of order, want failedToReadPlans",
+			content: `{"plans": [{"name": "premium", "currency": "EUR", "tiers": [{"from": "0", "rate": "0.01"}, {"from": "0", "rate": "0.02"}]}]}`,
+			wantErr: failedToReadPlans,
+		},
+		{
+			name:    "unknown field, want failedToReadPlans",
+			content: `{"plans": [{"name": "premium", "currency": "EUR", "rate": "0.01"}]}`,
+			wantErr: failedToReadPlans,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := NewTable(writePlans(t, tt.content))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, table.Plans()); diff != "" {
+				t.Errorf("Plans() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTable_Plan(t *testing.T) {
+	table, err := NewTable(writePlans(t, `{"plans": [{"name": "easy-saver", "currency": "EUR", "tiers": [{"from": "0", "rate": "0.02"}]}]}`))
+	if err != nil {
+		t.Fatalf("NewTable() error = %v", err)
+	}
+
+	if _, err := table.Plan("easy-saver"); err != nil {
+		t.Errorf("Plan() error = %v", err)
+	}
+	if _, err := table.Plan("premium"); !errors.Is(err, planNotFound) {
+		t.Errorf("Plan() error = %v, wantErr %v", err, planNotFound)
+	}
+}
@@ -0,0 +1,56 @@
+// Package reloadable holds what the bank reads from a file at startup and reads again while serving, such as the
+// exchange rates, the interest rate plans and the fee schedule.
+package reloadable
+
+import "sync"
+
+// File is what read made of the file at path. Reload reads it again and swaps the whole value at once, a failed
+// reload keeps the value read before. Values are never changed once read, Get hands out the current one.
+type File[T any] struct {
+	path  string
+	read  func(path string) (T, error)
+	value T
+	mutex sync.RWMutex
+}
+
+// NewFile reads the file at path with read, an empty path gives empty and is never read.
+func NewFile[T any](path string, empty T, read func(path string) (T, error)) (*File[T], error) {
+	file := &File[T]{
+		path:  path,
+		read:  read,
+		value: empty,
+	}
+
+	if err := file.Reload(); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Reload reads the file again and swaps the whole value at once.
+func (file *File[T]) Reload() error {
+	if file.path == "" {
+		return nil
+	}
+
+	value, err := file.read(file.path)
+	if err != nil {
+		return err
+	}
+
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+
+	file.value = value
+
+	return nil
+}
+
+// Get returns the value last read
+func (file *File[T]) Get() T {
+	file.mutex.RLock()
+	defer file.mutex.RUnlock()
+
+	return file.value
+}
@@ -0,0 +1,70 @@
+package reloadable
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func readNumber(path string) (int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(string(content))
+}
+
+func TestFile_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "number")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	write("1")
+	file, err := NewFile(path, 0, readNumber)
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+	if got := file.Get(); got != 1 {
+		t.Errorf("Get() = %d, want 1", got)
+	}
+
+	write("2")
+	if err := file.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := file.Get(); got != 2 {
+		t.Errorf("Get() after Reload() = %d, want 2", got)
+	}
+
+	write("broken")
+	var numErr *strconv.NumError
+	if err := file.Reload(); !errors.As(err, &numErr) {
+		t.Errorf("Reload() broken file error = %v, want a strconv.NumError", err)
+	}
+	if got := file.Get(); got != 2 {
+		t.Errorf("Get() after a failed Reload() = %d, want the value read before", got)
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	file, err := NewFile("", -1, func(string) (int, error) {
+		t.Fatal("read an empty path")
+		return 0, nil
+	})
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+	if got := file.Get(); got != -1 {
+		t.Errorf("Get() = %d, want the empty value", got)
+	}
+
+	if _, err := NewFile(filepath.Join(t.TempDir(), "missing"), 0, readNumber); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("NewFile() missing file error = %v, wantErr %v", err, os.ErrNotExist)
+	}
+}
@@ -1,16 +1,22 @@
 package memory
 
 import (
-	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 
 	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
 )
 
+// AccountRepository keeps its own copies of the accounts, changing an account returned by Get has no effect
+// until Update
 type AccountRepository struct {
-	transactions map[string]*domain.Transaction
-	accounts     map[string]*domain.Account
-	mutex        sync.RWMutex
+	accounts map[string]*domain.Account
+	mutex    sync.RWMutex
+	journal  Journal
 }
 
 func NewAccountRepository() *AccountRepository {
@@ -25,12 +31,17 @@ func (repo *AccountRepository) Insert(account *domain.Account) (*domain.Account,
 	defer repo.mutex.Unlock()
 
 	if repo.accounts[account.ID] != nil {
-		return nil, errors.New("account with id already exists")
+		return nil, fmt.Errorf("account with id %w", repository.ErrAlreadyExists)
 	}
 
-	repo.accounts[account.ID] = account
+	if err := appendTo(repo.journal, eventlog.AccountOpened{Account: *account}); err != nil {
+		return nil, err
+	}
+
+	stored := *account
+	repo.accounts[account.ID] = &stored
 
-	return repo.accounts[account.ID], nil
+	return account, nil
 }
 
 func (repo *AccountRepository) Get(accID string) (*domain.Account, error) {
@@ -40,13 +51,47 @@ func (repo *AccountRepository) Get(accID string) (*domain.Account, error) {
 	account, ok := repo.accounts[accID]
 
 	if !ok {
-		return nil, errors.New("account with id does not exists")
+		return nil, fmt.Errorf("account with id %w", repository.ErrNotFound)
+	}
+
+	accountCopy := *account
+	return &accountCopy, nil
+}
+
+// stored returns the account as the repository holds it, which must not be changed. Every write replaces it, units
+// of work compare it with the one they read to tell whether the account was written since.
+func (repo *AccountRepository) stored(accID string) (*domain.Account, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	account, ok := repo.accounts[accID]
+	if !ok {
+		return nil, fmt.Errorf("account with id %w", repository.ErrNotFound)
+	}
+
+	return account, nil
+}
+
+func (repo *AccountRepository) Update(account *domain.Account) (*domain.Account, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	current := repo.accounts[account.ID]
+	if current == nil {
+		return nil, fmt.Errorf("account with id %w", repository.ErrNotFound)
+	}
+
+	if err := appendTo(repo.journal, accountChanges(current, account)...); err != nil {
+		return nil, err
 	}
 
+	stored := *account
+	repo.accounts[account.ID] = &stored
+
 	return account, nil
 }
 
-func (repo *AccountRepository) GetUserAccounts(userID string) []domain.Account {
+func (repo *AccountRepository) GetUserAccounts(userID string) ([]domain.Account, error) {
 	repo.mutex.RLock()
 	defer repo.mutex.RUnlock()
 
@@ -58,14 +103,51 @@ func (repo *AccountRepository) GetUserAccounts(userID string) []domain.Account {
 		}
 	}
 
-	return accounts
+	return accounts, nil
 }
 
-func (repo *AccountRepository) UpdateBulk(accounts []domain.Account) {
+func (repo *AccountRepository) UpdateBulk(accounts []domain.Account) error {
 	repo.mutex.Lock()
 	defer repo.mutex.Unlock()
 
+	var events []eventlog.Event
+	for _, account := range accounts {
+		current := repo.accounts[account.ID]
+		if current == nil {
+			return fmt.Errorf("account with id %w", repository.ErrNotFound)
+		}
+
+		events = append(events, accountChanges(current, &account)...)
+	}
+
+	if err := appendTo(repo.journal, events...); err != nil {
+		return err
+	}
+
 	for _, account := range accounts {
 		repo.accounts[account.ID] = &account
 	}
+
+	return nil
+}
+
+func (repo *AccountRepository) GetAll(page repository.Page[string]) ([]domain.Account, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	var accounts []domain.Account
+	for _, account := range repo.accounts {
+		if page.After == nil || account.ID > *page.After {
+			accounts = append(accounts, *account)
+		}
+	}
+
+	slices.SortFunc(accounts, func(a, b domain.Account) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	if page.Limit > 0 && len(accounts) > page.Limit {
+		accounts = accounts[:page.Limit]
+	}
+
+	return accounts, nil
 }
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
+)
+
+type AuditRepository struct {
+	events  []domain.AuditEvent
+	ids     map[string]bool
+	mutex   sync.RWMutex
+	journal Journal
+}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{
+		ids:   make(map[string]bool),
+		mutex: sync.RWMutex{},
+	}
+}
+
+func (repo *AuditRepository) Insert(event *domain.AuditEvent) (*domain.AuditEvent, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.ids[event.ID] {
+		return nil, fmt.Errorf("audit event with id %w", repository.ErrAlreadyExists)
+	}
+
+	if err := appendTo(repo.journal, eventlog.AuditRecorded{Event: *event}); err != nil {
+		return nil, err
+	}
+
+	repo.insert(event)
+
+	return event, nil
+}
+
+// insert expects the caller to hold the write lock
+func (repo *AuditRepository) insert(event *domain.AuditEvent) {
+	stored := *event
+	stored.AccountIDs = slices.Clone(event.AccountIDs)
+	repo.events = append(repo.events, stored)
+	repo.ids[event.ID] = true
+}
+
+func (repo *AuditRepository) GetUserEvents(userID string) ([]domain.AuditEvent, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	var events []domain.AuditEvent
+	for _, event := range repo.events {
+		if event.UserID == userID {
+			event.AccountIDs = slices.Clone(event.AccountIDs)
+			events = append(events, event)
+		}
+	}
+
+	// events are appended as they happen but clocks can disagree between callers
+	slices.SortStableFunc(events, func(a, b domain.AuditEvent) int {
+		return a.OccurredAt.Compare(b.OccurredAt)
+	})
+
+	return events, nil
+}
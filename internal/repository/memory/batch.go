@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
+)
+
+// BatchRepository keeps its own copies of the batches and their lines
+type BatchRepository struct {
+	batches map[string]*domain.Batch
+	mutex   sync.RWMutex
+	journal Journal
+}
+
+func NewBatchRepository() *BatchRepository {
+	return &BatchRepository{
+		batches: make(map[string]*domain.Batch),
+		mutex:   sync.RWMutex{},
+	}
+}
+
+func (repo *BatchRepository) Insert(batch *domain.Batch) (*domain.Batch, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.batches[batch.ID] != nil {
+		return nil, fmt.Errorf("batch with id %w", repository.ErrAlreadyExists)
+	}
+
+	if err := appendTo(repo.journal, eventlog.BatchCreated{Batch: copyBatch(batch)}); err != nil {
+		return nil, err
+	}
+
+	repo.store(batch)
+
+	return batch, nil
+}
+
+func (repo *BatchRepository) Get(batchID string) (*domain.Batch, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	batch, ok := repo.batches[batchID]
+	if !ok {
+		return nil, fmt.Errorf("batch with id %w", repository.ErrNotFound)
+	}
+
+	batchCopy := copyBatch(batch)
+	return &batchCopy, nil
+}
+
+func (repo *BatchRepository) Update(batch *domain.Batch) (*domain.Batch, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.batches[batch.ID] == nil {
+		return nil, fmt.Errorf("batch with id %w", repository.ErrNotFound)
+	}
+
+	if err := appendTo(repo.journal, eventlog.BatchUpdated{Batch: copyBatch(batch)}); err != nil {
+		return nil, err
+	}
+
+	repo.store(batch)
+
+	return batch, nil
+}
+
+func (repo *BatchRepository) UpdateLine(batchID string, line *domain.BatchLine) (*domain.BatchLine, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if err := repo.findLine(batchID, line.Number); err != nil {
+		return nil, err
+	}
+
+	if err := appendTo(repo.journal, eventlog.BatchLineUpdated{BatchID: batchID, Line: *line}); err != nil {
+		return nil, err
+	}
+
+	repo.updateLine(batchID, line)
+
+	return line, nil
+}
+
+// findLine expects the caller to hold the lock
+func (repo *BatchRepository) findLine(batchID string, number int) error {
+	batch := repo.batches[batchID]
+	if batch == nil {
+		return fmt.Errorf("batch with id %w", repository.ErrNotFound)
+	}
+	if number < 1 || number > len(batch.Lines) {
+		return fmt.Errorf("batch line with number %w", repository.ErrNotFound)
+	}
+
+	return nil
+}
+
+// updateLine expects the caller to hold the write lock and the line to exist
+func (repo *BatchRepository) updateLine(batchID string, line *domain.BatchLine) {
+	repo.batches[batchID].Lines[line.Number-1] = *line
+}
+
+// store expects the caller to hold the write lock
+func (repo *BatchRepository) store(batch *domain.Batch) {
+	stored := copyBatch(batch)
+	repo.batches[batch.ID] = &stored
+}
+
+func copyBatch(batch *domain.Batch) domain.Batch {
+	batchCopy := *batch
+	batchCopy.Lines = slices.Clone(batch.Lines)
+
+	return batchCopy
+}
@@ -0,0 +1,155 @@
+package memory
+
+import "slices"
+
+// btreeDegree is the minimum degree of the tree, every node but the root holds between btreeDegree-1 and
+// 2*btreeDegree-1 items
+const btreeDegree = 32
+
+const btreeMaxItems = 2*btreeDegree - 1
+
+type btreeItem[K, V any] struct {
+	key   K
+	value V
+}
+
+type btreeNode[K, V any] struct {
+	items []btreeItem[K, V]
+	// children is empty on leaves, otherwise it holds one more child than there are items
+	children []*btreeNode[K, V]
+}
+
+// btree is an ordered map, inserting and finding where to start an ordered walk cost O(log n) whatever the order
+// keys arrive in. Keys are unique, callers check for duplicates before inserting. It isn't safe for concurrent use.
+type btree[K, V any] struct {
+	root    *btreeNode[K, V]
+	compare func(a, b K) int
+	length  int
+}
+
+func newBTree[K, V any](compare func(a, b K) int) *btree[K, V] {
+	return &btree[K, V]{compare: compare}
+}
+
+func (tree *btree[K, V]) size() int {
+	return tree.length
+}
+
+func (tree *btree[K, V]) insert(key K, value V) {
+	item := btreeItem[K, V]{key: key, value: value}
+	tree.length++
+
+	if tree.root == nil {
+		tree.root = &btreeNode[K, V]{items: []btreeItem[K, V]{item}}
+		return
+	}
+
+	// full nodes are split on the way down so there is always room for the item and a median moving up
+	if len(tree.root.items) == btreeMaxItems {
+		root := &btreeNode[K, V]{children: []*btreeNode[K, V]{tree.root}}
+		root.splitChild(0)
+		tree.root = root
+	}
+
+	node := tree.root
+	for {
+		i := node.search(key, tree.compare)
+		if len(node.children) == 0 {
+			node.items = slices.Insert(node.items, i, item)
+			return
+		}
+
+		if len(node.children[i].items) == btreeMaxItems {
+			node.splitChild(i)
+			if tree.compare(key, node.items[i].key) > 0 {
+				i++
+			}
+		}
+		node = node.children[i]
+	}
+}
+
+// ascend calls fn with every item whose key isn't below pivot, in order, until fn returns false
+func (tree *btree[K, V]) ascend(pivot K, fn func(key K, value V) bool) {
+	if tree.root != nil {
+		tree.root.ascend(pivot, tree.compare, fn)
+	}
+}
+
+// descend calls fn with every item whose key isn't above pivot, in reverse order, until fn returns false
+func (tree *btree[K, V]) descend(pivot K, fn func(key K, value V) bool) {
+	if tree.root != nil {
+		tree.root.descend(pivot, tree.compare, fn)
+	}
+}
+
+// search returns the index of the first item whose key isn't below key
+func (node *btreeNode[K, V]) search(key K, compare func(a, b K) int) int {
+	i, _ := slices.BinarySearchFunc(node.items, key, func(item btreeItem[K, V], key K) int {
+		return compare(item.key, key)
+	})
+
+	return i
+}
+
+// splitChild moves the median item of the full child i up into node, the items after it move to a new sibling
+func (node *btreeNode[K, V]) splitChild(i int) {
+	child := node.children[i]
+	median := child.items[btreeDegree-1]
+
+	sibling := &btreeNode[K, V]{items: slices.Clone(child.items[btreeDegree:])}
+	clear(child.items[btreeDegree-1:])
+	child.items = child.items[:btreeDegree-1]
+
+	if len(child.children) > 0 {
+		sibling.children = slices.Clone(child.children[btreeDegree:])
+		clear(child.children[btreeDegree:])
+		child.children = child.children[:btreeDegree]
+	}
+
+	node.items = slices.Insert(node.items, i, median)
+	node.children = slices.Insert(node.children, i+1, sibling)
+}
+
+func (node *btreeNode[K, V]) ascend(pivot K, compare func(a, b K) int, fn func(key K, value V) bool) bool {
+	// children before i only hold keys below pivot, children after i only keys above it
+	i := node.search(pivot, compare)
+	for ; i < len(node.items); i++ {
+		if len(node.children) > 0 && !node.children[i].ascend(pivot, compare, fn) {
+			return false
+		}
+		if !fn(node.items[i].key, node.items[i].value) {
+			return false
+		}
+	}
+
+	if len(node.children) > 0 {
+		return node.children[len(node.items)].ascend(pivot, compare, fn)
+	}
+
+	return true
+}
+
+func (node *btreeNode[K, V]) descend(pivot K, compare func(a, b K) int, fn func(key K, value V) bool) bool {
+	// items before i aren't above pivot, the child at i may still hold keys that aren't either
+	i, found := slices.BinarySearchFunc(node.items, pivot, func(item btreeItem[K, V], key K) int {
+		return compare(item.key, key)
+	})
+	if found {
+		i++
+	}
+
+	if len(node.children) > 0 && !node.children[i].descend(pivot, compare, fn) {
+		return false
+	}
+	for i--; i >= 0; i-- {
+		if !fn(node.items[i].key, node.items[i].value) {
+			return false
+		}
+		if len(node.children) > 0 && !node.children[i].descend(pivot, compare, fn) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+
+	gocmp "github.com/google/go-cmp/cmp"
+)
+
+func TestBTree_Walk(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []int
+	}{
+		{name: "empty"},
+		{name: "single leaf", keys: []int{5, 1, 3}},
+		{name: "ascending keys", keys: sequence(0, 5000, 1)},
+		{name: "descending keys", keys: sequence(5000, 0, -1)},
+		{name: "shuffled keys", keys: shuffled(sequence(0, 5000, 1))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newBTree[int, int](cmp.Compare[int])
+			for _, key := range tt.keys {
+				tree.insert(key, key*10)
+			}
+
+			if tree.size() != len(tt.keys) {
+				t.Errorf("size() = %d, want %d", tree.size(), len(tt.keys))
+			}
+
+			sorted := slices.Sorted(slices.Values(tt.keys))
+			for _, pivot := range []int{-1, 0, 1, 2, 63, 64, 2500, 4999, 5000, 5001} {
+				var want []int
+				for _, key := range sorted {
+					if key >= pivot {
+						want = append(want, key)
+					}
+				}
+
+				var got []int
+				tree.ascend(pivot, func(key, value int) bool {
+					if value != key*10 {
+						t.Errorf("ascend() value of %d = %d, want %d", key, value, key*10)
+					}
+					got = append(got, key)
+					return true
+				})
+
+				if diff := gocmp.Diff(want, got); diff != "" {
+					t.Errorf("ascend(%d) (-want +got):\n%s", pivot, diff)
+				}
+
+				var wantDescending []int
+				for i := len(sorted) - 1; i >= 0; i-- {
+					if sorted[i] <= pivot {
+						wantDescending = append(wantDescending, sorted[i])
+					}
+				}
+
+				var gotDescending []int
+				tree.descend(pivot, func(key, _ int) bool {
+					gotDescending = append(gotDescending, key)
+					return true
+				})
+
+				if diff := gocmp.Diff(wantDescending, gotDescending); diff != "" {
+					t.Errorf("descend(%d) (-want +got):\n%s", pivot, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestBTree_WalkStops(t *testing.T) {
+	tree := newBTree[int, int](cmp.Compare[int])
+	for _, key := range shuffled(sequence(0, 1000, 1)) {
+		tree.insert(key, key)
+	}
+
+	var got []int
+	tree.ascend(500, func(key, _ int) bool {
+		got = append(got, key)
+		return len(got) < 3
+	})
+
+	if diff := gocmp.Diff([]int{500, 501, 502}, got); diff != "" {
+		t.Errorf("ascend() (-want +got):\n%s", diff)
+	}
+
+	got = nil
+	tree.descend(500, func(key, _ int) bool {
+		got = append(got, key)
+		return len(got) < 3
+	})
+
+	if diff := gocmp.Diff([]int{500, 499, 498}, got); diff != "" {
+		t.Errorf("descend() (-want +got):\n%s", diff)
+	}
+}
+
+func sequence(from, to, step int) []int {
+	var keys []int
+	for key := from; key != to; key += step {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func shuffled(keys []int) []int {
+	random := rand.New(rand.NewSource(1))
+	random.Shuffle(len(keys), func(i, j int) {
+		keys[i], keys[j] = keys[j], keys[i]
+	})
+
+	return keys
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+// HoldRepository keeps its own copies of the holds, they're written by the unit of work that changes the account
+// they're on
+type HoldRepository struct {
+	holds map[string]*domain.Hold
+	mutex sync.RWMutex
+}
+
+func NewHoldRepository() *HoldRepository {
+	return &HoldRepository{
+		holds: make(map[string]*domain.Hold),
+		mutex: sync.RWMutex{},
+	}
+}
+
+func (repo *HoldRepository) Get(holdID string) (*domain.Hold, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	hold, ok := repo.holds[holdID]
+	if !ok {
+		return nil, fmt.Errorf("hold with id %w", repository.ErrNotFound)
+	}
+
+	holdCopy := copyHold(hold)
+	return &holdCopy, nil
+}
+
+// stored returns the hold as the repository holds it, which must not be changed. Every write replaces it, units of
+// work compare it with the one they read to tell whether the hold was written since.
+func (repo *HoldRepository) stored(holdID string) (*domain.Hold, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	hold, ok := repo.holds[holdID]
+	if !ok {
+		return nil, fmt.Errorf("hold with id %w", repository.ErrNotFound)
+	}
+
+	return hold, nil
+}
+
+func (repo *HoldRepository) GetByAccount(accountID string) ([]*domain.Hold, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	var holds []*domain.Hold
+	for _, hold := range repo.holds {
+		if hold.AccountID == accountID {
+			holdCopy := copyHold(hold)
+			holds = append(holds, &holdCopy)
+		}
+	}
+
+	slices.SortFunc(holds, func(a, b *domain.Hold) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return holds, nil
+}
+
+func (repo *HoldRepository) Expired(at time.Time) ([]*domain.Hold, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	var holds []*domain.Hold
+	for _, hold := range repo.holds {
+		if hold.Expired(at) {
+			holdCopy := copyHold(hold)
+			holds = append(holds, &holdCopy)
+		}
+	}
+
+	slices.SortFunc(holds, func(a, b *domain.Hold) int {
+		return cmp.Or(a.ExpiresAt.Compare(b.ExpiresAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return holds, nil
+}
+
+// store expects the caller to hold the write lock
+func (repo *HoldRepository) store(hold *domain.Hold) {
+	stored := copyHold(hold)
+	repo.holds[hold.ID] = &stored
+}
+
+func copyHold(hold *domain.Hold) domain.Hold {
+	holdCopy := *hold
+	holdCopy.ReleasedAt = copyTime(hold.ReleasedAt)
+
+	return holdCopy
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"http/internal/domain"
+	"http/internal/eventlog"
+)
+
+// Journal records changes before the repositories apply them, a change the journal refuses is not applied.
+// Repositories built without one keep everything in memory only.
+type Journal interface {
+	Append(events ...eventlog.Event) error
+}
+
+func appendTo(journal Journal, events ...eventlog.Event) error {
+	if journal == nil || len(events) == 0 {
+		return nil
+	}
+
+	return journal.Append(events...)
+}
+
+// userChanges describes how updated differs from current
+func userChanges(current, updated *domain.User) []eventlog.Event {
+	switch {
+	case current.Name != updated.Name:
+		return []eventlog.Event{eventlog.UserUpdated{User: *updated}}
+	case current.DeletedAt == nil && updated.DeletedAt != nil:
+		return []eventlog.Event{eventlog.UserDeleted{UserID: updated.ID, DeletedAt: *updated.DeletedAt}}
+	case current.DeletedAt != nil && updated.DeletedAt == nil:
+		return []eventlog.Event{eventlog.UserRestored{UserID: updated.ID}}
+	case current.DeletedAt != nil && !current.DeletedAt.Equal(*updated.DeletedAt):
+		return []eventlog.Event{eventlog.UserUpdated{User: *updated}}
+	default:
+		return nil
+	}
+}
+
+// accountChanges describes how updated differs from current, balance movements and closing get their own events
+// and anything else carries the whole account
+func accountChanges(current, updated *domain.Account) []eventlog.Event {
+	if current.UserID != updated.UserID || current.Type != updated.Type || current.Nickname != updated.Nickname ||
+		(current.DeletedAt != nil && updated.DeletedAt != nil && !current.DeletedAt.Equal(*updated.DeletedAt)) ||
+		overdraftChanged(current.Overdraft, updated.Overdraft) || savingsInterestChanged(current.Interest, updated.Interest) ||
+		current.Held != updated.Held {
+		return []eventlog.Event{eventlog.AccountUpdated{Account: *updated}}
+	}
+
+	var events []eventlog.Event
+	if current.Balance != updated.Balance {
+		events = append(events, eventlog.BalanceChanged{AccountID: updated.ID, Balance: updated.Balance})
+	}
+
+	switch {
+	case current.DeletedAt == nil && updated.DeletedAt != nil:
+		events = append(events, eventlog.AccountClosed{AccountID: updated.ID, ClosedAt: *updated.DeletedAt})
+	case current.DeletedAt != nil && updated.DeletedAt == nil:
+		events = append(events, eventlog.AccountReopened{AccountID: updated.ID})
+	}
+
+	return events
+}
+
+func overdraftChanged(current, updated domain.Overdraft) bool {
+	return current.Limit != updated.Limit || current.Rate != updated.Rate || current.Accrued != updated.Accrued ||
+		!current.AccruedThrough.Equal(updated.AccruedThrough)
+}
+
+func savingsInterestChanged(current, updated domain.SavingsInterest) bool {
+	return current.Plan != updated.Plan || current.Accrued != updated.Accrued ||
+		!current.AccruedThrough.Equal(updated.AccruedThrough)
+}
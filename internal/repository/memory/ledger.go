@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
+)
+
+// ledgerKey identifies a ledger account total, system accounts hold one per currency
+type ledgerKey struct {
+	accountID string
+	currency  domain.Currency
+}
+
+type LedgerRepository struct {
+	entries map[string]*domain.JournalEntry
+	totals  map[ledgerKey]domain.LedgerAccountTotal
+	mutex   sync.RWMutex
+	journal Journal
+}
+
+func NewLedgerRepository() *LedgerRepository {
+	return &LedgerRepository{
+		entries: make(map[string]*domain.JournalEntry),
+		totals:  make(map[ledgerKey]domain.LedgerAccountTotal),
+		mutex:   sync.RWMutex{},
+	}
+}
+
+func (repo *LedgerRepository) Insert(entry *domain.JournalEntry) (*domain.JournalEntry, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.entries[entry.ID]; ok {
+		return nil, fmt.Errorf("journal entry with id %w", repository.ErrAlreadyExists)
+	}
+
+	totals, err := repo.post(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := appendTo(repo.journal, eventlog.JournalEntryPosted{Entry: *entry}); err != nil {
+		return nil, err
+	}
+
+	repo.insert(totals, entry)
+
+	return repo.entries[entry.ID], nil
+}
+
+// post returns the totals touched by entries as they would be once posted, without applying them, so a commit can
+// fail before its first write. It expects the caller to hold the lock.
+func (repo *LedgerRepository) post(entries ...*domain.JournalEntry) (map[ledgerKey]domain.LedgerAccountTotal, error) {
+	totals := make(map[ledgerKey]domain.LedgerAccountTotal)
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			key := ledgerKey{accountID: line.AccountID, currency: line.Amount.Currency}
+			total, ok := totals[key]
+			if !ok {
+				total, ok = repo.totals[key]
+			}
+			if !ok {
+				total = domain.NewLedgerAccountTotal(line.AccountID, line.Amount.Currency)
+			}
+
+			if err := total.Post(line); err != nil {
+				return nil, err
+			}
+			totals[key] = total
+		}
+	}
+
+	return totals, nil
+}
+
+// insert expects the caller to hold the write lock and totals to come from post with the same entries
+func (repo *LedgerRepository) insert(totals map[ledgerKey]domain.LedgerAccountTotal, entries ...*domain.JournalEntry) {
+	for _, entry := range entries {
+		repo.entries[entry.ID] = entry
+	}
+	maps.Copy(repo.totals, totals)
+}
+
+// GetTotals returns one total per ledger account and currency sorted by account id, taken from a single consistent
+// snapshot
+func (repo *LedgerRepository) GetTotals() ([]domain.LedgerAccountTotal, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	totals := slices.Collect(maps.Values(repo.totals))
+	slices.SortFunc(totals, func(a, b domain.LedgerAccountTotal) int {
+		return cmp.Or(strings.Compare(a.AccountID, b.AccountID), strings.Compare(string(a.Currency), string(b.Currency)))
+	})
+
+	return totals, nil
+}
+
+func (repo *LedgerRepository) GetAccountTotal(accountID string, currency domain.Currency) (domain.LedgerAccountTotal, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	if total, ok := repo.totals[ledgerKey{accountID: accountID, currency: currency}]; ok {
+		return total, nil
+	}
+
+	return domain.NewLedgerAccountTotal(accountID, currency), nil
+}
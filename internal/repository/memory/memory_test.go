@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"http/internal/domain"
+	"http/internal/repository/repotest"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		accountRepository := NewAccountRepository()
+		transactionRepository := NewTransactionRepository()
+		ledgerRepository := NewLedgerRepository()
+		holdRepository := NewHoldRepository()
+		standingOrderRepository := NewStandingOrderRepository()
+
+		return repotest.Repositories{
+			Users:             NewUserRepository(),
+			Accounts:          accountRepository,
+			Transactions:      transactionRepository,
+			Ledger:            ledgerRepository,
+			Audit:             NewAuditRepository(),
+			Batches:           NewBatchRepository(),
+			StandingOrders:    standingOrderRepository,
+			Holds:             holdRepository,
+			UnitOfWorkFactory: NewUnitOfWorkFactory(accountRepository, transactionRepository, ledgerRepository, holdRepository, standingOrderRepository),
+		}
+	})
+}
+
+func TestStoreRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := NewStore(&recordingJournal{})
+
+		return repotest.Repositories{
+			Users:             store.Users,
+			Accounts:          store.Accounts,
+			Transactions:      store.Transactions,
+			Ledger:            store.Ledger,
+			Audit:             store.Audit,
+			Batches:           store.Batches,
+			StandingOrders:    store.StandingOrders,
+			Holds:             store.Holds,
+			UnitOfWorkFactory: store.UnitOfWorkFactory,
+		}
+	})
+}
+
+func TestUnitOfWork_ChangedSinceRead(t *testing.T) {
+	accountRepository := NewAccountRepository()
+	holdRepository := NewHoldRepository()
+	factory := NewUnitOfWorkFactory(accountRepository, NewTransactionRepository(), NewLedgerRepository(), holdRepository, NewStandingOrderRepository())
+	accountRepository.Insert(&domain.Account{ID: "1", UserID: "1", Balance: domain.NewMoney(100, domain.EUR)})
+
+	setup, _ := factory.Begin(context.Background())
+	setup.InsertHold(&domain.Hold{ID: "h", AccountID: "1", Amount: domain.NewMoney(10, domain.EUR), Status: domain.HoldActive})
+	if err := setup.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	// stale reads the account and the hold, then another write lands before it commits
+	stale, _ := factory.Begin(context.Background())
+	acc, _ := stale.GetAccount("1")
+	hold, _ := stale.GetHold("h")
+
+	other, _ := factory.Begin(context.Background())
+	changed, _ := other.GetAccount("1")
+	changed.Balance = domain.NewMoney(50, domain.EUR)
+	other.UpdateAccount(changed)
+	if err := other.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	acc.Nickname = "savings"
+	stale.UpdateAccount(acc)
+	if err := stale.Commit(); !errors.Is(err, changedSinceReadError) {
+		t.Errorf("Commit() of a changed account error = %v, wantErr %v", err, changedSinceReadError)
+	}
+	if got, _ := accountRepository.Get("1"); got.Balance != domain.NewMoney(50, domain.EUR) || got.Nickname != "" {
+		t.Errorf("account got = %+v, want the other write kept", got)
+	}
+
+	stale, _ = factory.Begin(context.Background())
+	stale.GetHold("h")
+	other, _ = factory.Begin(context.Background())
+	hold.Status = domain.HoldCaptured
+	other.UpdateHold(hold)
+	if err := other.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	hold.Status = domain.HoldVoided
+	stale.UpdateHold(hold)
+	if err := stale.Commit(); !errors.Is(err, changedSinceReadError) {
+		t.Errorf("Commit() of a changed hold error = %v, wantErr %v", err, changedSinceReadError)
+	}
+	if got, _ := holdRepository.Get("h"); got.Status != domain.HoldCaptured {
+		t.Errorf("hold status got = %s, want %s", got.Status, domain.HoldCaptured)
+	}
+}
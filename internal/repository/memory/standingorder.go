@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
+)
+
+// StandingOrderRepository keeps its own copies of the standing orders, executions are kept per order in the order
+// they were recorded
+type StandingOrderRepository struct {
+	orders       map[string]*domain.StandingOrder
+	executions   map[string][]domain.StandingOrderExecution
+	executionIDs map[string]bool
+	mutex        sync.RWMutex
+	journal      Journal
+}
+
+func NewStandingOrderRepository() *StandingOrderRepository {
+	return &StandingOrderRepository{
+		orders:       make(map[string]*domain.StandingOrder),
+		executions:   make(map[string][]domain.StandingOrderExecution),
+		executionIDs: make(map[string]bool),
+		mutex:        sync.RWMutex{},
+	}
+}
+
+func (repo *StandingOrderRepository) Insert(order *domain.StandingOrder) (*domain.StandingOrder, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.orders[order.ID] != nil {
+		return nil, fmt.Errorf("standing order with id %w", repository.ErrAlreadyExists)
+	}
+
+	if err := appendTo(repo.journal, eventlog.StandingOrderCreated{Order: copyStandingOrder(order)}); err != nil {
+		return nil, err
+	}
+
+	repo.store(order)
+
+	return order, nil
+}
+
+func (repo *StandingOrderRepository) Get(orderID string) (*domain.StandingOrder, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	order, ok := repo.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("standing order with id %w", repository.ErrNotFound)
+	}
+
+	orderCopy := copyStandingOrder(order)
+	return &orderCopy, nil
+}
+
+func (repo *StandingOrderRepository) GetByAccount(accountID string) ([]*domain.StandingOrder, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	var orders []*domain.StandingOrder
+	for _, order := range repo.orders {
+		if order.AccountID == accountID {
+			orderCopy := copyStandingOrder(order)
+			orders = append(orders, &orderCopy)
+		}
+	}
+
+	slices.SortFunc(orders, func(a, b *domain.StandingOrder) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return orders, nil
+}
+
+func (repo *StandingOrderRepository) Due(at time.Time) ([]*domain.StandingOrder, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	var orders []*domain.StandingOrder
+	for _, order := range repo.orders {
+		if order.Due(at) {
+			orderCopy := copyStandingOrder(order)
+			orders = append(orders, &orderCopy)
+		}
+	}
+
+	slices.SortFunc(orders, func(a, b *domain.StandingOrder) int {
+		return cmp.Or(a.DueAt.Compare(*b.DueAt), strings.Compare(a.ID, b.ID))
+	})
+
+	return orders, nil
+}
+
+func (repo *StandingOrderRepository) Update(order *domain.StandingOrder) (*domain.StandingOrder, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.orders[order.ID] == nil {
+		return nil, fmt.Errorf("standing order with id %w", repository.ErrNotFound)
+	}
+
+	if err := appendTo(repo.journal, eventlog.StandingOrderUpdated{Order: copyStandingOrder(order)}); err != nil {
+		return nil, err
+	}
+
+	repo.store(order)
+
+	return order, nil
+}
+
+func (repo *StandingOrderRepository) InsertExecution(execution *domain.StandingOrderExecution) (*domain.StandingOrderExecution, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.executionIDs[execution.ID] {
+		return nil, fmt.Errorf("standing order execution with id %w", repository.ErrAlreadyExists)
+	}
+	if repo.orders[execution.OrderID] == nil {
+		return nil, fmt.Errorf("standing order with id %w", repository.ErrNotFound)
+	}
+
+	if err := appendTo(repo.journal, eventlog.StandingOrderExecuted{Execution: *execution}); err != nil {
+		return nil, err
+	}
+
+	repo.insertExecution(execution)
+
+	return execution, nil
+}
+
+func (repo *StandingOrderRepository) UpdateExecution(execution *domain.StandingOrderExecution) (*domain.StandingOrderExecution, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if !repo.executionIDs[execution.ID] {
+		return nil, fmt.Errorf("standing order execution with id %w", repository.ErrNotFound)
+	}
+
+	if err := appendTo(repo.journal, eventlog.StandingOrderExecutionUpdated{Execution: *execution}); err != nil {
+		return nil, err
+	}
+
+	repo.updateExecution(execution)
+
+	return execution, nil
+}
+
+func (repo *StandingOrderRepository) GetExecutions(orderID string) ([]*domain.StandingOrderExecution, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	var executions []*domain.StandingOrderExecution
+	for _, execution := range repo.executions[orderID] {
+		executions = append(executions, &execution)
+	}
+
+	return executions, nil
+}
+
+// store expects the caller to hold the write lock
+func (repo *StandingOrderRepository) store(order *domain.StandingOrder) {
+	stored := copyStandingOrder(order)
+	repo.orders[order.ID] = &stored
+}
+
+// insertExecution expects the caller to hold the write lock
+func (repo *StandingOrderRepository) insertExecution(execution *domain.StandingOrderExecution) {
+	repo.executions[execution.OrderID] = append(repo.executions[execution.OrderID], *execution)
+	repo.executionIDs[execution.ID] = true
+}
+
+// updateExecution expects the caller to hold the write lock
+func (repo *StandingOrderRepository) updateExecution(execution *domain.StandingOrderExecution) {
+	executions := repo.executions[execution.OrderID]
+	for i := range executions {
+		if executions[i].ID == execution.ID {
+			executions[i] = *execution
+		}
+	}
+}
+
+func copyStandingOrder(order *domain.StandingOrder) domain.StandingOrder {
+	orderCopy := *order
+	orderCopy.EndAt = copyTime(order.EndAt)
+	orderCopy.NextRunAt = copyTime(order.NextRunAt)
+	orderCopy.DueAt = copyTime(order.DueAt)
+
+	return orderCopy
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	tCopy := *t
+	return &tCopy
+}
@@ -0,0 +1,345 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
+)
+
+// Store groups the in memory repositories that share a journal, it rebuilds them from the journal's events and
+// describes their whole state for snapshots.
+type Store struct {
+	Users             *UserRepository
+	Accounts          *AccountRepository
+	Transactions      *TransactionRepository
+	Ledger            *LedgerRepository
+	Audit             *AuditRepository
+	Batches           *BatchRepository
+	StandingOrders    *StandingOrderRepository
+	Holds             *HoldRepository
+	UnitOfWorkFactory *UnitOfWorkFactory
+}
+
+func NewStore(journal Journal) *Store {
+	store := &Store{
+		Users:          NewUserRepository(),
+		Accounts:       NewAccountRepository(),
+		Transactions:   NewTransactionRepository(),
+		Ledger:         NewLedgerRepository(),
+		Audit:          NewAuditRepository(),
+		Batches:        NewBatchRepository(),
+		StandingOrders: NewStandingOrderRepository(),
+		Holds:          NewHoldRepository(),
+	}
+	store.UnitOfWorkFactory = NewUnitOfWorkFactory(store.Accounts, store.Transactions, store.Ledger, store.Holds, store.StandingOrders)
+
+	store.Users.journal = journal
+	store.Accounts.journal = journal
+	store.Transactions.journal = journal
+	store.Ledger.journal = journal
+	store.Audit.journal = journal
+	store.Batches.journal = journal
+	store.StandingOrders.journal = journal
+	store.UnitOfWorkFactory.journal = journal
+
+	return store
+}
+
+// Apply changes the repositories as described by event without journaling it again, it's meant for replaying the
+// journal on startup
+func (store *Store) Apply(event eventlog.Event) error {
+	switch e := event.(type) {
+	case eventlog.UserCreated:
+		return store.applyUser(e.User.ID, false, func(*domain.User) domain.User { return e.User })
+	case eventlog.UserUpdated:
+		return store.applyUser(e.User.ID, true, func(*domain.User) domain.User { return e.User })
+	case eventlog.UserDeleted:
+		return store.applyUser(e.UserID, true, func(user *domain.User) domain.User {
+			user.DeletedAt = &e.DeletedAt
+			return *user
+		})
+	case eventlog.UserRestored:
+		return store.applyUser(e.UserID, true, func(user *domain.User) domain.User {
+			user.DeletedAt = nil
+			return *user
+		})
+	case eventlog.AccountOpened:
+		return store.applyAccount(e.Account.ID, false, func(*domain.Account) domain.Account { return e.Account })
+	case eventlog.AccountUpdated:
+		return store.applyAccount(e.Account.ID, true, func(*domain.Account) domain.Account { return e.Account })
+	case eventlog.BalanceChanged:
+		return store.applyAccount(e.AccountID, true, func(account *domain.Account) domain.Account {
+			account.Balance = e.Balance
+			return *account
+		})
+	case eventlog.AccountClosed:
+		return store.applyAccount(e.AccountID, true, func(account *domain.Account) domain.Account {
+			account.DeletedAt = &e.ClosedAt
+			return *account
+		})
+	case eventlog.AccountReopened:
+		return store.applyAccount(e.AccountID, true, func(account *domain.Account) domain.Account {
+			account.DeletedAt = nil
+			return *account
+		})
+	case eventlog.TransactionRecorded:
+		store.Transactions.mutex.Lock()
+		defer store.Transactions.mutex.Unlock()
+
+		if _, ok := store.Transactions.transactions[e.Transaction.ID]; ok {
+			return fmt.Errorf("transaction with id %w", repository.ErrAlreadyExists)
+		}
+		store.Transactions.insert(&e.Transaction)
+		return nil
+	case eventlog.JournalEntryPosted:
+		store.Ledger.mutex.Lock()
+		defer store.Ledger.mutex.Unlock()
+
+		if _, ok := store.Ledger.entries[e.Entry.ID]; ok {
+			return fmt.Errorf("journal entry with id %w", repository.ErrAlreadyExists)
+		}
+		totals, err := store.Ledger.post(&e.Entry)
+		if err != nil {
+			return err
+		}
+		store.Ledger.insert(totals, &e.Entry)
+		return nil
+	case eventlog.AuditRecorded:
+		store.Audit.mutex.Lock()
+		defer store.Audit.mutex.Unlock()
+
+		if store.Audit.ids[e.Event.ID] {
+			return fmt.Errorf("audit event with id %w", repository.ErrAlreadyExists)
+		}
+		store.Audit.insert(&e.Event)
+		return nil
+	case eventlog.BatchCreated:
+		return store.applyBatch(e.Batch, false)
+	case eventlog.BatchUpdated:
+		return store.applyBatch(e.Batch, true)
+	case eventlog.BatchLineUpdated:
+		store.Batches.mutex.Lock()
+		defer store.Batches.mutex.Unlock()
+
+		if err := store.Batches.findLine(e.BatchID, e.Line.Number); err != nil {
+			return err
+		}
+		store.Batches.updateLine(e.BatchID, &e.Line)
+		return nil
+	case eventlog.StandingOrderCreated:
+		return store.applyStandingOrder(e.Order, false)
+	case eventlog.StandingOrderUpdated:
+		return store.applyStandingOrder(e.Order, true)
+	case eventlog.StandingOrderExecuted:
+		store.StandingOrders.mutex.Lock()
+		defer store.StandingOrders.mutex.Unlock()
+
+		if store.StandingOrders.executionIDs[e.Execution.ID] {
+			return fmt.Errorf("standing order execution with id %w", repository.ErrAlreadyExists)
+		}
+		store.StandingOrders.insertExecution(&e.Execution)
+		return nil
+	case eventlog.StandingOrderExecutionUpdated:
+		store.StandingOrders.mutex.Lock()
+		defer store.StandingOrders.mutex.Unlock()
+
+		if !store.StandingOrders.executionIDs[e.Execution.ID] {
+			return fmt.Errorf("standing order execution with id %w", repository.ErrNotFound)
+		}
+		store.StandingOrders.updateExecution(&e.Execution)
+		return nil
+	case eventlog.HoldPlaced:
+		return store.applyHold(e.Hold, false)
+	case eventlog.HoldUpdated:
+		return store.applyHold(e.Hold, true)
+	default:
+		return fmt.Errorf("unsupported event %T", event)
+	}
+}
+
+// applyUser replaces the user with what change returns, exists tells whether the user must already be there
+func (store *Store) applyUser(userID string, exists bool, change func(*domain.User) domain.User) error {
+	store.Users.usersMutex.Lock()
+	defer store.Users.usersMutex.Unlock()
+
+	current := store.Users.users[userID]
+	if exists && current == nil {
+		return fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+	if !exists && current != nil {
+		return fmt.Errorf("user %w", repository.ErrAlreadyExists)
+	}
+
+	if current == nil {
+		user := change(&domain.User{})
+		store.Users.insert(&user)
+		return nil
+	}
+
+	updated := *current
+	user := change(&updated)
+	store.Users.users[userID] = &user
+
+	return nil
+}
+
+// applyAccount replaces the account with what change returns, exists tells whether the account must already be
+// there
+func (store *Store) applyAccount(accountID string, exists bool, change func(*domain.Account) domain.Account) error {
+	store.Accounts.mutex.Lock()
+	defer store.Accounts.mutex.Unlock()
+
+	current := store.Accounts.accounts[accountID]
+	if exists && current == nil {
+		return fmt.Errorf("account with id %w", repository.ErrNotFound)
+	}
+	if !exists && current != nil {
+		return fmt.Errorf("account with id %w", repository.ErrAlreadyExists)
+	}
+
+	var account domain.Account
+	if current != nil {
+		account = *current
+	}
+	account = change(&account)
+	store.Accounts.accounts[accountID] = &account
+
+	return nil
+}
+
+// applyBatch stores batch, exists tells whether the batch must already be there
+func (store *Store) applyBatch(batch domain.Batch, exists bool) error {
+	store.Batches.mutex.Lock()
+	defer store.Batches.mutex.Unlock()
+
+	current := store.Batches.batches[batch.ID]
+	if exists && current == nil {
+		return fmt.Errorf("batch with id %w", repository.ErrNotFound)
+	}
+	if !exists && current != nil {
+		return fmt.Errorf("batch with id %w", repository.ErrAlreadyExists)
+	}
+
+	store.Batches.store(&batch)
+
+	return nil
+}
+
+// applyStandingOrder stores order, exists tells whether the order must already be there
+func (store *Store) applyStandingOrder(order domain.StandingOrder, exists bool) error {
+	store.StandingOrders.mutex.Lock()
+	defer store.StandingOrders.mutex.Unlock()
+
+	current := store.StandingOrders.orders[order.ID]
+	if exists && current == nil {
+		return fmt.Errorf("standing order with id %w", repository.ErrNotFound)
+	}
+	if !exists && current != nil {
+		return fmt.Errorf("standing order with id %w", repository.ErrAlreadyExists)
+	}
+
+	store.StandingOrders.store(&order)
+
+	return nil
+}
+
+// applyHold stores hold, exists tells whether the hold must already be there
+func (store *Store) applyHold(hold domain.Hold, exists bool) error {
+	store.Holds.mutex.Lock()
+	defer store.Holds.mutex.Unlock()
+
+	current := store.Holds.holds[hold.ID]
+	if exists && current == nil {
+		return fmt.Errorf("hold with id %w", repository.ErrNotFound)
+	}
+	if !exists && current != nil {
+		return fmt.Errorf("hold with id %w", repository.ErrAlreadyExists)
+	}
+
+	store.Holds.store(&hold)
+
+	return nil
+}
+
+type snapshotter interface {
+	Snapshot(events []eventlog.Event) error
+}
+
+// Snapshot hands the whole state to snapshotter as the events that recreate it. Writes wait until snapshotter
+// returns so nothing is journaled while the snapshot replaces the journal.
+func (store *Store) Snapshot(snapshotter snapshotter) error {
+	store.Users.usersMutex.Lock()
+	defer store.Users.usersMutex.Unlock()
+	store.Accounts.mutex.Lock()
+	defer store.Accounts.mutex.Unlock()
+	store.Transactions.mutex.Lock()
+	defer store.Transactions.mutex.Unlock()
+	store.Ledger.mutex.Lock()
+	defer store.Ledger.mutex.Unlock()
+	store.Audit.mutex.Lock()
+	defer store.Audit.mutex.Unlock()
+	store.Batches.mutex.Lock()
+	defer store.Batches.mutex.Unlock()
+	store.StandingOrders.mutex.Lock()
+	defer store.StandingOrders.mutex.Unlock()
+	store.Holds.mutex.Lock()
+	defer store.Holds.mutex.Unlock()
+
+	var events []eventlog.Event
+
+	for _, id := range store.Users.ids {
+		events = append(events, eventlog.UserCreated{User: *store.Users.users[id]})
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(store.Accounts.accounts)) {
+		events = append(events, eventlog.AccountOpened{Account: *store.Accounts.accounts[id]})
+	}
+
+	transactions := slices.Collect(maps.Values(store.Transactions.transactions))
+	slices.SortFunc(transactions, func(a, b *domain.Transaction) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	for _, transaction := range transactions {
+		events = append(events, eventlog.TransactionRecorded{Transaction: *transaction})
+	}
+
+	entries := slices.Collect(maps.Values(store.Ledger.entries))
+	slices.SortFunc(entries, func(a, b *domain.JournalEntry) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	for _, entry := range entries {
+		events = append(events, eventlog.JournalEntryPosted{Entry: *entry})
+	}
+
+	for _, event := range store.Audit.events {
+		events = append(events, eventlog.AuditRecorded{Event: event})
+	}
+
+	batches := slices.Collect(maps.Values(store.Batches.batches))
+	slices.SortFunc(batches, func(a, b *domain.Batch) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	for _, batch := range batches {
+		events = append(events, eventlog.BatchCreated{Batch: copyBatch(batch)})
+	}
+
+	// executions follow their order, which is created as it is now
+	for _, id := range slices.Sorted(maps.Keys(store.StandingOrders.orders)) {
+		events = append(events, eventlog.StandingOrderCreated{Order: copyStandingOrder(store.StandingOrders.orders[id])})
+		for _, execution := range store.StandingOrders.executions[id] {
+			events = append(events, eventlog.StandingOrderExecuted{Execution: execution})
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(store.Holds.holds)) {
+		events = append(events, eventlog.HoldPlaced{Hold: copyHold(store.Holds.holds[id])})
+	}
+
+	return snapshotter.Snapshot(events)
+}
@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
+)
+
+type recordingJournal struct {
+	events []eventlog.Event
+	err    error
+	mutex  sync.Mutex
+}
+
+func (journal *recordingJournal) Append(events ...eventlog.Event) error {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	if journal.err != nil {
+		return journal.err
+	}
+
+	journal.events = append(journal.events, events...)
+	return nil
+}
+
+type recordingSnapshotter struct {
+	events []eventlog.Event
+}
+
+func (snapshotter *recordingSnapshotter) Snapshot(events []eventlog.Event) error {
+	snapshotter.events = events
+	return nil
+}
+
+func snapshotOf(t *testing.T, store *Store) []eventlog.Event {
+	t.Helper()
+
+	var snapshotter recordingSnapshotter
+	if err := store.Snapshot(&snapshotter); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	return snapshotter.events
+}
+
+func TestStore_Replay(t *testing.T) {
+	journal := &recordingJournal{}
+	store := NewStore(journal)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	user, err := domain.NewUser("Ada")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Users.Insert(user); err != nil {
+		t.Fatal(err)
+	}
+
+	from, _ := domain.NewAccount(user.ID, domain.EUR, domain.Checking, "main")
+	to, _ := domain.NewAccount(user.ID, domain.EUR, domain.Savings, "")
+	for _, acc := range []*domain.Account{from, to} {
+		if _, err := store.Accounts.Insert(acc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deposit, _ := domain.NewDeposit(from.ID, domain.NewMoney(1000, domain.EUR), time.Now())
+	if _, err := store.Transactions.Insert(deposit); err != nil {
+		t.Fatal(err)
+	}
+	depositEntry, _ := domain.NewJournalEntry(deposit)
+	if _, err := store.Ledger.Insert(depositEntry); err != nil {
+		t.Fatal(err)
+	}
+	from.Balance = domain.NewMoney(1000, domain.EUR)
+	if err := from.SetOverdraft(domain.NewMoney(5000, domain.EUR), "0.1", now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Accounts.Update(from); err != nil {
+		t.Fatal(err)
+	}
+
+	transfer, _ := domain.NewTransfer(from.ID, to.ID, domain.NewMoney(1000, domain.EUR), time.Now())
+	transferEntry, _ := domain.NewJournalEntry(transfer)
+	uow, _ := store.UnitOfWorkFactory.Begin(context.Background())
+	for accID, amount := range map[string]int64{from.ID: -1000, to.ID: 1000} {
+		acc, err := uow.GetAccount(accID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := acc.AddBalance(domain.NewMoney(amount, domain.EUR)); err != nil {
+			t.Fatal(err)
+		}
+		if err := uow.UpdateAccount(acc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := uow.InsertTransaction(transfer); err != nil {
+		t.Fatal(err)
+	}
+	if err := uow.InsertJournalEntry(transferEntry); err != nil {
+		t.Fatal(err)
+	}
+	if err := uow.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	from, _ = store.Accounts.Get(from.ID)
+	if err := from.Close(now); err != nil {
+		t.Fatal(err)
+	}
+	to, _ = store.Accounts.Get(to.ID)
+	to.Nickname = "rainy day"
+	if err := store.Accounts.UpdateBulk([]domain.Account{*from, *to}); err != nil {
+		t.Fatal(err)
+	}
+
+	user.DeletedAt = &now
+	if _, err := store.Users.Update(user); err != nil {
+		t.Fatal(err)
+	}
+	user.Name = "Ada Lovelace"
+	user.DeletedAt = nil
+	if _, err := store.Users.Update(user); err != nil {
+		t.Fatal(err)
+	}
+	auditEvent, _ := domain.NewAuditEvent(user.ID, domain.UserDeleted, []string{from.ID}, now)
+	if _, err := store.Audit.Insert(auditEvent); err != nil {
+		t.Fatal(err)
+	}
+	batch, _ := domain.NewBatch(to.ID, domain.EUR, domain.BestEffort, []domain.BatchTransfer{{ToAccountID: from.ID, Amount: domain.NewMoney(10, domain.EUR)}}, time.Now())
+	if _, err := store.Batches.Insert(batch); err != nil {
+		t.Fatal(err)
+	}
+	batch.Fail(0, "account_closed", "account is closed")
+	if _, err := store.Batches.UpdateLine(batch.ID, &batch.Lines[0]); err != nil {
+		t.Fatal(err)
+	}
+	batch.Finish(now)
+	if _, err := store.Batches.Update(batch); err != nil {
+		t.Fatal(err)
+	}
+	order, _ := domain.NewStandingOrder(to.ID, domain.EUR, domain.StandingOrderTerms{ToAccountID: from.ID, Amount: domain.NewMoney(10, domain.EUR), Schedule: "@monthly"}, now)
+	if _, err := store.StandingOrders.Insert(order); err != nil {
+		t.Fatal(err)
+	}
+	execution := order.Attempt(now)
+	if _, err := store.StandingOrders.InsertExecution(execution); err != nil {
+		t.Fatal(err)
+	}
+	order.Fail(execution, "account_closed", "account is closed", false, now)
+	if _, err := store.StandingOrders.Update(order); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.StandingOrders.UpdateExecution(execution); err != nil {
+		t.Fatal(err)
+	}
+
+	want := snapshotOf(t, store)
+
+	replayed := NewStore(nil)
+	for _, event := range journal.events {
+		if err := replayed.Apply(event); err != nil {
+			t.Fatalf("Apply(%T) error = %v", event, err)
+		}
+	}
+	if diff := cmp.Diff(want, snapshotOf(t, replayed)); diff != "" {
+		t.Errorf("replaying the journal mismatch (-want +got):\n%s", diff)
+	}
+
+	restored := NewStore(nil)
+	for _, event := range want {
+		if err := restored.Apply(event); err != nil {
+			t.Fatalf("Apply(%T) error = %v", event, err)
+		}
+	}
+	if diff := cmp.Diff(want, snapshotOf(t, restored)); diff != "" {
+		t.Errorf("replaying the snapshot mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStore_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   eventlog.Event
+		wantErr error
+	}{
+		{
+			name:    "balance of an unknown account, want ErrNotFound",
+			event:   eventlog.BalanceChanged{AccountID: "missing", Balance: domain.NewMoney(1, domain.EUR)},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name:    "restoring an unknown user, want ErrNotFound",
+			event:   eventlog.UserRestored{UserID: "missing"},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name:    "creating an existing user, want ErrAlreadyExists",
+			event:   eventlog.UserCreated{User: domain.User{ID: "user-1", Name: "Ada"}},
+			wantErr: repository.ErrAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(nil)
+			if err := store.Apply(eventlog.UserCreated{User: domain.User{ID: "user-1", Name: "Ada"}}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Apply(tt.event); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStore_JournalFailure(t *testing.T) {
+	journal := &recordingJournal{}
+	store := NewStore(journal)
+
+	acc, _ := domain.NewAccount("user-1", domain.EUR, domain.Checking, "")
+	if _, err := store.Accounts.Insert(acc); err != nil {
+		t.Fatal(err)
+	}
+
+	journal.err = errors.New("disk full")
+
+	user, _ := domain.NewUser("Ada")
+	if _, err := store.Users.Insert(user); !errors.Is(err, journal.err) {
+		t.Errorf("Users.Insert() error = %v, want %v", err, journal.err)
+	}
+	if _, err := store.Users.Get(user.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Users.Get() error = %v, want ErrNotFound", err)
+	}
+
+	deposit, _ := domain.NewDeposit(acc.ID, domain.NewMoney(500, domain.EUR), time.Now())
+	entry, _ := domain.NewJournalEntry(deposit)
+	uow, _ := store.UnitOfWorkFactory.Begin(context.Background())
+	staged, _ := uow.GetAccount(acc.ID)
+	_ = staged.AddBalance(deposit.Amount)
+	_ = uow.UpdateAccount(staged)
+	_ = uow.InsertTransaction(deposit)
+	_ = uow.InsertJournalEntry(entry)
+	if err := uow.Commit(); !errors.Is(err, journal.err) {
+		t.Errorf("Commit() error = %v, want %v", err, journal.err)
+	}
+
+	got, _ := store.Accounts.Get(acc.ID)
+	if diff := cmp.Diff(domain.NewMoney(0, domain.EUR), got.Balance); diff != "" {
+		t.Errorf("balance after a refused commit mismatch (-want +got):\n%s", diff)
+	}
+	if transactions, _ := store.Transactions.GetAccountTransactions(acc.ID, repository.TransactionFilter{}, repository.Page[repository.TransactionCursor]{}); len(transactions) != 0 {
+		t.Errorf("GetAccountTransactions() = %v, want none", transactions)
+	}
+	if total, _ := store.Ledger.GetAccountTotal(acc.ID, domain.EUR); !total.Credits.IsZero() {
+		t.Errorf("ledger credits after a refused commit = %v, want zero", total.Credits)
+	}
+}
@@ -1,25 +1,46 @@
 package memory
 
 import (
-	"errors"
-	"sort"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
 )
 
 type TransactionRepository struct {
-	transactions          map[string]*domain.Transaction
-	transactionsDateIndex map[time.Time][]string
-	mutex                 sync.RWMutex
+	transactions map[string]*domain.Transaction
+	// accountIndex orders the transactions of every account by creation time, a transfer is in it twice
+	accountIndex *btree[accountTransactionKey, *domain.Transaction]
+	// parentIndex holds the transactions made for each parent in the order they were inserted
+	parentIndex map[string][]*domain.Transaction
+	mutex       sync.RWMutex
+	journal     Journal
+}
+
+type accountTransactionKey struct {
+	accountID string
+	cursor    repository.TransactionCursor
+}
+
+func compareAccountTransactionKeys(a, b accountTransactionKey) int {
+	if c := strings.Compare(a.accountID, b.accountID); c != 0 {
+		return c
+	}
+
+	return a.cursor.Compare(b.cursor)
 }
 
 func NewTransactionRepository() *TransactionRepository {
 	return &TransactionRepository{
-		transactions:          make(map[string]*domain.Transaction),
-		transactionsDateIndex: make(map[time.Time][]string),
-		mutex:                 sync.RWMutex{},
+		transactions: make(map[string]*domain.Transaction),
+		accountIndex: newBTree[accountTransactionKey, *domain.Transaction](compareAccountTransactionKeys),
+		parentIndex:  make(map[string][]*domain.Transaction),
+		mutex:        sync.RWMutex{},
 	}
 }
 
@@ -28,46 +49,127 @@ func (repo *TransactionRepository) Insert(transaction *domain.Transaction) (*dom
 	defer repo.mutex.Unlock()
 
 	if _, ok := repo.transactions[transaction.ID]; ok {
-		return nil, errors.New("transaction with id already exists")
+		return nil, fmt.Errorf("transaction with id %w", repository.ErrAlreadyExists)
 	}
 
-	repo.transactions[transaction.ID] = transaction
-	repo.transactionsDateIndex[transaction.CreatedAt] = append(repo.transactionsDateIndex[transaction.CreatedAt], transaction.ID)
+	if err := appendTo(repo.journal, eventlog.TransactionRecorded{Transaction: *transaction}); err != nil {
+		return nil, err
+	}
+
+	repo.insert(transaction)
 
 	return repo.transactions[transaction.ID], nil
 }
 
-func (repo *TransactionRepository) GetAccountTransactions(accountID string, fromDate time.Time, toDate time.Time) ([]domain.Transaction, error) {
+// insert expects the caller to hold the write lock
+func (repo *TransactionRepository) insert(transaction *domain.Transaction) {
+	repo.transactions[transaction.ID] = transaction
+
+	cursor := repository.CursorOf(*transaction)
+	for _, accountID := range accountIDs(transaction) {
+		repo.accountIndex.insert(accountTransactionKey{accountID: accountID, cursor: cursor}, transaction)
+	}
+	if transaction.ParentID != "" {
+		repo.parentIndex[transaction.ParentID] = append(repo.parentIndex[transaction.ParentID], transaction)
+	}
+}
+
+func (repo *TransactionRepository) Get(transactionID string) (*domain.Transaction, error) {
 	repo.mutex.RLock()
 	defer repo.mutex.RUnlock()
 
-	var dateKeys []time.Time
+	transaction, ok := repo.transactions[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("transaction with id %w", repository.ErrNotFound)
+	}
 
-	// TODO normalize dateIndex to days
-	for key, _ := range repo.transactionsDateIndex {
-		dateKeys = append(dateKeys, key)
+	transactionCopy := *transaction
+	return &transactionCopy, nil
+}
+
+func (repo *TransactionRepository) GetByParent(parentID string) ([]domain.Transaction, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	transactions := make([]domain.Transaction, 0, len(repo.parentIndex[parentID]))
+	for _, transaction := range repo.parentIndex[parentID] {
+		transactions = append(transactions, *transaction)
 	}
 
-	sort.Slice(dateKeys, func(i, j int) bool {
-		return dateKeys[i].Before(dateKeys[j])
+	slices.SortFunc(transactions, func(a, b domain.Transaction) int {
+		return repository.CursorOf(a).Compare(repository.CursorOf(b))
 	})
 
-	var transactions = make([]domain.Transaction, 0)
-	for _, date := range dateKeys {
-		if date.Before(fromDate) {
-			continue
+	return transactions, nil
+}
+
+// GetAccountTransactions costs O(log n + k) for a page of k transactions out of n, when the filter only keeps
+// some of them every transaction of the account within the dates is read
+func (repo *TransactionRepository) GetAccountTransactions(
+	accountID string,
+	filter repository.TransactionFilter,
+	page repository.Page[repository.TransactionCursor],
+) ([]domain.Transaction, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	// the walk starts at the oldest transaction in the range, or at the newest one when descending. Pivots built from
+	// a date have an empty id so they never are a transaction themselves.
+	walk, start := repo.accountIndex.ascend, accountTransactionKey{accountID: accountID}
+	start.cursor.CreatedAt = filter.FromDate
+	if page.After != nil && page.After.Compare(start.cursor) >= 0 {
+		start.cursor = *page.After
+	}
+	pastRange := func(cursor repository.TransactionCursor) bool {
+		return !filter.ToDate.IsZero() && cursor.CreatedAt.After(filter.ToDate)
+	}
+
+	if filter.Descending {
+		walk = repo.accountIndex.descend
+		switch {
+		case page.After != nil:
+			start.cursor = *page.After
+		case !filter.ToDate.IsZero():
+			start.cursor = repository.TransactionCursor{CreatedAt: filter.ToDate.Add(time.Nanosecond)}
+		default:
+			// sorts right after every transaction of the account
+			start = accountTransactionKey{accountID: accountID + "\x00"}
 		}
-		if date.After(toDate) {
-			break
+		pastRange = func(cursor repository.TransactionCursor) bool {
+			return cursor.CreatedAt.Before(filter.FromDate)
 		}
+	}
 
-		for _, transactionID := range repo.transactionsDateIndex[date] {
-			if (repo.transactions[transactionID].ToAccountID != nil && *repo.transactions[transactionID].ToAccountID == accountID) ||
-				(repo.transactions[transactionID].FromAccountID != nil && *repo.transactions[transactionID].FromAccountID == accountID) {
-				transactions = append(transactions, *repo.transactions[transactionID])
-			}
+	var transactions = make([]domain.Transaction, 0)
+	walk(start, func(key accountTransactionKey, transaction *domain.Transaction) bool {
+		if key.accountID != accountID || pastRange(key.cursor) {
+			return false
 		}
-	}
+		// a page starts right after the last transaction of the previous one
+		if page.After != nil && key.cursor.Compare(*page.After) == 0 {
+			return true
+		}
+		if !filter.Matches(accountID, transaction) {
+			return true
+		}
+
+		transactions = append(transactions, *transaction)
+
+		return page.Limit == 0 || len(transactions) < page.Limit
+	})
 
 	return transactions, nil
 }
+
+// accountIDs returns the accounts whose history holds transaction
+func accountIDs(transaction *domain.Transaction) []string {
+	var ids []string
+	if transaction.FromAccountID != nil {
+		ids = append(ids, *transaction.FromAccountID)
+	}
+	if transaction.ToAccountID != nil && (transaction.FromAccountID == nil || *transaction.ToAccountID != *transaction.FromAccountID) {
+		ids = append(ids, *transaction.ToAccountID)
+	}
+
+	return ids
+}
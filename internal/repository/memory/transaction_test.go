@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+const (
+	benchmarkTransactions = 1_000_000
+	benchmarkAccounts     = 10_000
+)
+
+var benchmarkStart = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// dateIndexTransactions is how transactions used to be looked up, a date index scanned and sorted on every query.
+// It's kept to measure the account index against.
+type dateIndexTransactions struct {
+	transactions map[string]*domain.Transaction
+	dateIndex    map[time.Time][]string
+}
+
+func (repo *dateIndexTransactions) insert(transaction *domain.Transaction) {
+	repo.transactions[transaction.ID] = transaction
+	repo.dateIndex[transaction.CreatedAt] = append(repo.dateIndex[transaction.CreatedAt], transaction.ID)
+}
+
+func (repo *dateIndexTransactions) GetAccountTransactions(accountID string, fromDate, toDate time.Time) []domain.Transaction {
+	var dateKeys []time.Time
+	for key := range repo.dateIndex {
+		dateKeys = append(dateKeys, key)
+	}
+	sort.Slice(dateKeys, func(i, j int) bool {
+		return dateKeys[i].Before(dateKeys[j])
+	})
+
+	var transactions = make([]domain.Transaction, 0)
+	for _, date := range dateKeys {
+		if date.Before(fromDate) {
+			continue
+		}
+		if date.After(toDate) {
+			break
+		}
+
+		for _, transactionID := range repo.dateIndex[date] {
+			transaction := repo.transactions[transactionID]
+			if (transaction.ToAccountID != nil && *transaction.ToAccountID == accountID) ||
+				(transaction.FromAccountID != nil && *transaction.FromAccountID == accountID) {
+				transactions = append(transactions, *transaction)
+			}
+		}
+	}
+
+	return transactions
+}
+
+// benchmarkTransactionSet returns transfers between random accounts spread over a year, in random order
+func benchmarkTransactionSet() []*domain.Transaction {
+	random := rand.New(rand.NewSource(1))
+	year := int64(365 * 24 * time.Hour)
+
+	transactions := make([]*domain.Transaction, benchmarkTransactions)
+	for i := range transactions {
+		from := fmt.Sprintf("acc-%d", random.Intn(benchmarkAccounts))
+		to := fmt.Sprintf("acc-%d", random.Intn(benchmarkAccounts))
+		transactions[i] = &domain.Transaction{
+			ID:            fmt.Sprintf("tx-%07d", i),
+			CreatedAt:     benchmarkStart.Add(time.Duration(random.Int63n(year))),
+			FromAccountID: &from,
+			ToAccountID:   &to,
+			Amount:        domain.NewMoney(100, domain.EUR),
+			Type:          domain.Transfer,
+		}
+	}
+
+	return transactions
+}
+
+func BenchmarkGetAccountTransactions(b *testing.B) {
+	transactions := benchmarkTransactionSet()
+	fromDate := benchmarkStart.AddDate(0, 6, 0)
+	toDate := fromDate.AddDate(0, 1, 0)
+
+	b.Run("date index", func(b *testing.B) {
+		repo := &dateIndexTransactions{
+			transactions: make(map[string]*domain.Transaction),
+			dateIndex:    make(map[time.Time][]string),
+		}
+		for _, transaction := range transactions {
+			repo.insert(transaction)
+		}
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			repo.GetAccountTransactions(fmt.Sprintf("acc-%d", i%benchmarkAccounts), fromDate, toDate)
+		}
+	})
+
+	repo := NewTransactionRepository()
+	for _, transaction := range transactions {
+		repo.insert(transaction)
+	}
+
+	b.Run("account index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			repo.GetAccountTransactions(fmt.Sprintf("acc-%d", i%benchmarkAccounts),
+				repository.TransactionFilter{FromDate: fromDate, ToDate: toDate}, repository.Page[repository.TransactionCursor]{})
+		}
+	})
+
+	b.Run("account index page", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			repo.GetAccountTransactions(fmt.Sprintf("acc-%d", i%benchmarkAccounts),
+				repository.TransactionFilter{}, repository.Page[repository.TransactionCursor]{Limit: 50})
+		}
+	})
+}
+
+func BenchmarkTransactionRepository_Insert(b *testing.B) {
+	transactions := benchmarkTransactionSet()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		repo := NewTransactionRepository()
+		for _, transaction := range transactions {
+			repo.insert(transaction)
+		}
+	}
+}
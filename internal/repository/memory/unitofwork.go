@@ -0,0 +1,387 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
+)
+
+type UnitOfWorkFactory struct {
+	accountRepository       *AccountRepository
+	transactionRepository   *TransactionRepository
+	ledgerRepository        *LedgerRepository
+	holdRepository          *HoldRepository
+	standingOrderRepository *StandingOrderRepository
+	journal                 Journal
+}
+
+func NewUnitOfWorkFactory(
+	accountRepository *AccountRepository,
+	transactionRepository *TransactionRepository,
+	ledgerRepository *LedgerRepository,
+	holdRepository *HoldRepository,
+	standingOrderRepository *StandingOrderRepository,
+) *UnitOfWorkFactory {
+	return &UnitOfWorkFactory{
+		accountRepository:       accountRepository,
+		transactionRepository:   transactionRepository,
+		ledgerRepository:        ledgerRepository,
+		holdRepository:          holdRepository,
+		standingOrderRepository: standingOrderRepository,
+	}
+}
+
+func (factory *UnitOfWorkFactory) Begin(_ context.Context) (repository.UnitOfWork, error) {
+	return &unitOfWork{
+		accountRepository:       factory.accountRepository,
+		transactionRepository:   factory.transactionRepository,
+		ledgerRepository:        factory.ledgerRepository,
+		holdRepository:          factory.holdRepository,
+		standingOrderRepository: factory.standingOrderRepository,
+		journal:                 factory.journal,
+		accounts:                make(map[string]*domain.Account),
+		readAccounts:            make(map[string]*domain.Account),
+		updatedAccounts:         make(map[string]bool),
+		holds:                   make(map[string]*domain.Hold),
+		readHolds:               make(map[string]*domain.Hold),
+		insertedHolds:           make(map[string]bool),
+		updatedHolds:            make(map[string]bool),
+		orders:                  make(map[string]*domain.StandingOrder),
+	}, nil
+}
+
+// unitOfWork keeps copies of everything it touches, the repositories are only changed on Commit. Repositories
+// replace what they store on every write, so the accounts and holds as they were stored when read tell whether
+// someone else wrote them since: Commit fails rather than overwrite their change with the staged copy. Services lock
+// the accounts they change through the lock manager so this only catches writes that went around it. Standing orders
+// are only written by whoever holds their lock, their staged copy replaces the stored one.
+type unitOfWork struct {
+	accountRepository       *AccountRepository
+	transactionRepository   *TransactionRepository
+	ledgerRepository        *LedgerRepository
+	holdRepository          *HoldRepository
+	standingOrderRepository *StandingOrderRepository
+	journal                 Journal
+
+	accounts        map[string]*domain.Account
+	readAccounts    map[string]*domain.Account
+	updatedAccounts map[string]bool
+	transactions    []*domain.Transaction
+	journalEntries  []*domain.JournalEntry
+	holds           map[string]*domain.Hold
+	readHolds       map[string]*domain.Hold
+	insertedHolds   map[string]bool
+	updatedHolds    map[string]bool
+	orders          map[string]*domain.StandingOrder
+	executions      []*domain.StandingOrderExecution
+	finished        bool
+}
+
+var unitOfWorkFinishedError = errors.New("unit of work already finished")
+var changedSinceReadError = errors.New("was changed since the unit of work read it")
+
+func (uow *unitOfWork) GetAccount(accID string) (*domain.Account, error) {
+	if uow.finished {
+		return nil, unitOfWorkFinishedError
+	}
+
+	if acc, ok := uow.accounts[accID]; ok {
+		accCopy := *acc
+		return &accCopy, nil
+	}
+
+	acc, err := uow.accountRepository.stored(accID)
+	if err != nil {
+		return nil, err
+	}
+	uow.readAccounts[accID] = acc
+
+	staged := *acc
+	uow.accounts[accID] = &staged
+
+	accCopy := staged
+	return &accCopy, nil
+}
+
+func (uow *unitOfWork) UpdateAccount(acc *domain.Account) error {
+	if uow.finished {
+		return unitOfWorkFinishedError
+	}
+
+	if _, ok := uow.accounts[acc.ID]; !ok {
+		stored, err := uow.accountRepository.stored(acc.ID)
+		if err != nil {
+			return err
+		}
+		uow.readAccounts[acc.ID] = stored
+	}
+
+	staged := *acc
+	uow.accounts[acc.ID] = &staged
+	uow.updatedAccounts[acc.ID] = true
+
+	return nil
+}
+
+func (uow *unitOfWork) InsertTransaction(transaction *domain.Transaction) error {
+	if uow.finished {
+		return unitOfWorkFinishedError
+	}
+
+	staged := *transaction
+	uow.transactions = append(uow.transactions, &staged)
+
+	return nil
+}
+
+func (uow *unitOfWork) InsertJournalEntry(entry *domain.JournalEntry) error {
+	if uow.finished {
+		return unitOfWorkFinishedError
+	}
+
+	staged := *entry
+	staged.Lines = slices.Clone(entry.Lines)
+	uow.journalEntries = append(uow.journalEntries, &staged)
+
+	return nil
+}
+
+func (uow *unitOfWork) GetHold(holdID string) (*domain.Hold, error) {
+	if uow.finished {
+		return nil, unitOfWorkFinishedError
+	}
+
+	if hold, ok := uow.holds[holdID]; ok {
+		holdCopy := copyHold(hold)
+		return &holdCopy, nil
+	}
+
+	hold, err := uow.holdRepository.stored(holdID)
+	if err != nil {
+		return nil, err
+	}
+	uow.readHolds[holdID] = hold
+
+	staged := copyHold(hold)
+	uow.holds[holdID] = &staged
+
+	holdCopy := copyHold(&staged)
+	return &holdCopy, nil
+}
+
+func (uow *unitOfWork) InsertHold(hold *domain.Hold) error {
+	if uow.finished {
+		return unitOfWorkFinishedError
+	}
+
+	if _, ok := uow.holds[hold.ID]; ok {
+		return fmt.Errorf("hold with id %w", repository.ErrAlreadyExists)
+	}
+
+	staged := copyHold(hold)
+	uow.holds[hold.ID] = &staged
+	uow.insertedHolds[hold.ID] = true
+
+	return nil
+}
+
+func (uow *unitOfWork) UpdateHold(hold *domain.Hold) error {
+	if uow.finished {
+		return unitOfWorkFinishedError
+	}
+
+	if _, ok := uow.holds[hold.ID]; !ok {
+		stored, err := uow.holdRepository.stored(hold.ID)
+		if err != nil {
+			return err
+		}
+		uow.readHolds[hold.ID] = stored
+	}
+
+	staged := copyHold(hold)
+	uow.holds[hold.ID] = &staged
+	if !uow.insertedHolds[hold.ID] {
+		uow.updatedHolds[hold.ID] = true
+	}
+
+	return nil
+}
+
+func (uow *unitOfWork) UpdateStandingOrder(order *domain.StandingOrder) error {
+	if uow.finished {
+		return unitOfWorkFinishedError
+	}
+
+	staged := copyStandingOrder(order)
+	uow.orders[order.ID] = &staged
+
+	return nil
+}
+
+func (uow *unitOfWork) InsertExecution(execution *domain.StandingOrderExecution) error {
+	if uow.finished {
+		return unitOfWorkFinishedError
+	}
+
+	staged := *execution
+	uow.executions = append(uow.executions, &staged)
+
+	return nil
+}
+
+func (uow *unitOfWork) Commit() error {
+	if uow.finished {
+		return unitOfWorkFinishedError
+	}
+	uow.finished = true
+
+	uow.accountRepository.mutex.Lock()
+	defer uow.accountRepository.mutex.Unlock()
+	uow.transactionRepository.mutex.Lock()
+	defer uow.transactionRepository.mutex.Unlock()
+	uow.ledgerRepository.mutex.Lock()
+	defer uow.ledgerRepository.mutex.Unlock()
+	uow.holdRepository.mutex.Lock()
+	defer uow.holdRepository.mutex.Unlock()
+	uow.standingOrderRepository.mutex.Lock()
+	defer uow.standingOrderRepository.mutex.Unlock()
+
+	// every check happens before the first write so a failed commit leaves the repositories untouched
+	for accID := range uow.updatedAccounts {
+		current, ok := uow.accountRepository.accounts[accID]
+		if !ok {
+			return fmt.Errorf("account with id %w", repository.ErrNotFound)
+		}
+		if current != uow.readAccounts[accID] {
+			return fmt.Errorf("account with id %s %w", accID, changedSinceReadError)
+		}
+	}
+
+	seen := make(map[string]bool, len(uow.transactions))
+	for _, transaction := range uow.transactions {
+		if _, ok := uow.transactionRepository.transactions[transaction.ID]; ok || seen[transaction.ID] {
+			return fmt.Errorf("transaction with id %w", repository.ErrAlreadyExists)
+		}
+		seen[transaction.ID] = true
+	}
+
+	seen = make(map[string]bool, len(uow.journalEntries))
+	for _, entry := range uow.journalEntries {
+		if _, ok := uow.ledgerRepository.entries[entry.ID]; ok || seen[entry.ID] {
+			return fmt.Errorf("journal entry with id %w", repository.ErrAlreadyExists)
+		}
+		seen[entry.ID] = true
+	}
+
+	for holdID := range uow.insertedHolds {
+		if _, ok := uow.holdRepository.holds[holdID]; ok {
+			return fmt.Errorf("hold with id %w", repository.ErrAlreadyExists)
+		}
+	}
+	for holdID := range uow.updatedHolds {
+		current, ok := uow.holdRepository.holds[holdID]
+		if !ok {
+			return fmt.Errorf("hold with id %w", repository.ErrNotFound)
+		}
+		if current != uow.readHolds[holdID] {
+			return fmt.Errorf("hold with id %s %w", holdID, changedSinceReadError)
+		}
+	}
+
+	for orderID := range uow.orders {
+		if uow.standingOrderRepository.orders[orderID] == nil {
+			return fmt.Errorf("standing order with id %w", repository.ErrNotFound)
+		}
+	}
+	seen = make(map[string]bool, len(uow.executions))
+	for _, execution := range uow.executions {
+		if uow.standingOrderRepository.executionIDs[execution.ID] || seen[execution.ID] {
+			return fmt.Errorf("standing order execution with id %w", repository.ErrAlreadyExists)
+		}
+		if uow.standingOrderRepository.orders[execution.OrderID] == nil {
+			return fmt.Errorf("standing order with id %w", repository.ErrNotFound)
+		}
+		seen[execution.ID] = true
+	}
+
+	totals, err := uow.ledgerRepository.post(uow.journalEntries...)
+	if err != nil {
+		return err
+	}
+
+	// the whole unit of work is a single record, it's replayed entirely or not at all
+	var events []eventlog.Event
+	for _, accID := range slices.Sorted(maps.Keys(uow.updatedAccounts)) {
+		events = append(events, accountChanges(uow.accountRepository.accounts[accID], uow.accounts[accID])...)
+	}
+	for _, transaction := range uow.transactions {
+		events = append(events, eventlog.TransactionRecorded{Transaction: *transaction})
+	}
+	for _, entry := range uow.journalEntries {
+		events = append(events, eventlog.JournalEntryPosted{Entry: *entry})
+	}
+	for _, holdID := range slices.Sorted(maps.Keys(uow.holds)) {
+		switch {
+		case uow.insertedHolds[holdID]:
+			events = append(events, eventlog.HoldPlaced{Hold: copyHold(uow.holds[holdID])})
+		case uow.updatedHolds[holdID]:
+			events = append(events, eventlog.HoldUpdated{Hold: copyHold(uow.holds[holdID])})
+		}
+	}
+	for _, orderID := range slices.Sorted(maps.Keys(uow.orders)) {
+		events = append(events, eventlog.StandingOrderUpdated{Order: copyStandingOrder(uow.orders[orderID])})
+	}
+	for _, execution := range uow.executions {
+		events = append(events, eventlog.StandingOrderExecuted{Execution: *execution})
+	}
+
+	if err := appendTo(uow.journal, events...); err != nil {
+		return err
+	}
+
+	for accID := range uow.updatedAccounts {
+		uow.accountRepository.accounts[accID] = uow.accounts[accID]
+	}
+
+	for _, transaction := range uow.transactions {
+		uow.transactionRepository.insert(transaction)
+	}
+
+	uow.ledgerRepository.insert(totals, uow.journalEntries...)
+
+	for holdID, hold := range uow.holds {
+		if uow.insertedHolds[holdID] || uow.updatedHolds[holdID] {
+			uow.holdRepository.store(hold)
+		}
+	}
+
+	for _, order := range uow.orders {
+		uow.standingOrderRepository.store(order)
+	}
+	for _, execution := range uow.executions {
+		uow.standingOrderRepository.insertExecution(execution)
+	}
+
+	return nil
+}
+
+func (uow *unitOfWork) Rollback() {
+	uow.finished = true
+	uow.accounts = nil
+	uow.readAccounts = nil
+	uow.updatedAccounts = nil
+	uow.transactions = nil
+	uow.journalEntries = nil
+	uow.holds = nil
+	uow.readHolds = nil
+	uow.insertedHolds = nil
+	uow.updatedHolds = nil
+	uow.orders = nil
+	uow.executions = nil
+}
@@ -1,16 +1,22 @@
 package memory
 
 import (
-	"errors"
-	"maps"
+	"fmt"
+	"slices"
 	"sync"
 
 	"http/internal/domain"
+	"http/internal/eventlog"
+	"http/internal/repository"
 )
 
+// UserRepository keeps its own copies of the users, changing a user returned by Get has no effect until Update
 type UserRepository struct {
-	users      map[string]*domain.User
+	users map[string]*domain.User
+	// ids holds every user id, sorted
+	ids        []string
 	usersMutex sync.RWMutex
+	journal    Journal
 }
 
 func NewUserRepository() *UserRepository {
@@ -26,10 +32,11 @@ func (repo *UserRepository) Get(userID string) (*domain.User, error) {
 
 	user := repo.users[userID]
 	if user == nil {
-		return nil, errors.New("user does not exist")
+		return nil, fmt.Errorf("user %w", repository.ErrNotFound)
 	}
 
-	return user, nil
+	userCopy := *user
+	return &userCopy, nil
 }
 
 func (repo *UserRepository) Insert(user *domain.User) (*domain.User, error) {
@@ -37,34 +44,66 @@ func (repo *UserRepository) Insert(user *domain.User) (*domain.User, error) {
 	defer repo.usersMutex.Unlock()
 
 	if repo.users[user.ID] != nil {
-		return nil, errors.New("user already exists")
+		return nil, fmt.Errorf("user %w", repository.ErrAlreadyExists)
+	}
+
+	if err := appendTo(repo.journal, eventlog.UserCreated{User: *user}); err != nil {
+		return nil, err
 	}
 
-	repo.users[user.ID] = user
+	repo.insert(user)
 
-	return repo.users[user.ID], nil
+	return user, nil
+}
+
+// insert expects the caller to hold the write lock
+func (repo *UserRepository) insert(user *domain.User) {
+	stored := *user
+	repo.users[user.ID] = &stored
+
+	i, _ := slices.BinarySearch(repo.ids, user.ID)
+	repo.ids = slices.Insert(repo.ids, i, user.ID)
 }
 
 func (repo *UserRepository) Update(user *domain.User) (*domain.User, error) {
 	repo.usersMutex.Lock()
 	defer repo.usersMutex.Unlock()
 
-	if repo.users[user.ID] == nil {
-		return nil, errors.New("user does not exist")
+	current := repo.users[user.ID]
+	if current == nil {
+		return nil, fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+
+	if err := appendTo(repo.journal, userChanges(current, user)...); err != nil {
+		return nil, err
 	}
 
-	repo.users[user.ID] = user
+	stored := *user
+	repo.users[user.ID] = &stored
 
-	return repo.users[user.ID], nil
+	return user, nil
 }
 
-func (repo *UserRepository) GetAll(returnDeleted bool) ([]domain.User, error) {
-	repo.usersMutex.Lock()
-	defer repo.usersMutex.Unlock()
+func (repo *UserRepository) GetAll(returnDeleted bool, page repository.Page[string]) ([]domain.User, error) {
+	repo.usersMutex.RLock()
+	defer repo.usersMutex.RUnlock()
+
+	var start int
+	if page.After != nil {
+		var found bool
+		start, found = slices.BinarySearch(repo.ids, *page.After)
+		if found {
+			start++
+		}
+	}
 
 	var users []domain.User
+	for _, id := range repo.ids[start:] {
+		if page.Limit > 0 && len(users) == page.Limit {
+			break
+		}
 
-	for user := range maps.Values(repo.users) {
+		user := repo.users[id]
 		if user.DeletedAt != nil && !returnDeleted {
 			continue
 		}
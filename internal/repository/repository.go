@@ -0,0 +1,181 @@
+// Package repository holds the contracts every storage backend implements.
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"http/internal/domain"
+)
+
+// UnitOfWork stages account balance changes, transactions, holds and the standing orders paid by them, none of them
+// are visible to other readers until Commit succeeds. Rollback discards whatever was staged and is a no-op once the unit of work was committed.
+type UnitOfWork interface {
+	GetAccount(accID string) (*domain.Account, error)
+	UpdateAccount(acc *domain.Account) error
+	InsertTransaction(transaction *domain.Transaction) error
+	InsertJournalEntry(entry *domain.JournalEntry) error
+	GetHold(holdID string) (*domain.Hold, error)
+	InsertHold(hold *domain.Hold) error
+	UpdateHold(hold *domain.Hold) error
+	UpdateStandingOrder(order *domain.StandingOrder) error
+	InsertExecution(execution *domain.StandingOrderExecution) error
+	Commit() error
+	Rollback()
+}
+
+// UnitOfWorkFactory starts a new UnitOfWork for every business operation.
+type UnitOfWorkFactory interface {
+	Begin(ctx context.Context) (UnitOfWork, error)
+}
+
+type UserRepository interface {
+	Insert(user *domain.User) (*domain.User, error)
+	Get(userID string) (*domain.User, error)
+	Update(user *domain.User) (*domain.User, error)
+	// GetAll lists users ordered by id
+	GetAll(returnDeleted bool, page Page[string]) ([]domain.User, error)
+}
+
+type AccountRepository interface {
+	Insert(acc *domain.Account) (*domain.Account, error)
+	Get(accID string) (*domain.Account, error)
+	Update(acc *domain.Account) (*domain.Account, error)
+	GetUserAccounts(userID string) ([]domain.Account, error)
+	UpdateBulk(accs []domain.Account) error
+	// GetAll lists accounts ordered by id, closed ones included
+	GetAll(page Page[string]) ([]domain.Account, error)
+}
+
+type TransactionRepository interface {
+	Insert(transaction *domain.Transaction) (*domain.Transaction, error)
+	Get(transactionID string) (*domain.Transaction, error)
+	// GetByParent lists the transactions made for parentID, such as its fee and reversals, oldest first
+	GetByParent(parentID string) ([]domain.Transaction, error)
+	// GetAccountTransactions lists the transactions of an account that match filter, ordered by TransactionCursor
+	GetAccountTransactions(accountID string, filter TransactionFilter, page Page[TransactionCursor]) ([]domain.Transaction, error)
+}
+
+type LedgerRepository interface {
+	Insert(entry *domain.JournalEntry) (*domain.JournalEntry, error)
+	GetTotals() ([]domain.LedgerAccountTotal, error)
+	GetAccountTotal(accountID string, currency domain.Currency) (domain.LedgerAccountTotal, error)
+}
+
+// AuditRepository is append only, events are returned oldest first.
+type AuditRepository interface {
+	Insert(event *domain.AuditEvent) (*domain.AuditEvent, error)
+	GetUserEvents(userID string) ([]domain.AuditEvent, error)
+}
+
+// BatchRepository keeps a batch together with its lines, Update changes the status of the batch and of every line
+// and UpdateLine the status of a single line.
+type BatchRepository interface {
+	Insert(batch *domain.Batch) (*domain.Batch, error)
+	Get(batchID string) (*domain.Batch, error)
+	Update(batch *domain.Batch) (*domain.Batch, error)
+	UpdateLine(batchID string, line *domain.BatchLine) (*domain.BatchLine, error)
+}
+
+// StandingOrderRepository keeps standing orders and the history of their executions. Due lists the active orders
+// whose next attempt is due at a time, oldest due first.
+type StandingOrderRepository interface {
+	Insert(order *domain.StandingOrder) (*domain.StandingOrder, error)
+	Get(orderID string) (*domain.StandingOrder, error)
+	GetByAccount(accountID string) ([]*domain.StandingOrder, error)
+	Due(at time.Time) ([]*domain.StandingOrder, error)
+	Update(order *domain.StandingOrder) (*domain.StandingOrder, error)
+	// InsertExecution records an execution, GetExecutions lists the ones of an order oldest first
+	InsertExecution(execution *domain.StandingOrderExecution) (*domain.StandingOrderExecution, error)
+	// UpdateExecution records how the payment of an execution went, it keeps its place among the executions
+	UpdateExecution(execution *domain.StandingOrderExecution) (*domain.StandingOrderExecution, error)
+	GetExecutions(orderID string) ([]*domain.StandingOrderExecution, error)
+}
+
+// HoldRepository reads holds, they're only written through a UnitOfWork together with the account whose funds they
+// reserve. Expired lists the active holds that expired at a time, soonest expired first.
+type HoldRepository interface {
+	Get(holdID string) (*domain.Hold, error)
+	// GetByAccount lists the holds of an account oldest first, released ones included
+	GetByAccount(accountID string) ([]*domain.Hold, error)
+	Expired(at time.Time) ([]*domain.Hold, error)
+}
+
+// Page limits a listing to at most Limit items following After, the position of the last item of the previous page.
+// A nil After starts with the first item and a zero Limit returns every item.
+type Page[C any] struct {
+	After *C
+	Limit int
+}
+
+// TransactionCursor is the position of a transaction in a listing. Transactions are ordered by creation time and
+// then id, a position never moves so pages stay stable while transactions are inserted.
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Compare orders cursor against other the way cmp.Compare does
+func (cursor TransactionCursor) Compare(other TransactionCursor) int {
+	if c := cursor.CreatedAt.Compare(other.CreatedAt); c != 0 {
+		return c
+	}
+
+	return strings.Compare(cursor.ID, other.ID)
+}
+
+// CursorOf returns the position of transaction
+func CursorOf(transaction domain.Transaction) TransactionCursor {
+	return TransactionCursor{CreatedAt: transaction.CreatedAt, ID: transaction.ID}
+}
+
+// TransactionFilter narrows the transactions of an account, zero fields don't filter anything
+type TransactionFilter struct {
+	// FromDate and ToDate bound the creation time, both are included
+	FromDate time.Time
+	ToDate   time.Time
+	// Types keeps the transactions of any of these types
+	Types []domain.TransactionType
+	// MinAmount and MaxAmount bound the amount that moved in or out of the account, both are included and in the
+	// account's currency
+	MinAmount *domain.Money
+	MaxAmount *domain.Money
+	// CounterpartyID keeps the transfers to or from that account
+	CounterpartyID string
+	// Descending lists the newest transactions first, the page then continues with the transactions before After
+	Descending bool
+}
+
+// Matches tells whether filter keeps transaction in the history of accountID
+func (filter TransactionFilter) Matches(accountID string, transaction *domain.Transaction) bool {
+	if !filter.FromDate.IsZero() && transaction.CreatedAt.Before(filter.FromDate) {
+		return false
+	}
+	if !filter.ToDate.IsZero() && transaction.CreatedAt.After(filter.ToDate) {
+		return false
+	}
+	if len(filter.Types) > 0 && !slices.Contains(filter.Types, transaction.Type) {
+		return false
+	}
+	if filter.CounterpartyID != "" && transaction.Counterparty(accountID) != filter.CounterpartyID {
+		return false
+	}
+
+	amount := transaction.AccountAmount(accountID).Amount
+	if filter.MinAmount != nil && amount < filter.MinAmount.Amount {
+		return false
+	}
+	if filter.MaxAmount != nil && amount > filter.MaxAmount.Amount {
+		return false
+	}
+
+	return true
+}
+
+// ErrNotFound and ErrAlreadyExists are wrapped by every backend so callers can tell these cases apart from
+// storage failures.
+var ErrNotFound = errors.New("does not exist")
+var ErrAlreadyExists = errors.New("already exists")
@@ -0,0 +1,39 @@
+package repotest
+
+import (
+	"slices"
+
+	"http/internal/domain"
+)
+
+// the helpers below return sorted ids so results can be compared regardless of backend iteration order
+
+func userIDs(users []domain.User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+func accountIDs(accounts []domain.Account) []string {
+	ids := make([]string, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.ID
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+// transactionIDs keeps the repository order, transactions are expected sorted by creation date
+func transactionIDs(transactions []domain.Transaction) []string {
+	ids := make([]string, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.ID
+	}
+
+	return ids
+}
@@ -0,0 +1,1366 @@
+// Package repotest is a conformance suite every repository backend runs from its own tests, it checks the
+// behavior the services rely on regardless of how the data is stored.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+// Repositories must all share the same underlying storage.
+type Repositories struct {
+	Users             repository.UserRepository
+	Accounts          repository.AccountRepository
+	Transactions      repository.TransactionRepository
+	Ledger            repository.LedgerRepository
+	Audit             repository.AuditRepository
+	Batches           repository.BatchRepository
+	StandingOrders    repository.StandingOrderRepository
+	Holds             repository.HoldRepository
+	UnitOfWorkFactory repository.UnitOfWorkFactory
+}
+
+// Factory returns empty repositories, it is called once per test.
+type Factory func(t *testing.T) Repositories
+
+// Run runs the whole suite against the backend built by factory.
+func Run(t *testing.T, factory Factory) {
+	t.Run("UserRepository", func(t *testing.T) {
+		testUserRepository(t, factory)
+	})
+	t.Run("AccountRepository", func(t *testing.T) {
+		testAccountRepository(t, factory)
+	})
+	t.Run("TransactionRepository", func(t *testing.T) {
+		testTransactionRepository(t, factory)
+	})
+	t.Run("LedgerRepository", func(t *testing.T) {
+		testLedgerRepository(t, factory)
+	})
+	t.Run("AuditRepository", func(t *testing.T) {
+		testAuditRepository(t, factory)
+	})
+	t.Run("BatchRepository", func(t *testing.T) {
+		testBatchRepository(t, factory)
+	})
+	t.Run("StandingOrderRepository", func(t *testing.T) {
+		testStandingOrderRepository(t, factory)
+	})
+	t.Run("HoldRepository", func(t *testing.T) {
+		testHoldRepository(t, factory)
+	})
+	t.Run("UnitOfWork", func(t *testing.T) {
+		testUnitOfWork(t, factory)
+	})
+}
+
+const concurrentInserts = 50
+
+func testUserRepository(t *testing.T, factory Factory) {
+	t.Run("insert and get", func(t *testing.T) {
+		repo := factory(t).Users
+		user := &domain.User{ID: "1", Name: "name"}
+
+		if _, err := repo.Insert(user); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+
+		got, err := repo.Get(user.ID)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(user, got); diff != "" {
+			t.Errorf("Get() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("duplicated id, want ErrAlreadyExists", func(t *testing.T) {
+		repo := factory(t).Users
+		repo.Insert(&domain.User{ID: "1", Name: "first"})
+
+		if _, err := repo.Insert(&domain.User{ID: "1", Name: "second"}); !errors.Is(err, repository.ErrAlreadyExists) {
+			t.Errorf("Insert() error = %v, wantErr %v", err, repository.ErrAlreadyExists)
+		}
+
+		got, _ := repo.Get("1")
+		if got == nil || got.Name != "first" {
+			t.Errorf("Get() got = %v, want the first inserted user", got)
+		}
+	})
+
+	t.Run("get and update unknown id, want ErrNotFound", func(t *testing.T) {
+		repo := factory(t).Users
+
+		if _, err := repo.Get("unknown"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Get() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+		if _, err := repo.Update(&domain.User{ID: "unknown", Name: "name"}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Update() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+	})
+
+	t.Run("get all filters soft deleted users", func(t *testing.T) {
+		repo := factory(t).Users
+		deletedAt := time.Now()
+		repo.Insert(&domain.User{ID: "active", Name: "active"})
+		repo.Insert(&domain.User{ID: "deleted", Name: "deleted"})
+		repo.Update(&domain.User{ID: "deleted", Name: "deleted", DeletedAt: &deletedAt})
+
+		tests := []struct {
+			returnDeleted bool
+			want          []string
+		}{
+			{returnDeleted: false, want: []string{"active"}},
+			{returnDeleted: true, want: []string{"active", "deleted"}},
+		}
+		for _, tt := range tests {
+			users, err := repo.GetAll(tt.returnDeleted, repository.Page[string]{})
+			if err != nil {
+				t.Fatalf("GetAll() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, userIDs(users)); diff != "" {
+				t.Errorf("GetAll(%v) (-want +got):\n%s", tt.returnDeleted, diff)
+			}
+		}
+	})
+
+	t.Run("concurrent inserts", func(t *testing.T) {
+		repo := factory(t).Users
+
+		runConcurrently(t, func(i int) error {
+			_, err := repo.Insert(&domain.User{ID: fmt.Sprintf("%03d", i), Name: "name"})
+			return err
+		})
+
+		users, _ := repo.GetAll(false, repository.Page[string]{})
+		if len(users) != concurrentInserts {
+			t.Errorf("GetAll() got %v users, want %v", len(users), concurrentInserts)
+		}
+	})
+
+	t.Run("get all pages by id", func(t *testing.T) {
+		repo := factory(t).Users
+		deletedAt := time.Now()
+		for _, id := range []string{"d", "b", "e", "a", "c"} {
+			repo.Insert(&domain.User{ID: id, Name: id})
+		}
+		repo.Update(&domain.User{ID: "b", Name: "b", DeletedAt: &deletedAt})
+
+		tests := []struct {
+			name          string
+			returnDeleted bool
+			page          repository.Page[string]
+			want          []string
+		}{
+			{name: "first page", page: repository.Page[string]{Limit: 2}, want: []string{"a", "c"}},
+			{name: "after a", page: repository.Page[string]{After: stringPtr("a"), Limit: 2}, want: []string{"c", "d"}},
+			{name: "after a deleted user", page: repository.Page[string]{After: stringPtr("b"), Limit: 2}, want: []string{"c", "d"}},
+			{name: "after an unknown id", page: repository.Page[string]{After: stringPtr("cc"), Limit: 5}, want: []string{"d", "e"}},
+			{name: "last page", page: repository.Page[string]{After: stringPtr("e"), Limit: 2}, want: []string{}},
+			{name: "with deleted users", returnDeleted: true, page: repository.Page[string]{After: stringPtr("a"), Limit: 2}, want: []string{"b", "c"}},
+		}
+		for _, tt := range tests {
+			users, err := repo.GetAll(tt.returnDeleted, tt.page)
+			if err != nil {
+				t.Fatalf("GetAll() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, userIDs(users)); diff != "" {
+				t.Errorf("GetAll() %s (-want +got):\n%s", tt.name, diff)
+			}
+		}
+	})
+}
+
+func testAccountRepository(t *testing.T, factory Factory) {
+	t.Run("insert, update and get", func(t *testing.T) {
+		repo := factory(t).Accounts
+		account := &domain.Account{ID: "1", UserID: "1"}
+
+		if _, err := repo.Insert(account); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+
+		updated := &domain.Account{ID: "1", UserID: "1", Balance: eur(100), Type: domain.Savings, Nickname: "Holidays"}
+		if _, err := repo.Update(updated); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		got, err := repo.Get(account.ID)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(updated, got); diff != "" {
+			t.Errorf("Get() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("overdraft", func(t *testing.T) {
+		repo := factory(t).Accounts
+		account := &domain.Account{ID: "1", UserID: "1", Balance: eur(0)}
+		repo.Insert(account)
+
+		account.Balance = eur(-2500)
+		account.Overdraft = domain.Overdraft{
+			Limit:          eur(50000),
+			Rate:           "0.12",
+			Accrued:        "-8.219178082",
+			AccruedThrough: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		}
+		if _, err := repo.Update(account); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		got, err := repo.Get(account.ID)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(account, got); diff != "" {
+			t.Errorf("Get() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("savings interest", func(t *testing.T) {
+		repo := factory(t).Accounts
+		account := &domain.Account{ID: "1", UserID: "1", Balance: eur(100000), Type: domain.Savings}
+		repo.Insert(account)
+
+		account.Interest = domain.SavingsInterest{
+			Plan:           "easy-saver",
+			Accrued:        "5.479452054",
+			AccruedThrough: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		}
+		if _, err := repo.Update(account); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		got, err := repo.Get(account.ID)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(account, got); diff != "" {
+			t.Errorf("Get() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("held funds", func(t *testing.T) {
+		repo := factory(t).Accounts
+		account := &domain.Account{ID: "1", UserID: "1", Balance: eur(100)}
+		repo.Insert(account)
+
+		account.Held = eur(40)
+		if _, err := repo.Update(account); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		got, err := repo.Get(account.ID)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(account, got); diff != "" {
+			t.Errorf("Get() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("all accounts a page at a time", func(t *testing.T) {
+		repo := factory(t).Accounts
+		closedAt := time.Now()
+		for _, account := range []domain.Account{
+			{ID: "3", UserID: "2"},
+			{ID: "1", UserID: "1"},
+			{ID: "4", UserID: "1", DeletedAt: &closedAt},
+			{ID: "2", UserID: "1"},
+		} {
+			repo.Insert(&account)
+		}
+
+		first, err := repo.GetAll(repository.Page[string]{Limit: 3})
+		if err != nil {
+			t.Fatalf("GetAll() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"1", "2", "3"}, accountIDs(first)); diff != "" {
+			t.Errorf("GetAll() first page (-want +got):\n%s", diff)
+		}
+
+		after := first[len(first)-1].ID
+		rest, err := repo.GetAll(repository.Page[string]{After: &after})
+		if err != nil {
+			t.Fatalf("GetAll() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"4"}, accountIDs(rest)); diff != "" {
+			t.Errorf("GetAll() after %s (-want +got):\n%s", after, diff)
+		}
+	})
+
+	t.Run("duplicated id, want ErrAlreadyExists", func(t *testing.T) {
+		repo := factory(t).Accounts
+		repo.Insert(&domain.Account{ID: "1", UserID: "1"})
+
+		if _, err := repo.Insert(&domain.Account{ID: "1", UserID: "2"}); !errors.Is(err, repository.ErrAlreadyExists) {
+			t.Errorf("Insert() error = %v, wantErr %v", err, repository.ErrAlreadyExists)
+		}
+	})
+
+	t.Run("get and update unknown id, want ErrNotFound", func(t *testing.T) {
+		repo := factory(t).Accounts
+
+		if _, err := repo.Get("unknown"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Get() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+		if _, err := repo.Update(&domain.Account{ID: "unknown", UserID: "1"}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Update() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+	})
+
+	t.Run("user accounts and bulk soft delete", func(t *testing.T) {
+		repo := factory(t).Accounts
+		repo.Insert(&domain.Account{ID: "1", UserID: "1"})
+		repo.Insert(&domain.Account{ID: "2", UserID: "1"})
+		repo.Insert(&domain.Account{ID: "3", UserID: "2"})
+
+		accounts, err := repo.GetUserAccounts("1")
+		if err != nil {
+			t.Fatalf("GetUserAccounts() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"1", "2"}, accountIDs(accounts)); diff != "" {
+			t.Errorf("GetUserAccounts() (-want +got):\n%s", diff)
+		}
+
+		deletedAt := time.Now()
+		for i := range accounts {
+			accounts[i].DeletedAt = &deletedAt
+		}
+		if err := repo.UpdateBulk(accounts); err != nil {
+			t.Fatalf("UpdateBulk() error = %v", err)
+		}
+
+		for _, accID := range []string{"1", "2"} {
+			got, _ := repo.Get(accID)
+			if got == nil || got.DeletedAt == nil || !got.DeletedAt.Equal(deletedAt) {
+				t.Errorf("Get(%s) got = %v, want deleted at %v", accID, got, deletedAt)
+			}
+		}
+	})
+
+	t.Run("concurrent inserts", func(t *testing.T) {
+		repo := factory(t).Accounts
+
+		runConcurrently(t, func(i int) error {
+			_, err := repo.Insert(&domain.Account{ID: fmt.Sprintf("%03d", i), UserID: "1"})
+			return err
+		})
+
+		accounts, _ := repo.GetUserAccounts("1")
+		if len(accounts) != concurrentInserts {
+			t.Errorf("GetUserAccounts() got %v accounts, want %v", len(accounts), concurrentInserts)
+		}
+	})
+}
+
+func testTransactionRepository(t *testing.T, factory Factory) {
+	t.Run("duplicated id, want ErrAlreadyExists", func(t *testing.T) {
+		repo := factory(t).Transactions
+		repo.Insert(newDeposit("1", "1", time.Now()))
+
+		if _, err := repo.Insert(newDeposit("1", "2", time.Now())); !errors.Is(err, repository.ErrAlreadyExists) {
+			t.Errorf("Insert() error = %v, wantErr %v", err, repository.ErrAlreadyExists)
+		}
+	})
+
+	t.Run("account transactions within inclusive dates", func(t *testing.T) {
+		repo := factory(t).Transactions
+		fromDate := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		toDate := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+		otherAccount := "2"
+
+		repo.Insert(newDeposit("before", "1", fromDate.Add(-time.Nanosecond)))
+		repo.Insert(newDeposit("to-date", "1", toDate))
+		repo.Insert(newDeposit("from-date", "1", fromDate))
+		repo.Insert(&domain.Transaction{
+			ID:            "outgoing",
+			CreatedAt:     fromDate.Add(time.Hour),
+			FromAccountID: stringPtr("1"),
+			ToAccountID:   &otherAccount,
+			Amount:        eur(1),
+			Type:          domain.Transfer,
+		})
+		repo.Insert(newDeposit("other-account", otherAccount, fromDate.Add(time.Hour)))
+		repo.Insert(newDeposit("after", "1", toDate.Add(time.Nanosecond)))
+
+		transactions, err := repo.GetAccountTransactions("1", repository.TransactionFilter{FromDate: fromDate, ToDate: toDate}, repository.Page[repository.TransactionCursor]{})
+		if err != nil {
+			t.Fatalf("GetAccountTransactions() error = %v", err)
+		}
+
+		if diff := cmp.Diff([]string{"from-date", "outgoing", "to-date"}, transactionIDs(transactions)); diff != "" {
+			t.Errorf("GetAccountTransactions() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("fee keeps its parent", func(t *testing.T) {
+		repo := factory(t).Transactions
+		fee := &domain.Transaction{
+			ID:            "fee",
+			CreatedAt:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			FromAccountID: stringPtr("1"),
+			Amount:        eur(25),
+			Type:          domain.Fee,
+			ParentID:      "withdrawal",
+		}
+		repo.Insert(fee)
+
+		transactions, err := repo.GetAccountTransactions("1", repository.TransactionFilter{}, repository.Page[repository.TransactionCursor]{})
+		if err != nil {
+			t.Fatalf("GetAccountTransactions() error = %v", err)
+		}
+
+		if diff := cmp.Diff([]domain.Transaction{*fee}, transactions); diff != "" {
+			t.Errorf("GetAccountTransactions() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("interest keeps its value date", func(t *testing.T) {
+		repo := factory(t).Transactions
+		valueDate := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+		interest := &domain.Transaction{
+			ID:          "interest",
+			CreatedAt:   time.Date(2025, 2, 3, 9, 0, 0, 0, time.UTC),
+			ToAccountID: stringPtr("1"),
+			Amount:      eur(25),
+			Type:        domain.Interest,
+			ValueDate:   &valueDate,
+		}
+		repo.Insert(interest)
+
+		got, err := repo.Get("interest")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(interest, got); diff != "" {
+			t.Errorf("Get() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("get by id and by parent", func(t *testing.T) {
+		repo := factory(t).Transactions
+		at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		child := func(id, parentID string, createdAt time.Time) *domain.Transaction {
+			return &domain.Transaction{
+				ID:            id,
+				CreatedAt:     createdAt,
+				FromAccountID: stringPtr("1"),
+				Amount:        eur(10),
+				Type:          domain.Reversal,
+				ParentID:      parentID,
+			}
+		}
+
+		deposit := newDeposit("deposit", "1", at)
+		repo.Insert(deposit)
+		repo.Insert(child("second", "deposit", at.Add(2*time.Hour)))
+		repo.Insert(child("first", "deposit", at.Add(time.Hour)))
+		repo.Insert(child("other", "other-deposit", at.Add(time.Hour)))
+
+		got, err := repo.Get("deposit")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(deposit, got); diff != "" {
+			t.Errorf("Get() (-want +got):\n%s", diff)
+		}
+
+		children, err := repo.GetByParent("deposit")
+		if err != nil {
+			t.Fatalf("GetByParent() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"first", "second"}, transactionIDs(children)); diff != "" {
+			t.Errorf("GetByParent() (-want +got):\n%s", diff)
+		}
+
+		if children, err := repo.GetByParent("first"); err != nil || len(children) != 0 {
+			t.Errorf("GetByParent() of a transaction without children = %v, %v, want none", children, err)
+		}
+		if _, err := repo.Get("unknown"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Get() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+	})
+
+	t.Run("no transactions, want empty list", func(t *testing.T) {
+		repo := factory(t).Transactions
+
+		transactions, err := repo.GetAccountTransactions("1", repository.TransactionFilter{}, repository.Page[repository.TransactionCursor]{})
+		if err != nil {
+			t.Fatalf("GetAccountTransactions() error = %v", err)
+		}
+		if transactions == nil || len(transactions) != 0 {
+			t.Errorf("GetAccountTransactions() got = %v, want empty list", transactions)
+		}
+	})
+
+	t.Run("concurrent inserts", func(t *testing.T) {
+		repo := factory(t).Transactions
+		now := time.Now()
+
+		runConcurrently(t, func(i int) error {
+			_, err := repo.Insert(newDeposit(fmt.Sprintf("%03d", i), "1", now))
+			return err
+		})
+
+		transactions, _ := repo.GetAccountTransactions("1", repository.TransactionFilter{FromDate: now, ToDate: now}, repository.Page[repository.TransactionCursor]{})
+		if len(transactions) != concurrentInserts {
+			t.Errorf("GetAccountTransactions() got %v transactions, want %v", len(transactions), concurrentInserts)
+		}
+	})
+
+	t.Run("account transactions pages", func(t *testing.T) {
+		repo := factory(t).Transactions
+		at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		// b and c share their creation time, ids break the tie
+		repo.Insert(newDeposit("c", "1", at.Add(time.Second)))
+		repo.Insert(newDeposit("a", "1", at))
+		repo.Insert(newDeposit("d", "1", at.Add(2*time.Second)))
+		repo.Insert(newDeposit("b", "1", at.Add(time.Second)))
+		repo.Insert(newDeposit("other", "2", at.Add(time.Second)))
+
+		cursor := func(id string, offset time.Duration) *repository.TransactionCursor {
+			return &repository.TransactionCursor{CreatedAt: at.Add(offset), ID: id}
+		}
+		tests := []struct {
+			name   string
+			filter repository.TransactionFilter
+			page   repository.Page[repository.TransactionCursor]
+			want   []string
+		}{
+			{name: "first page", page: repository.Page[repository.TransactionCursor]{Limit: 2}, want: []string{"a", "b"}},
+			{name: "after b", page: repository.Page[repository.TransactionCursor]{After: cursor("b", time.Second), Limit: 2}, want: []string{"c", "d"}},
+			{name: "after c", page: repository.Page[repository.TransactionCursor]{After: cursor("c", time.Second), Limit: 2}, want: []string{"d"}},
+			{name: "after the last", page: repository.Page[repository.TransactionCursor]{After: cursor("d", 2*time.Second), Limit: 2}, want: []string{}},
+			{name: "cursor before from date", filter: repository.TransactionFilter{FromDate: at.Add(time.Second)}, page: repository.Page[repository.TransactionCursor]{After: cursor("a", 0), Limit: 1}, want: []string{"b"}},
+			{name: "no limit", page: repository.Page[repository.TransactionCursor]{After: cursor("a", 0)}, want: []string{"b", "c", "d"}},
+			{name: "descending first page", filter: repository.TransactionFilter{Descending: true}, page: repository.Page[repository.TransactionCursor]{Limit: 2}, want: []string{"d", "c"}},
+			{name: "descending after c", filter: repository.TransactionFilter{Descending: true}, page: repository.Page[repository.TransactionCursor]{After: cursor("c", time.Second), Limit: 2}, want: []string{"b", "a"}},
+			{name: "descending after the last", filter: repository.TransactionFilter{Descending: true}, page: repository.Page[repository.TransactionCursor]{After: cursor("a", 0), Limit: 2}, want: []string{}},
+			{name: "descending within dates", filter: repository.TransactionFilter{FromDate: at.Add(time.Second), ToDate: at.Add(time.Second), Descending: true}, want: []string{"c", "b"}},
+		}
+		for _, tt := range tests {
+			transactions, err := repo.GetAccountTransactions("1", tt.filter, tt.page)
+			if err != nil {
+				t.Fatalf("GetAccountTransactions() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, transactionIDs(transactions)); diff != "" {
+				t.Errorf("GetAccountTransactions() %s (-want +got):\n%s", tt.name, diff)
+			}
+		}
+	})
+
+	t.Run("filtered account transactions", func(t *testing.T) {
+		repo := factory(t).Transactions
+		at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		transfer := func(id, from, to string, amount int64, offset time.Duration) *domain.Transaction {
+			return &domain.Transaction{
+				ID:            id,
+				CreatedAt:     at.Add(offset),
+				FromAccountID: stringPtr(from),
+				ToAccountID:   stringPtr(to),
+				Amount:        eur(amount),
+				Type:          domain.Transfer,
+			}
+		}
+
+		repo.Insert(newDeposit("deposit", "1", at))
+		repo.Insert(&domain.Transaction{
+			ID:            "withdrawal",
+			CreatedAt:     at.Add(time.Second),
+			FromAccountID: stringPtr("1"),
+			Amount:        eur(500),
+			Type:          domain.Withdrawal,
+		})
+		repo.Insert(transfer("to-2", "1", "2", 300, 2*time.Second))
+		repo.Insert(transfer("from-2", "2", "1", 50, 3*time.Second))
+		repo.Insert(transfer("to-3", "1", "3", 1000, 4*time.Second))
+		// account 1 holds USD, the converted amount is what it received
+		converted := transfer("from-4", "4", "1", 100, 5*time.Second)
+		converted.Conversion = &domain.FXConversion{DestinationAmount: domain.NewMoney(900, domain.USD), Rate: "9", RateTimestamp: at}
+		repo.Insert(converted)
+		repo.Insert(transfer("between-2-and-3", "2", "3", 300, 6*time.Second))
+
+		amount := func(minorUnits int64) *domain.Money {
+			money := eur(minorUnits)
+			return &money
+		}
+		tests := []struct {
+			name   string
+			filter repository.TransactionFilter
+			want   []string
+		}{
+			{name: "no filter", want: []string{"deposit", "withdrawal", "to-2", "from-2", "to-3", "from-4"}},
+			{name: "transfers", filter: repository.TransactionFilter{Types: []domain.TransactionType{domain.Transfer}}, want: []string{"to-2", "from-2", "to-3", "from-4"}},
+			{name: "deposits and withdrawals", filter: repository.TransactionFilter{Types: []domain.TransactionType{domain.Deposit, domain.Withdrawal}}, want: []string{"deposit", "withdrawal"}},
+			{name: "counterparty", filter: repository.TransactionFilter{CounterpartyID: "2"}, want: []string{"to-2", "from-2"}},
+			{name: "min amount", filter: repository.TransactionFilter{MinAmount: amount(500)}, want: []string{"withdrawal", "to-3", "from-4"}},
+			{name: "max amount", filter: repository.TransactionFilter{MaxAmount: amount(300)}, want: []string{"deposit", "to-2", "from-2"}},
+			{name: "amount range", filter: repository.TransactionFilter{MinAmount: amount(300), MaxAmount: amount(900)}, want: []string{"withdrawal", "to-2", "from-4"}},
+			{name: "min amount equals max amount", filter: repository.TransactionFilter{MinAmount: amount(300), MaxAmount: amount(300)}, want: []string{"to-2"}},
+			{
+				name:   "every filter descending",
+				filter: repository.TransactionFilter{Types: []domain.TransactionType{domain.Transfer}, CounterpartyID: "2", MaxAmount: amount(300), Descending: true},
+				want:   []string{"from-2", "to-2"},
+			},
+		}
+		for _, tt := range tests {
+			transactions, err := repo.GetAccountTransactions("1", tt.filter, repository.Page[repository.TransactionCursor]{})
+			if err != nil {
+				t.Fatalf("GetAccountTransactions() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, transactionIDs(transactions)); diff != "" {
+				t.Errorf("GetAccountTransactions() %s (-want +got):\n%s", tt.name, diff)
+			}
+		}
+
+		// pages are filled with matching transactions only
+		page := repository.Page[repository.TransactionCursor]{Limit: 1}
+		transactions, _ := repo.GetAccountTransactions("1", repository.TransactionFilter{CounterpartyID: "2"}, page)
+		page.After = &repository.TransactionCursor{CreatedAt: transactions[0].CreatedAt, ID: transactions[0].ID}
+		transactions, _ = repo.GetAccountTransactions("1", repository.TransactionFilter{CounterpartyID: "2"}, page)
+		if diff := cmp.Diff([]string{"from-2"}, transactionIDs(transactions)); diff != "" {
+			t.Errorf("GetAccountTransactions() second page (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func testLedgerRepository(t *testing.T, factory Factory) {
+	t.Run("duplicated id, want ErrAlreadyExists", func(t *testing.T) {
+		repo := factory(t).Ledger
+		repo.Insert(newJournalEntry("1", "1", 100))
+
+		if _, err := repo.Insert(newJournalEntry("1", "2", 100)); !errors.Is(err, repository.ErrAlreadyExists) {
+			t.Errorf("Insert() error = %v, wantErr %v", err, repository.ErrAlreadyExists)
+		}
+	})
+
+	t.Run("totals per account", func(t *testing.T) {
+		repo := factory(t).Ledger
+		repo.Insert(newJournalEntry("1", "1", 100))
+		repo.Insert(newJournalEntry("2", "1", 50))
+		repo.Insert(newJournalEntry("3", "2", 10))
+
+		totals, err := repo.GetTotals()
+		if err != nil {
+			t.Fatalf("GetTotals() error = %v", err)
+		}
+
+		want := []domain.LedgerAccountTotal{
+			{AccountID: "1", Currency: domain.EUR, Debits: eur(0), Credits: eur(150)},
+			{AccountID: "2", Currency: domain.EUR, Debits: eur(0), Credits: eur(10)},
+			{AccountID: domain.CashInAccountID, Currency: domain.EUR, Debits: eur(160), Credits: eur(0)},
+		}
+		if diff := cmp.Diff(want, totals); diff != "" {
+			t.Errorf("GetTotals() (-want +got):\n%s", diff)
+		}
+
+		total, err := repo.GetAccountTotal("1", domain.EUR)
+		if err != nil {
+			t.Fatalf("GetAccountTotal() error = %v", err)
+		}
+		if diff := cmp.Diff(want[0], total); diff != "" {
+			t.Errorf("GetAccountTotal() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("account without entries, want zero total", func(t *testing.T) {
+		total, err := factory(t).Ledger.GetAccountTotal("1", domain.EUR)
+		if err != nil {
+			t.Fatalf("GetAccountTotal() error = %v", err)
+		}
+		if diff := cmp.Diff(domain.NewLedgerAccountTotal("1", domain.EUR), total); diff != "" {
+			t.Errorf("GetAccountTotal() (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func testAuditRepository(t *testing.T, factory Factory) {
+	t.Run("insert and get user events oldest first", func(t *testing.T) {
+		repo := factory(t).Audit
+		deletedAt := time.Unix(0, 1_000)
+		restoredAt := time.Unix(0, 2_000)
+
+		restored := &domain.AuditEvent{ID: "2", UserID: "1", Action: domain.UserRestored, AccountIDs: []string{"a"}, OccurredAt: restoredAt}
+		deleted := &domain.AuditEvent{ID: "1", UserID: "1", Action: domain.UserDeleted, AccountIDs: []string{"a", "b"}, OccurredAt: deletedAt}
+		other := &domain.AuditEvent{ID: "3", UserID: "2", Action: domain.UserDeleted, AccountIDs: []string{}, OccurredAt: deletedAt}
+		for _, event := range []*domain.AuditEvent{restored, deleted, other} {
+			if _, err := repo.Insert(event); err != nil {
+				t.Fatalf("Insert() error = %v", err)
+			}
+		}
+
+		got, err := repo.GetUserEvents("1")
+		if err != nil {
+			t.Fatalf("GetUserEvents() error = %v", err)
+		}
+		if diff := cmp.Diff([]domain.AuditEvent{*deleted, *restored}, got); diff != "" {
+			t.Errorf("GetUserEvents() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("duplicated id, want ErrAlreadyExists", func(t *testing.T) {
+		repo := factory(t).Audit
+		repo.Insert(&domain.AuditEvent{ID: "1", UserID: "1", Action: domain.UserDeleted, OccurredAt: time.Now()})
+
+		if _, err := repo.Insert(&domain.AuditEvent{ID: "1", UserID: "2", Action: domain.UserDeleted, OccurredAt: time.Now()}); !errors.Is(err, repository.ErrAlreadyExists) {
+			t.Errorf("Insert() error = %v, wantErr %v", err, repository.ErrAlreadyExists)
+		}
+	})
+}
+
+func testBatchRepository(t *testing.T, factory Factory) {
+	t.Run("insert, update and get", func(t *testing.T) {
+		repo := factory(t).Batches
+		createdAt := time.Unix(0, 1_000)
+		completedAt := time.Unix(0, 2_000)
+
+		batch := &domain.Batch{
+			ID:        "1",
+			AccountID: "a",
+			Mode:      domain.BestEffort,
+			Status:    domain.BatchProcessing,
+			Lines: []domain.BatchLine{
+				{Number: 1, ToAccountID: "b", Amount: eur(100), Reference: "salary-b", Status: domain.BatchLinePending},
+				{Number: 2, ToAccountID: "c", Amount: eur(250), Status: domain.BatchLinePending},
+			},
+			CreatedAt: createdAt,
+		}
+		if _, err := repo.Insert(batch); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+
+		got, err := repo.Get("1")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(batch, got); diff != "" {
+			t.Errorf("Get() after Insert() (-want +got):\n%s", diff)
+		}
+
+		got.Complete(0, "tx-1")
+		got.Fail(1, "account_closed", "account is closed")
+		got.Finish(completedAt)
+		if _, err := repo.Update(got); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		want := &domain.Batch{
+			ID:        "1",
+			AccountID: "a",
+			Mode:      domain.BestEffort,
+			Status:    domain.BatchPartiallyCompleted,
+			Lines: []domain.BatchLine{
+				{Number: 1, ToAccountID: "b", Amount: eur(100), Reference: "salary-b", Status: domain.BatchLineCompleted, TransactionID: "tx-1"},
+				{Number: 2, ToAccountID: "c", Amount: eur(250), Status: domain.BatchLineFailed, FailureCode: "account_closed", FailureReason: "account is closed"},
+			},
+			CreatedAt:   createdAt,
+			CompletedAt: &completedAt,
+		}
+		got, err = repo.Get("1")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Get() after Update() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("update a line", func(t *testing.T) {
+		repo := factory(t).Batches
+		batch := &domain.Batch{
+			ID:        "1",
+			AccountID: "a",
+			Mode:      domain.BestEffort,
+			Status:    domain.BatchProcessing,
+			Lines: []domain.BatchLine{
+				{Number: 1, ToAccountID: "b", Amount: eur(100), Status: domain.BatchLinePending},
+				{Number: 2, ToAccountID: "c", Amount: eur(250), Status: domain.BatchLinePending},
+			},
+			CreatedAt: time.Unix(0, 1_000),
+		}
+		if _, err := repo.Insert(batch); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+
+		batch.Complete(1, "tx-2")
+		if _, err := repo.UpdateLine(batch.ID, &batch.Lines[1]); err != nil {
+			t.Fatalf("UpdateLine() error = %v", err)
+		}
+
+		got, err := repo.Get("1")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(batch, got); diff != "" {
+			t.Errorf("Get() after UpdateLine() (-want +got):\n%s", diff)
+		}
+
+		unknown := domain.BatchLine{Number: 3, Status: domain.BatchLineCompleted}
+		if _, err := repo.UpdateLine(batch.ID, &unknown); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("UpdateLine() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+	})
+
+	t.Run("duplicated id, want ErrAlreadyExists", func(t *testing.T) {
+		repo := factory(t).Batches
+		batch := &domain.Batch{ID: "1", AccountID: "a", Mode: domain.AllOrNothing, Status: domain.BatchProcessing, CreatedAt: time.Now()}
+		repo.Insert(batch)
+
+		if _, err := repo.Insert(batch); !errors.Is(err, repository.ErrAlreadyExists) {
+			t.Errorf("Insert() error = %v, wantErr %v", err, repository.ErrAlreadyExists)
+		}
+	})
+
+	t.Run("get and update unknown id, want ErrNotFound", func(t *testing.T) {
+		repo := factory(t).Batches
+
+		if _, err := repo.Get("unknown"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Get() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+		if _, err := repo.Update(&domain.Batch{ID: "unknown"}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Update() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+		if _, err := repo.UpdateLine("unknown", &domain.BatchLine{Number: 1}); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("UpdateLine() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+	})
+}
+
+func testStandingOrderRepository(t *testing.T, factory Factory) {
+	at := func(n int64) *time.Time {
+		t := time.Unix(0, n)
+		return &t
+	}
+	newOrder := func(id, accountID string, status domain.StandingOrderStatus, dueAt *time.Time) *domain.StandingOrder {
+		return &domain.StandingOrder{
+			ID:          id,
+			AccountID:   accountID,
+			ToAccountID: "z",
+			Amount:      eur(100),
+			Reference:   "rent",
+			Schedule:    "0 9 1 * *",
+			TimeZone:    "Europe/Lisbon",
+			StartAt:     *at(1_000),
+			EndAt:       at(1_000_000),
+			Policy:      domain.FailurePolicy{Retries: 2, RetryInterval: time.Hour, OnFailure: domain.SuspendOrder},
+			Status:      status,
+			NextRunAt:   dueAt,
+			DueAt:       dueAt,
+			CreatedAt:   *at(1_000),
+		}
+	}
+
+	t.Run("insert, update and get", func(t *testing.T) {
+		repo := factory(t).StandingOrders
+		order := newOrder("1", "a", domain.StandingOrderActive, at(5_000))
+
+		if _, err := repo.Insert(order); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+
+		got, err := repo.Get("1")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(order, got); diff != "" {
+			t.Errorf("Get() after Insert() (-want +got):\n%s", diff)
+		}
+
+		got.Status = domain.StandingOrderSuspended
+		got.NextRunAt = at(6_000)
+		got.DueAt = nil
+		got.Attempts = 2
+		if _, err := repo.Update(got); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		want := newOrder("1", "a", domain.StandingOrderSuspended, at(6_000))
+		want.DueAt = nil
+		want.Attempts = 2
+		got, err = repo.Get("1")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Get() after Update() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("orders of an account and due orders", func(t *testing.T) {
+		repo := factory(t).StandingOrders
+		orders := []*domain.StandingOrder{
+			newOrder("1", "a", domain.StandingOrderActive, at(3_000)),
+			newOrder("2", "b", domain.StandingOrderActive, at(2_000)),
+			newOrder("3", "a", domain.StandingOrderActive, at(9_000)),
+			newOrder("4", "a", domain.StandingOrderSuspended, at(1_000)),
+			newOrder("5", "b", domain.StandingOrderCompleted, nil),
+		}
+		orders[0].CreatedAt = *at(2_000)
+		for _, order := range orders {
+			if _, err := repo.Insert(order); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		ids := func(orders []*domain.StandingOrder) []string {
+			var ids []string
+			for _, order := range orders {
+				ids = append(ids, order.ID)
+			}
+			return ids
+		}
+
+		byAccount, err := repo.GetByAccount("a")
+		if err != nil {
+			t.Fatalf("GetByAccount() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"3", "4", "1"}, ids(byAccount)); diff != "" {
+			t.Errorf("GetByAccount() (-want +got):\n%s", diff)
+		}
+
+		due, err := repo.Due(*at(3_000))
+		if err != nil {
+			t.Fatalf("Due() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"2", "1"}, ids(due)); diff != "" {
+			t.Errorf("Due() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("executions are listed in the order they ran", func(t *testing.T) {
+		repo := factory(t).StandingOrders
+		repo.Insert(newOrder("1", "a", domain.StandingOrderActive, at(5_000)))
+		repo.Insert(newOrder("2", "a", domain.StandingOrderActive, at(5_000)))
+
+		executions := []*domain.StandingOrderExecution{
+			{ID: "e1", OrderID: "1", ScheduledFor: *at(5_000), ExecutedAt: *at(5_000), Attempt: 1, Status: domain.ExecutionRetrying, FailureCode: "insufficient_funds", FailureReason: "insufficient funds"},
+			{ID: "e0", OrderID: "1", ScheduledFor: *at(5_000), ExecutedAt: *at(6_000), Attempt: 2, Status: domain.ExecutionCompleted, TransactionID: "tx-1"},
+			{ID: "e2", OrderID: "2", ScheduledFor: *at(5_000), ExecutedAt: *at(5_000), Attempt: 1, Status: domain.ExecutionCompleted, TransactionID: "tx-2"},
+		}
+		for _, execution := range executions {
+			if _, err := repo.InsertExecution(execution); err != nil {
+				t.Fatalf("InsertExecution() error = %v", err)
+			}
+		}
+
+		got, err := repo.GetExecutions("1")
+		if err != nil {
+			t.Fatalf("GetExecutions() error = %v", err)
+		}
+		if diff := cmp.Diff(executions[:2], got); diff != "" {
+			t.Errorf("GetExecutions() (-want +got):\n%s", diff)
+		}
+
+		if _, err := repo.InsertExecution(executions[0]); !errors.Is(err, repository.ErrAlreadyExists) {
+			t.Errorf("InsertExecution() error = %v, wantErr %v", err, repository.ErrAlreadyExists)
+		}
+	})
+
+	t.Run("updated executions keep their place", func(t *testing.T) {
+		repo := factory(t).StandingOrders
+		repo.Insert(newOrder("1", "a", domain.StandingOrderActive, at(5_000)))
+
+		pending := &domain.StandingOrderExecution{ID: "e1", OrderID: "1", ScheduledFor: *at(5_000), ExecutedAt: *at(5_000), Attempt: 1, Status: domain.ExecutionPending}
+		later := &domain.StandingOrderExecution{ID: "e0", OrderID: "1", ScheduledFor: *at(6_000), ExecutedAt: *at(6_000), Attempt: 1, Status: domain.ExecutionPending}
+		for _, execution := range []*domain.StandingOrderExecution{pending, later} {
+			if _, err := repo.InsertExecution(execution); err != nil {
+				t.Fatalf("InsertExecution() error = %v", err)
+			}
+		}
+
+		completed := *pending
+		completed.Status = domain.ExecutionCompleted
+		completed.TransactionID = "tx-1"
+		if _, err := repo.UpdateExecution(&completed); err != nil {
+			t.Fatalf("UpdateExecution() error = %v", err)
+		}
+
+		got, err := repo.GetExecutions("1")
+		if err != nil {
+			t.Fatalf("GetExecutions() error = %v", err)
+		}
+		if diff := cmp.Diff([]*domain.StandingOrderExecution{&completed, later}, got); diff != "" {
+			t.Errorf("GetExecutions() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("unit of work stores the order with its execution or neither", func(t *testing.T) {
+		for _, commit := range []bool{true, false} {
+			repos := factory(t)
+			order := newOrder("1", "a", domain.StandingOrderActive, at(5_000))
+			repos.StandingOrders.Insert(order)
+
+			uow, err := repos.UnitOfWorkFactory.Begin(context.Background())
+			if err != nil {
+				t.Fatalf("Begin() error = %v", err)
+			}
+			moved := newOrder("1", "a", domain.StandingOrderActive, at(6_000))
+			execution := &domain.StandingOrderExecution{ID: "e1", OrderID: "1", ScheduledFor: *at(5_000), ExecutedAt: *at(5_000), Attempt: 1, Status: domain.ExecutionCompleted, TransactionID: "tx-1"}
+			if err := uow.UpdateStandingOrder(moved); err != nil {
+				t.Fatalf("UpdateStandingOrder() error = %v", err)
+			}
+			if err := uow.InsertExecution(execution); err != nil {
+				t.Fatalf("InsertExecution() error = %v", err)
+			}
+			if commit {
+				if err := uow.Commit(); err != nil {
+					t.Fatalf("Commit() error = %v", err)
+				}
+			}
+			uow.Rollback()
+
+			wantOrder, wantExecutions := order, []*domain.StandingOrderExecution(nil)
+			if commit {
+				wantOrder, wantExecutions = moved, []*domain.StandingOrderExecution{execution}
+			}
+			if got, _ := repos.StandingOrders.Get("1"); !cmp.Equal(wantOrder, got) {
+				t.Errorf("Get() with commit %v (-want +got):\n%s", commit, cmp.Diff(wantOrder, got))
+			}
+			if got, _ := repos.StandingOrders.GetExecutions("1"); !cmp.Equal(wantExecutions, got) {
+				t.Errorf("GetExecutions() with commit %v (-want +got):\n%s", commit, cmp.Diff(wantExecutions, got))
+			}
+		}
+	})
+
+	t.Run("duplicated id, want ErrAlreadyExists", func(t *testing.T) {
+		repo := factory(t).StandingOrders
+		order := newOrder("1", "a", domain.StandingOrderActive, at(5_000))
+		repo.Insert(order)
+
+		if _, err := repo.Insert(order); !errors.Is(err, repository.ErrAlreadyExists) {
+			t.Errorf("Insert() error = %v, wantErr %v", err, repository.ErrAlreadyExists)
+		}
+	})
+
+	t.Run("unknown id, want ErrNotFound", func(t *testing.T) {
+		repo := factory(t).StandingOrders
+
+		if _, err := repo.Get("unknown"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Get() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+		if _, err := repo.Update(newOrder("unknown", "a", domain.StandingOrderActive, nil)); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Update() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+		execution := &domain.StandingOrderExecution{ID: "e1", OrderID: "unknown", Status: domain.ExecutionCompleted}
+		if _, err := repo.InsertExecution(execution); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("InsertExecution() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+		if _, err := repo.UpdateExecution(execution); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("UpdateExecution() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+	})
+}
+
+func testHoldRepository(t *testing.T, factory Factory) {
+	at := func(n int64) time.Time {
+		return time.Unix(0, n)
+	}
+	newHold := func(id, accountID string, createdAt, expiresAt int64) *domain.Hold {
+		return &domain.Hold{
+			ID:        id,
+			AccountID: accountID,
+			Amount:    eur(100),
+			Status:    domain.HoldActive,
+			CreatedAt: at(createdAt),
+			ExpiresAt: at(expiresAt),
+		}
+	}
+	// write runs fn in a unit of work and commits it when commit is set
+	write := func(t *testing.T, repos Repositories, commit bool, fn func(uow repository.UnitOfWork) error) error {
+		t.Helper()
+
+		uow, err := repos.UnitOfWorkFactory.Begin(context.Background())
+		if err != nil {
+			t.Fatalf("Begin() error = %v", err)
+		}
+		defer uow.Rollback()
+
+		if err := fn(uow); err != nil {
+			return err
+		}
+		if commit {
+			return uow.Commit()
+		}
+
+		return nil
+	}
+	ids := func(holds []*domain.Hold) []string {
+		var ids []string
+		for _, hold := range holds {
+			ids = append(ids, hold.ID)
+		}
+		return ids
+	}
+
+	t.Run("insert, update and get", func(t *testing.T) {
+		repos := factory(t)
+		repos.Accounts.Insert(&domain.Account{ID: "a", UserID: "1", Balance: eur(100)})
+		hold := newHold("1", "a", 1_000, 5_000)
+		hold.ToAccountID = "b"
+
+		if err := write(t, repos, true, func(uow repository.UnitOfWork) error { return uow.InsertHold(hold) }); err != nil {
+			t.Fatalf("InsertHold() error = %v", err)
+		}
+
+		got, err := repos.Holds.Get("1")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(hold, got); diff != "" {
+			t.Errorf("Get() after InsertHold() (-want +got):\n%s", diff)
+		}
+
+		releasedAt := at(2_000)
+		hold.Status = domain.HoldCaptured
+		hold.Captured = eur(60)
+		hold.TransactionID = "tx-1"
+		hold.ReleasedAt = &releasedAt
+		err = write(t, repos, true, func(uow repository.UnitOfWork) error {
+			if _, err := uow.GetHold("1"); err != nil {
+				return err
+			}
+			return uow.UpdateHold(hold)
+		})
+		if err != nil {
+			t.Fatalf("UpdateHold() error = %v", err)
+		}
+
+		got, err = repos.Holds.Get("1")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if diff := cmp.Diff(hold, got); diff != "" {
+			t.Errorf("Get() after UpdateHold() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("rolled back holds are discarded", func(t *testing.T) {
+		repos := factory(t)
+		repos.Accounts.Insert(&domain.Account{ID: "a", UserID: "1", Balance: eur(100)})
+
+		if err := write(t, repos, false, func(uow repository.UnitOfWork) error { return uow.InsertHold(newHold("1", "a", 1_000, 5_000)) }); err != nil {
+			t.Fatalf("InsertHold() error = %v", err)
+		}
+
+		if _, err := repos.Holds.Get("1"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Get() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+	})
+
+	t.Run("holds of an account and expired holds", func(t *testing.T) {
+		repos := factory(t)
+		repos.Accounts.Insert(&domain.Account{ID: "a", UserID: "1", Balance: eur(1000)})
+		repos.Accounts.Insert(&domain.Account{ID: "b", UserID: "1", Balance: eur(1000)})
+
+		voided := newHold("4", "a", 1_000, 1_000)
+		voided.Status = domain.HoldVoided
+		holds := []*domain.Hold{
+			newHold("1", "a", 2_000, 3_000),
+			newHold("2", "b", 1_000, 2_000),
+			newHold("3", "a", 1_000, 9_000),
+			voided,
+		}
+		err := write(t, repos, true, func(uow repository.UnitOfWork) error {
+			for _, hold := range holds {
+				if err := uow.InsertHold(hold); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("InsertHold() error = %v", err)
+		}
+
+		byAccount, err := repos.Holds.GetByAccount("a")
+		if err != nil {
+			t.Fatalf("GetByAccount() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"3", "4", "1"}, ids(byAccount)); diff != "" {
+			t.Errorf("GetByAccount() (-want +got):\n%s", diff)
+		}
+
+		expired, err := repos.Holds.Expired(at(3_000))
+		if err != nil {
+			t.Fatalf("Expired() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"2", "1"}, ids(expired)); diff != "" {
+			t.Errorf("Expired() (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("duplicated id, want ErrAlreadyExists", func(t *testing.T) {
+		repos := factory(t)
+		repos.Accounts.Insert(&domain.Account{ID: "a", UserID: "1", Balance: eur(100)})
+		write(t, repos, true, func(uow repository.UnitOfWork) error { return uow.InsertHold(newHold("1", "a", 1_000, 5_000)) })
+
+		err := write(t, repos, true, func(uow repository.UnitOfWork) error { return uow.InsertHold(newHold("1", "a", 1_000, 5_000)) })
+		if !errors.Is(err, repository.ErrAlreadyExists) {
+			t.Errorf("InsertHold() error = %v, wantErr %v", err, repository.ErrAlreadyExists)
+		}
+	})
+
+	t.Run("unknown id, want ErrNotFound", func(t *testing.T) {
+		repos := factory(t)
+
+		if _, err := repos.Holds.Get("unknown"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Get() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+		err := write(t, repos, true, func(uow repository.UnitOfWork) error {
+			_, err := uow.GetHold("unknown")
+			return err
+		})
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetHold() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+		err = write(t, repos, true, func(uow repository.UnitOfWork) error { return uow.UpdateHold(newHold("unknown", "a", 1_000, 5_000)) })
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("UpdateHold() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+	})
+}
+
+func testUnitOfWork(t *testing.T, factory Factory) {
+	tests := []struct {
+		name             string
+		commit           bool
+		wantBalance      domain.Money
+		wantTransactions int
+	}{
+		{
+			name:             "committed changes are visible",
+			commit:           true,
+			wantBalance:      eur(100),
+			wantTransactions: 1,
+		},
+		{
+			name:             "rolled back changes are discarded",
+			commit:           false,
+			wantBalance:      eur(0),
+			wantTransactions: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := factory(t)
+			repos.Accounts.Insert(&domain.Account{ID: "1", UserID: "1", Balance: eur(0)})
+
+			uow, err := repos.UnitOfWorkFactory.Begin(context.Background())
+			if err != nil {
+				t.Fatalf("Begin() error = %v", err)
+			}
+
+			acc, err := uow.GetAccount("1")
+			if err != nil {
+				t.Fatalf("GetAccount() error = %v", err)
+			}
+			acc.Balance = eur(100)
+			if err := uow.UpdateAccount(acc); err != nil {
+				t.Fatalf("UpdateAccount() error = %v", err)
+			}
+			if err := uow.InsertTransaction(newDeposit("1", "1", time.Now())); err != nil {
+				t.Fatalf("InsertTransaction() error = %v", err)
+			}
+			if err := uow.InsertJournalEntry(newJournalEntry("1", "1", 100)); err != nil {
+				t.Fatalf("InsertJournalEntry() error = %v", err)
+			}
+
+			if tt.commit {
+				if err := uow.Commit(); err != nil {
+					t.Fatalf("Commit() error = %v", err)
+				}
+			}
+			uow.Rollback()
+
+			got, _ := repos.Accounts.Get("1")
+			if got == nil || got.Balance != tt.wantBalance {
+				t.Errorf("Get() got = %v, want balance %v", got, tt.wantBalance)
+			}
+
+			transactions, _ := repos.Transactions.GetAccountTransactions("1", repository.TransactionFilter{}, repository.Page[repository.TransactionCursor]{})
+			if len(transactions) != tt.wantTransactions {
+				t.Errorf("GetAccountTransactions() got %v transactions, want %v", len(transactions), tt.wantTransactions)
+			}
+
+			total, _ := repos.Ledger.GetAccountTotal("1", domain.EUR)
+			if balance, _ := total.Balance(); balance != tt.wantBalance {
+				t.Errorf("GetAccountTotal() got balance %v, want %v", balance, tt.wantBalance)
+			}
+		})
+	}
+
+	t.Run("unknown account, want ErrNotFound", func(t *testing.T) {
+		uow, err := factory(t).UnitOfWorkFactory.Begin(context.Background())
+		if err != nil {
+			t.Fatalf("Begin() error = %v", err)
+		}
+		defer uow.Rollback()
+
+		if _, err := uow.GetAccount("unknown"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("GetAccount() error = %v, wantErr %v", err, repository.ErrNotFound)
+		}
+	})
+}
+
+func runConcurrently(t *testing.T, fn func(i int) error) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentInserts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := fn(i); err != nil {
+				t.Errorf("concurrent call %d error = %v", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func newDeposit(id, accountID string, createdAt time.Time) *domain.Transaction {
+	return &domain.Transaction{
+		ID:          id,
+		CreatedAt:   createdAt,
+		ToAccountID: &accountID,
+		Amount:      eur(1),
+		Type:        domain.Deposit,
+	}
+}
+
+// newJournalEntry records a deposit of amount into accountID
+func newJournalEntry(id, accountID string, amount int64) *domain.JournalEntry {
+	return &domain.JournalEntry{
+		ID:            id,
+		TransactionID: id,
+		CreatedAt:     time.Now(),
+		Lines: []domain.JournalLine{
+			{AccountID: domain.CashInAccountID, Side: domain.Debit, Amount: eur(amount)},
+			{AccountID: accountID, Side: domain.Credit, Amount: eur(amount)},
+		},
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func eur(amount int64) domain.Money {
+	return domain.NewMoney(amount, domain.EUR)
+}
@@ -0,0 +1,206 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+const accountColumns = `id, user_id, balance, currency, type, nickname, deleted_at, overdraft_limit, overdraft_rate, overdraft_accrued,
+	overdraft_accrued_through, interest_plan, interest_accrued, interest_accrued_through, held`
+
+type AccountRepository struct {
+	db *sql.DB
+}
+
+func NewAccountRepository(db *sql.DB) *AccountRepository {
+	return &AccountRepository{
+		db: db,
+	}
+}
+
+func (repo *AccountRepository) Insert(account *domain.Account) (*domain.Account, error) {
+	limit, accruedThrough := overdraftValues(account.Overdraft)
+	result, err := repo.db.Exec(
+		`INSERT INTO accounts (`+accountColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		account.ID, account.UserID, account.Balance.Amount, account.Balance.Currency.String(), account.Type.String(), account.Nickname,
+		toNullTime(account.DeletedAt), limit, account.Overdraft.Rate, account.Overdraft.Accrued, accruedThrough,
+		account.Interest.Plan, account.Interest.Accrued, toNullDay(account.Interest.AccruedThrough), toNullMoney(account.Held),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.Join(fmt.Errorf("account with id %w", repository.ErrAlreadyExists), err)
+	}
+
+	return account, nil
+}
+
+func (repo *AccountRepository) Get(accID string) (*domain.Account, error) {
+	return getAccount(repo.db, accID)
+}
+
+func (repo *AccountRepository) Update(account *domain.Account) (*domain.Account, error) {
+	if err := updateAccount(repo.db, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (repo *AccountRepository) GetUserAccounts(userID string) ([]domain.Account, error) {
+	rows, err := repo.db.Query(`SELECT `+accountColumns+` FROM accounts WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []domain.Account
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, *account)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (repo *AccountRepository) GetAll(page repository.Page[string]) ([]domain.Account, error) {
+	var after string
+	if page.After != nil {
+		after = *page.After
+	}
+
+	limit := -1
+	if page.Limit > 0 {
+		limit = page.Limit
+	}
+
+	rows, err := repo.db.Query(`SELECT `+accountColumns+` FROM accounts WHERE id > ? ORDER BY id LIMIT ?`, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []domain.Account
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, *account)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (repo *AccountRepository) UpdateBulk(accounts []domain.Account) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, account := range accounts {
+		if err := updateAccount(tx, &account); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func getAccount(q querier, accID string) (*domain.Account, error) {
+	row := q.QueryRow(`SELECT `+accountColumns+` FROM accounts WHERE id = ?`, accID)
+
+	account, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("account with id %w", repository.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func updateAccount(q querier, account *domain.Account) error {
+	limit, accruedThrough := overdraftValues(account.Overdraft)
+	result, err := q.Exec(
+		`UPDATE accounts SET user_id = ?, balance = ?, currency = ?, type = ?, nickname = ?, deleted_at = ?, overdraft_limit = ?,
+		overdraft_rate = ?, overdraft_accrued = ?, overdraft_accrued_through = ?, interest_plan = ?, interest_accrued = ?,
+		interest_accrued_through = ?, held = ? WHERE id = ?`,
+		account.UserID, account.Balance.Amount, account.Balance.Currency.String(), account.Type.String(), account.Nickname,
+		toNullTime(account.DeletedAt), limit, account.Overdraft.Rate, account.Overdraft.Accrued, accruedThrough,
+		account.Interest.Plan, account.Interest.Accrued, toNullDay(account.Interest.AccruedThrough), toNullMoney(account.Held),
+		account.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.Join(fmt.Errorf("account with id %w", repository.ErrNotFound), err)
+	}
+
+	return nil
+}
+
+func scanAccount(row scanner) (*domain.Account, error) {
+	var account domain.Account
+	var deletedAt, limit, accruedThrough, interestAccruedThrough, held sql.NullInt64
+
+	if err := row.Scan(&account.ID, &account.UserID, &account.Balance.Amount, &account.Balance.Currency, &account.Type, &account.Nickname,
+		&deletedAt, &limit, &account.Overdraft.Rate, &account.Overdraft.Accrued, &accruedThrough,
+		&account.Interest.Plan, &account.Interest.Accrued, &interestAccruedThrough, &held); err != nil {
+		return nil, err
+	}
+	account.DeletedAt = fromNullTime(deletedAt)
+	if limit.Valid {
+		account.Overdraft.Limit = domain.NewMoney(limit.Int64, account.Balance.Currency)
+	}
+	if accruedThrough.Valid {
+		account.Overdraft.AccruedThrough = time.Unix(0, accruedThrough.Int64).UTC()
+	}
+	if interestAccruedThrough.Valid {
+		account.Interest.AccruedThrough = time.Unix(0, interestAccruedThrough.Int64).UTC()
+	}
+	if held.Valid {
+		account.Held = domain.NewMoney(held.Int64, account.Balance.Currency)
+	}
+
+	return &account, nil
+}
+
+// overdraftValues stores the parts of an overdraft that are NULL when no overdraft was ever arranged
+func overdraftValues(overdraft domain.Overdraft) (limit sql.NullInt64, accruedThrough sql.NullInt64) {
+	return toNullMoney(overdraft.Limit), toNullDay(overdraft.AccruedThrough)
+}
+
+// toNullMoney stores amounts in the account currency that are NULL until they were first set
+func toNullMoney(amount domain.Money) sql.NullInt64 {
+	if amount.Currency == "" {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: amount.Amount, Valid: true}
+}
+
+// toNullDay stores the day accrued interest was accrued through, NULL until interest started accruing
+func toNullDay(day time.Time) sql.NullInt64 {
+	if day.IsZero() {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: day.UnixNano(), Valid: true}
+}
@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (repo *AuditRepository) Insert(event *domain.AuditEvent) (*domain.AuditEvent, error) {
+	accountIDs, err := json.Marshal(event.AccountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := repo.db.Exec(
+		`INSERT INTO audit_events (id, user_id, action, account_ids, occurred_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		event.ID, event.UserID, event.Action.String(), string(accountIDs), event.OccurredAt.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.Join(fmt.Errorf("audit event with id %w", repository.ErrAlreadyExists), err)
+	}
+
+	return event, nil
+}
+
+func (repo *AuditRepository) GetUserEvents(userID string) ([]domain.AuditEvent, error) {
+	rows, err := repo.db.Query(
+		`SELECT id, user_id, action, account_ids, occurred_at FROM audit_events WHERE user_id = ? ORDER BY occurred_at, rowid`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.AuditEvent
+	for rows.Next() {
+		var event domain.AuditEvent
+		var accountIDs string
+		var occurredAt int64
+
+		if err := rows.Scan(&event.ID, &event.UserID, &event.Action, &accountIDs, &occurredAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(accountIDs), &event.AccountIDs); err != nil {
+			return nil, err
+		}
+		event.OccurredAt = time.Unix(0, occurredAt)
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
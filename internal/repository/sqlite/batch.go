@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+type BatchRepository struct {
+	db *sql.DB
+}
+
+func NewBatchRepository(db *sql.DB) *BatchRepository {
+	return &BatchRepository{
+		db: db,
+	}
+}
+
+func (repo *BatchRepository) Insert(batch *domain.Batch) (*domain.Batch, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`INSERT INTO batches (id, account_id, mode, status, created_at, completed_at) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		batch.ID, batch.AccountID, batch.Mode.String(), batch.Status.String(), batch.CreatedAt.UnixNano(), toNullTime(batch.CompletedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.Join(fmt.Errorf("batch with id %w", repository.ErrAlreadyExists), err)
+	}
+
+	for _, line := range batch.Lines {
+		_, err := tx.Exec(
+			`INSERT INTO batch_lines (batch_id, number, to_account_id, amount, currency, reference, status, transaction_id, failure_code, failure_reason)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			batch.ID, line.Number, line.ToAccountID, line.Amount.Amount, line.Amount.Currency.String(), line.Reference,
+			line.Status.String(), line.TransactionID, line.FailureCode, line.FailureReason,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return batch, tx.Commit()
+}
+
+func (repo *BatchRepository) Get(batchID string) (*domain.Batch, error) {
+	var batch domain.Batch
+	var createdAt int64
+	var completedAt sql.NullInt64
+
+	err := repo.db.QueryRow(
+		`SELECT id, account_id, mode, status, created_at, completed_at FROM batches WHERE id = ?`,
+		batchID,
+	).Scan(&batch.ID, &batch.AccountID, &batch.Mode, &batch.Status, &createdAt, &completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("batch with id %w", repository.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	batch.CreatedAt = time.Unix(0, createdAt)
+	batch.CompletedAt = fromNullTime(completedAt)
+
+	rows, err := repo.db.Query(
+		`SELECT number, to_account_id, amount, currency, reference, status, transaction_id, failure_code, failure_reason
+		FROM batch_lines WHERE batch_id = ? ORDER BY number`,
+		batchID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line domain.BatchLine
+		err := rows.Scan(&line.Number, &line.ToAccountID, &line.Amount.Amount, &line.Amount.Currency, &line.Reference,
+			&line.Status, &line.TransactionID, &line.FailureCode, &line.FailureReason)
+		if err != nil {
+			return nil, err
+		}
+
+		batch.Lines = append(batch.Lines, line)
+	}
+
+	return &batch, rows.Err()
+}
+
+func (repo *BatchRepository) UpdateLine(batchID string, line *domain.BatchLine) (*domain.BatchLine, error) {
+	result, err := repo.db.Exec(
+		`UPDATE batch_lines SET status = ?, transaction_id = ?, failure_code = ?, failure_reason = ? WHERE batch_id = ? AND number = ?`,
+		line.Status.String(), line.TransactionID, line.FailureCode, line.FailureReason, batchID, line.Number,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.Join(fmt.Errorf("batch line with number %w", repository.ErrNotFound), err)
+	}
+
+	return line, nil
+}
+
+func (repo *BatchRepository) Update(batch *domain.Batch) (*domain.Batch, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`UPDATE batches SET status = ?, completed_at = ? WHERE id = ?`,
+		batch.Status.String(), toNullTime(batch.CompletedAt), batch.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.Join(fmt.Errorf("batch with id %w", repository.ErrNotFound), err)
+	}
+
+	for _, line := range batch.Lines {
+		_, err := tx.Exec(
+			`UPDATE batch_lines SET status = ?, transaction_id = ?, failure_code = ?, failure_reason = ? WHERE batch_id = ? AND number = ?`,
+			line.Status.String(), line.TransactionID, line.FailureCode, line.FailureReason, batch.ID, line.Number,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return batch, tx.Commit()
+}
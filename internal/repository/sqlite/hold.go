@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+// HoldRepository reads the holds, they're written by the unit of work that changes the account they're on
+type HoldRepository struct {
+	db *sql.DB
+}
+
+func NewHoldRepository(db *sql.DB) *HoldRepository {
+	return &HoldRepository{
+		db: db,
+	}
+}
+
+const holdColumns = `id, account_id, to_account_id, amount, currency, status, captured, transaction_id, created_at, expires_at,
+	released_at`
+
+func (repo *HoldRepository) Get(holdID string) (*domain.Hold, error) {
+	return getHold(repo.db, holdID)
+}
+
+func (repo *HoldRepository) GetByAccount(accountID string) ([]*domain.Hold, error) {
+	return repo.query(`SELECT `+holdColumns+` FROM holds WHERE account_id = ? ORDER BY created_at, id`, accountID)
+}
+
+func (repo *HoldRepository) Expired(at time.Time) ([]*domain.Hold, error) {
+	return repo.query(
+		`SELECT `+holdColumns+` FROM holds WHERE status = ? AND expires_at <= ? ORDER BY expires_at, id`,
+		domain.HoldActive.String(), at.UnixNano(),
+	)
+}
+
+func (repo *HoldRepository) query(query string, args ...any) ([]*domain.Hold, error) {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []*domain.Hold
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+func getHold(q querier, holdID string) (*domain.Hold, error) {
+	hold, err := scanHold(q.QueryRow(`SELECT `+holdColumns+` FROM holds WHERE id = ?`, holdID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("hold with id %w", repository.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func insertHold(q querier, hold *domain.Hold) error {
+	result, err := q.Exec(
+		`INSERT INTO holds (`+holdColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		hold.ID, hold.AccountID, hold.ToAccountID, hold.Amount.Amount, hold.Amount.Currency.String(), hold.Status.String(),
+		toNullMoney(hold.Captured), hold.TransactionID, hold.CreatedAt.UnixNano(), hold.ExpiresAt.UnixNano(),
+		toNullTime(hold.ReleasedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.Join(fmt.Errorf("hold with id %w", repository.ErrAlreadyExists), err)
+	}
+
+	return nil
+}
+
+func updateHold(q querier, hold *domain.Hold) error {
+	result, err := q.Exec(
+		`UPDATE holds SET status = ?, captured = ?, transaction_id = ?, released_at = ? WHERE id = ?`,
+		hold.Status.String(), toNullMoney(hold.Captured), hold.TransactionID, toNullTime(hold.ReleasedAt), hold.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.Join(fmt.Errorf("hold with id %w", repository.ErrNotFound), err)
+	}
+
+	return nil
+}
+
+func scanHold(row scanner) (*domain.Hold, error) {
+	var hold domain.Hold
+	var createdAt, expiresAt int64
+	var captured, releasedAt sql.NullInt64
+
+	err := row.Scan(&hold.ID, &hold.AccountID, &hold.ToAccountID, &hold.Amount.Amount, &hold.Amount.Currency, &hold.Status,
+		&captured, &hold.TransactionID, &createdAt, &expiresAt, &releasedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if captured.Valid {
+		hold.Captured = domain.NewMoney(captured.Int64, hold.Amount.Currency)
+	}
+	hold.CreatedAt = time.Unix(0, createdAt)
+	hold.ExpiresAt = time.Unix(0, expiresAt)
+	hold.ReleasedAt = fromNullTime(releasedAt)
+
+	return &hold, nil
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+type LedgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) *LedgerRepository {
+	return &LedgerRepository{
+		db: db,
+	}
+}
+
+func (repo *LedgerRepository) Insert(entry *domain.JournalEntry) (*domain.JournalEntry, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := insertJournalEntry(tx, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, tx.Commit()
+}
+
+func (repo *LedgerRepository) GetTotals() ([]domain.LedgerAccountTotal, error) {
+	rows, err := repo.db.Query(
+		`SELECT account_id, currency,
+			SUM(CASE side WHEN 'debit' THEN amount ELSE 0 END),
+			SUM(CASE side WHEN 'credit' THEN amount ELSE 0 END)
+		FROM journal_lines GROUP BY account_id, currency ORDER BY account_id, currency`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make([]domain.LedgerAccountTotal, 0)
+	for rows.Next() {
+		var accountID string
+		var currency domain.Currency
+		var debits, credits int64
+		if err := rows.Scan(&accountID, &currency, &debits, &credits); err != nil {
+			return nil, err
+		}
+
+		totals = append(totals, ledgerAccountTotal(accountID, currency, debits, credits))
+	}
+
+	return totals, rows.Err()
+}
+
+func (repo *LedgerRepository) GetAccountTotal(accountID string, currency domain.Currency) (domain.LedgerAccountTotal, error) {
+	var debits, credits int64
+	err := repo.db.QueryRow(
+		`SELECT COALESCE(SUM(CASE side WHEN 'debit' THEN amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE side WHEN 'credit' THEN amount ELSE 0 END), 0)
+		FROM journal_lines WHERE account_id = ? AND currency = ?`,
+		accountID, currency.String(),
+	).Scan(&debits, &credits)
+
+	return ledgerAccountTotal(accountID, currency, debits, credits), err
+}
+
+func ledgerAccountTotal(accountID string, currency domain.Currency, debits, credits int64) domain.LedgerAccountTotal {
+	total := domain.NewLedgerAccountTotal(accountID, currency)
+	total.Debits.Amount = debits
+	total.Credits.Amount = credits
+
+	return total
+}
+
+func insertJournalEntry(q querier, entry *domain.JournalEntry) error {
+	result, err := q.Exec(
+		`INSERT INTO journal_entries (id, transaction_id, created_at) VALUES (?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		entry.ID, entry.TransactionID, entry.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.Join(fmt.Errorf("journal entry with id %w", repository.ErrAlreadyExists), err)
+	}
+
+	for i, line := range entry.Lines {
+		_, err := q.Exec(
+			`INSERT INTO journal_lines (entry_id, line, account_id, side, amount, currency) VALUES (?, ?, ?, ?, ?, ?)`,
+			entry.ID, i, line.AccountID, line.Side.String(), line.Amount.Amount, line.Amount.Currency.String(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// migrations are applied in order and never edited once released, schema changes go in a new entry
+var migrations = []string{
+	`CREATE TABLE users (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		deleted_at INTEGER
+	);
+	CREATE TABLE accounts (
+		id         TEXT PRIMARY KEY,
+		user_id    TEXT NOT NULL,
+		balance    INTEGER NOT NULL,
+		deleted_at INTEGER
+	);
+	CREATE INDEX accounts_user_id_idx ON accounts (user_id);
+	CREATE TABLE transactions (
+		id              TEXT PRIMARY KEY,
+		created_at      INTEGER NOT NULL,
+		from_account_id TEXT,
+		to_account_id   TEXT,
+		amount          INTEGER NOT NULL,
+		type            TEXT NOT NULL
+	);
+	CREATE INDEX transactions_from_account_id_created_at_idx ON transactions (from_account_id, created_at);
+	CREATE INDEX transactions_to_account_id_created_at_idx ON transactions (to_account_id, created_at);
+	CREATE INDEX transactions_created_at_idx ON transactions (created_at);`,
+	`CREATE TABLE journal_entries (
+		id             TEXT PRIMARY KEY,
+		transaction_id TEXT NOT NULL,
+		created_at     INTEGER NOT NULL
+	);
+	CREATE INDEX journal_entries_transaction_id_idx ON journal_entries (transaction_id);
+	CREATE TABLE journal_lines (
+		entry_id   TEXT NOT NULL REFERENCES journal_entries (id),
+		line       INTEGER NOT NULL,
+		account_id TEXT NOT NULL,
+		side       TEXT NOT NULL CHECK (side IN ('debit', 'credit')),
+		amount     INTEGER NOT NULL CHECK (amount > 0),
+		PRIMARY KEY (entry_id, line)
+	);
+	CREATE INDEX journal_lines_account_id_idx ON journal_lines (account_id);
+	-- accounts funded before the ledger existed get an opening balance entry so the books reconcile
+	INSERT INTO journal_entries (id, transaction_id, created_at)
+		SELECT 'opening-' || id, '', CAST(strftime('%s', 'now') AS INTEGER) * 1000000000 FROM accounts WHERE balance > 0;
+	INSERT INTO journal_lines (entry_id, line, account_id, side, amount)
+		SELECT 'opening-' || id, 0, 'system:opening-balance', 'debit', balance FROM accounts WHERE balance > 0;
+	INSERT INTO journal_lines (entry_id, line, account_id, side, amount)
+		SELECT 'opening-' || id, 1, id, 'credit', balance FROM accounts WHERE balance > 0;`,
+	// amounts used to be whole units without a currency, they become minor units of the default currency
+	`ALTER TABLE accounts ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+	UPDATE accounts SET balance = balance * 100;
+	ALTER TABLE transactions ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+	UPDATE transactions SET amount = amount * 100;
+	ALTER TABLE journal_lines ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+	UPDATE journal_lines SET amount = amount * 100;`,
+	`ALTER TABLE transactions ADD COLUMN destination_amount INTEGER;
+	ALTER TABLE transactions ADD COLUMN destination_currency TEXT;
+	ALTER TABLE transactions ADD COLUMN fx_rate TEXT;
+	ALTER TABLE transactions ADD COLUMN fx_rate_at INTEGER;`,
+	`ALTER TABLE accounts ADD COLUMN type TEXT NOT NULL DEFAULT 'checking';
+	ALTER TABLE accounts ADD COLUMN nickname TEXT NOT NULL DEFAULT '';`,
+	`CREATE TABLE audit_events (
+		id          TEXT PRIMARY KEY,
+		user_id     TEXT NOT NULL,
+		action      TEXT NOT NULL,
+		account_ids TEXT NOT NULL,
+		occurred_at INTEGER NOT NULL
+	);
+	CREATE INDEX audit_events_user_id_occurred_at_idx ON audit_events (user_id, occurred_at);`,
+	// history pages are ordered by created_at and then id
+	`DROP INDEX transactions_from_account_id_created_at_idx;
+	DROP INDEX transactions_to_account_id_created_at_idx;
+	CREATE INDEX transactions_from_account_id_created_at_id_idx ON transactions (from_account_id, created_at, id);
+	CREATE INDEX transactions_to_account_id_created_at_id_idx ON transactions (to_account_id, created_at, id);`,
+	`CREATE TABLE batches (
+		id           TEXT PRIMARY KEY,
+		account_id   TEXT NOT NULL,
+		mode         TEXT NOT NULL,
+		status       TEXT NOT NULL,
+		created_at   INTEGER NOT NULL,
+		completed_at INTEGER
+	);
+	CREATE TABLE batch_lines (
+		batch_id       TEXT NOT NULL REFERENCES batches (id),
+		number         INTEGER NOT NULL,
+		to_account_id  TEXT NOT NULL,
+		amount         INTEGER NOT NULL,
+		currency       TEXT NOT NULL,
+		reference      TEXT NOT NULL,
+		status         TEXT NOT NULL,
+		transaction_id TEXT NOT NULL,
+		failure_code   TEXT NOT NULL,
+		failure_reason TEXT NOT NULL,
+		PRIMARY KEY (batch_id, number)
+	);`,
+	`CREATE TABLE standing_orders (
+		id             TEXT PRIMARY KEY,
+		account_id     TEXT NOT NULL,
+		to_account_id  TEXT NOT NULL,
+		amount         INTEGER NOT NULL,
+		currency       TEXT NOT NULL,
+		reference      TEXT NOT NULL,
+		schedule       TEXT NOT NULL,
+		time_zone      TEXT NOT NULL,
+		start_at       INTEGER NOT NULL,
+		end_at         INTEGER,
+		retries        INTEGER NOT NULL,
+		retry_interval INTEGER NOT NULL,
+		on_failure     TEXT NOT NULL,
+		status         TEXT NOT NULL,
+		next_run_at    INTEGER,
+		due_at         INTEGER,
+		attempts       INTEGER NOT NULL,
+		created_at     INTEGER NOT NULL
+	);
+	CREATE INDEX standing_orders_account_id_idx ON standing_orders (account_id);
+	CREATE INDEX standing_orders_status_due_at_idx ON standing_orders (status, due_at);
+	CREATE TABLE standing_order_executions (
+		id             TEXT PRIMARY KEY,
+		order_id       TEXT NOT NULL REFERENCES standing_orders (id),
+		scheduled_for  INTEGER NOT NULL,
+		executed_at    INTEGER NOT NULL,
+		attempt        INTEGER NOT NULL,
+		status         TEXT NOT NULL,
+		transaction_id TEXT NOT NULL,
+		failure_code   TEXT NOT NULL,
+		failure_reason TEXT NOT NULL
+	);
+	CREATE INDEX standing_order_executions_order_id_idx ON standing_order_executions (order_id, executed_at);`,
+	// the overdraft limit is in the account currency, NULL for accounts that never had an overdraft
+	`ALTER TABLE accounts ADD COLUMN overdraft_limit INTEGER;
+	ALTER TABLE accounts ADD COLUMN overdraft_rate TEXT NOT NULL DEFAULT '';
+	ALTER TABLE accounts ADD COLUMN overdraft_accrued TEXT NOT NULL DEFAULT '';
+	ALTER TABLE accounts ADD COLUMN overdraft_accrued_through INTEGER;`,
+	// interest_accrued_through is NULL for accounts that were never put on a rate plan
+	`ALTER TABLE accounts ADD COLUMN interest_plan TEXT NOT NULL DEFAULT '';
+	ALTER TABLE accounts ADD COLUMN interest_accrued TEXT NOT NULL DEFAULT '';
+	ALTER TABLE accounts ADD COLUMN interest_accrued_through INTEGER;`,
+	`ALTER TABLE transactions ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';`,
+	// held is in the account currency, NULL for accounts that never had a hold
+	`ALTER TABLE accounts ADD COLUMN held INTEGER;
+	CREATE TABLE holds (
+		id             TEXT PRIMARY KEY,
+		account_id     TEXT NOT NULL REFERENCES accounts (id),
+		to_account_id  TEXT NOT NULL,
+		amount         INTEGER NOT NULL,
+		currency       TEXT NOT NULL,
+		status         TEXT NOT NULL,
+		captured       INTEGER,
+		transaction_id TEXT NOT NULL,
+		created_at     INTEGER NOT NULL,
+		expires_at     INTEGER NOT NULL,
+		released_at    INTEGER
+	);
+	CREATE INDEX holds_account_id_idx ON holds (account_id, created_at);
+	CREATE INDEX holds_status_expires_at_idx ON holds (status, expires_at);`,
+	// most transactions have no parent, only the ones that do are indexed
+	`CREATE INDEX transactions_parent_id_created_at_idx ON transactions (parent_id, created_at, id) WHERE parent_id != '';`,
+	// value_date is only set on interest, the other transactions take effect when they're made
+	`ALTER TABLE transactions ADD COLUMN value_date INTEGER;`,
+}
+
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(migrations); i++ {
+		if err := applyMigration(db, i+1, migrations[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, version int, migration string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().UnixNano()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,75 @@
+// Package sqlite stores the bank state in a SQLite database file.
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+var failedToOpenDatabase = errors.New("failed to open database")
+var failedToMigrateDatabase = errors.New("failed to migrate database")
+
+// Open opens the database at path and applies any pending migration.
+func Open(path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_txlock=immediate&_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, errors.Join(failedToOpenDatabase, err)
+	}
+
+	// SQLite allows a single writer, sharing one connection avoids busy errors between concurrent units of work
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, errors.Join(failedToMigrateDatabase, err)
+	}
+
+	return db, nil
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx so queries can be shared by repositories and units of work
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func toNullTime(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func fromNullTime(n sql.NullInt64) *time.Time {
+	if !n.Valid {
+		return nil
+	}
+
+	t := time.Unix(0, n.Int64)
+	return &t
+}
+
+func toNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func fromNullString(n sql.NullString) *string {
+	if !n.Valid {
+		return nil
+	}
+
+	s := n.String
+	return &s
+}
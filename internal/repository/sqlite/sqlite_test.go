@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+	"http/internal/repository/repotest"
+)
+
+func newTestDB(t *testing.T) string {
+	t.Helper()
+
+	return filepath.Join(t.TempDir(), "tiny_bank.db")
+}
+
+func TestOpen_MigratesOnce(t *testing.T) {
+	path := newTestDB(t)
+
+	for i := 0; i < 2; i++ {
+		db, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+
+		var version int
+		if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+			t.Fatalf("failed to read schema version: %v", err)
+		}
+		if version != len(migrations) {
+			t.Errorf("schema version got = %v, want %v", version, len(migrations))
+		}
+
+		db.Close()
+	}
+}
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		db, err := Open(newTestDB(t))
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		return repotest.Repositories{
+			Users:             NewUserRepository(db),
+			Accounts:          NewAccountRepository(db),
+			Transactions:      NewTransactionRepository(db),
+			Ledger:            NewLedgerRepository(db),
+			Audit:             NewAuditRepository(db),
+			Batches:           NewBatchRepository(db),
+			StandingOrders:    NewStandingOrderRepository(db),
+			Holds:             NewHoldRepository(db),
+			UnitOfWorkFactory: NewUnitOfWorkFactory(db),
+		}
+	})
+}
+
+func TestOpen_BackfillsOpeningBalances(t *testing.T) {
+	path := newTestDB(t)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at INTEGER NOT NULL)`); err != nil {
+		t.Fatalf("failed to create schema_migrations: %v", err)
+	}
+	if err := applyMigration(db, 1, migrations[0]); err != nil {
+		t.Fatalf("failed to apply first migration: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO accounts (id, user_id, balance) VALUES ('1', '1', 100), ('2', '1', 0)`); err != nil {
+		t.Fatalf("failed to insert accounts: %v", err)
+	}
+	db.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	totals, err := NewLedgerRepository(db).GetTotals()
+	if err != nil {
+		t.Fatalf("GetTotals() error = %v", err)
+	}
+
+	// the legacy balance of 100 whole units becomes 10000 cents, the migration balances it on a system account of its own
+	want := []domain.LedgerAccountTotal{
+		{AccountID: "1", Currency: domain.EUR, Debits: domain.NewMoney(0, domain.EUR), Credits: domain.NewMoney(10000, domain.EUR)},
+		{AccountID: "system:opening-balance", Currency: domain.EUR, Debits: domain.NewMoney(10000, domain.EUR), Credits: domain.NewMoney(0, domain.EUR)},
+	}
+	if diff := cmp.Diff(want, totals); diff != "" {
+		t.Errorf("GetTotals() (-want +got):\n%s", diff)
+	}
+
+	account, err := NewAccountRepository(db).Get("1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if want := domain.NewMoney(10000, domain.EUR); account.Balance != want {
+		t.Errorf("Get() balance = %v, want %v", account.Balance, want)
+	}
+}
@@ -0,0 +1,219 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+type StandingOrderRepository struct {
+	db *sql.DB
+}
+
+func NewStandingOrderRepository(db *sql.DB) *StandingOrderRepository {
+	return &StandingOrderRepository{
+		db: db,
+	}
+}
+
+const standingOrderColumns = `id, account_id, to_account_id, amount, currency, reference, schedule, time_zone, start_at, end_at,
+	retries, retry_interval, on_failure, status, next_run_at, due_at, attempts, created_at`
+
+func (repo *StandingOrderRepository) Insert(order *domain.StandingOrder) (*domain.StandingOrder, error) {
+	result, err := repo.db.Exec(
+		`INSERT INTO standing_orders (`+standingOrderColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		order.ID, order.AccountID, order.ToAccountID, order.Amount.Amount, order.Amount.Currency.String(), order.Reference,
+		order.Schedule, order.TimeZone, order.StartAt.UnixNano(), toNullTime(order.EndAt), order.Policy.Retries,
+		int64(order.Policy.RetryInterval), order.Policy.OnFailure.String(), order.Status.String(), toNullTime(order.NextRunAt),
+		toNullTime(order.DueAt), order.Attempts, order.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.Join(fmt.Errorf("standing order with id %w", repository.ErrAlreadyExists), err)
+	}
+
+	return order, nil
+}
+
+func (repo *StandingOrderRepository) Get(orderID string) (*domain.StandingOrder, error) {
+	order, err := scanStandingOrder(repo.db.QueryRow(`SELECT `+standingOrderColumns+` FROM standing_orders WHERE id = ?`, orderID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("standing order with id %w", repository.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (repo *StandingOrderRepository) GetByAccount(accountID string) ([]*domain.StandingOrder, error) {
+	return repo.query(`SELECT `+standingOrderColumns+` FROM standing_orders WHERE account_id = ? ORDER BY created_at, id`, accountID)
+}
+
+func (repo *StandingOrderRepository) Due(at time.Time) ([]*domain.StandingOrder, error) {
+	return repo.query(
+		`SELECT `+standingOrderColumns+` FROM standing_orders WHERE status = ? AND due_at <= ? ORDER BY due_at, id`,
+		domain.StandingOrderActive.String(), at.UnixNano(),
+	)
+}
+
+func (repo *StandingOrderRepository) Update(order *domain.StandingOrder) (*domain.StandingOrder, error) {
+	if err := updateStandingOrder(repo.db, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (repo *StandingOrderRepository) InsertExecution(execution *domain.StandingOrderExecution) (*domain.StandingOrderExecution, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := insertExecution(tx, execution); err != nil {
+		return nil, err
+	}
+
+	return execution, tx.Commit()
+}
+
+func updateStandingOrder(q querier, order *domain.StandingOrder) error {
+	result, err := q.Exec(
+		`UPDATE standing_orders SET status = ?, next_run_at = ?, due_at = ?, attempts = ? WHERE id = ?`,
+		order.Status.String(), toNullTime(order.NextRunAt), toNullTime(order.DueAt), order.Attempts, order.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.Join(fmt.Errorf("standing order with id %w", repository.ErrNotFound), err)
+	}
+
+	return nil
+}
+
+// insertExecution checks the order of execution exists first, q must be a transaction for the check to hold
+func insertExecution(q querier, execution *domain.StandingOrderExecution) error {
+	var exists bool
+	if err := q.QueryRow(`SELECT EXISTS (SELECT 1 FROM standing_orders WHERE id = ?)`, execution.OrderID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("standing order with id %w", repository.ErrNotFound)
+	}
+
+	result, err := q.Exec(
+		`INSERT INTO standing_order_executions (id, order_id, scheduled_for, executed_at, attempt, status, transaction_id, failure_code, failure_reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		execution.ID, execution.OrderID, execution.ScheduledFor.UnixNano(), execution.ExecutedAt.UnixNano(), execution.Attempt,
+		execution.Status.String(), execution.TransactionID, execution.FailureCode, execution.FailureReason,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.Join(fmt.Errorf("standing order execution with id %w", repository.ErrAlreadyExists), err)
+	}
+
+	return nil
+}
+
+func (repo *StandingOrderRepository) UpdateExecution(execution *domain.StandingOrderExecution) (*domain.StandingOrderExecution, error) {
+	result, err := repo.db.Exec(
+		`UPDATE standing_order_executions SET status = ?, transaction_id = ?, failure_code = ?, failure_reason = ? WHERE id = ?`,
+		execution.Status.String(), execution.TransactionID, execution.FailureCode, execution.FailureReason, execution.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.Join(fmt.Errorf("standing order execution with id %w", repository.ErrNotFound), err)
+	}
+
+	return execution, nil
+}
+
+func (repo *StandingOrderRepository) GetExecutions(orderID string) ([]*domain.StandingOrderExecution, error) {
+	rows, err := repo.db.Query(
+		`SELECT id, order_id, scheduled_for, executed_at, attempt, status, transaction_id, failure_code, failure_reason
+		FROM standing_order_executions WHERE order_id = ? ORDER BY executed_at, rowid`,
+		orderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*domain.StandingOrderExecution
+	for rows.Next() {
+		var execution domain.StandingOrderExecution
+		var scheduledFor, executedAt int64
+		err := rows.Scan(&execution.ID, &execution.OrderID, &scheduledFor, &executedAt, &execution.Attempt, &execution.Status,
+			&execution.TransactionID, &execution.FailureCode, &execution.FailureReason)
+		if err != nil {
+			return nil, err
+		}
+		execution.ScheduledFor = time.Unix(0, scheduledFor)
+		execution.ExecutedAt = time.Unix(0, executedAt)
+
+		executions = append(executions, &execution)
+	}
+
+	return executions, rows.Err()
+}
+
+func (repo *StandingOrderRepository) query(query string, args ...any) ([]*domain.StandingOrder, error) {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*domain.StandingOrder
+	for rows.Next() {
+		order, err := scanStandingOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+func scanStandingOrder(row scanner) (*domain.StandingOrder, error) {
+	var order domain.StandingOrder
+	var startAt, createdAt, retryInterval int64
+	var endAt, nextRunAt, dueAt sql.NullInt64
+
+	err := row.Scan(&order.ID, &order.AccountID, &order.ToAccountID, &order.Amount.Amount, &order.Amount.Currency,
+		&order.Reference, &order.Schedule, &order.TimeZone, &startAt, &endAt, &order.Policy.Retries, &retryInterval,
+		&order.Policy.OnFailure, &order.Status, &nextRunAt, &dueAt, &order.Attempts, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	order.StartAt = time.Unix(0, startAt)
+	order.EndAt = fromNullTime(endAt)
+	order.Policy.RetryInterval = time.Duration(retryInterval)
+	order.NextRunAt = fromNullTime(nextRunAt)
+	order.DueAt = fromNullTime(dueAt)
+	order.CreatedAt = time.Unix(0, createdAt)
+
+	return &order, nil
+}
@@ -0,0 +1,233 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+type TransactionRepository struct {
+	db *sql.DB
+}
+
+func NewTransactionRepository(db *sql.DB) *TransactionRepository {
+	return &TransactionRepository{
+		db: db,
+	}
+}
+
+func (repo *TransactionRepository) Insert(transaction *domain.Transaction) (*domain.Transaction, error) {
+	if err := insertTransaction(repo.db, transaction); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (repo *TransactionRepository) Get(transactionID string) (*domain.Transaction, error) {
+	transaction, err := scanTransaction(repo.db.QueryRow(`SELECT `+transactionColumns+` FROM transactions WHERE id = ?`, transactionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("transaction with id %w", repository.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (repo *TransactionRepository) GetByParent(parentID string) ([]domain.Transaction, error) {
+	rows, err := repo.db.Query(
+		`SELECT `+transactionColumns+` FROM transactions WHERE parent_id = ? AND parent_id != '' ORDER BY created_at, id`,
+		parentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions = make([]domain.Transaction, 0)
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, *transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+// transactionColumns is the column list scanTransaction expects
+const transactionColumns = `id, created_at, from_account_id, to_account_id, amount, currency, type,
+	destination_amount, destination_currency, fx_rate, fx_rate_at, parent_id, value_date`
+
+func (repo *TransactionRepository) GetAccountTransactions(
+	accountID string,
+	filter repository.TransactionFilter,
+	page repository.Page[repository.TransactionCursor],
+) ([]domain.Transaction, error) {
+	// row values compare created_at and then id, the position of the first page sorts before every transaction
+	order, after := "created_at, id", ">"
+	afterCreatedAt, afterID := int64(math.MinInt64), ""
+	if filter.Descending {
+		order, after = "created_at DESC, id DESC", "<"
+		afterCreatedAt = math.MaxInt64
+	}
+	if page.After != nil {
+		afterCreatedAt, afterID = page.After.CreatedAt.UnixNano(), page.After.ID
+	}
+
+	limit := -1
+	if page.Limit > 0 {
+		limit = page.Limit
+	}
+
+	query, args := transactionFilterConditions(filter, []any{accountID, afterCreatedAt, afterID, limit})
+
+	// each side of the union walks its own index and stops after a page, an OR would read the whole history. The
+	// from side sees the amount taken from the account and the to side the amount credited to it.
+	side := strings.NewReplacer(
+		"{account}", "from_account_id", "{counterparty}", "to_account_id", "{amount}", "amount",
+	).Replace(query)
+	otherSide := strings.NewReplacer(
+		"{account}", "to_account_id", "{counterparty}", "from_account_id", "{amount}", "COALESCE(destination_amount, amount)",
+	).Replace(query)
+
+	rows, err := repo.db.Query(
+		`SELECT `+transactionColumns+` FROM (
+			SELECT * FROM (
+				SELECT `+transactionColumns+` FROM transactions
+				WHERE `+side+` AND (created_at, id) `+after+` (?2, ?3)
+				ORDER BY `+order+` LIMIT ?4
+			)
+			UNION
+			SELECT * FROM (
+				SELECT `+transactionColumns+` FROM transactions
+				WHERE `+otherSide+` AND (created_at, id) `+after+` (?2, ?3)
+				ORDER BY `+order+` LIMIT ?4
+			)
+		)
+		ORDER BY `+order+` LIMIT ?4`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions = make([]domain.Transaction, 0)
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, *transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+// transactionFilterConditions returns the WHERE conditions of filter with their arguments appended to args, the
+// first argument is the account. {account}, {counterparty} and {amount} stand for the columns of either side of a
+// transaction.
+func transactionFilterConditions(filter repository.TransactionFilter, args []any) (string, []any) {
+	conditions := []string{"{account} = ?1"}
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if !filter.FromDate.IsZero() {
+		add("created_at >= ?%d", filter.FromDate.UnixNano())
+	}
+	if !filter.ToDate.IsZero() {
+		add("created_at <= ?%d", filter.ToDate.UnixNano())
+	}
+	if len(filter.Types) > 0 {
+		placeholders := make([]string, len(filter.Types))
+		for i, transactionType := range filter.Types {
+			args = append(args, transactionType.String())
+			placeholders[i] = fmt.Sprintf("?%d", len(args))
+		}
+		conditions = append(conditions, "type IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.CounterpartyID != "" {
+		add("{counterparty} = ?%d", filter.CounterpartyID)
+	}
+	if filter.MinAmount != nil {
+		add("{amount} >= ?%d", filter.MinAmount.Amount)
+	}
+	if filter.MaxAmount != nil {
+		add("{amount} <= ?%d", filter.MaxAmount.Amount)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func insertTransaction(q querier, transaction *domain.Transaction) error {
+	var destinationAmount, rateAt sql.NullInt64
+	var destinationCurrency, rate sql.NullString
+	if conversion := transaction.Conversion; conversion != nil {
+		destinationAmount = sql.NullInt64{Int64: conversion.DestinationAmount.Amount, Valid: true}
+		destinationCurrency = sql.NullString{String: conversion.DestinationAmount.Currency.String(), Valid: true}
+		rate = sql.NullString{String: conversion.Rate, Valid: true}
+		rateAt = toNullTime(&conversion.RateTimestamp)
+	}
+
+	result, err := q.Exec(
+		`INSERT INTO transactions (`+transactionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		transaction.ID, transaction.CreatedAt.UnixNano(), toNullString(transaction.FromAccountID),
+		toNullString(transaction.ToAccountID), transaction.Amount.Amount, transaction.Amount.Currency.String(),
+		transaction.Type.String(), destinationAmount, destinationCurrency, rate, rateAt, transaction.ParentID,
+		toNullTime(transaction.ValueDate),
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return errors.Join(fmt.Errorf("transaction with id %w", repository.ErrAlreadyExists), err)
+	}
+
+	return nil
+}
+
+func scanTransaction(row scanner) (*domain.Transaction, error) {
+	var transaction domain.Transaction
+	var createdAt int64
+	var fromAccountID, toAccountID sql.NullString
+	var destinationAmount, rateAt, valueDate sql.NullInt64
+	var destinationCurrency, rate sql.NullString
+
+	err := row.Scan(&transaction.ID, &createdAt, &fromAccountID, &toAccountID, &transaction.Amount.Amount,
+		&transaction.Amount.Currency, &transaction.Type, &destinationAmount, &destinationCurrency, &rate, &rateAt,
+		&transaction.ParentID, &valueDate)
+	if err != nil {
+		return nil, err
+	}
+	transaction.CreatedAt = time.Unix(0, createdAt)
+	transaction.FromAccountID = fromNullString(fromAccountID)
+	transaction.ToAccountID = fromNullString(toAccountID)
+	transaction.ValueDate = fromNullTime(valueDate)
+
+	if destinationAmount.Valid {
+		transaction.Conversion = &domain.FXConversion{
+			DestinationAmount: domain.NewMoney(destinationAmount.Int64, domain.Currency(destinationCurrency.String)),
+			Rate:              rate.String,
+			RateTimestamp:     time.Unix(0, rateAt.Int64),
+		}
+	}
+
+	return &transaction, nil
+}
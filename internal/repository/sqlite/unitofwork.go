@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+type UnitOfWorkFactory struct {
+	db *sql.DB
+}
+
+func NewUnitOfWorkFactory(db *sql.DB) *UnitOfWorkFactory {
+	return &UnitOfWorkFactory{
+		db: db,
+	}
+}
+
+func (factory *UnitOfWorkFactory) Begin(ctx context.Context) (repository.UnitOfWork, error) {
+	tx, err := factory.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unitOfWork{tx: tx}, nil
+}
+
+// unitOfWork maps directly to a database transaction
+type unitOfWork struct {
+	tx *sql.Tx
+}
+
+func (uow *unitOfWork) GetAccount(accID string) (*domain.Account, error) {
+	return getAccount(uow.tx, accID)
+}
+
+func (uow *unitOfWork) UpdateAccount(acc *domain.Account) error {
+	return updateAccount(uow.tx, acc)
+}
+
+func (uow *unitOfWork) InsertTransaction(transaction *domain.Transaction) error {
+	return insertTransaction(uow.tx, transaction)
+}
+
+func (uow *unitOfWork) InsertJournalEntry(entry *domain.JournalEntry) error {
+	return insertJournalEntry(uow.tx, entry)
+}
+
+func (uow *unitOfWork) GetHold(holdID string) (*domain.Hold, error) {
+	return getHold(uow.tx, holdID)
+}
+
+func (uow *unitOfWork) InsertHold(hold *domain.Hold) error {
+	return insertHold(uow.tx, hold)
+}
+
+func (uow *unitOfWork) UpdateHold(hold *domain.Hold) error {
+	return updateHold(uow.tx, hold)
+}
+
+func (uow *unitOfWork) UpdateStandingOrder(order *domain.StandingOrder) error {
+	return updateStandingOrder(uow.tx, order)
+}
+
+func (uow *unitOfWork) InsertExecution(execution *domain.StandingOrderExecution) error {
+	return insertExecution(uow.tx, execution)
+}
+
+func (uow *unitOfWork) Commit() error {
+	return uow.tx.Commit()
+}
+
+func (uow *unitOfWork) Rollback() {
+	_ = uow.tx.Rollback()
+}
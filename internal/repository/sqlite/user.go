@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+func (repo *UserRepository) Get(userID string) (*domain.User, error) {
+	row := repo.db.QueryRow(`SELECT id, name, deleted_at FROM users WHERE id = ?`, userID)
+
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (repo *UserRepository) Insert(user *domain.User) (*domain.User, error) {
+	result, err := repo.db.Exec(
+		`INSERT INTO users (id, name, deleted_at) VALUES (?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		user.ID, user.Name, toNullTime(user.DeletedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.Join(fmt.Errorf("user %w", repository.ErrAlreadyExists), err)
+	}
+
+	return user, nil
+}
+
+func (repo *UserRepository) Update(user *domain.User) (*domain.User, error) {
+	result, err := repo.db.Exec(
+		`UPDATE users SET name = ?, deleted_at = ? WHERE id = ?`,
+		user.Name, toNullTime(user.DeletedAt), user.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, errors.Join(fmt.Errorf("user %w", repository.ErrNotFound), err)
+	}
+
+	return user, nil
+}
+
+func (repo *UserRepository) GetAll(returnDeleted bool, page repository.Page[string]) ([]domain.User, error) {
+	var after string
+	if page.After != nil {
+		after = *page.After
+	}
+
+	limit := -1
+	if page.Limit > 0 {
+		limit = page.Limit
+	}
+
+	rows, err := repo.db.Query(
+		`SELECT id, name, deleted_at FROM users WHERE (? OR deleted_at IS NULL) AND id > ? ORDER BY id LIMIT ?`,
+		returnDeleted, after, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row scanner) (*domain.User, error) {
+	var user domain.User
+	var deletedAt sql.NullInt64
+
+	if err := row.Scan(&user.ID, &user.Name, &deletedAt); err != nil {
+		return nil, err
+	}
+	user.DeletedAt = fromNullTime(deletedAt)
+
+	return &user, nil
+}
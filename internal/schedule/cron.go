@@ -0,0 +1,199 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cron is a standard five field expression: minute, hour, day of month, month and day of week. Fields take *,
+// numbers, ranges, steps and lists such as 1-5 or */15, months and week days take their three letter English names
+// too. Like cron, a day matches when either its day of month or its day of week does if both are restricted.
+type cron struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday tell whether their field was a *
+	anyDay, anyWeekday bool
+	start              time.Time
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+var weekdayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	// 7 is Sunday as well as 0
+	{name: "day of week", min: 0, max: 7, names: weekdayNames},
+}
+
+func parseCron(expr string, start time.Time) (*cron, error) {
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, invalidSchedule(fmt.Sprintf("cron expression must have 5 fields, got %d", len(fields)))
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := cronFields[i].parse(strings.ToUpper(field))
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	weekdays := sets[4]
+	if weekdays&(1<<7) != 0 {
+		weekdays |= 1
+	}
+
+	return &cron{
+		minutes:    sets[0],
+		hours:      sets[1],
+		days:       sets[2],
+		months:     sets[3],
+		weekdays:   weekdays,
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+		start:      start,
+	}, nil
+}
+
+// parse reads a comma separated list of values, ranges and steps into a bit set of the values they cover
+func (field cronField) parse(expr string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step < 1 {
+				return 0, invalidSchedule(fmt.Sprintf("invalid step %q in %s field", stepExpr, field.name))
+			}
+		}
+
+		low, high := field.min, field.max
+		if rangeExpr != "*" {
+			lowExpr, highExpr, isRange := strings.Cut(rangeExpr, "-")
+
+			var err error
+			if low, err = field.value(lowExpr); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = field.value(highExpr); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// 5/15 means from 5 to the end in steps of 15
+				high = field.max
+			}
+			if low > high {
+				return 0, invalidSchedule(fmt.Sprintf("invalid range %q in %s field", rangeExpr, field.name))
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << value
+		}
+	}
+
+	return set, nil
+}
+
+func (field cronField) value(expr string) (int, error) {
+	for i, name := range field.names {
+		if name != "" && name == expr {
+			return i, nil
+		}
+	}
+
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < field.min || value > field.max {
+		return 0, invalidSchedule(fmt.Sprintf("invalid value %q in %s field, expected %d to %d", expr, field.name, field.min, field.max))
+	}
+
+	return value, nil
+}
+
+func (c *cron) Next(after time.Time) (time.Time, bool) {
+	location := c.start.Location()
+	if after.Before(c.start) {
+		after = c.start.Add(-time.Nanosecond)
+	}
+	after = after.In(location)
+
+	// occurrences are on the minute, the first candidate is the minute after after
+	from := after.Truncate(time.Minute).Add(time.Minute)
+	year, month, day := from.Date()
+
+	for date := time.Date(year, month, day, 0, 0, 0, 0, location); date.Sub(from) < horizon; date = date.AddDate(0, 0, 1) {
+		if !c.matchesDay(date) {
+			continue
+		}
+
+		for hour := 0; hour < 24; hour++ {
+			if c.hours&(1<<hour) == 0 {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if c.minutes&(1<<minute) == 0 {
+					continue
+				}
+
+				next := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, location)
+				// times skipped by a daylight saving change don't happen
+				if next.Hour() != hour || next.Minute() != minute || !next.After(after) {
+					continue
+				}
+
+				return next, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (c *cron) matchesDay(date time.Time) bool {
+	if c.months&(1<<int(date.Month())) == 0 {
+		return false
+	}
+
+	day := c.days&(1<<date.Day()) != 0
+	weekday := c.weekdays&(1<<int(date.Weekday())) != 0
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
@@ -0,0 +1,386 @@
+package schedule
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type frequency int
+
+const (
+	daily frequency = iota
+	weekly
+	monthly
+	yearly
+)
+
+var frequencies = map[string]frequency{"DAILY": daily, "WEEKLY": weekly, "MONTHLY": monthly, "YEARLY": yearly}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday,
+}
+
+// byDay is a BYDAY entry, ordinal is 0 for every such week day of the period, 1 for the first one and -1 for the
+// last one
+type byDay struct {
+	weekday time.Weekday
+	ordinal int
+}
+
+// rrule is the subset of RFC 5545 recurrence rules standing orders need: FREQ of DAILY, WEEKLY, MONTHLY or YEARLY,
+// INTERVAL, COUNT, UNTIL, BYMONTH, BYMONTHDAY, BYDAY, BYHOUR and BYMINUTE. Weeks start on Monday.
+type rrule struct {
+	frequency frequency
+	interval  int
+	count     int
+	until     time.Time
+	months    []time.Month
+	monthDays []int
+	days      []byDay
+	hours     []int
+	minutes   []int
+	start     time.Time
+}
+
+func parseRRule(expr string, start time.Time) (*rrule, error) {
+	rule := &rrule{interval: 1, start: start}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(strings.TrimSuffix(expr, ";"), ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, invalidSchedule(fmt.Sprintf("invalid RRULE part %q", part))
+		}
+		if seen[key] {
+			return nil, invalidSchedule(fmt.Sprintf("RRULE has %s twice", key))
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			var known bool
+			if rule.frequency, known = frequencies[value]; !known {
+				err = invalidSchedule(fmt.Sprintf("FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY, got %q", value))
+			}
+		case "INTERVAL":
+			rule.interval, err = parseRulePositive(key, value)
+		case "COUNT":
+			rule.count, err = parseRulePositive(key, value)
+		case "UNTIL":
+			rule.until, err = parseUntil(value, start.Location())
+		case "BYMONTH":
+			var months []int
+			months, err = parseRuleList(key, value, 1, 12, false)
+			for _, month := range months {
+				rule.months = append(rule.months, time.Month(month))
+			}
+		case "BYMONTHDAY":
+			rule.monthDays, err = parseRuleList(key, value, 1, 31, true)
+		case "BYDAY":
+			rule.days, err = parseByDay(value)
+		case "BYHOUR":
+			rule.hours, err = parseRuleList(key, value, 0, 23, false)
+		case "BYMINUTE":
+			rule.minutes, err = parseRuleList(key, value, 0, 59, false)
+		case "WKST":
+			if value != "MO" {
+				err = invalidSchedule("only WKST=MO is supported")
+			}
+		default:
+			err = invalidSchedule(fmt.Sprintf("unsupported RRULE part %s", key))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case !seen["FREQ"]:
+		return nil, invalidSchedule("RRULE must have a FREQ")
+	case seen["COUNT"] && seen["UNTIL"]:
+		return nil, invalidSchedule("RRULE can't have both COUNT and UNTIL")
+	case rule.frequency == weekly && seen["BYMONTHDAY"]:
+		return nil, invalidSchedule("BYMONTHDAY can't be used with FREQ=WEEKLY")
+	}
+	for _, day := range rule.days {
+		if day.ordinal != 0 && rule.frequency != monthly && !(rule.frequency == yearly && len(rule.months) > 0) {
+			return nil, invalidSchedule("BYDAY ordinals such as 1MO are only supported with FREQ=MONTHLY or FREQ=YEARLY and BYMONTH")
+		}
+	}
+
+	slices.Sort(rule.hours)
+	slices.Sort(rule.minutes)
+
+	return rule, nil
+}
+
+func parseRulePositive(key, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, invalidSchedule(fmt.Sprintf("%s must be a positive number, got %q", key, value))
+	}
+
+	return n, nil
+}
+
+// parseRuleList reads a comma separated list of numbers from min to max, or from -max to -min too when negative
+// counts from the end
+func parseRuleList(key, value string, min, max int, negative bool) ([]int, error) {
+	var list []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		inRange := n >= min && n <= max || negative && n <= -min && n >= -max
+		if err != nil || !inRange {
+			return nil, invalidSchedule(fmt.Sprintf("invalid %s value %q", key, item))
+		}
+		list = append(list, n)
+	}
+
+	return list, nil
+}
+
+func parseByDay(value string) ([]byDay, error) {
+	var days []byDay
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, invalidSchedule(fmt.Sprintf("invalid BYDAY value %q", item))
+		}
+
+		weekday, ok := weekdayCodes[item[len(item)-2:]]
+		if !ok {
+			return nil, invalidSchedule(fmt.Sprintf("invalid BYDAY value %q", item))
+		}
+
+		day := byDay{weekday: weekday}
+		if ordinal := item[:len(item)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, invalidSchedule(fmt.Sprintf("invalid BYDAY value %q", item))
+			}
+			day.ordinal = n
+		}
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+// parseUntil reads a date, which includes the whole day, or a date and time in UTC when it ends in Z and in location
+// otherwise
+func parseUntil(value string, location *time.Location) (time.Time, error) {
+	if until, err := time.ParseInLocation("20060102", value, location); err == nil {
+		return until.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	if until, err := time.Parse("20060102T150405Z", value); err == nil {
+		return until, nil
+	}
+	if until, err := time.ParseInLocation("20060102T150405", value, location); err == nil {
+		return until, nil
+	}
+
+	return time.Time{}, invalidSchedule(fmt.Sprintf("UNTIL must be a date such as 20251231 or a time such as 20251231T235959Z, got %q", value))
+}
+
+func (rule *rrule) Next(after time.Time) (time.Time, bool) {
+	start := rule.start
+	first := 0
+	if rule.count == 0 {
+		// without a count nothing depends on the periods before after, they can be skipped
+		first = max(0, rule.periodsUntil(after)/rule.interval-1)
+	}
+
+	seen := 0
+	for period := first; ; period++ {
+		periodStart := rule.periodStart(period * rule.interval)
+		if periodStart.Sub(after) > horizon {
+			return time.Time{}, false
+		}
+
+		for _, occurrence := range rule.occurrences(periodStart) {
+			if occurrence.Before(start) {
+				continue
+			}
+			if !rule.until.IsZero() && occurrence.After(rule.until) {
+				return time.Time{}, false
+			}
+
+			seen++
+			if rule.count > 0 && seen > rule.count {
+				return time.Time{}, false
+			}
+			if occurrence.After(after) {
+				return occurrence, true
+			}
+		}
+	}
+}
+
+// periodStart returns the first day of the period n frequency units after the one start is in
+func (rule *rrule) periodStart(n int) time.Time {
+	year, month, day := rule.start.Date()
+	location := rule.start.Location()
+
+	switch rule.frequency {
+	case daily:
+		return time.Date(year, month, day+n, 0, 0, 0, 0, location)
+	case weekly:
+		monday := day - (int(rule.start.Weekday())+6)%7
+		return time.Date(year, month, monday+7*n, 0, 0, 0, 0, location)
+	case monthly:
+		return time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, location)
+	default:
+		return time.Date(year+n, time.January, 1, 0, 0, 0, 0, location)
+	}
+}
+
+// periodsUntil returns roughly how many frequency units there are from start to t, never more
+func (rule *rrule) periodsUntil(t time.Time) int {
+	if !t.After(rule.start) {
+		return 0
+	}
+
+	t = t.In(rule.start.Location())
+	switch rule.frequency {
+	case daily:
+		return int(t.Sub(rule.start).Hours()/24) - 1
+	case weekly:
+		return int(t.Sub(rule.start).Hours()/24/7) - 1
+	case monthly:
+		return (t.Year()-rule.start.Year())*12 + int(t.Month()) - int(rule.start.Month()) - 1
+	default:
+		return t.Year() - rule.start.Year() - 1
+	}
+}
+
+// occurrences lists the occurrences of the period starting at periodStart in order
+func (rule *rrule) occurrences(periodStart time.Time) []time.Time {
+	var dates []time.Time
+	switch rule.frequency {
+	case daily:
+		dates = []time.Time{periodStart}
+	case weekly:
+		for i := 0; i < 7; i++ {
+			date := periodStart.AddDate(0, 0, i)
+			if rule.days == nil && date.Weekday() == rule.start.Weekday() || rule.days != nil && rule.matchesWeekday(date) {
+				dates = append(dates, date)
+			}
+		}
+	case monthly:
+		dates = rule.monthDates(periodStart.Year(), periodStart.Month())
+	case yearly:
+		// days without months are picked in every month, a yearly rule with neither happens on the day of start
+		months := slices.Sorted(slices.Values(rule.months))
+		switch {
+		case months == nil && rule.monthDays == nil && rule.days == nil:
+			months = []time.Month{rule.start.Month()}
+		case months == nil:
+			for month := time.January; month <= time.December; month++ {
+				months = append(months, month)
+			}
+		}
+		for _, month := range months {
+			dates = append(dates, rule.monthDates(periodStart.Year(), month)...)
+		}
+	}
+
+	var occurrences []time.Time
+	for _, date := range dates {
+		if !rule.matchesMonth(date) || rule.frequency == daily && !(rule.matchesMonthDay(date) && rule.matchesWeekday(date)) {
+			continue
+		}
+
+		for _, hour := range orDefault(rule.hours, rule.start.Hour()) {
+			for _, minute := range orDefault(rule.minutes, rule.start.Minute()) {
+				occurrence := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, rule.start.Second(), 0, date.Location())
+				// times skipped by a daylight saving change don't happen
+				if occurrence.Hour() == hour && occurrence.Minute() == minute {
+					occurrences = append(occurrences, occurrence)
+				}
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// monthDates lists the days of month the rule picks, the day of the month of start when BYMONTHDAY and BYDAY are
+// both missing. Days that don't exist in month, such as the 31st of April, are skipped.
+func (rule *rrule) monthDates(year int, month time.Month) []time.Time {
+	location := rule.start.Location()
+	last := daysIn(year, month)
+
+	var dates []time.Time
+	for day := 1; day <= last; day++ {
+		date := time.Date(year, month, day, 0, 0, 0, 0, location)
+
+		var picked bool
+		switch {
+		case rule.monthDays == nil && rule.days == nil:
+			picked = day == rule.start.Day()
+		default:
+			picked = rule.matchesMonthDay(date) && rule.matchesWeekday(date)
+		}
+		if picked {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates
+}
+
+func (rule *rrule) matchesMonth(date time.Time) bool {
+	return rule.months == nil || slices.Contains(rule.months, date.Month())
+}
+
+func (rule *rrule) matchesMonthDay(date time.Time) bool {
+	if rule.monthDays == nil {
+		return true
+	}
+
+	last := daysIn(date.Year(), date.Month())
+	for _, day := range rule.monthDays {
+		if day == date.Day() || day < 0 && last+day+1 == date.Day() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesWeekday tells whether date is one of the BYDAY days, ordinals count the week days of its month
+func (rule *rrule) matchesWeekday(date time.Time) bool {
+	if rule.days == nil {
+		return true
+	}
+
+	last := daysIn(date.Year(), date.Month())
+	for _, day := range rule.days {
+		if day.weekday != date.Weekday() {
+			continue
+		}
+
+		switch {
+		case day.ordinal == 0:
+			return true
+		case day.ordinal > 0 && (date.Day()-1)/7+1 == day.ordinal:
+			return true
+		case day.ordinal < 0 && (last-date.Day())/7+1 == -day.ordinal:
+			return true
+		}
+	}
+
+	return false
+}
+
+func orDefault(values []int, fallback int) []int {
+	if values == nil {
+		return []int{fallback}
+	}
+
+	return values
+}
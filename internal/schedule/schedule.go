@@ -0,0 +1,47 @@
+// Package schedule reads the recurrence of standing orders, written either as a five field cron expression such as
+// "0 9 1 * *" or as an iCalendar RRULE such as "FREQ=MONTHLY;BYMONTHDAY=1".
+package schedule
+
+import (
+	"strings"
+	"time"
+
+	"http/internal/tberrors"
+)
+
+// horizon bounds how far ahead Next looks for an occurrence, schedules with none in sight are over
+const horizon = 10 * 366 * 24 * time.Hour
+
+// Schedule lists the times a recurrence happens at
+type Schedule interface {
+	// Next returns the first occurrence strictly after after, ok is false when there are no more
+	Next(after time.Time) (next time.Time, ok bool)
+}
+
+// Parse reads expr as an RRULE when it names a FREQ and as a cron expression otherwise. Occurrences are never
+// before start, they're computed in its location and RRULEs take their time of day and anchor from it like
+// DTSTART does.
+func Parse(expr string, start time.Time) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, emptyScheduleError
+	}
+
+	upper := strings.ToUpper(expr)
+	if strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ=") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"), start)
+	}
+
+	return parseCron(expr, start)
+}
+
+var emptyScheduleError = tberrors.NewValidationError("missing_schedule", "schedule is required", "schedule")
+
+func invalidSchedule(message string) error {
+	return tberrors.NewValidationError("invalid_schedule", message, "schedule")
+}
+
+// daysIn returns the number of days of month in year
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
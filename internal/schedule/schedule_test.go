@@ -0,0 +1,193 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/tberrors"
+)
+
+func TestParse_Next(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	start := utc(time.January, 15, 10, 30)
+
+	tests := []struct {
+		name  string
+		expr  string
+		start time.Time
+		want  []time.Time
+	}{
+		{
+			name:  "cron on the first of every month",
+			expr:  "0 9 1 * *",
+			start: start,
+			want:  []time.Time{utc(time.February, 1, 9, 0), utc(time.March, 1, 9, 0), utc(time.April, 1, 9, 0)},
+		},
+		{
+			name:  "cron with ranges, steps and names",
+			expr:  "*/30 9-10 * * mon-fri",
+			start: start,
+			want:  []time.Time{utc(time.January, 15, 10, 30), utc(time.January, 16, 9, 0), utc(time.January, 16, 9, 30), utc(time.January, 16, 10, 0), utc(time.January, 16, 10, 30)},
+		},
+		{
+			name:  "cron day of month or day of week",
+			expr:  "0 0 20 * SUN",
+			start: start,
+			want:  []time.Time{utc(time.January, 19, 0, 0), utc(time.January, 20, 0, 0), utc(time.January, 26, 0, 0)},
+		},
+		{
+			name:  "cron on days some months don't have",
+			expr:  "0 12 31 * *",
+			start: start,
+			want:  []time.Time{utc(time.January, 31, 12, 0), utc(time.March, 31, 12, 0), utc(time.May, 31, 12, 0)},
+		},
+		{
+			name:  "cron macro",
+			expr:  "@monthly",
+			start: start,
+			want:  []time.Time{utc(time.February, 1, 0, 0), utc(time.March, 1, 0, 0)},
+		},
+		{
+			name:  "cron in the time zone of start",
+			expr:  "0 9 * * *",
+			start: time.Date(2025, time.March, 29, 12, 0, 0, 0, lisbon),
+			want:  []time.Time{utc(time.March, 30, 8, 0), utc(time.March, 31, 8, 0)},
+		},
+		{
+			name:  "monthly rule takes its time of day from start",
+			expr:  "FREQ=MONTHLY;BYMONTHDAY=1",
+			start: start,
+			want:  []time.Time{utc(time.February, 1, 10, 30), utc(time.March, 1, 10, 30)},
+		},
+		{
+			name:  "rule on the last day of the month",
+			expr:  "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=8;BYMINUTE=0",
+			start: start,
+			want:  []time.Time{utc(time.January, 31, 8, 0), utc(time.February, 28, 8, 0), utc(time.March, 31, 8, 0)},
+		},
+		{
+			name:  "rule on the last friday of every other month",
+			expr:  "FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR",
+			start: start,
+			want:  []time.Time{utc(time.January, 31, 10, 30), utc(time.March, 28, 10, 30), utc(time.May, 30, 10, 30)},
+		},
+		{
+			name:  "monthly rule on the day of start skips short months",
+			expr:  "FREQ=MONTHLY",
+			start: utc(time.January, 31, 9, 0),
+			want:  []time.Time{utc(time.January, 31, 9, 0), utc(time.March, 31, 9, 0), utc(time.May, 31, 9, 0)},
+		},
+		{
+			name:  "weekly rule on several days",
+			expr:  "FREQ=WEEKLY;BYDAY=MO,TH",
+			start: start,
+			want:  []time.Time{utc(time.January, 16, 10, 30), utc(time.January, 20, 10, 30), utc(time.January, 23, 10, 30)},
+		},
+		{
+			name:  "weekly rule on the day of start",
+			expr:  "FREQ=WEEKLY;INTERVAL=2",
+			start: start,
+			want:  []time.Time{utc(time.January, 15, 10, 30), utc(time.January, 29, 10, 30), utc(time.February, 12, 10, 30)},
+		},
+		{
+			name:  "daily rule with a count",
+			expr:  "FREQ=DAILY;COUNT=2",
+			start: start,
+			want:  []time.Time{utc(time.January, 15, 10, 30), utc(time.January, 16, 10, 30)},
+		},
+		{
+			name:  "yearly rule until a date",
+			expr:  "FREQ=YEARLY;BYMONTH=1,7;BYMONTHDAY=15;UNTIL=20260115",
+			start: start,
+			want:  []time.Time{utc(time.January, 15, 10, 30), utc(time.July, 15, 10, 30), time.Date(2026, time.January, 15, 10, 30, 0, 0, time.UTC)},
+		},
+		{
+			name:  "cron that never happens",
+			expr:  "0 0 30 2 *",
+			start: start,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr, tt.start)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			var got []time.Time
+			after := tt.start.Add(-time.Nanosecond)
+			for len(got) < 5 {
+				next, ok := schedule.Next(after)
+				if !ok {
+					break
+				}
+				got = append(got, next.UTC())
+				after = next
+			}
+			if len(got) > len(tt.want) {
+				got = got[:len(tt.want)]
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Next() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRRule_NextSkipsAhead(t *testing.T) {
+	start := time.Date(2000, time.January, 1, 9, 0, 0, 0, time.UTC)
+	schedule, err := Parse("FREQ=DAILY", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after := time.Date(2025, time.June, 10, 12, 0, 0, 0, time.UTC)
+	got, ok := schedule.Next(after)
+
+	want := time.Date(2025, time.June, 11, 9, 0, 0, 0, time.UTC)
+	if !ok || !got.Equal(want) {
+		t.Errorf("Next() = %v, %t, want %v", got, ok, want)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"0 9 1 *",
+		"60 9 1 * *",
+		"0 9 0 * *",
+		"0 9 5-1 * *",
+		"0 9 */0 * *",
+		"0 9 1 FOO *",
+		"FREQ=HOURLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"FREQ=DAILY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYSETPOS=1",
+		"FREQ=MONTHLY;FREQ=DAILY",
+		"FREQ=MONTHLY;UNTIL=tomorrow",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr, time.Now())
+
+			var validationErr tberrors.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field() != "schedule" {
+				t.Errorf("Parse() error = %v, want a validation error of schedule", err)
+			}
+		})
+	}
+}
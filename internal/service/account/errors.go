@@ -1,10 +1,27 @@
 package account
 
-import "fmt"
+import (
+	"fmt"
+
+	"http/internal/tberrors"
+)
 
 var failedToCreateAccount = fmt.Errorf("failed to create account")
 var failedToPersistAccount = fmt.Errorf("failed to persist account")
 var failedToGetAccount = fmt.Errorf("failed to get  account")
-var failedToAddBalance = fmt.Errorf("failed to add balance")
-var invalidAccountID = fmt.Errorf("invalid account ID")
-var invalidUserID = fmt.Errorf("invalid user ID")
+var failedToSetOverdraft = fmt.Errorf("failed to set overdraft")
+var invalidAccountID = tberrors.NewValidationError("missing_account_id", "invalid account ID", "account_id")
+var invalidUserID = tberrors.NewValidationError("missing_user_id", "invalid user ID", "user_id")
+var accountNotFound = tberrors.NewNotFoundError("account_not_found", "account not found", "account_id")
+var failedToGetUser = fmt.Errorf("failed to get user")
+var failedToCloseAccount = fmt.Errorf("failed to close account")
+var failedToSweepAccount = fmt.Errorf("failed to sweep account")
+var failedToLockAccounts = tberrors.NewConflictError("account_busy", "failed to lock accounts, try again", "")
+var failedToBeginUnitOfWork = tberrors.NewInternalError("storage_failure", "failed to begin unit of work")
+var failedToCommit = tberrors.NewInternalError("storage_failure", "failed to commit account changes")
+var userNotFound = tberrors.NewNotFoundError("user_not_found", "user not found", "user_id")
+var userDeleted = tberrors.NewForbiddenError("user_deleted", "accounts can't be opened for a deleted user", "user_id")
+var sweepAccountNotFound = tberrors.NewNotFoundError("account_not_found", "sweep account not found", "sweep_account")
+var sweepAccountClosed = tberrors.NewConflictError("account_closed", "sweep account is closed", "sweep_account")
+var sweepCurrencyMismatch = tberrors.NewValidationError("currency_mismatch", "sweep account must hold the same currency", "sweep_account")
+var invalidSweepAccount = tberrors.NewValidationError("invalid_sweep_account", "an account can't be swept into itself", "sweep_account")
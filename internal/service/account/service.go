@@ -1,31 +1,65 @@
 package account
 
 import (
+	"context"
 	"errors"
+	"slices"
 	"time"
 
+	"http/internal/clock"
 	"http/internal/domain"
+	"http/internal/repository"
+	"http/internal/tberrors"
 )
 
+// lockTimeout bounds how long closing an account waits for its accounts to be free
+const lockTimeout = 5 * time.Second
+
 type accountRepository interface {
-	GetUserAccounts(userID string) []domain.Account
+	GetUserAccounts(userID string) ([]domain.Account, error)
 	Insert(acc *domain.Account) (*domain.Account, error)
-	UpdateBulk(acc []domain.Account)
 	Get(accID string) (*domain.Account, error)
 }
 
+type userRepository interface {
+	Get(userID string) (*domain.User, error)
+}
+
+type unitOfWorkFactory interface {
+	Begin(ctx context.Context) (repository.UnitOfWork, error)
+}
+
+type accountLocker interface {
+	Lock(ctx context.Context, keys ...string) (func(), error)
+}
+
 type Service struct {
 	accountRepository accountRepository
+	userRepository    userRepository
+	unitOfWorkFactory unitOfWorkFactory
+	accountLocker     accountLocker
+	clock             clock.Clock
 }
 
-func NewService(accountRepository accountRepository) *Service {
+func NewService(
+	accountRepository accountRepository,
+	userRepository userRepository,
+	unitOfWorkFactory unitOfWorkFactory,
+	accountLocker accountLocker,
+	clock clock.Clock,
+) *Service {
 	return &Service{
 		accountRepository: accountRepository,
+		userRepository:    userRepository,
+		unitOfWorkFactory: unitOfWorkFactory,
+		accountLocker:     accountLocker,
+		clock:             clock,
 	}
 }
 
-func (service Service) Create(userID string) error {
-	acc, err := domain.NewAccount(userID)
+// Create opens the checking account every user starts with, the user is being created and not stored yet
+func (service Service) Create(userID string, currency domain.Currency) error {
+	acc, err := domain.NewAccount(userID, currency, domain.Checking, "")
 	if err != nil {
 		return errors.Join(failedToCreateAccount, err)
 	}
@@ -38,35 +72,282 @@ func (service Service) Create(userID string) error {
 	return nil
 }
 
-func (service Service) DeleteUserAccounts(userID string) {
-	accs := service.accountRepository.GetUserAccounts(userID)
+// Open opens another account for an existing user
+func (service Service) Open(userID string, currency domain.Currency, accountType domain.AccountType, nickname string) (*domain.Account, error) {
+	if userID == "" {
+		return nil, invalidUserID
+	}
+
+	u, err := service.userRepository.Get(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(failedToGetUser, userNotFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetUser, err)
+	}
+
+	if u.DeletedAt != nil {
+		return nil, userDeleted
+	}
+
+	acc, err := domain.NewAccount(u.ID, currency, accountType, nickname)
+	if err != nil {
+		return nil, errors.Join(failedToCreateAccount, err)
+	}
+
+	acc, err = service.accountRepository.Insert(acc)
+	if err != nil {
+		return nil, errors.Join(failedToPersistAccount, err)
+	}
+
+	return acc, nil
+}
+
+// Close closes the account, an account holding money is only closed when sweepAccountID names another open
+// account of the same currency to move the balance to. The sweep transfer and the closing are committed together.
+func (service Service) Close(ctx context.Context, accountID, sweepAccountID string) error {
+	if accountID == "" {
+		return invalidAccountID
+	}
+
+	if sweepAccountID == accountID {
+		return invalidSweepAccount
+	}
+
+	lockKeys := []string{accountID}
+	if sweepAccountID != "" {
+		lockKeys = append(lockKeys, sweepAccountID)
+	}
+
+	unlock, err := service.lockAccounts(ctx, lockKeys...)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	uow, err := service.unitOfWorkFactory.Begin(ctx)
+	if err != nil {
+		return errors.Join(failedToBeginUnitOfWork, err)
+	}
+	defer uow.Rollback()
+
+	acc, err := getAccount(uow, accountID, accountNotFound)
+	if err != nil {
+		return err
+	}
+
+	now := service.clock.Now()
+
+	// held funds can't be swept, Close refuses the account until its holds are released
+	if sweepAccountID != "" && !acc.Closed() && acc.Balance.IsPositive() && acc.Held.Amount == 0 {
+		if err := sweep(uow, acc, sweepAccountID, now); err != nil {
+			return errors.Join(failedToSweepAccount, err)
+		}
+	}
+
+	if err := acc.Close(now); err != nil {
+		return errors.Join(failedToCloseAccount, err)
+	}
+
+	if err := uow.UpdateAccount(acc); err != nil {
+		return errors.Join(failedToPersistAccount, err)
+	}
+
+	if err := uow.Commit(); err != nil {
+		return errors.Join(failedToCommit, err)
+	}
+
+	return nil
+}
+
+// sweep stages a transfer of the whole balance of acc to the sweep account together with its journal entry
+func sweep(uow repository.UnitOfWork, acc *domain.Account, sweepAccountID string, now time.Time) error {
+	sweepAcc, err := getAccount(uow, sweepAccountID, sweepAccountNotFound)
+	if err != nil {
+		return err
+	}
 
-	for _, acc := range accs {
-		now := time.Now()
-		acc.DeletedAt = &now
+	if sweepAcc.Closed() {
+		return sweepAccountClosed
 	}
 
-	service.accountRepository.UpdateBulk(accs)
+	if sweepAcc.Balance.Currency != acc.Balance.Currency {
+		return sweepCurrencyMismatch
+	}
+
+	transaction, err := domain.NewTransfer(acc.ID, sweepAcc.ID, acc.Balance, now)
+	if err != nil {
+		return err
+	}
+
+	debit, err := transaction.Amount.Neg()
+	if err != nil {
+		return err
+	}
+
+	if err := acc.AddBalance(debit); err != nil {
+		return err
+	}
+
+	if err := sweepAcc.AddBalance(transaction.Amount); err != nil {
+		return err
+	}
+
+	if err := uow.UpdateAccount(sweepAcc); err != nil {
+		return err
+	}
+
+	if err := uow.InsertTransaction(transaction); err != nil {
+		return err
+	}
+
+	entry, err := domain.NewJournalEntry(transaction)
+	if err != nil {
+		return err
+	}
+
+	return uow.InsertJournalEntry(entry)
 }
 
-func (service Service) GetUserAccounts(userID string) ([]domain.Account, error) {
+// DeleteUserAccounts closes every open account of a deleted user at deletedAt, the user's deletion time, so
+// RestoreUserAccounts can tell them apart from accounts closed on their own. It returns the closed account IDs.
+func (service Service) DeleteUserAccounts(ctx context.Context, userID string, deletedAt time.Time) ([]string, error) {
+	return service.updateUserAccounts(ctx, userID, func(acc *domain.Account) bool {
+		if acc.Closed() {
+			return false
+		}
+
+		acc.DeletedAt = &deletedAt
+		return true
+	})
+}
+
+// RestoreUserAccounts reopens the accounts closed when the user was deleted at deletedAt and returns their IDs
+func (service Service) RestoreUserAccounts(ctx context.Context, userID string, deletedAt time.Time) ([]string, error) {
+	return service.updateUserAccounts(ctx, userID, func(acc *domain.Account) bool {
+		if !acc.Closed() || !acc.DeletedAt.Equal(deletedAt) {
+			return false
+		}
+
+		acc.Reopen()
+		return true
+	})
+}
+
+// updateUserAccounts locks every account of the user and applies update to each, the accounts update returns
+// true for are persisted together
+func (service Service) updateUserAccounts(ctx context.Context, userID string, update func(acc *domain.Account) bool) ([]string, error) {
 	if userID == "" {
 		return nil, invalidUserID
 	}
 
-	accounts := service.accountRepository.GetUserAccounts(userID)
+	accs, err := service.accountRepository.GetUserAccounts(userID)
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
 
-	return accounts, nil
+	accountIDs := make([]string, len(accs))
+	for i := range accs {
+		accountIDs[i] = accs[i].ID
+	}
+
+	unlock, err := service.lockAccounts(ctx, accountIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	uow, err := service.unitOfWorkFactory.Begin(ctx)
+	if err != nil {
+		return nil, errors.Join(failedToBeginUnitOfWork, err)
+	}
+	defer uow.Rollback()
+
+	updated := []string{}
+	for _, accountID := range accountIDs {
+		// the balance may have moved since the accounts were listed, only the locked copy is current
+		acc, err := getAccount(uow, accountID, accountNotFound)
+		if err != nil {
+			return nil, err
+		}
+
+		if !update(acc) {
+			continue
+		}
+
+		if err := uow.UpdateAccount(acc); err != nil {
+			return nil, errors.Join(failedToPersistAccount, err)
+		}
+		updated = append(updated, acc.ID)
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, errors.Join(failedToCommit, err)
+	}
+
+	return updated, nil
 }
 
-func (service Service) AddBalance(accountID string, balance int) (*domain.Account, error) {
-	acc, err := service.accountRepository.Get(accountID)
+// GetUserAccounts lists the user's accounts, closed ones only when returnDeleted is set
+func (service Service) GetUserAccounts(userID string, returnDeleted bool) ([]domain.Account, error) {
+	if userID == "" {
+		return nil, invalidUserID
+	}
+
+	accounts, err := service.accountRepository.GetUserAccounts(userID)
 	if err != nil {
 		return nil, errors.Join(failedToGetAccount, err)
 	}
 
-	if err := acc.AddBalance(balance); err != nil {
-		return nil, errors.Join(failedToAddBalance, err)
+	if !returnDeleted {
+		accounts = slices.DeleteFunc(accounts, func(acc domain.Account) bool {
+			return acc.Closed()
+		})
+	}
+
+	return accounts, nil
+}
+
+// SetOverdraft arranges an overdraft of limit, in the account's currency unless it names one, on the account charging
+// the yearly interest rate, a zero limit removes it. The account is locked so its balance can't move past the new
+// limit while it's being changed.
+func (service Service) SetOverdraft(ctx context.Context, accountID string, limit domain.Amount, rate string) (*domain.Account, error) {
+	if accountID == "" {
+		return nil, invalidAccountID
+	}
+
+	unlock, err := service.lockAccounts(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	uow, err := service.unitOfWorkFactory.Begin(ctx)
+	if err != nil {
+		return nil, errors.Join(failedToBeginUnitOfWork, err)
+	}
+	defer uow.Rollback()
+
+	acc, err := getAccount(uow, accountID, accountNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	money, err := limit.In(acc.Balance.Currency)
+	if err != nil {
+		return nil, errors.Join(failedToSetOverdraft, tberrors.Nested("limit", err))
+	}
+
+	if err := acc.SetOverdraft(money, rate, service.clock.Now()); err != nil {
+		return nil, errors.Join(failedToSetOverdraft, err)
+	}
+
+	if err := uow.UpdateAccount(acc); err != nil {
+		return nil, errors.Join(failedToPersistAccount, err)
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, errors.Join(failedToCommit, err)
 	}
 
 	return acc, nil
@@ -77,5 +358,43 @@ func (service Service) Get(accountID string) (*domain.Account, error) {
 		return nil, invalidAccountID
 	}
 
-	return service.accountRepository.Get(accountID)
+	acc, err := service.getAccount(accountID)
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
+
+	return acc, nil
+}
+
+func (service Service) lockAccounts(ctx context.Context, accountIDs ...string) (func(), error) {
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+
+	unlock, err := service.accountLocker.Lock(ctx, accountIDs...)
+	if err != nil {
+		return nil, errors.Join(failedToLockAccounts, err)
+	}
+
+	return unlock, nil
+}
+
+func getAccount(uow repository.UnitOfWork, accountID string, notFound error) (*domain.Account, error) {
+	acc, err := uow.GetAccount(accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(failedToGetAccount, notFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
+
+	return acc, nil
+}
+
+func (service Service) getAccount(accountID string) (*domain.Account, error) {
+	acc, err := service.accountRepository.Get(accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(accountNotFound, err)
+	}
+
+	return acc, err
 }
@@ -1,72 +1,54 @@
 package account
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"http/internal/clock"
 	"http/internal/domain"
+	"http/internal/lock"
+	"http/internal/repository"
 	"http/internal/repository/memory"
 )
 
-func TestService_AddBalance(t *testing.T) {
+func TestService_Create(t *testing.T) {
 	accountRepository := memory.NewAccountRepository()
-	accountRepository.Insert(&domain.Account{
-		ID:      "1",
-		Balance: 0,
-	})
-	accountRepository.Insert(&domain.Account{
-		ID:      "withBalance",
-		Balance: 100,
-	})
 
 	type args struct {
-		accountID string
-		balance   int
+		userID   string
+		currency domain.Currency
 	}
 	tests := []struct {
 		name    string
 		args    args
-		want    *domain.Account
 		wantErr error
 	}{
 		{
-			name: "successfully add balance",
+			name: "successfully create account",
 			args: args{
-				accountID: "1",
-				balance:   100,
-			},
-			want: &domain.Account{
-				ID:      "1",
-				Balance: 100,
+				userID:   "1",
+				currency: domain.USD,
 			},
 		},
 		{
-			name: "successfully subtract balance",
-			args: args{
-				accountID: "withBalance",
-				balance:   -100,
-			},
-			want: &domain.Account{
-				ID:      "withBalance",
-				Balance: 0,
-			},
-		},
-		{
-			name: "invalid account id, return failedToGetAccount",
+			name: "invalid user id, return failedToCreateAccount",
 			args: args{
-				accountID: "invalid",
-				balance:   100,
+				userID:   "",
+				currency: domain.EUR,
 			},
-			wantErr: failedToGetAccount,
+			wantErr: failedToCreateAccount,
 		},
 		{
-			name: "invalid balance, return failedToAddBalance",
+			name: "unsupported currency, return failedToCreateAccount",
 			args: args{
-				accountID: "1",
-				balance:   -1000,
+				userID:   "1",
+				currency: "XXX",
 			},
-			wantErr: failedToAddBalance,
+			wantErr: failedToCreateAccount,
 		},
 	}
 	for _, tt := range tests {
@@ -74,40 +56,70 @@ func TestService_AddBalance(t *testing.T) {
 			service := Service{
 				accountRepository: accountRepository,
 			}
-			got, err := service.AddBalance(tt.args.accountID, tt.args.balance)
-			if !errors.Is(err, tt.wantErr) {
-				t.Errorf("AddBalance() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-
-			if diff := cmp.Diff(tt.want, got); diff != "" {
-				t.Errorf("AddBalance() (-want +got):\n%s", diff)
+			if err := service.Create(tt.args.userID, tt.args.currency); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestService_Create(t *testing.T) {
-	accountRepository := memory.NewAccountRepository()
+func TestService_Open(t *testing.T) {
+	deletedAt := time.Now()
+	userRepository := memory.NewUserRepository()
+	userRepository.Insert(&domain.User{ID: "1", Name: "Ada"})
+	userRepository.Insert(&domain.User{ID: "deleted", Name: "Grace", DeletedAt: &deletedAt})
 
 	type args struct {
-		userID string
+		userID      string
+		currency    domain.Currency
+		accountType domain.AccountType
+		nickname    string
 	}
 	tests := []struct {
 		name    string
 		args    args
+		want    *domain.Account
 		wantErr error
 	}{
 		{
-			name: "successfully create account",
+			name: "successfully open savings account",
 			args: args{
-				userID: "1",
+				userID:      "1",
+				currency:    domain.USD,
+				accountType: domain.Savings,
+				nickname:    "Holidays",
+			},
+			want: &domain.Account{
+				UserID:   "1",
+				Balance:  domain.NewMoney(0, domain.USD),
+				Type:     domain.Savings,
+				Nickname: "Holidays",
 			},
 		},
 		{
-			name: "invalid user id, return failedToCreateAccount",
+			name: "unknown user, return userNotFound",
 			args: args{
-				userID: "",
+				userID:      "unknown",
+				currency:    domain.EUR,
+				accountType: domain.Checking,
+			},
+			wantErr: userNotFound,
+		},
+		{
+			name: "deleted user, return userDeleted",
+			args: args{
+				userID:      "deleted",
+				currency:    domain.EUR,
+				accountType: domain.Checking,
+			},
+			wantErr: userDeleted,
+		},
+		{
+			name: "unknown account type, return failedToCreateAccount",
+			args: args{
+				userID:      "1",
+				currency:    domain.EUR,
+				accountType: "brokerage",
 			},
 			wantErr: failedToCreateAccount,
 		},
@@ -115,10 +127,188 @@ func TestService_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			service := Service{
-				accountRepository: accountRepository,
+				accountRepository: memory.NewAccountRepository(),
+				userRepository:    userRepository,
 			}
-			if err := service.Create(tt.args.userID); !errors.Is(err, tt.wantErr) {
-				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			got, err := service.Open(tt.args.userID, tt.args.currency, tt.args.accountType, tt.args.nickname)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Open() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(domain.Account{}, "ID")); diff != "" {
+				t.Errorf("Open() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_Close(t *testing.T) {
+	closedAt := time.Now()
+
+	tests := []struct {
+		name           string
+		accountID      string
+		sweepAccountID string
+		wantErr        error
+		wantClosed     bool
+		wantBalances   map[string]domain.Money
+	}{
+		{
+			name:         "close empty account",
+			accountID:    "empty",
+			wantClosed:   true,
+			wantBalances: map[string]domain.Money{"empty": eur(0)},
+		},
+		{
+			name:           "close account with money, sweep the balance",
+			accountID:      "funded",
+			sweepAccountID: "sweep",
+			wantClosed:     true,
+			wantBalances:   map[string]domain.Money{"funded": eur(0), "sweep": eur(150)},
+		},
+		{
+			name:         "close account with money without sweep account, return failedToCloseAccount",
+			accountID:    "funded",
+			wantErr:      failedToCloseAccount,
+			wantBalances: map[string]domain.Money{"funded": eur(100)},
+		},
+		{
+			name:           "sweep into another currency, return sweepCurrencyMismatch",
+			accountID:      "funded",
+			sweepAccountID: "usd",
+			wantErr:        sweepCurrencyMismatch,
+			wantBalances:   map[string]domain.Money{"funded": eur(100), "usd": domain.NewMoney(0, domain.USD)},
+		},
+		{
+			name:           "sweep into a closed account, return sweepAccountClosed",
+			accountID:      "funded",
+			sweepAccountID: "closed",
+			wantErr:        sweepAccountClosed,
+			wantBalances:   map[string]domain.Money{"funded": eur(100)},
+		},
+		{
+			name:           "sweep into an unknown account, return sweepAccountNotFound",
+			accountID:      "funded",
+			sweepAccountID: "unknown",
+			wantErr:        sweepAccountNotFound,
+			wantBalances:   map[string]domain.Money{"funded": eur(100)},
+		},
+		{
+			name:           "sweep into itself, return invalidSweepAccount",
+			accountID:      "funded",
+			sweepAccountID: "funded",
+			wantErr:        invalidSweepAccount,
+			wantBalances:   map[string]domain.Money{"funded": eur(100)},
+		},
+		{
+			name:           "account with held funds, nothing is swept and return failedToCloseAccount",
+			accountID:      "held",
+			sweepAccountID: "sweep",
+			wantErr:        failedToCloseAccount,
+			wantBalances:   map[string]domain.Money{"held": eur(100), "sweep": eur(50)},
+		},
+		{
+			name:      "unknown account, return accountNotFound",
+			accountID: "unknown",
+			wantErr:   accountNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepository := memory.NewAccountRepository()
+			transactionRepository := memory.NewTransactionRepository()
+			ledgerRepository := memory.NewLedgerRepository()
+			accountRepository.Insert(&domain.Account{ID: "empty", UserID: "1", Balance: eur(0), Type: domain.Checking})
+			accountRepository.Insert(&domain.Account{ID: "funded", UserID: "1", Balance: eur(100), Type: domain.Checking})
+			accountRepository.Insert(&domain.Account{ID: "sweep", UserID: "1", Balance: eur(50), Type: domain.Savings})
+			accountRepository.Insert(&domain.Account{ID: "usd", UserID: "1", Balance: domain.NewMoney(0, domain.USD), Type: domain.Checking})
+			accountRepository.Insert(&domain.Account{ID: "closed", UserID: "1", Balance: eur(0), Type: domain.Checking, DeletedAt: &closedAt})
+			accountRepository.Insert(&domain.Account{ID: "held", UserID: "1", Balance: eur(100), Type: domain.Checking, Held: eur(30)})
+
+			service := NewService(
+				accountRepository,
+				memory.NewUserRepository(),
+				memory.NewUnitOfWorkFactory(accountRepository, transactionRepository, ledgerRepository, memory.NewHoldRepository(), memory.NewStandingOrderRepository()),
+				lock.NewManager(),
+				clock.NewManual(closedAt),
+			)
+
+			err := service.Close(context.Background(), tt.accountID, tt.sweepAccountID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Close() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for accountID, want := range tt.wantBalances {
+				acc, _ := accountRepository.Get(accountID)
+				if acc.Balance != want {
+					t.Errorf("account %s balance got = %v, want %v", accountID, acc.Balance, want)
+				}
+			}
+
+			if acc, err := accountRepository.Get(tt.accountID); err == nil && acc.Closed() != tt.wantClosed {
+				t.Errorf("account closed got = %v, want %v", acc.Closed(), tt.wantClosed)
+			} else if err == nil && tt.wantClosed && tt.wantErr == nil && !acc.DeletedAt.Equal(closedAt) {
+				t.Errorf("account closed at got = %v, want %v", acc.DeletedAt, closedAt)
+			}
+
+			// a sweep is a regular transfer, recorded and posted to the ledger
+			transactions, _ := transactionRepository.GetAccountTransactions(tt.accountID, repository.TransactionFilter{}, repository.Page[repository.TransactionCursor]{})
+			wantTransactions := 0
+			if tt.sweepAccountID != "" && tt.wantErr == nil {
+				wantTransactions = 1
+			}
+			if len(transactions) != wantTransactions {
+				t.Errorf("recorded transactions got = %v, want %v", len(transactions), wantTransactions)
+			}
+
+			total, _ := ledgerRepository.GetAccountTotal("sweep", domain.EUR)
+			if balance, _ := total.Balance(); wantTransactions == 1 && balance != eur(100) {
+				t.Errorf("sweep account ledger balance got = %v, want %v", balance, eur(100))
+			}
+		})
+	}
+}
+
+func TestService_SetOverdraft(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		accountID string
+		limit     domain.Money
+		rate      string
+		wantErr   error
+		wantLimit domain.Money
+	}{
+		{name: "arrange an overdraft", accountID: "funded", limit: eur(50000), rate: "0.12", wantLimit: eur(50000)},
+		{name: "shrink the limit of an overdrawn account", accountID: "overdrawn", limit: eur(300), rate: "0.1", wantLimit: eur(300)},
+		{name: "limit below the overdrawn balance, return failedToSetOverdraft", accountID: "overdrawn", limit: eur(299), wantErr: failedToSetOverdraft, wantLimit: eur(1000)},
+		{name: "rate of 12 rather than 0.12, return failedToSetOverdraft", accountID: "funded", limit: eur(100), rate: "12", wantErr: failedToSetOverdraft},
+		{name: "unknown account, return accountNotFound", accountID: "unknown", limit: eur(100), wantErr: accountNotFound},
+		{name: "empty account id, return invalidAccountID", limit: eur(100), wantErr: invalidAccountID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepository := memory.NewAccountRepository()
+			accountRepository.Insert(&domain.Account{ID: "funded", UserID: "1", Balance: eur(100), Type: domain.Checking})
+			accountRepository.Insert(&domain.Account{ID: "overdrawn", UserID: "1", Balance: eur(-300), Type: domain.Checking,
+				Overdraft: domain.Overdraft{Limit: eur(1000), Rate: "0.1", AccruedThrough: now.Truncate(24 * time.Hour)}})
+
+			service := NewService(
+				accountRepository,
+				memory.NewUserRepository(),
+				memory.NewUnitOfWorkFactory(accountRepository, memory.NewTransactionRepository(), memory.NewLedgerRepository(), memory.NewHoldRepository(), memory.NewStandingOrderRepository()),
+				lock.NewManager(),
+				clock.NewManual(now),
+			)
+
+			_, err := service.SetOverdraft(context.Background(), tt.accountID, tt.limit, tt.rate)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetOverdraft() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if acc, err := accountRepository.Get(tt.accountID); err == nil && acc.Overdraft.Limit != tt.wantLimit {
+				t.Errorf("overdraft limit got = %v, want %v", acc.Overdraft.Limit, tt.wantLimit)
 			}
 		})
 	}
@@ -128,7 +318,7 @@ func TestService_Get(t *testing.T) {
 	accountRepository := memory.NewAccountRepository()
 	accountRepository.Insert(&domain.Account{
 		ID:      "1",
-		Balance: 0,
+		Balance: eur(0),
 	})
 
 	type args struct {
@@ -147,7 +337,7 @@ func TestService_Get(t *testing.T) {
 			},
 			want: &domain.Account{
 				ID:      "1",
-				Balance: 0,
+				Balance: eur(0),
 			},
 		},
 		{
@@ -176,16 +366,25 @@ func TestService_Get(t *testing.T) {
 }
 
 func TestService_GetUserAccounts(t *testing.T) {
+	closedAt := time.Now()
 	userAccount := domain.Account{
 		ID:      "1",
 		UserID:  "1",
-		Balance: 0,
+		Balance: eur(0),
+	}
+	closedAccount := domain.Account{
+		ID:        "2",
+		UserID:    "1",
+		Balance:   eur(0),
+		DeletedAt: &closedAt,
 	}
 	accountRepository := memory.NewAccountRepository()
 	accountRepository.Insert(&userAccount)
+	accountRepository.Insert(&closedAccount)
 
 	type args struct {
-		userID string
+		userID        string
+		returnDeleted bool
 	}
 	tests := []struct {
 		name    string
@@ -194,7 +393,7 @@ func TestService_GetUserAccounts(t *testing.T) {
 		wantErr error
 	}{
 		{
-			name: "successfully get accounts",
+			name: "successfully get open accounts",
 			args: args{
 				userID: "1",
 			},
@@ -202,6 +401,17 @@ func TestService_GetUserAccounts(t *testing.T) {
 				userAccount,
 			},
 		},
+		{
+			name: "successfully get accounts including closed ones",
+			args: args{
+				userID:        "1",
+				returnDeleted: true,
+			},
+			want: []domain.Account{
+				userAccount,
+				closedAccount,
+			},
+		},
 		{
 			name: "empty user id, return invalidUserID",
 			args: args{
@@ -215,14 +425,74 @@ func TestService_GetUserAccounts(t *testing.T) {
 			service := Service{
 				accountRepository: accountRepository,
 			}
-			got, err := service.GetUserAccounts(tt.args.userID)
+			got, err := service.GetUserAccounts(tt.args.userID, tt.args.returnDeleted)
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("GetUserAccounts() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if diff := cmp.Diff(tt.want, got); diff != "" {
+			sortByID := cmpopts.SortSlices(func(a, b domain.Account) bool { return a.ID < b.ID })
+			if diff := cmp.Diff(tt.want, got, sortByID); diff != "" {
 				t.Errorf("GetUserAccounts() (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
+
+func TestService_DeleteAndRestoreUserAccounts(t *testing.T) {
+	closedBefore := time.Now().Add(-time.Hour)
+	deletedAt := time.Now()
+
+	accountRepository := memory.NewAccountRepository()
+	accountRepository.Insert(&domain.Account{ID: "open", UserID: "1", Balance: eur(100), Type: domain.Checking})
+	accountRepository.Insert(&domain.Account{ID: "closed", UserID: "1", Balance: eur(0), Type: domain.Savings, DeletedAt: &closedBefore})
+	accountRepository.Insert(&domain.Account{ID: "other", UserID: "2", Balance: eur(0), Type: domain.Checking})
+
+	service := NewService(
+		accountRepository,
+		memory.NewUserRepository(),
+		memory.NewUnitOfWorkFactory(accountRepository, memory.NewTransactionRepository(), memory.NewLedgerRepository(), memory.NewHoldRepository(), memory.NewStandingOrderRepository()),
+		lock.NewManager(),
+		clock.System{},
+	)
+
+	deleted, err := service.DeleteUserAccounts(context.Background(), "1", deletedAt)
+	if err != nil {
+		t.Fatalf("DeleteUserAccounts() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"open"}, deleted); diff != "" {
+		t.Errorf("DeleteUserAccounts() (-want +got):\n%s", diff)
+	}
+
+	wantDeletedAt := map[string]*time.Time{"open": &deletedAt, "closed": &closedBefore, "other": nil}
+	for accountID, want := range wantDeletedAt {
+		acc, _ := accountRepository.Get(accountID)
+		if diff := cmp.Diff(want, acc.DeletedAt); diff != "" {
+			t.Errorf("account %s deleted at after delete (-want +got):\n%s", accountID, diff)
+		}
+	}
+
+	// the balance is kept so the money is still there once the user is restored
+	if acc, _ := accountRepository.Get("open"); acc.Balance != eur(100) {
+		t.Errorf("account open balance got = %v, want %v", acc.Balance, eur(100))
+	}
+
+	restored, err := service.RestoreUserAccounts(context.Background(), "1", deletedAt)
+	if err != nil {
+		t.Fatalf("RestoreUserAccounts() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"open"}, restored); diff != "" {
+		t.Errorf("RestoreUserAccounts() (-want +got):\n%s", diff)
+	}
+
+	wantDeletedAt["open"] = nil
+	for accountID, want := range wantDeletedAt {
+		acc, _ := accountRepository.Get(accountID)
+		if diff := cmp.Diff(want, acc.DeletedAt); diff != "" {
+			t.Errorf("account %s deleted at after restore (-want +got):\n%s", accountID, diff)
+		}
+	}
+}
+
+func eur(amount int64) domain.Money {
+	return domain.NewMoney(amount, domain.EUR)
+}
@@ -0,0 +1,20 @@
+package accrual
+
+import (
+	"errors"
+
+	"http/internal/tberrors"
+)
+
+var failedToAccrueInterest = errors.New("failed to accrue interest")
+var failedToPostInterest = errors.New("failed to post interest")
+var failedToGetAccount = errors.New("failed to get account")
+var failedToGetBalances = errors.New("failed to get end of day balances")
+var failedToCreateJournalEntry = errors.New("failed to create journal entry")
+var failedToGetAccounts = tberrors.NewInternalError("storage_failure", "failed to get accounts")
+var failedToLockAccount = tberrors.NewConflictError("account_busy", "failed to lock account, try again", "")
+var failedToBeginUnitOfWork = tberrors.NewInternalError("storage_failure", "failed to begin unit of work")
+var failedToCommit = tberrors.NewInternalError("storage_failure", "failed to commit interest")
+var failedToPersistAccount = tberrors.NewInternalError("storage_failure", "failed to persist account")
+var failedToInsertTransaction = tberrors.NewInternalError("storage_failure", "failed to insert transaction")
+var failedToInsertJournalEntry = tberrors.NewInternalError("storage_failure", "failed to insert journal entry")
@@ -0,0 +1,228 @@
+// Package accrual catches accounts up on interest that accrues every day from the balance they ended it with and is
+// posted once a month. The overdraft and savings interest services tell it which interest through Interest.
+package accrual
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+// lockTimeout bounds how long accruing waits for an account to be free
+const lockTimeout = 5 * time.Second
+
+// accountBatchSize is how many accounts are read at a time when looking for the ones accruing interest
+const accountBatchSize = 500
+
+// Interest is the kind of interest an account accrues, such as the debit interest of its overdraft
+type Interest interface {
+	// Accrues tells whether acc accrues this interest at all
+	Accrues(acc *domain.Account) bool
+	// AccruedThrough is the last day acc accrued
+	AccruedThrough(acc *domain.Account) time.Time
+	// Accrue accrues one day for each of balances, the first is the day after AccruedThrough
+	Accrue(acc *domain.Account, balances []domain.Money) error
+	// Post returns the transaction posting what acc accrued at postedAt with the last day accrued as its value date,
+	// nil when it rounds to nothing
+	Post(acc *domain.Account, postedAt time.Time) (*domain.Transaction, error)
+}
+
+type unitOfWorkFactory interface {
+	Begin(ctx context.Context) (repository.UnitOfWork, error)
+}
+
+type accountRepository interface {
+	Get(accID string) (*domain.Account, error)
+	GetAll(page repository.Page[string]) ([]domain.Account, error)
+}
+
+type balanceHistory interface {
+	GetEndOfDayBalances(accountID string, from, to time.Time) ([]domain.Money, error)
+}
+
+type accountLocker interface {
+	Lock(ctx context.Context, keys ...string) (func(), error)
+}
+
+// Service accrues interest every day and posts it once a month. Days are UTC days.
+type Service struct {
+	unitOfWorkFactory unitOfWorkFactory
+	accountRepository accountRepository
+	balanceHistory    balanceHistory
+	accountLocker     accountLocker
+	clock             clock.Clock
+	interest          Interest
+}
+
+func NewService(
+	unitOfWorkFactory unitOfWorkFactory,
+	accountRepository accountRepository,
+	balanceHistory balanceHistory,
+	accountLocker accountLocker,
+	clock clock.Clock,
+	interest Interest,
+) *Service {
+	return &Service{
+		unitOfWorkFactory: unitOfWorkFactory,
+		accountRepository: accountRepository,
+		balanceHistory:    balanceHistory,
+		accountLocker:     accountLocker,
+		clock:             clock,
+		interest:          interest,
+	}
+}
+
+// AccrueInterest accrues every day that ended since an account last accrued and posts the interest of every month
+// that ended with them. It returns how many accounts accrued, an account that fails is left for the next run and
+// doesn't stop the others.
+func (service *Service) AccrueInterest(ctx context.Context) (int, error) {
+	today := service.clock.Now().UTC().Truncate(24 * time.Hour)
+
+	accrued := 0
+	var errs []error
+	page := repository.Page[string]{Limit: accountBatchSize}
+	for {
+		accounts, err := service.accountRepository.GetAll(page)
+		if err != nil {
+			return accrued, errors.Join(append(errs, failedToGetAccounts, err)...)
+		}
+
+		for _, acc := range accounts {
+			if !service.due(&acc, today) {
+				continue
+			}
+
+			if err := service.accrue(ctx, acc.ID, today); err != nil {
+				errs = append(errs, fmt.Errorf("account %s: %w", acc.ID, err))
+				continue
+			}
+			accrued++
+		}
+
+		if len(accounts) < accountBatchSize {
+			return accrued, errors.Join(errs...)
+		}
+		page.After = &accounts[len(accounts)-1].ID
+	}
+}
+
+// due tells whether acc has days that ended before today left to accrue
+func (service *Service) due(acc *domain.Account, today time.Time) bool {
+	return service.interest.Accrues(acc) && service.interest.AccruedThrough(acc).AddDate(0, 0, 1).Before(today)
+}
+
+// accrue catches an account up to the day before today a month at a time, so the balances of a month include the
+// interest posted for the month before
+func (service *Service) accrue(ctx context.Context, accountID string, today time.Time) error {
+	unlock, err := service.lockAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for {
+		done, err := service.accrueMonth(ctx, accountID, today)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// accrueMonth accrues the days left of the month of the first day the account didn't accrue yet, up to the day before
+// today. When that ends the month its interest is posted now, valued on the last day of the month. It tells whether the
+// account accrued every day before today.
+func (service *Service) accrueMonth(ctx context.Context, accountID string, today time.Time) (bool, error) {
+	acc, err := service.accountRepository.Get(accountID)
+	if err != nil {
+		return false, errors.Join(failedToGetAccount, err)
+	}
+	if !service.due(acc, today) {
+		return true, nil
+	}
+
+	from := service.interest.AccruedThrough(acc).AddDate(0, 0, 1)
+	yesterday := today.AddDate(0, 0, -1)
+	nextMonth := time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	through := nextMonth.AddDate(0, 0, -1)
+	if through.After(yesterday) {
+		through = yesterday
+	}
+
+	// the history is read before the unit of work begins, the account being locked keeps it from moving meanwhile
+	balances, err := service.balanceHistory.GetEndOfDayBalances(accountID, from, through)
+	if err != nil {
+		return false, errors.Join(failedToGetBalances, err)
+	}
+
+	uow, err := service.unitOfWorkFactory.Begin(ctx)
+	if err != nil {
+		return false, errors.Join(failedToBeginUnitOfWork, err)
+	}
+	defer uow.Rollback()
+
+	acc, err = uow.GetAccount(accountID)
+	if err != nil {
+		return false, errors.Join(failedToGetAccount, err)
+	}
+
+	if err := service.interest.Accrue(acc, balances); err != nil {
+		return false, errors.Join(failedToAccrueInterest, err)
+	}
+
+	if service.interest.AccruedThrough(acc).Equal(nextMonth.AddDate(0, 0, -1)) {
+		if err := service.post(uow, acc, service.clock.Now()); err != nil {
+			return false, errors.Join(failedToPostInterest, err)
+		}
+	}
+
+	if err := uow.UpdateAccount(acc); err != nil {
+		return false, errors.Join(failedToPersistAccount, err)
+	}
+
+	if err := uow.Commit(); err != nil {
+		return false, errors.Join(failedToCommit, err)
+	}
+
+	return through.Equal(yesterday), nil
+}
+
+// post stages the transaction of the interest acc accrued with its journal entry, nothing is posted when it rounds to
+// nothing
+func (service *Service) post(uow repository.UnitOfWork, acc *domain.Account, postedAt time.Time) error {
+	transaction, err := service.interest.Post(acc, postedAt)
+	if err != nil || transaction == nil {
+		return err
+	}
+
+	if err := uow.InsertTransaction(transaction); err != nil {
+		return errors.Join(failedToInsertTransaction, err)
+	}
+
+	entry, err := domain.NewJournalEntry(transaction)
+	if err != nil {
+		return errors.Join(failedToCreateJournalEntry, err)
+	}
+
+	if err := uow.InsertJournalEntry(entry); err != nil {
+		return errors.Join(failedToInsertJournalEntry, err)
+	}
+
+	return nil
+}
+
+func (service *Service) lockAccount(ctx context.Context, accountID string) (func(), error) {
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+
+	unlock, err := service.accountLocker.Lock(ctx, accountID)
+	if err != nil {
+		return nil, errors.Join(failedToLockAccount, err)
+	}
+
+	return unlock, nil
+}
@@ -0,0 +1,25 @@
+package batch
+
+import (
+	"errors"
+
+	"http/internal/tberrors"
+)
+
+var failedToCreateBatch = errors.New("failed to create batch")
+var failedToGetAccount = errors.New("failed to get account")
+var emptyAccountID = tberrors.NewValidationError("missing_account_id", "invalid empty account ID", "account_id")
+var accountNotFound = tberrors.NewNotFoundError("account_not_found", "account not found", "account_id")
+var accountClosed = tberrors.NewForbiddenError("account_closed", "account is closed", "account_id")
+var invalidBatchID = tberrors.NewValidationError("missing_batch_id", "invalid empty batch ID", "batch_id")
+var batchNotFound = tberrors.NewNotFoundError("batch_not_found", "batch not found", "batch_id")
+var failedToGetBatch = tberrors.NewInternalError("storage_failure", "failed to get batch")
+var failedToInsertBatch = tberrors.NewInternalError("storage_failure", "failed to insert batch")
+var failedToUpdateBatch = tberrors.NewInternalError("storage_failure", "failed to update batch")
+var failedToUpdateBatchLine = tberrors.NewInternalError("storage_failure", "failed to update batch line")
+
+// line errors are reported under the field of the line they belong to
+
+var lineAccountNotFound = tberrors.NewValidationError("account_not_found", "account not found", "to_account")
+var lineAccountClosed = tberrors.NewValidationError("account_closed", "account is closed", "to_account")
+var lineCurrencyMismatch = tberrors.NewValidationError("currency_mismatch", "amount must be in the currency of the debited account", "currency")
@@ -0,0 +1,227 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/repository"
+	"http/internal/service/transaction"
+	"http/internal/tberrors"
+)
+
+type batchRepository interface {
+	Insert(batch *domain.Batch) (*domain.Batch, error)
+	Get(batchID string) (*domain.Batch, error)
+	Update(batch *domain.Batch) (*domain.Batch, error)
+	UpdateLine(batchID string, line *domain.BatchLine) (*domain.BatchLine, error)
+}
+
+type accountRepository interface {
+	Get(accID string) (*domain.Account, error)
+}
+
+type transferService interface {
+	Transfer(ctx context.Context, fromAccountID, toAccountID string, amount domain.Amount) (*domain.Transaction, error)
+	TransferAll(ctx context.Context, fromAccountID string, orders []transaction.TransferOrder) ([]*domain.Transaction, error)
+}
+
+type Service struct {
+	batchRepository   batchRepository
+	accountRepository accountRepository
+	transferService   transferService
+	clock             clock.Clock
+}
+
+func NewService(batchRepository batchRepository, accountRepository accountRepository, transferService transferService, clock clock.Clock) *Service {
+	return &Service{
+		batchRepository:   batchRepository,
+		accountRepository: accountRepository,
+		transferService:   transferService,
+		clock:             clock,
+	}
+}
+
+// Submit checks every line of a batch of transfers out of accountID before making any of them, a batch with invalid
+// lines is rejected as a whole. Amounts that name no currency are in the one of the account. Accepted batches are
+// stored and then made according to mode, the returned batch tells what happened to every line. A batch whose outcome
+// couldn't be stored once its transfers were made is returned along with the error, it must not be submitted again.
+func (service *Service) Submit(ctx context.Context, accountID string, mode domain.BatchMode, transfers []domain.BatchTransfer) (*domain.Batch, error) {
+	from, err := service.getDebitedAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := domain.NewBatch(accountID, from.Balance.Currency, mode, transfers, service.clock.Now())
+	if err != nil {
+		return nil, errors.Join(failedToCreateBatch, err)
+	}
+
+	if err := service.validateLines(batch, from); err != nil {
+		return nil, err
+	}
+
+	if _, err := service.batchRepository.Insert(batch); err != nil {
+		return nil, errors.Join(failedToInsertBatch, err)
+	}
+
+	// once accepted the batch runs to the end even if the client goes away, half made batches are what
+	// all-or-nothing is there to avoid
+	ctx = context.WithoutCancel(ctx)
+	var lineErrs []error
+	if batch.Mode == domain.AllOrNothing {
+		service.transferAll(ctx, batch)
+	} else {
+		lineErrs = service.transferEach(ctx, batch)
+	}
+	batch.Finish(service.clock.Now())
+
+	// the batch is stored with every line, lines that failed to be stored as they were made are stored along
+	if _, err := service.batchRepository.Update(batch); err != nil {
+		return batch, errors.Join(append([]error{failedToUpdateBatch, err}, lineErrs...)...)
+	}
+
+	return batch, nil
+}
+
+func (service *Service) Get(batchID string) (*domain.Batch, error) {
+	if batchID == "" {
+		return nil, invalidBatchID
+	}
+
+	batch, err := service.batchRepository.Get(batchID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(batchNotFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetBatch, err)
+	}
+
+	return batch, nil
+}
+
+func (service *Service) getDebitedAccount(accountID string) (*domain.Account, error) {
+	if accountID == "" {
+		return nil, errors.Join(failedToCreateBatch, emptyAccountID)
+	}
+
+	from, err := service.accountRepository.Get(accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(failedToGetAccount, accountNotFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
+	if from.Closed() {
+		return nil, errors.Join(failedToGetAccount, accountClosed)
+	}
+
+	return from, nil
+}
+
+// validateLines checks every line is in the currency of from and credits an account that exists and is open, every
+// invalid line is reported at once
+func (service *Service) validateLines(batch *domain.Batch, from *domain.Account) error {
+	// payroll files credit the same accounts over and over, each is only read once
+	accountErrs := make(map[string]error)
+	var errs []error
+	for _, line := range batch.Lines {
+		var lineErrs []error
+		if line.Amount.Currency != from.Balance.Currency {
+			lineErrs = append(lineErrs, lineCurrencyMismatch)
+		}
+
+		accountErr, ok := accountErrs[line.ToAccountID]
+		if !ok {
+			if accountErr = service.validateCreditedAccount(line.ToAccountID); accountErr != nil && !isValidation(accountErr) {
+				return accountErr
+			}
+			accountErrs[line.ToAccountID] = accountErr
+		}
+		if accountErr != nil {
+			lineErrs = append(lineErrs, accountErr)
+		}
+
+		if len(lineErrs) > 0 {
+			errs = append(errs, tberrors.Nested(domain.LineField(line.Number), errors.Join(lineErrs...)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (service *Service) validateCreditedAccount(accountID string) error {
+	acc, err := service.accountRepository.Get(accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return lineAccountNotFound
+	}
+	if err != nil {
+		return errors.Join(failedToGetAccount, err)
+	}
+	if acc.Closed() {
+		return lineAccountClosed
+	}
+
+	return nil
+}
+
+func isValidation(err error) bool {
+	var validationErr tberrors.ValidationError
+	return errors.As(err, &validationErr)
+}
+
+// transferAll makes every line in a single unit of work, when a line fails the others are cancelled
+func (service *Service) transferAll(ctx context.Context, batch *domain.Batch) {
+	orders := make([]transaction.TransferOrder, len(batch.Lines))
+	for i, line := range batch.Lines {
+		orders[i] = transaction.TransferOrder{ToAccountID: line.ToAccountID, Amount: line.Amount}
+	}
+
+	transactions, err := service.transferService.TransferAll(ctx, batch.AccountID, orders)
+	if err == nil {
+		for i, made := range transactions {
+			batch.Complete(i, made.ID)
+		}
+		return
+	}
+
+	code, reason := tberrors.Describe(err)
+	var transferErr transaction.TransferError
+	if !errors.As(err, &transferErr) {
+		// nothing points at a line, the unit of work as a whole failed
+		for i := range batch.Lines {
+			batch.Fail(i, code, reason)
+		}
+		return
+	}
+
+	for i := range batch.Lines {
+		if i == transferErr.Index {
+			batch.Fail(i, code, reason)
+		} else {
+			batch.Cancel(i, batch.Lines[transferErr.Index].Number)
+		}
+	}
+}
+
+// transferEach makes every line on its own, a failing line doesn't stop the ones after it. Every line is stored as
+// soon as it's made or failed, so the batch tells which transfers were made while it runs. It returns the errors of
+// the lines that failed to be stored.
+func (service *Service) transferEach(ctx context.Context, batch *domain.Batch) []error {
+	var errs []error
+	for i, line := range batch.Lines {
+		made, err := service.transferService.Transfer(ctx, batch.AccountID, line.ToAccountID, line.Amount)
+		if err != nil {
+			code, reason := tberrors.Describe(err)
+			batch.Fail(i, code, reason)
+		} else {
+			batch.Complete(i, made.ID)
+		}
+
+		if _, err := service.batchRepository.UpdateLine(batch.ID, &batch.Lines[i]); err != nil {
+			errs = append(errs, errors.Join(failedToUpdateBatchLine, err))
+		}
+	}
+
+	return errs
+}
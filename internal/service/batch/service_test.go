@@ -0,0 +1,281 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/repository"
+	"http/internal/repository/memory"
+	"http/internal/service/servicetest"
+	"http/internal/service/transaction"
+	"http/internal/tberrors"
+)
+
+var submittedAt = time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+func TestService_Submit(t *testing.T) {
+	closedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lines := func(amounts ...int64) []domain.BatchTransfer {
+		var lines []domain.BatchTransfer
+		for i, amount := range amounts {
+			lines = append(lines, domain.BatchTransfer{ToAccountID: []string{"2", "3"}[i%2], Amount: eur(amount)})
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name         string
+		mode         domain.BatchMode
+		lines        []domain.BatchTransfer
+		wantStatus   domain.BatchStatus
+		wantLines    []domain.BatchLineStatus
+		wantReasons  []string
+		wantBalances map[string]int64
+	}{
+		{
+			name:         "all or nothing, every line made",
+			mode:         domain.AllOrNothing,
+			lines:        lines(30, 50, 20),
+			wantStatus:   domain.BatchCompleted,
+			wantLines:    []domain.BatchLineStatus{domain.BatchLineCompleted, domain.BatchLineCompleted, domain.BatchLineCompleted},
+			wantReasons:  []string{"", "", ""},
+			wantBalances: map[string]int64{"1": 0, "2": 50, "3": 50},
+		},
+		{
+			name:         "all or nothing, a line overdraws and nothing is made",
+			mode:         domain.AllOrNothing,
+			lines:        lines(30, 50, 40),
+			wantStatus:   domain.BatchFailed,
+			wantLines:    []domain.BatchLineStatus{domain.BatchLineCancelled, domain.BatchLineCancelled, domain.BatchLineFailed},
+			wantReasons:  []string{"batch_failed", "batch_failed", "insufficient_funds"},
+			wantBalances: map[string]int64{"1": 100, "2": 0, "3": 0},
+		},
+		{
+			name:         "best effort, a line overdraws and the others are made",
+			mode:         domain.BestEffort,
+			lines:        lines(30, 80, 40),
+			wantStatus:   domain.BatchPartiallyCompleted,
+			wantLines:    []domain.BatchLineStatus{domain.BatchLineCompleted, domain.BatchLineFailed, domain.BatchLineCompleted},
+			wantReasons:  []string{"", "insufficient_funds", ""},
+			wantBalances: map[string]int64{"1": 30, "2": 70, "3": 0},
+		},
+		{
+			name:         "best effort, every line overdraws",
+			mode:         domain.BestEffort,
+			lines:        lines(101, 200),
+			wantStatus:   domain.BatchFailed,
+			wantLines:    []domain.BatchLineStatus{domain.BatchLineFailed, domain.BatchLineFailed},
+			wantReasons:  []string{"insufficient_funds", "insufficient_funds"},
+			wantBalances: map[string]int64{"1": 100, "2": 0, "3": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(100)},
+				domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+				domain.Account{ID: "3", UserID: "3", Balance: eur(0)},
+			)
+
+			got, err := bank.service.Submit(context.Background(), "1", tt.mode, tt.lines)
+			if err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+
+			if got.Status != tt.wantStatus {
+				t.Errorf("Submit() status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if !got.CreatedAt.Equal(submittedAt) || got.CompletedAt == nil || !got.CompletedAt.Equal(submittedAt) {
+				t.Errorf("Submit() created at %v and completed at %v, want both at %v", got.CreatedAt, got.CompletedAt, submittedAt)
+			}
+			var gotLines []domain.BatchLineStatus
+			var gotReasons []string
+			for _, line := range got.Lines {
+				gotLines = append(gotLines, line.Status)
+				gotReasons = append(gotReasons, line.FailureCode)
+				if (line.Status == domain.BatchLineCompleted) != (line.TransactionID != "") {
+					t.Errorf("Submit() line %d is %s with transaction %q", line.Number, line.Status, line.TransactionID)
+				}
+			}
+			if diff := cmp.Diff(tt.wantLines, gotLines); diff != "" {
+				t.Errorf("Submit() line statuses (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReasons, gotReasons); diff != "" {
+				t.Errorf("Submit() line failure codes (-want +got):\n%s", diff)
+			}
+
+			for accountID, want := range tt.wantBalances {
+				acc, _ := bank.Accounts.Get(accountID)
+				if acc.Balance != eur(want) {
+					t.Errorf("account %s balance = %v, want %v", accountID, acc.Balance, eur(want))
+				}
+			}
+
+			stored, err := bank.service.Get(got.ID)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if diff := cmp.Diff(got, stored); diff != "" {
+				t.Errorf("Get() (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid lines, want every one reported and nothing made", func(t *testing.T) {
+		bank := newTestBank(
+			domain.Account{ID: "1", UserID: "1", Balance: eur(100)},
+			domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+			domain.Account{ID: "closed", UserID: "3", Balance: eur(0), DeletedAt: &closedAt},
+		)
+		lines := []domain.BatchTransfer{
+			{ToAccountID: "2", Amount: eur(10)},
+			{ToAccountID: "unknown", Amount: eur(10)},
+			{ToAccountID: "closed", Amount: domain.NewMoney(10, domain.USD)},
+			{ToAccountID: "unknown", Amount: eur(10)},
+		}
+
+		_, err := bank.service.Submit(context.Background(), "1", domain.AllOrNothing, lines)
+
+		want := []string{"lines[2].to_account", "lines[3].currency", "lines[3].to_account", "lines[4].to_account"}
+		if diff := cmp.Diff(want, validationFields(err)); diff != "" {
+			t.Errorf("Submit() error = %v, fields (-want +got):\n%s", err, diff)
+		}
+		acc, _ := bank.Accounts.Get("1")
+		if acc.Balance != eur(100) {
+			t.Errorf("account 1 balance = %v, want it untouched", acc.Balance)
+		}
+	})
+
+	t.Run("amounts without a currency, want them in the one of the debited account", func(t *testing.T) {
+		usd := func(amount int64) domain.Money { return domain.NewMoney(amount, domain.USD) }
+		bank := newTestBank(
+			domain.Account{ID: "1", UserID: "1", Balance: usd(100)},
+			domain.Account{ID: "2", UserID: "2", Balance: usd(0)},
+		)
+		lines := []domain.BatchTransfer{{ToAccountID: "2", Amount: domain.DecimalAmount("0.30")}, {ToAccountID: "2", Amount: domain.DecimalAmount("0.001")}}
+
+		_, err := bank.service.Submit(context.Background(), "1", domain.AllOrNothing, lines)
+		if diff := cmp.Diff([]string{"lines[2].amount"}, validationFields(err)); diff != "" {
+			t.Errorf("Submit() error = %v, fields (-want +got):\n%s", err, diff)
+		}
+
+		got, err := bank.service.Submit(context.Background(), "1", domain.AllOrNothing, lines[:1])
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		if got.Lines[0].Amount != usd(30) {
+			t.Errorf("Submit() line amount = %v, want %v", got.Lines[0].Amount, usd(30))
+		}
+		if acc, _ := bank.Accounts.Get("2"); acc.Balance != usd(30) {
+			t.Errorf("account 2 balance = %v, want %v", acc.Balance, usd(30))
+		}
+	})
+
+	t.Run("debited account", func(t *testing.T) {
+		bank := newTestBank(domain.Account{ID: "closed", UserID: "1", Balance: eur(100), DeletedAt: &closedAt})
+		lines := []domain.BatchTransfer{{ToAccountID: "2", Amount: eur(10)}}
+
+		if _, err := bank.service.Submit(context.Background(), "unknown", domain.BestEffort, lines); !errors.Is(err, accountNotFound) {
+			t.Errorf("Submit() unknown account error = %v, wantErr %v", err, accountNotFound)
+		}
+		if _, err := bank.service.Submit(context.Background(), "closed", domain.BestEffort, lines); !errors.Is(err, accountClosed) {
+			t.Errorf("Submit() closed account error = %v, wantErr %v", err, accountClosed)
+		}
+	})
+}
+
+func TestService_SubmitStoreFailure(t *testing.T) {
+	bank := newTestBank(
+		domain.Account{ID: "1", UserID: "1", Balance: eur(100)},
+		domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+	)
+	service := NewService(&failingBatches{BatchRepository: bank.Batches}, bank.Accounts, bank.transactionService, bank.clock)
+	lines := []domain.BatchTransfer{{ToAccountID: "2", Amount: eur(30)}, {ToAccountID: "2", Amount: eur(200)}}
+
+	got, err := service.Submit(context.Background(), "1", domain.BestEffort, lines)
+	if !errors.Is(err, injectedError) {
+		t.Errorf("Submit() error = %v, wantErr %v", err, injectedError)
+	}
+	if got == nil || got.Status != domain.BatchPartiallyCompleted {
+		t.Fatalf("Submit() = %+v, want the partially completed batch", got)
+	}
+
+	// the lines were stored as they were made, only the status of the batch is missing
+	stored, err := service.Get(got.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if diff := cmp.Diff(got.Lines, stored.Lines); diff != "" {
+		t.Errorf("Get() lines (-want +got):\n%s", diff)
+	}
+	if stored.Status != domain.BatchProcessing {
+		t.Errorf("Get() status = %s, want %s", stored.Status, domain.BatchProcessing)
+	}
+}
+
+func TestService_Get(t *testing.T) {
+	bank := newTestBank()
+
+	if _, err := bank.service.Get(""); !errors.Is(err, invalidBatchID) {
+		t.Errorf("Get() error = %v, wantErr %v", err, invalidBatchID)
+	}
+	if _, err := bank.service.Get("unknown"); !errors.Is(err, batchNotFound) || !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Get() error = %v, wantErr %v", err, batchNotFound)
+	}
+}
+
+type testBank struct {
+	*servicetest.Bank
+	transactionService *transaction.Service
+	clock              *clock.Manual
+	service            *Service
+}
+
+// newTestBank runs batches through a transaction service over the accounts, its clock starts at submittedAt
+func newTestBank(accounts ...domain.Account) *testBank {
+	bank := servicetest.NewBank(accounts...)
+	manual := clock.NewManual(submittedAt)
+	transactionSvc := transaction.NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, bank.AccountLocker, servicetest.NoRates{}, servicetest.NoFees{}, manual)
+
+	return &testBank{
+		Bank:               bank,
+		transactionService: transactionSvc,
+		clock:              manual,
+		service:            NewService(bank.Batches, bank.Accounts, transactionSvc, manual),
+	}
+}
+
+var injectedError = errors.New("injected error")
+
+// failingBatches fails to update whole batches, lines are updated
+type failingBatches struct {
+	*memory.BatchRepository
+}
+
+func (repo *failingBatches) Update(batch *domain.Batch) (*domain.Batch, error) {
+	return nil, injectedError
+}
+
+// validationFields lists the field of every validation error joined in err
+func validationFields(err error) []string {
+	var fields []string
+	switch e := err.(type) {
+	case tberrors.ValidationError:
+		fields = append(fields, e.Field())
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			fields = append(fields, validationFields(inner)...)
+		}
+	}
+
+	return fields
+}
+
+func eur(amount int64) domain.Money {
+	return domain.NewMoney(amount, domain.EUR)
+}
@@ -0,0 +1,15 @@
+package interest
+
+import (
+	"errors"
+
+	"http/internal/tberrors"
+)
+
+var invalidAccountID = tberrors.NewValidationError("missing_account_id", "invalid account ID", "account_id")
+var accountNotFound = tberrors.NewNotFoundError("account_not_found", "account not found", "account_id")
+var failedToSetRatePlan = errors.New("failed to set rate plan")
+var failedToGetRatePlan = errors.New("failed to get rate plan")
+var failedToGetAccount = errors.New("failed to get account")
+var failedToLockAccount = tberrors.NewConflictError("account_busy", "failed to lock account, try again", "")
+var failedToPersistAccount = tberrors.NewInternalError("storage_failure", "failed to persist account")
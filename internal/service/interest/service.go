@@ -0,0 +1,160 @@
+package interest
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/repository"
+	"http/internal/service/accrual"
+)
+
+// lockTimeout bounds how long changing a plan waits for an account to be free
+const lockTimeout = 5 * time.Second
+
+type unitOfWorkFactory interface {
+	Begin(ctx context.Context) (repository.UnitOfWork, error)
+}
+
+type accountRepository interface {
+	Get(accID string) (*domain.Account, error)
+	GetAll(page repository.Page[string]) ([]domain.Account, error)
+	Update(account *domain.Account) (*domain.Account, error)
+}
+
+type balanceHistory interface {
+	GetEndOfDayBalances(accountID string, from, to time.Time) ([]domain.Money, error)
+}
+
+type ratePlans interface {
+	Plan(name string) (domain.RatePlan, error)
+}
+
+type accountLocker interface {
+	Lock(ctx context.Context, keys ...string) (func(), error)
+}
+
+// Service pays interest on savings accounts. It accrues every day from the balance an account ended the day with
+// and the rates of its plan, and capitalizes the interest once a month. Days are UTC days.
+type Service struct {
+	accountRepository accountRepository
+	ratePlans         ratePlans
+	accountLocker     accountLocker
+	clock             clock.Clock
+	accrual           *accrual.Service
+}
+
+func NewService(
+	unitOfWorkFactory unitOfWorkFactory,
+	accountRepository accountRepository,
+	balanceHistory balanceHistory,
+	ratePlans ratePlans,
+	accountLocker accountLocker,
+	clock clock.Clock,
+) *Service {
+	return &Service{
+		accountRepository: accountRepository,
+		ratePlans:         ratePlans,
+		accountLocker:     accountLocker,
+		clock:             clock,
+		accrual: accrual.NewService(unitOfWorkFactory, accountRepository, balanceHistory, accountLocker, clock,
+			savingsInterest{ratePlans: ratePlans}),
+	}
+}
+
+// SetRatePlan puts a savings account on the plan named planName, an empty name takes it off its plan. The interest
+// earned until then is still paid at the end of the month.
+func (service *Service) SetRatePlan(ctx context.Context, accountID, planName string) (*domain.Account, error) {
+	if accountID == "" {
+		return nil, invalidAccountID
+	}
+
+	var plan domain.RatePlan
+	if planName != "" {
+		var err error
+		if plan, err = service.ratePlans.Plan(planName); err != nil {
+			return nil, errors.Join(failedToGetRatePlan, err)
+		}
+	}
+
+	unlock, err := service.lockAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	acc, err := service.accountRepository.Get(accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(failedToGetAccount, accountNotFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
+
+	if err := acc.SetRatePlan(plan, service.clock.Now()); err != nil {
+		return nil, errors.Join(failedToSetRatePlan, err)
+	}
+
+	acc, err = service.accountRepository.Update(acc)
+	if err != nil {
+		return nil, errors.Join(failedToPersistAccount, err)
+	}
+
+	return acc, nil
+}
+
+// AccrueInterest accrues every day that ended since an account last accrued and capitalizes the interest of every
+// month that ended with them. It returns how many accounts accrued, an account that fails is left for the next run
+// and doesn't stop the others.
+func (service *Service) AccrueInterest(ctx context.Context) (int, error) {
+	return service.accrual.AccrueInterest(ctx)
+}
+
+// savingsInterest is the interest a savings account earns with the rates its plan has on the day it accrues
+type savingsInterest struct {
+	ratePlans ratePlans
+}
+
+func (savingsInterest) Accrues(acc *domain.Account) bool {
+	return acc.EarnsInterest()
+}
+
+func (savingsInterest) AccruedThrough(acc *domain.Account) time.Time {
+	return acc.Interest.AccruedThrough
+}
+
+func (interest savingsInterest) Accrue(acc *domain.Account, balances []domain.Money) error {
+	var plan domain.RatePlan
+	if acc.Interest.Plan != "" {
+		var err error
+		if plan, err = interest.ratePlans.Plan(acc.Interest.Plan); err != nil {
+			return errors.Join(failedToGetRatePlan, err)
+		}
+	}
+
+	for _, balance := range balances {
+		if err := acc.AccrueSavingsInterest(plan, balance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (savingsInterest) Post(acc *domain.Account, postedAt time.Time) (*domain.Transaction, error) {
+	return acc.PostSavingsInterest(postedAt)
+}
+
+func (service *Service) lockAccount(ctx context.Context, accountID string) (func(), error) {
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+
+	unlock, err := service.accountLocker.Lock(ctx, accountID)
+	if err != nil {
+		return nil, errors.Join(failedToLockAccount, err)
+	}
+
+	return unlock, nil
+}
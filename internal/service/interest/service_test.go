@@ -0,0 +1,205 @@
+package interest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/repository"
+	"http/internal/service/servicetest"
+	"http/internal/service/transaction"
+)
+
+// tiered pays 2% on the first 5000.00 and 3% on the rest
+var tiered = domain.RatePlan{Name: "tiered", Currency: domain.EUR, Tiers: []domain.RateTier{
+	{From: eur(0), Rate: "0.02"},
+	{From: eur(500000), Rate: "0.03"},
+}}
+
+func TestService_AccrueInterest(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	// every month capitalized on its last day, rounded half to even and earning interest from then on
+	wantMonthly := []int64{2123, 1923, 2134, 2070, 2144, 2080, 2155, 2161, 2096, 2171, 2107, 2182}
+
+	tests := []struct {
+		name string
+		// step is how often the accrual runs while the clock is fast-forwarded a year
+		step time.Duration
+	}{
+		{name: "a year accrued every day", step: 24 * time.Hour},
+		{name: "a year accrued every week", step: 7 * 24 * time.Hour},
+		{name: "a year accrued at once", step: 365 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(start)
+			bank.openSavings(t, "saver", domain.NewMoney(1000000, domain.EUR), start.Add(-24*time.Hour))
+			if _, err := bank.service.SetRatePlan(context.Background(), "saver", tiered.Name); err != nil {
+				t.Fatalf("SetRatePlan() error = %v", err)
+			}
+
+			end := start.AddDate(1, 0, 0)
+			for bank.clock.Now().Before(end) {
+				bank.clock.Advance(min(tt.step, end.Sub(bank.clock.Now())))
+				if _, err := bank.service.AccrueInterest(context.Background()); err != nil {
+					t.Fatalf("AccrueInterest() at %v error = %v", bank.clock.Now(), err)
+				}
+			}
+
+			var want []domain.Transaction
+			total := int64(0)
+			for i, amount := range wantMonthly {
+				want = append(want, domain.Transaction{
+					ToAccountID: ptr("saver"),
+					Amount:      eur(amount),
+					Type:        domain.Interest,
+					ValueDate:   ptr(time.Date(2025, time.Month(i+2), 0, 0, 0, 0, 0, time.UTC)),
+				})
+				total += amount
+			}
+			// interest is made when the accrual runs, which can be months after its value date
+			paid := bank.transactionsOf(t, "saver", domain.Interest)
+			for _, interest := range paid {
+				if interest.CreatedAt.Before(interest.ValueDate.AddDate(0, 0, 1)) || interest.CreatedAt.Hour() != start.Hour() {
+					t.Errorf("interest valued at %v made at %v, want it made by a run after that day", interest.ValueDate, interest.CreatedAt)
+				}
+			}
+			byValueDate := cmpopts.SortSlices(func(a, b domain.Transaction) bool { return a.ValueDate.Before(*b.ValueDate) })
+			if diff := cmp.Diff(want, paid, byValueDate, cmpopts.IgnoreFields(domain.Transaction{}, "ID", "CreatedAt")); diff != "" {
+				t.Errorf("interest paid (-want +got):\n%s", diff)
+			}
+
+			acc, _ := bank.Accounts.Get("saver")
+			if acc.Balance != eur(1000000+total) || acc.Interest.Accrued != "" || !acc.Interest.AccruedThrough.Equal(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("account got = %v with interest %+v, want %v and everything paid through the year", acc.Balance, acc.Interest, eur(1000000+total))
+			}
+
+			ledgerTotal, _ := bank.Ledger.GetAccountTotal(domain.InterestExpenseAccountID, domain.EUR)
+			if ledgerTotal.Debits != eur(total) {
+				t.Errorf("interest expense debits got = %v, want %v", ledgerTotal.Debits, eur(total))
+			}
+		})
+	}
+}
+
+func TestService_SetRatePlan(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		accountID string
+		plan      string
+		want      domain.SavingsInterest
+		wantErr   error
+	}{
+		{
+			name:      "savings account, accrues from today",
+			accountID: "saver",
+			plan:      tiered.Name,
+			want:      domain.SavingsInterest{Plan: tiered.Name, AccruedThrough: time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:      "unknown plan, want errPlanNotFound",
+			accountID: "saver",
+			plan:      "premium",
+			wantErr:   errPlanNotFound,
+		},
+		{
+			name:      "unknown account, want accountNotFound",
+			accountID: "missing",
+			plan:      tiered.Name,
+			wantErr:   accountNotFound,
+		},
+		{
+			name:    "empty account id, want invalidAccountID",
+			plan:    tiered.Name,
+			wantErr: invalidAccountID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(now)
+			bank.openSavings(t, "saver", eur(0), now)
+
+			acc, err := bank.service.SetRatePlan(context.Background(), tt.accountID, tt.plan)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetRatePlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, acc.Interest); diff != "" {
+				t.Errorf("SetRatePlan() interest (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type testBank struct {
+	*servicetest.Bank
+	clock   *clock.Manual
+	service *Service
+}
+
+// newTestBank pays interest with the plans in testPlans, starting at now
+func newTestBank(now time.Time) *testBank {
+	bank := servicetest.NewBank()
+	transactionSvc := transaction.NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, bank.AccountLocker, servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+	manual := clock.NewManual(now)
+
+	return &testBank{
+		Bank:    bank,
+		clock:   manual,
+		service: NewService(bank.UnitOfWorkFactory, bank.Accounts, transactionSvc, testPlans{}, bank.AccountLocker, manual),
+	}
+}
+
+// openSavings opens a savings account holding balance, deposited at depositedAt
+func (bank *testBank) openSavings(t *testing.T, accountID string, balance domain.Money, depositedAt time.Time) {
+	t.Helper()
+
+	if _, err := bank.Accounts.Insert(&domain.Account{ID: accountID, UserID: "1", Balance: balance, Type: domain.Savings}); err != nil {
+		t.Fatal(err)
+	}
+	if balance.IsPositive() {
+		bank.Transactions.Insert(&domain.Transaction{ID: "deposit", CreatedAt: depositedAt, ToAccountID: &accountID, Amount: balance, Type: domain.Deposit})
+	}
+}
+
+func (bank *testBank) transactionsOf(t *testing.T, accountID string, transactionType domain.TransactionType) []domain.Transaction {
+	t.Helper()
+
+	filter := repository.TransactionFilter{Types: []domain.TransactionType{transactionType}}
+	transactions, err := bank.Transactions.GetAccountTransactions(accountID, filter, repository.Page[repository.TransactionCursor]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transactions
+}
+
+var errPlanNotFound = errors.New("rate plan not found")
+
+type testPlans struct{}
+
+func (testPlans) Plan(name string) (domain.RatePlan, error) {
+	if name != tiered.Name {
+		return domain.RatePlan{}, errPlanNotFound
+	}
+
+	return tiered, nil
+}
+
+func eur(amount int64) domain.Money {
+	return domain.NewMoney(amount, domain.EUR)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,13 @@
+package ledger
+
+import (
+	"errors"
+
+	"http/internal/tberrors"
+)
+
+var failedToGetLedgerTotals = tberrors.NewInternalError("storage_failure", "failed to get ledger totals")
+var failedToLockAccount = tberrors.NewConflictError("account_busy", "failed to lock account, try again", "")
+var failedToGetAccount = errors.New("failed to get account")
+var failedToGetAccounts = tberrors.NewInternalError("storage_failure", "failed to get accounts")
+var failedToSumLedgerTotals = tberrors.NewInternalError("ledger_overflow", "failed to sum ledger totals")
@@ -0,0 +1,170 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+// lockTimeout bounds how long reconciling an account waits for it to be free
+const lockTimeout = 5 * time.Second
+
+// accountBatchSize is how many accounts are read at a time when reconciling them
+const accountBatchSize = 500
+
+type ledgerRepository interface {
+	GetTotals() ([]domain.LedgerAccountTotal, error)
+	GetAccountTotal(accountID string, currency domain.Currency) (domain.LedgerAccountTotal, error)
+}
+
+type accountRepository interface {
+	Get(accID string) (*domain.Account, error)
+	GetAll(page repository.Page[string]) ([]domain.Account, error)
+}
+
+type accountLocker interface {
+	Lock(ctx context.Context, keys ...string) (func(), error)
+}
+
+type Service struct {
+	ledgerRepository  ledgerRepository
+	accountRepository accountRepository
+	accountLocker     accountLocker
+}
+
+func NewService(ledgerRepository ledgerRepository, accountRepository accountRepository, accountLocker accountLocker) *Service {
+	return &Service{
+		ledgerRepository:  ledgerRepository,
+		accountRepository: accountRepository,
+		accountLocker:     accountLocker,
+	}
+}
+
+// TrialBalance sums the whole ledger and checks every customer account balance against it. An account the ledger
+// has no total for is checked against zero, and the ledger total of an account that doesn't exist against a zero
+// balance.
+func (service *Service) TrialBalance(ctx context.Context) (*domain.TrialBalance, error) {
+	totals, err := service.ledgerRepository.GetTotals()
+	if err != nil {
+		return nil, errors.Join(failedToGetLedgerTotals, err)
+	}
+
+	trialBalance := &domain.TrialBalance{
+		Accounts:   totals,
+		Mismatches: make([]domain.BalanceMismatch, 0),
+	}
+
+	currencyTotals := make(map[domain.Currency]*domain.CurrencyTotal)
+	for _, total := range totals {
+		currencyTotal, ok := currencyTotals[total.Currency]
+		if !ok {
+			currencyTotal = &domain.CurrencyTotal{
+				Currency: total.Currency,
+				Debits:   domain.NewMoney(0, total.Currency),
+				Credits:  domain.NewMoney(0, total.Currency),
+			}
+			currencyTotals[total.Currency] = currencyTotal
+		}
+
+		if currencyTotal.Debits, err = currencyTotal.Debits.Add(total.Debits); err != nil {
+			return nil, errors.Join(failedToSumLedgerTotals, err)
+		}
+		if currencyTotal.Credits, err = currencyTotal.Credits.Add(total.Credits); err != nil {
+			return nil, errors.Join(failedToSumLedgerTotals, err)
+		}
+	}
+
+	reconciled := make(map[string]bool)
+	page := repository.Page[string]{Limit: accountBatchSize}
+	for {
+		accounts, err := service.accountRepository.GetAll(page)
+		if err != nil {
+			return nil, errors.Join(failedToGetAccounts, err)
+		}
+
+		for _, acc := range accounts {
+			mismatch, err := service.reconcile(ctx, acc.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			if mismatch != nil {
+				trialBalance.Mismatches = append(trialBalance.Mismatches, *mismatch)
+			}
+			reconciled[acc.ID] = true
+		}
+
+		if len(accounts) < accountBatchSize {
+			break
+		}
+		page.After = &accounts[len(accounts)-1].ID
+	}
+
+	for _, total := range totals {
+		if domain.IsSystemAccount(total.AccountID) || reconciled[total.AccountID] {
+			continue
+		}
+
+		ledgerBalance, err := total.Balance()
+		if err != nil {
+			return nil, errors.Join(failedToSumLedgerTotals, err)
+		}
+		if !ledgerBalance.IsZero() {
+			trialBalance.Mismatches = append(trialBalance.Mismatches, domain.BalanceMismatch{
+				AccountID:      total.AccountID,
+				LedgerBalance:  ledgerBalance,
+				AccountBalance: domain.NewMoney(0, total.Currency),
+			})
+		}
+	}
+
+	trialBalance.Totals = make([]domain.CurrencyTotal, 0, len(currencyTotals))
+	for _, currency := range slices.Sorted(maps.Keys(currencyTotals)) {
+		trialBalance.Totals = append(trialBalance.Totals, *currencyTotals[currency])
+	}
+
+	return trialBalance, nil
+}
+
+// reconcile compares an account with its ledger total while holding the account lock, so a posting in flight
+// can't be reported as a mismatch
+func (service *Service) reconcile(ctx context.Context, accountID string) (*domain.BalanceMismatch, error) {
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+
+	unlock, err := service.accountLocker.Lock(ctx, accountID)
+	if err != nil {
+		return nil, errors.Join(failedToLockAccount, err)
+	}
+	defer unlock()
+
+	acc, err := service.accountRepository.Get(accountID)
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
+
+	total, err := service.ledgerRepository.GetAccountTotal(accountID, acc.Balance.Currency)
+	if err != nil {
+		return nil, errors.Join(failedToGetLedgerTotals, err)
+	}
+
+	ledgerBalance, err := total.Balance()
+	if err != nil {
+		return nil, errors.Join(failedToSumLedgerTotals, err)
+	}
+
+	if acc.Balance == ledgerBalance {
+		return nil, nil
+	}
+
+	return &domain.BalanceMismatch{
+		AccountID:      accountID,
+		LedgerBalance:  ledgerBalance,
+		AccountBalance: acc.Balance,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package ledger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+	"http/internal/lock"
+	"http/internal/repository/memory"
+)
+
+func TestService_TrialBalance(t *testing.T) {
+	deposit := func(accountID string, amount int64) *domain.JournalEntry {
+		entry, _ := domain.NewJournalEntry(&domain.Transaction{ToAccountID: &accountID, Amount: eur(amount), Type: domain.Deposit})
+		return entry
+	}
+	withdrawal := func(accountID string, amount int64) *domain.JournalEntry {
+		entry, _ := domain.NewJournalEntry(&domain.Transaction{FromAccountID: &accountID, Amount: eur(amount), Type: domain.Withdrawal})
+		return entry
+	}
+
+	tests := []struct {
+		name         string
+		accounts     []domain.Account
+		entries      []*domain.JournalEntry
+		want         *domain.TrialBalance
+		wantBalanced bool
+	}{
+		{
+			name:     "balanced books",
+			accounts: []domain.Account{{ID: "1", UserID: "1", Balance: eur(70)}},
+			entries:  []*domain.JournalEntry{deposit("1", 100), withdrawal("1", 30)},
+			want: &domain.TrialBalance{
+				Accounts: []domain.LedgerAccountTotal{
+					{AccountID: "1", Currency: domain.EUR, Debits: eur(30), Credits: eur(100)},
+					{AccountID: domain.CashInAccountID, Currency: domain.EUR, Debits: eur(100), Credits: eur(0)},
+					{AccountID: domain.CashOutAccountID, Currency: domain.EUR, Debits: eur(0), Credits: eur(30)},
+				},
+				Totals: []domain.CurrencyTotal{
+					{Currency: domain.EUR, Debits: eur(130), Credits: eur(130)},
+				},
+				Mismatches: []domain.BalanceMismatch{},
+			},
+			wantBalanced: true,
+		},
+		{
+			name:     "account balance disagrees with the ledger",
+			accounts: []domain.Account{{ID: "1", UserID: "1", Balance: eur(90)}},
+			entries:  []*domain.JournalEntry{deposit("1", 100)},
+			want: &domain.TrialBalance{
+				Accounts: []domain.LedgerAccountTotal{
+					{AccountID: "1", Currency: domain.EUR, Debits: eur(0), Credits: eur(100)},
+					{AccountID: domain.CashInAccountID, Currency: domain.EUR, Debits: eur(100), Credits: eur(0)},
+				},
+				Totals: []domain.CurrencyTotal{
+					{Currency: domain.EUR, Debits: eur(100), Credits: eur(100)},
+				},
+				Mismatches: []domain.BalanceMismatch{
+					{AccountID: "1", LedgerBalance: eur(100), AccountBalance: eur(90)},
+				},
+			},
+			wantBalanced: false,
+		},
+		{
+			name:     "account without ledger entries is checked against zero",
+			accounts: []domain.Account{{ID: "1", UserID: "1", Balance: eur(100)}, {ID: "2", UserID: "2", Balance: eur(50)}},
+			entries:  []*domain.JournalEntry{deposit("1", 100)},
+			want: &domain.TrialBalance{
+				Accounts: []domain.LedgerAccountTotal{
+					{AccountID: "1", Currency: domain.EUR, Debits: eur(0), Credits: eur(100)},
+					{AccountID: domain.CashInAccountID, Currency: domain.EUR, Debits: eur(100), Credits: eur(0)},
+				},
+				Totals: []domain.CurrencyTotal{
+					{Currency: domain.EUR, Debits: eur(100), Credits: eur(100)},
+				},
+				Mismatches: []domain.BalanceMismatch{
+					{AccountID: "2", LedgerBalance: eur(0), AccountBalance: eur(50)},
+				},
+			},
+			wantBalanced: false,
+		},
+		{
+			name:     "ledger total of an unknown account is checked against zero",
+			accounts: []domain.Account{{ID: "1", UserID: "1", Balance: eur(100)}},
+			entries:  []*domain.JournalEntry{deposit("1", 100), deposit("unknown", 20)},
+			want: &domain.TrialBalance{
+				Accounts: []domain.LedgerAccountTotal{
+					{AccountID: "1", Currency: domain.EUR, Debits: eur(0), Credits: eur(100)},
+					{AccountID: domain.CashInAccountID, Currency: domain.EUR, Debits: eur(120), Credits: eur(0)},
+					{AccountID: "unknown", Currency: domain.EUR, Debits: eur(0), Credits: eur(20)},
+				},
+				Totals: []domain.CurrencyTotal{
+					{Currency: domain.EUR, Debits: eur(120), Credits: eur(120)},
+				},
+				Mismatches: []domain.BalanceMismatch{
+					{AccountID: "unknown", LedgerBalance: eur(20), AccountBalance: eur(0)},
+				},
+			},
+			wantBalanced: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepository := memory.NewAccountRepository()
+			for _, acc := range tt.accounts {
+				accountRepository.Insert(&acc)
+			}
+			ledgerRepository := memory.NewLedgerRepository()
+			for _, entry := range tt.entries {
+				ledgerRepository.Insert(entry)
+			}
+
+			service := NewService(ledgerRepository, accountRepository, lock.NewManager())
+
+			got, err := service.TrialBalance(context.Background())
+			if err != nil {
+				t.Fatalf("TrialBalance() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("TrialBalance() (-want +got):\n%s", diff)
+			}
+
+			if got.Balanced() != tt.wantBalanced {
+				t.Errorf("Balanced() got = %v, want %v", got.Balanced(), tt.wantBalanced)
+			}
+		})
+	}
+}
+
+func eur(amount int64) domain.Money {
+	return domain.NewMoney(amount, domain.EUR)
+}
@@ -0,0 +1,78 @@
+package overdraft
+
+import (
+	"context"
+	"time"
+
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/repository"
+	"http/internal/service/accrual"
+)
+
+type unitOfWorkFactory interface {
+	Begin(ctx context.Context) (repository.UnitOfWork, error)
+}
+
+type accountRepository interface {
+	Get(accID string) (*domain.Account, error)
+	GetAll(page repository.Page[string]) ([]domain.Account, error)
+}
+
+type balanceHistory interface {
+	GetEndOfDayBalances(accountID string, from, to time.Time) ([]domain.Money, error)
+}
+
+type accountLocker interface {
+	Lock(ctx context.Context, keys ...string) (func(), error)
+}
+
+// Service accrues the debit interest of overdrawn accounts every day from the balances they ended the day with, and
+// charges it once a month. Days are UTC days.
+type Service struct {
+	accrual *accrual.Service
+}
+
+func NewService(
+	unitOfWorkFactory unitOfWorkFactory,
+	accountRepository accountRepository,
+	balanceHistory balanceHistory,
+	accountLocker accountLocker,
+	clock clock.Clock,
+) *Service {
+	return &Service{
+		accrual: accrual.NewService(unitOfWorkFactory, accountRepository, balanceHistory, accountLocker, clock, overdraftInterest{}),
+	}
+}
+
+// AccrueInterest accrues every day that ended since an account last accrued and charges the interest of every month
+// that ended with them. It returns how many accounts accrued, an account that fails is left for the next run and
+// doesn't stop the others.
+func (service *Service) AccrueInterest(ctx context.Context) (int, error) {
+	return service.accrual.AccrueInterest(ctx)
+}
+
+// overdraftInterest is the debit interest an account accrues on the days it ends overdrawn
+type overdraftInterest struct{}
+
+func (overdraftInterest) Accrues(acc *domain.Account) bool {
+	return acc.AccruesInterest()
+}
+
+func (overdraftInterest) AccruedThrough(acc *domain.Account) time.Time {
+	return acc.Overdraft.AccruedThrough
+}
+
+func (overdraftInterest) Accrue(acc *domain.Account, balances []domain.Money) error {
+	for _, balance := range balances {
+		if err := acc.AccrueOverdraftInterest(balance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (overdraftInterest) Post(acc *domain.Account, postedAt time.Time) (*domain.Transaction, error) {
+	return acc.PostOverdraftInterest(postedAt)
+}
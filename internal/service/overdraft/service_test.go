@@ -0,0 +1,125 @@
+package overdraft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/repository"
+	"http/internal/service/servicetest"
+	"http/internal/service/transaction"
+)
+
+func TestService_AccrueInterest(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC) }
+	// 3.65% a year is a hundredth of a percent a day
+	overdraft := domain.Overdraft{Limit: eur(200000), Rate: "0.0365", AccruedThrough: day(time.January, 31)}
+
+	t.Run("a month is charged when the accrual runs, valued on its last day, and accrues interest from then on", func(t *testing.T) {
+		now := day(time.March, 3).Add(12 * time.Hour)
+		bank := newTestBank(now,
+			domain.Account{ID: "overdrawn", UserID: "1", Balance: eur(-100000), Overdraft: overdraft},
+			domain.Account{ID: "plain", UserID: "2", Balance: eur(0)},
+		)
+		bank.history("overdrawn", day(time.February, 1).Add(10*time.Hour), eur(100000))
+
+		accrued, err := bank.service.AccrueInterest(context.Background())
+		if err != nil {
+			t.Fatalf("AccrueInterest() error = %v", err)
+		}
+		if accrued != 1 {
+			t.Errorf("AccrueInterest() accrued = %d, want 1", accrued)
+		}
+
+		// 28 days of 10 cents in February, then 10.028 cents a day on the balance including them
+		acc, _ := bank.Accounts.Get("overdrawn")
+		wantOverdraft := overdraft
+		wantOverdraft.Accrued = "-20.056000000"
+		wantOverdraft.AccruedThrough = day(time.March, 2)
+		if diff := cmp.Diff(wantOverdraft, acc.Overdraft); diff != "" {
+			t.Errorf("overdraft (-want +got):\n%s", diff)
+		}
+		if acc.Balance != eur(-100280) {
+			t.Errorf("balance got = %v, want %v", acc.Balance, eur(-100280))
+		}
+
+		charges := bank.transactionsOf(t, "overdrawn", domain.OverdraftInterest)
+		want := []domain.Transaction{{CreatedAt: now, FromAccountID: ptr("overdrawn"), Amount: eur(280), Type: domain.OverdraftInterest, ValueDate: ptr(day(time.February, 28))}}
+		if diff := cmp.Diff(want, charges, cmpopts.IgnoreFields(domain.Transaction{}, "ID")); diff != "" {
+			t.Errorf("interest charged (-want +got):\n%s", diff)
+		}
+
+		total, _ := bank.Ledger.GetAccountTotal(domain.InterestIncomeAccountID, domain.EUR)
+		if income, _ := total.Balance(); income != eur(280) {
+			t.Errorf("interest income got = %v, want %v", income, eur(280))
+		}
+
+		if accrued, err := bank.service.AccrueInterest(context.Background()); err != nil || accrued != 0 {
+			t.Errorf("AccrueInterest() again the same day = %d, %v, want nothing accrued", accrued, err)
+		}
+	})
+
+	t.Run("interest rounding to nothing isn't charged", func(t *testing.T) {
+		bank := newTestBank(day(time.March, 1),
+			domain.Account{ID: "overdrawn", UserID: "1", Balance: eur(-100), Overdraft: overdraft},
+		)
+		bank.history("overdrawn", day(time.February, 27).Add(10*time.Hour), eur(100))
+
+		if _, err := bank.service.AccrueInterest(context.Background()); err != nil {
+			t.Fatalf("AccrueInterest() error = %v", err)
+		}
+
+		acc, _ := bank.Accounts.Get("overdrawn")
+		if acc.Balance != eur(-100) || acc.Overdraft.Accrued != "" || !acc.Overdraft.AccruedThrough.Equal(day(time.February, 28)) {
+			t.Errorf("account got = %v with overdraft %+v, want nothing charged and the accrual reset", acc.Balance, acc.Overdraft)
+		}
+		if charges := bank.transactionsOf(t, "overdrawn", domain.OverdraftInterest); len(charges) != 0 {
+			t.Errorf("interest charged = %v, want none", charges)
+		}
+	})
+}
+
+type testBank struct {
+	*servicetest.Bank
+	service *Service
+}
+
+// newTestBank accrues interest over the accounts, now is the time the accrual runs at
+func newTestBank(now time.Time, accounts ...domain.Account) *testBank {
+	bank := servicetest.NewBank(accounts...)
+	transactionSvc := transaction.NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, bank.AccountLocker, servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+	return &testBank{
+		Bank:    bank,
+		service: NewService(bank.UnitOfWorkFactory, bank.Accounts, transactionSvc, bank.AccountLocker, clock.NewManual(now)),
+	}
+}
+
+// history records a withdrawal made at a time in the past, the account balance already includes it
+func (bank *testBank) history(accountID string, at time.Time, amount domain.Money) {
+	bank.Transactions.Insert(&domain.Transaction{ID: "withdrawal", CreatedAt: at, FromAccountID: &accountID, Amount: amount, Type: domain.Withdrawal})
+}
+
+func (bank *testBank) transactionsOf(t *testing.T, accountID string, transactionType domain.TransactionType) []domain.Transaction {
+	t.Helper()
+
+	filter := repository.TransactionFilter{Types: []domain.TransactionType{transactionType}}
+	transactions, err := bank.Transactions.GetAccountTransactions(accountID, filter, repository.Page[repository.TransactionCursor]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transactions
+}
+
+func eur(amount int64) domain.Money {
+	return domain.NewMoney(amount, domain.EUR)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,63 @@
+// Package servicetest is the bank the service tests run against: in memory repositories holding the accounts a test
+// starts with, and an exchange rate table and fee schedule that leave transactions as they are.
+package servicetest
+
+import (
+	"errors"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/lock"
+	"http/internal/repository/memory"
+)
+
+// Bank is an in memory store with the lock manager the services of a test share
+type Bank struct {
+	*memory.Store
+	AccountLocker *lock.Manager
+	// Openings are the deposits the accounts were given their balance with, by account
+	Openings map[string]*domain.Transaction
+}
+
+// NewBank stores the accounts and makes their balance a deposit, the way any money gets into the bank
+func NewBank(accounts ...domain.Account) *Bank {
+	bank := &Bank{
+		Store:         memory.NewStore(nil),
+		AccountLocker: lock.NewManager(),
+		Openings:      make(map[string]*domain.Transaction),
+	}
+
+	for _, acc := range accounts {
+		bank.Accounts.Insert(&acc)
+
+		if acc.Balance.IsPositive() {
+			deposit, err := domain.NewDeposit(acc.ID, acc.Balance, time.Now())
+			if err != nil {
+				panic(err)
+			}
+			entry, err := domain.NewJournalEntry(deposit)
+			if err != nil {
+				panic(err)
+			}
+			bank.Transactions.Insert(deposit)
+			bank.Ledger.Insert(entry)
+			bank.Openings[acc.ID] = deposit
+		}
+	}
+
+	return bank
+}
+
+// NoRates is an exchange rate table without any rate
+type NoRates struct{}
+
+func (NoRates) Rate(from, to domain.Currency) (domain.ExchangeRate, error) {
+	return domain.ExchangeRate{}, errors.New("no rate")
+}
+
+// NoFees is a fee schedule without any fee
+type NoFees struct{}
+
+func (NoFees) Rule(transactionType domain.TransactionType, currency domain.Currency) (domain.FeeRule, bool) {
+	return domain.FeeRule{}, false
+}
@@ -0,0 +1,26 @@
+package standingorder
+
+import (
+	"errors"
+
+	"http/internal/tberrors"
+)
+
+var failedToCreateStandingOrder = errors.New("failed to create standing order")
+var failedToGetAccount = errors.New("failed to get account")
+var failedToExecute = errors.New("failed to execute standing order")
+var accountNotFound = tberrors.NewNotFoundError("account_not_found", "account not found", "account_id")
+var accountClosed = tberrors.NewForbiddenError("account_closed", "account is closed", "account_id")
+var toAccountNotFound = tberrors.NewValidationError("account_not_found", "account not found", "to_account")
+var toAccountClosed = tberrors.NewValidationError("account_closed", "account is closed", "to_account")
+var currencyMismatch = tberrors.NewValidationError("currency_mismatch", "amount must be in the currency of the debited account", "currency")
+var invalidStandingOrderID = tberrors.NewValidationError("missing_standing_order_id", "invalid empty standing order ID", "standing_order_id")
+var standingOrderNotFound = tberrors.NewNotFoundError("standing_order_not_found", "standing order not found", "standing_order_id")
+var failedToGetStandingOrders = tberrors.NewInternalError("storage_failure", "failed to get standing orders")
+var failedToInsertStandingOrder = tberrors.NewInternalError("storage_failure", "failed to insert standing order")
+var failedToUpdateStandingOrder = tberrors.NewInternalError("storage_failure", "failed to update standing order")
+var failedToInsertExecution = tberrors.NewInternalError("storage_failure", "failed to insert standing order execution")
+var failedToBeginUnitOfWork = tberrors.NewInternalError("storage_failure", "failed to begin unit of work")
+var failedToCommit = tberrors.NewInternalError("storage_failure", "failed to commit standing order execution")
+var failedToGetExecutions = tberrors.NewInternalError("storage_failure", "failed to get standing order executions")
+var failedToLockStandingOrder = tberrors.NewConflictError("standing_order_busy", "standing order is being executed, try again", "")
@@ -0,0 +1,350 @@
+package standingorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/repository"
+	"http/internal/tberrors"
+)
+
+// lockTimeout bounds how long a change waits for an order the worker is executing
+const lockTimeout = 5 * time.Second
+
+type unitOfWorkFactory interface {
+	Begin(ctx context.Context) (repository.UnitOfWork, error)
+}
+
+type standingOrderRepository interface {
+	Insert(order *domain.StandingOrder) (*domain.StandingOrder, error)
+	Get(orderID string) (*domain.StandingOrder, error)
+	GetByAccount(accountID string) ([]*domain.StandingOrder, error)
+	Due(at time.Time) ([]*domain.StandingOrder, error)
+	Update(order *domain.StandingOrder) (*domain.StandingOrder, error)
+	GetExecutions(orderID string) ([]*domain.StandingOrderExecution, error)
+}
+
+type accountRepository interface {
+	Get(accID string) (*domain.Account, error)
+}
+
+type transferService interface {
+	TransferWith(ctx context.Context, fromAccountID, toAccountID string, amount domain.Amount,
+		stage func(uow repository.UnitOfWork, transaction *domain.Transaction) error) (*domain.Transaction, error)
+}
+
+type orderLocker interface {
+	Lock(ctx context.Context, keys ...string) (func(), error)
+}
+
+type Service struct {
+	unitOfWorkFactory       unitOfWorkFactory
+	standingOrderRepository standingOrderRepository
+	accountRepository       accountRepository
+	transferService         transferService
+	orderLocker             orderLocker
+	clock                   clock.Clock
+}
+
+func NewService(
+	unitOfWorkFactory unitOfWorkFactory,
+	standingOrderRepository standingOrderRepository,
+	accountRepository accountRepository,
+	transferService transferService,
+	orderLocker orderLocker,
+	clock clock.Clock,
+) *Service {
+	return &Service{
+		unitOfWorkFactory:       unitOfWorkFactory,
+		standingOrderRepository: standingOrderRepository,
+		accountRepository:       accountRepository,
+		transferService:         transferService,
+		orderLocker:             orderLocker,
+		clock:                   clock,
+	}
+}
+
+// Create sets up a standing order out of accountID, both accounts must be open and the amount in the currency of
+// accountID, which it's read in when it names none
+func (service *Service) Create(accountID string, terms domain.StandingOrderTerms) (*domain.StandingOrder, error) {
+	from, err := service.getAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := domain.NewStandingOrder(accountID, from.Balance.Currency, terms, service.clock.Now())
+	if err != nil {
+		return nil, errors.Join(failedToCreateStandingOrder, err)
+	}
+
+	if err := service.validateAccounts(order); err != nil {
+		return nil, err
+	}
+
+	if _, err := service.standingOrderRepository.Insert(order); err != nil {
+		return nil, errors.Join(failedToInsertStandingOrder, err)
+	}
+
+	return order, nil
+}
+
+func (service *Service) Get(orderID string) (*domain.StandingOrder, error) {
+	if orderID == "" {
+		return nil, invalidStandingOrderID
+	}
+
+	order, err := service.standingOrderRepository.Get(orderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(standingOrderNotFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetStandingOrders, err)
+	}
+
+	return order, nil
+}
+
+// GetAccountStandingOrders lists every standing order out of accountID, ended ones included, oldest first
+func (service *Service) GetAccountStandingOrders(accountID string) ([]*domain.StandingOrder, error) {
+	if _, err := service.getAccount(accountID); err != nil {
+		return nil, err
+	}
+
+	orders, err := service.standingOrderRepository.GetByAccount(accountID)
+	if err != nil {
+		return nil, errors.Join(failedToGetStandingOrders, err)
+	}
+
+	return orders, nil
+}
+
+// GetExecutions lists every attempt at paying the order, oldest first
+func (service *Service) GetExecutions(orderID string) ([]*domain.StandingOrderExecution, error) {
+	if _, err := service.Get(orderID); err != nil {
+		return nil, err
+	}
+
+	executions, err := service.standingOrderRepository.GetExecutions(orderID)
+	if err != nil {
+		return nil, errors.Join(failedToGetExecutions, err)
+	}
+
+	return executions, nil
+}
+
+func (service *Service) Cancel(ctx context.Context, orderID string) (*domain.StandingOrder, error) {
+	return service.change(ctx, orderID, func(order *domain.StandingOrder) error {
+		return order.Cancel()
+	})
+}
+
+// Resume runs a suspended order again from its next occurrence
+func (service *Service) Resume(ctx context.Context, orderID string) (*domain.StandingOrder, error) {
+	return service.change(ctx, orderID, func(order *domain.StandingOrder) error {
+		return order.Resume(service.clock.Now())
+	})
+}
+
+// change applies apply to the stored order while the worker can't execute it
+func (service *Service) change(ctx context.Context, orderID string, apply func(order *domain.StandingOrder) error) (*domain.StandingOrder, error) {
+	if orderID == "" {
+		return nil, invalidStandingOrderID
+	}
+
+	unlock, err := service.lockOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	order, err := service.Get(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := apply(order); err != nil {
+		return nil, err
+	}
+
+	if _, err := service.standingOrderRepository.Update(order); err != nil {
+		return nil, errors.Join(failedToUpdateStandingOrder, err)
+	}
+
+	return order, nil
+}
+
+// ExecuteDue pays every occurrence of every order that is due now, orders the service was down for are caught up
+// one occurrence at a time. It returns how many executions were recorded, orders that failed for a reason that
+// may go away on its own, such as storage failures, are left due and executed again on the next call. An occurrence
+// is paid, moved past and recorded in a single unit of work, so one that fails to be stored is neither paid nor
+// recorded and stays due.
+func (service *Service) ExecuteDue(ctx context.Context) (int, error) {
+	now := service.clock.Now()
+
+	orders, err := service.standingOrderRepository.Due(now)
+	if err != nil {
+		return 0, errors.Join(failedToGetStandingOrders, err)
+	}
+
+	executed := 0
+	var errs []error
+	for _, order := range orders {
+		n, err := service.execute(ctx, order.ID, now)
+		executed += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("standing order %s: %w", order.ID, err))
+		}
+	}
+
+	return executed, errors.Join(errs...)
+}
+
+func (service *Service) execute(ctx context.Context, orderID string, now time.Time) (int, error) {
+	unlock, err := service.lockOrder(ctx, orderID)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// the order is read again as it may have been cancelled since it was listed
+	order, err := service.Get(orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	executed := 0
+	for order.Due(now) {
+		// the order moves past the occurrence and the execution is recorded in the unit of work of the payment, so
+		// that an occurrence is paid and recorded together or not at all
+		execution := order.Attempt(service.clock.Now())
+		_, err := service.transferService.TransferWith(ctx, order.AccountID, order.ToAccountID, order.Amount,
+			func(uow repository.UnitOfWork, transaction *domain.Transaction) error {
+				completed := *execution
+				completed.Succeed(transaction.ID)
+				return stage(uow, order, &completed)
+			})
+		if err == nil {
+			executed++
+			continue
+		}
+
+		// nothing of the payment was stored, an order whose payment failed goes back to the occurrence it didn't pay
+		interrupted := transient(err)
+		code, reason := tberrors.Describe(err)
+		if interrupted {
+			order.Interrupt(execution, code, reason)
+		} else {
+			order.Fail(execution, code, reason, insufficientFunds(err), service.clock.Now())
+		}
+		if err := service.record(ctx, order, execution); err != nil {
+			return executed, err
+		}
+		executed++
+
+		if interrupted {
+			return executed, errors.Join(failedToExecute, err)
+		}
+	}
+
+	return executed, nil
+}
+
+// record stores the order along with the execution of a payment that failed
+func (service *Service) record(ctx context.Context, order *domain.StandingOrder, execution *domain.StandingOrderExecution) error {
+	uow, err := service.unitOfWorkFactory.Begin(ctx)
+	if err != nil {
+		return errors.Join(failedToBeginUnitOfWork, err)
+	}
+	defer uow.Rollback()
+
+	if err := stage(uow, order, execution); err != nil {
+		return err
+	}
+	if err := uow.Commit(); err != nil {
+		return errors.Join(failedToCommit, err)
+	}
+
+	return nil
+}
+
+func (service *Service) lockOrder(ctx context.Context, orderID string) (func(), error) {
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+
+	unlock, err := service.orderLocker.Lock(ctx, orderID)
+	if err != nil {
+		return nil, errors.Join(failedToLockStandingOrder, err)
+	}
+
+	return unlock, nil
+}
+
+func stage(uow repository.UnitOfWork, order *domain.StandingOrder, execution *domain.StandingOrderExecution) error {
+	if err := uow.UpdateStandingOrder(order); err != nil {
+		return errors.Join(failedToUpdateStandingOrder, err)
+	}
+	if err := uow.InsertExecution(execution); err != nil {
+		return errors.Join(failedToInsertExecution, err)
+	}
+
+	return nil
+}
+
+// validateAccounts checks the debited account exists and is open, problems with the credited account are
+// validation errors of the order
+func (service *Service) validateAccounts(order *domain.StandingOrder) error {
+	from, err := service.getAccount(order.AccountID)
+	if err != nil {
+		return err
+	}
+	if from.Closed() {
+		return errors.Join(failedToGetAccount, accountClosed)
+	}
+
+	var errs []error
+	if order.Amount.Currency != from.Balance.Currency {
+		errs = append(errs, currencyMismatch)
+	}
+
+	to, err := service.accountRepository.Get(order.ToAccountID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		errs = append(errs, toAccountNotFound)
+	case err != nil:
+		return errors.Join(failedToGetAccount, err)
+	case to.Closed():
+		errs = append(errs, toAccountClosed)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (service *Service) getAccount(accountID string) (*domain.Account, error) {
+	acc, err := service.accountRepository.Get(accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(failedToGetAccount, accountNotFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
+
+	return acc, nil
+}
+
+// transient tells whether err may go away without anything changing, such as a busy account or a storage failure
+func transient(err error) bool {
+	var conflictErr tberrors.ConflictError
+	var internalErr tberrors.InternalError
+
+	return errors.As(err, &conflictErr) || errors.As(err, &internalErr)
+}
+
+// insufficientFunds failures are worth retrying, the account may be funded meanwhile
+func insufficientFunds(err error) bool {
+	var insufficientFundsErr tberrors.InsufficientFundsError
+	return errors.As(err, &insufficientFundsErr)
+}
@@ -0,0 +1,465 @@
+package standingorder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/lock"
+	"http/internal/repository"
+	"http/internal/service/servicetest"
+	"http/internal/service/transaction"
+	"http/internal/tberrors"
+)
+
+var createdAt = time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+func TestService_Create(t *testing.T) {
+	closedAt := createdAt.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name       string
+		accountID  string
+		terms      domain.StandingOrderTerms
+		wantErr    error
+		wantFields []string
+	}{
+		{
+			name:      "open accounts in the same currency",
+			accountID: "1",
+			terms:     domain.StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "0 9 1 * *"},
+		},
+		{
+			name:      "unknown debited account, want accountNotFound",
+			accountID: "unknown",
+			terms:     domain.StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "0 9 1 * *"},
+			wantErr:   accountNotFound,
+		},
+		{
+			name:      "closed debited account, want accountClosed",
+			accountID: "closed",
+			terms:     domain.StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "0 9 1 * *"},
+			wantErr:   accountClosed,
+		},
+		{
+			name:       "closed credited account in another currency",
+			accountID:  "1",
+			terms:      domain.StandingOrderTerms{ToAccountID: "closed", Amount: domain.NewMoney(100, domain.USD), Schedule: "0 9 1 * *"},
+			wantFields: []string{"currency", "to_account"},
+		},
+		{
+			name:       "unknown credited account",
+			accountID:  "1",
+			terms:      domain.StandingOrderTerms{ToAccountID: "unknown", Amount: eur(100), Schedule: "0 9 1 * *"},
+			wantFields: []string{"to_account"},
+		},
+		{
+			name:       "invalid schedule",
+			accountID:  "1",
+			terms:      domain.StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "every month"},
+			wantFields: []string{"schedule"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(100)},
+				domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+				domain.Account{ID: "closed", UserID: "3", Balance: eur(0), DeletedAt: &closedAt},
+			)
+
+			got, err := bank.service.Create(tt.accountID, tt.terms)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Create() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if diff := cmp.Diff(tt.wantFields, validationFields(err)); diff != "" {
+				t.Fatalf("Create() error = %v, fields (-want +got):\n%s", err, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			stored, err := bank.service.Get(got.ID)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if diff := cmp.Diff(got, stored); diff != "" {
+				t.Errorf("Get() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_ExecuteDue(t *testing.T) {
+	firstOfFebruary := time.Date(2025, time.February, 1, 9, 0, 0, 0, time.UTC)
+	monthly := func(policy domain.FailurePolicy) domain.StandingOrderTerms {
+		return domain.StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "0 9 1 * *", Policy: policy}
+	}
+
+	type step struct {
+		// at is when ExecuteDue runs, deposit is paid into the debited account right before
+		at           time.Time
+		deposit      int64
+		wantExecuted int
+	}
+
+	tests := []struct {
+		name         string
+		balance      int64
+		terms        domain.StandingOrderTerms
+		closeTo      bool
+		steps        []step
+		wantStatuses []domain.ExecutionStatus
+		wantStatus   domain.StandingOrderStatus
+		wantNextRun  *time.Time
+		wantBalance  int64
+	}{
+		{
+			name:    "paid on every occurrence, missed ones are caught up",
+			balance: 300,
+			terms:   monthly(domain.FailurePolicy{}),
+			steps: []step{
+				{at: firstOfFebruary.Add(-time.Minute)},
+				{at: firstOfFebruary, wantExecuted: 1},
+				{at: firstOfFebruary.AddDate(0, 2, 0), wantExecuted: 2},
+			},
+			wantStatuses: []domain.ExecutionStatus{domain.ExecutionCompleted, domain.ExecutionCompleted, domain.ExecutionCompleted},
+			wantStatus:   domain.StandingOrderActive,
+			wantNextRun:  ptr(firstOfFebruary.AddDate(0, 3, 0)),
+			wantBalance:  0,
+		},
+		{
+			name:    "lack of funds is retried until the account is funded",
+			balance: 50,
+			terms:   monthly(domain.FailurePolicy{Retries: 2, RetryInterval: time.Hour}),
+			steps: []step{
+				{at: firstOfFebruary, wantExecuted: 1},
+				{at: firstOfFebruary.Add(30 * time.Minute)},
+				{at: firstOfFebruary.Add(time.Hour), deposit: 50, wantExecuted: 1},
+			},
+			wantStatuses: []domain.ExecutionStatus{domain.ExecutionRetrying, domain.ExecutionCompleted},
+			wantStatus:   domain.StandingOrderActive,
+			wantNextRun:  ptr(firstOfFebruary.AddDate(0, 1, 0)),
+			wantBalance:  0,
+		},
+		{
+			name:    "lack of funds is skipped once retries run out",
+			balance: 50,
+			terms:   monthly(domain.FailurePolicy{Retries: 1, RetryInterval: time.Hour, OnFailure: domain.SkipPayment}),
+			steps: []step{
+				{at: firstOfFebruary, wantExecuted: 1},
+				{at: firstOfFebruary.Add(time.Hour), wantExecuted: 1},
+			},
+			wantStatuses: []domain.ExecutionStatus{domain.ExecutionRetrying, domain.ExecutionSkipped},
+			wantStatus:   domain.StandingOrderActive,
+			wantNextRun:  ptr(firstOfFebruary.AddDate(0, 1, 0)),
+			wantBalance:  50,
+		},
+		{
+			name:    "a closed credited account suspends without retrying",
+			balance: 300,
+			terms:   monthly(domain.FailurePolicy{Retries: 3, RetryInterval: time.Hour, OnFailure: domain.SuspendOrder}),
+			closeTo: true,
+			steps: []step{
+				{at: firstOfFebruary, wantExecuted: 1},
+				{at: firstOfFebruary.AddDate(0, 1, 0)},
+			},
+			wantStatuses: []domain.ExecutionStatus{domain.ExecutionSuspended},
+			wantStatus:   domain.StandingOrderSuspended,
+			wantNextRun:  ptr(firstOfFebruary),
+			wantBalance:  300,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(tt.balance)},
+				domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+			)
+			order, err := bank.service.Create("1", tt.terms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.closeTo {
+				bank.closeAccount(t, "2")
+			}
+
+			for _, step := range tt.steps {
+				bank.clock.Set(step.at)
+				if step.deposit > 0 {
+					if _, err := bank.transactionService.Deposit(context.Background(), "1", eur(step.deposit)); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				executed, err := bank.service.ExecuteDue(context.Background())
+				if err != nil {
+					t.Fatalf("ExecuteDue() at %v error = %v", step.at, err)
+				}
+				if executed != step.wantExecuted {
+					t.Errorf("ExecuteDue() at %v executed %d, want %d", step.at, executed, step.wantExecuted)
+				}
+			}
+
+			executions, err := bank.service.GetExecutions(order.ID)
+			if err != nil {
+				t.Fatalf("GetExecutions() error = %v", err)
+			}
+			var statuses []domain.ExecutionStatus
+			for _, execution := range executions {
+				statuses = append(statuses, execution.Status)
+				if (execution.Status == domain.ExecutionCompleted) != (execution.TransactionID != "") {
+					t.Errorf("execution %d is %s with transaction %q", execution.Attempt, execution.Status, execution.TransactionID)
+				}
+				if execution.TransactionID != "" {
+					// the history and the executions tell the same time
+					if transfer, _ := bank.Transactions.Get(execution.TransactionID); !transfer.CreatedAt.Equal(execution.ExecutedAt) {
+						t.Errorf("execution %d made at %v, its transfer at %v", execution.Attempt, execution.ExecutedAt, transfer.CreatedAt)
+					}
+				}
+			}
+			if diff := cmp.Diff(tt.wantStatuses, statuses); diff != "" {
+				t.Errorf("GetExecutions() statuses (-want +got):\n%s", diff)
+			}
+
+			got, err := bank.service.Get(order.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("order status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if diff := cmp.Diff(tt.wantNextRun, got.NextRunAt); diff != "" {
+				t.Errorf("order next run (-want +got):\n%s", diff)
+			}
+			acc, _ := bank.Accounts.Get("1")
+			if acc.Balance != eur(tt.wantBalance) {
+				t.Errorf("account 1 balance = %v, want %v", acc.Balance, eur(tt.wantBalance))
+			}
+		})
+	}
+}
+
+func TestService_ExecuteDueStorageFailures(t *testing.T) {
+	firstOfFebruary := time.Date(2025, time.February, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		failures     failingUnitOfWorkFactory
+		wantStatuses []domain.ExecutionStatus
+	}{
+		{
+			name:         "execution not staged, the payment is rolled back and made on the next run",
+			failures:     failingUnitOfWorkFactory{failedInserts: 1},
+			wantStatuses: []domain.ExecutionStatus{domain.ExecutionRetrying, domain.ExecutionCompleted},
+		},
+		{
+			name:         "payment not committed, it's recorded as interrupted and made on the next run",
+			failures:     failingUnitOfWorkFactory{failedCommits: 1},
+			wantStatuses: []domain.ExecutionStatus{domain.ExecutionRetrying, domain.ExecutionCompleted},
+		},
+		{
+			name:         "neither the payment nor its failure committed, nothing is stored until the next run",
+			failures:     failingUnitOfWorkFactory{failedCommits: 2},
+			wantStatuses: []domain.ExecutionStatus{domain.ExecutionCompleted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(300)},
+				domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+			)
+			uows := &tt.failures
+			uows.UnitOfWorkFactory = bank.UnitOfWorkFactory
+			transactionSvc := transaction.NewService(uows, bank.Accounts, bank.Transactions, bank.Holds, bank.AccountLocker, servicetest.NoRates{}, servicetest.NoFees{}, bank.clock)
+			service := NewService(uows, bank.StandingOrders, bank.Accounts, transactionSvc, lock.NewManager(), bank.clock)
+
+			order, err := service.Create("1", domain.StandingOrderTerms{ToAccountID: "2", Amount: eur(100), Schedule: "0 9 1 * *"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bank.clock.Set(firstOfFebruary)
+			if _, err := service.ExecuteDue(context.Background()); !errors.Is(err, injectedError) {
+				t.Errorf("ExecuteDue() error = %v, wantErr %v", err, injectedError)
+			}
+			if _, err := service.ExecuteDue(context.Background()); err != nil {
+				t.Fatalf("ExecuteDue() retried error = %v", err)
+			}
+
+			for accountID, want := range map[string]int64{"1": 200, "2": 100} {
+				if acc, _ := bank.Accounts.Get(accountID); acc.Balance != eur(want) {
+					t.Errorf("account %s balance = %v, want %v", accountID, acc.Balance, eur(want))
+				}
+			}
+
+			executions, err := service.GetExecutions(order.ID)
+			if err != nil {
+				t.Fatalf("GetExecutions() error = %v", err)
+			}
+			var statuses []domain.ExecutionStatus
+			for _, execution := range executions {
+				statuses = append(statuses, execution.Status)
+			}
+			if diff := cmp.Diff(tt.wantStatuses, statuses); diff != "" {
+				t.Errorf("GetExecutions() statuses (-want +got):\n%s", diff)
+			}
+
+			got, _ := service.Get(order.ID)
+			if next := firstOfFebruary.AddDate(0, 1, 0); !got.NextRunAt.Equal(next) {
+				t.Errorf("order next run = %v, want %v", got.NextRunAt, next)
+			}
+		})
+	}
+}
+
+func TestService_CancelAndResume(t *testing.T) {
+	bank := newTestBank(
+		domain.Account{ID: "1", UserID: "1", Balance: eur(0)},
+		domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+	)
+	order, err := bank.service.Create("1", domain.StandingOrderTerms{
+		ToAccountID: "2",
+		Amount:      eur(100),
+		Schedule:    "FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=9;BYMINUTE=0",
+		Policy:      domain.FailurePolicy{OnFailure: domain.SuspendOrder},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conflictErr tberrors.ConflictError
+	if _, err := bank.service.Resume(context.Background(), order.ID); !errors.As(err, &conflictErr) {
+		t.Errorf("Resume() active order error = %v, want a conflict", err)
+	}
+
+	bank.clock.Set(time.Date(2025, time.February, 1, 9, 0, 0, 0, time.UTC))
+	if _, err := bank.service.ExecuteDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	bank.clock.Set(time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC))
+	resumed, err := bank.service.Resume(context.Background(), order.ID)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if want := time.Date(2025, time.April, 1, 9, 0, 0, 0, time.UTC); resumed.Status != domain.StandingOrderActive || !resumed.NextRunAt.Equal(want) {
+		t.Errorf("Resume() = %s order next run at %v, want active at %v", resumed.Status, resumed.NextRunAt, want)
+	}
+
+	if _, err := bank.service.Cancel(context.Background(), order.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	bank.clock.Set(time.Date(2025, time.May, 1, 9, 0, 0, 0, time.UTC))
+	if executed, err := bank.service.ExecuteDue(context.Background()); err != nil || executed != 0 {
+		t.Errorf("ExecuteDue() after Cancel() = %d, %v, want nothing executed", executed, err)
+	}
+
+	if _, err := bank.service.Cancel(context.Background(), "unknown"); !errors.Is(err, standingOrderNotFound) || !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Cancel() unknown order error = %v, wantErr %v", err, standingOrderNotFound)
+	}
+}
+
+type testBank struct {
+	*servicetest.Bank
+	transactionService *transaction.Service
+	clock              *clock.Manual
+	service            *Service
+}
+
+// newTestBank executes standing orders through a transaction service over the accounts, its clock starts at
+// createdAt
+func newTestBank(accounts ...domain.Account) *testBank {
+	bank := servicetest.NewBank(accounts...)
+	manual := clock.NewManual(createdAt)
+	transactionSvc := transaction.NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, bank.AccountLocker, servicetest.NoRates{}, servicetest.NoFees{}, manual)
+
+	return &testBank{
+		Bank:               bank,
+		transactionService: transactionSvc,
+		clock:              manual,
+		service:            NewService(bank.UnitOfWorkFactory, bank.StandingOrders, bank.Accounts, transactionSvc, lock.NewManager(), manual),
+	}
+}
+
+func (bank *testBank) closeAccount(t *testing.T, accountID string) {
+	acc, _ := bank.Accounts.Get(accountID)
+	if err := acc.Close(bank.clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bank.Accounts.Update(acc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var injectedError = errors.New("injected error")
+
+// failingUnitOfWorkFactory begins units of work that fail the first failedCommits commits and failedInserts
+// execution inserts of all of them
+type failingUnitOfWorkFactory struct {
+	repository.UnitOfWorkFactory
+	failedCommits int
+	failedInserts int
+}
+
+type failingUnitOfWork struct {
+	repository.UnitOfWork
+	factory *failingUnitOfWorkFactory
+}
+
+func (factory *failingUnitOfWorkFactory) Begin(ctx context.Context) (repository.UnitOfWork, error) {
+	uow, err := factory.UnitOfWorkFactory.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &failingUnitOfWork{UnitOfWork: uow, factory: factory}, nil
+}
+
+func (uow *failingUnitOfWork) InsertExecution(execution *domain.StandingOrderExecution) error {
+	if uow.factory.failedInserts > 0 {
+		uow.factory.failedInserts--
+		return injectedError
+	}
+	return uow.UnitOfWork.InsertExecution(execution)
+}
+
+func (uow *failingUnitOfWork) Commit() error {
+	if uow.factory.failedCommits > 0 {
+		uow.factory.failedCommits--
+		return injectedError
+	}
+	return uow.UnitOfWork.Commit()
+}
+
+// validationFields lists the field of every validation error joined in err
+func validationFields(err error) []string {
+	var fields []string
+	switch e := err.(type) {
+	case tberrors.ValidationError:
+		fields = append(fields, e.Field())
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			fields = append(fields, validationFields(inner)...)
+		}
+	}
+
+	return fields
+}
+
+func eur(amount int64) domain.Money {
+	return domain.NewMoney(amount, domain.EUR)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -2,9 +2,35 @@ package transaction
 
 import (
 	"errors"
+
+	"http/internal/tberrors"
 )
 
 var failedToCreateTransaction = errors.New("failed to create transaction")
 var failedToGetAccount error = errors.New("failed to get account")
 var failedAddBalance error = errors.New("failed to add balance")
-var failedToInsertTransaction = errors.New("failed to insert transaction")
+var failedToInsertTransaction = tberrors.NewInternalError("storage_failure", "failed to insert transaction")
+var failedToLockAccounts = tberrors.NewConflictError("account_busy", "failed to lock accounts, try again", "")
+var failedToBeginUnitOfWork = tberrors.NewInternalError("storage_failure", "failed to begin unit of work")
+var failedToCommit = tberrors.NewInternalError("storage_failure", "failed to commit transaction")
+var failedToCreateJournalEntry = errors.New("failed to create journal entry")
+var failedToInsertJournalEntry = tberrors.NewInternalError("storage_failure", "failed to insert journal entry")
+var invalidAccountID = tberrors.NewValidationError("missing_account_id", "invalid empty account ID", "account_id")
+var failedToConvert = errors.New("failed to convert transfer")
+var invalidDateRange = tberrors.NewValidationError("invalid_date_range", "from date must not be after to date", "from-date")
+var invalidAmountRange = tberrors.NewValidationError("invalid_amount_range", "min amount must not be above max amount", "min-amount")
+var filterCurrencyMismatch = tberrors.NewValidationError("currency_mismatch", "amounts must be in the account's currency", "currency")
+var failedToGetTransactions = tberrors.NewInternalError("storage_failure", "failed to get transactions")
+var failedToComputeBalance = errors.New("failed to compute balance")
+var failedToWriteStatement = errors.New("failed to write statement")
+var failedToChargeFee = errors.New("failed to charge fee")
+var failedToPlaceHold = errors.New("failed to place hold")
+var failedToReleaseHold = errors.New("failed to release hold")
+var invalidHoldID = tberrors.NewValidationError("missing_hold_id", "invalid empty hold ID", "hold_id")
+var holdNotFound = tberrors.NewNotFoundError("hold_not_found", "hold not found", "hold_id")
+var failedToGetHolds = tberrors.NewInternalError("storage_failure", "failed to get holds")
+var failedToInsertHold = tberrors.NewInternalError("storage_failure", "failed to insert hold")
+var failedToUpdateHold = tberrors.NewInternalError("storage_failure", "failed to update hold")
+var invalidTransactionID = tberrors.NewValidationError("missing_transaction_id", "invalid empty transaction ID", "transaction_id")
+var transactionNotFound = tberrors.NewNotFoundError("transaction_not_found", "transaction not found", "transaction_id")
+var failedToReverse = errors.New("failed to reverse transaction")
@@ -0,0 +1,243 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+type holdRepository interface {
+	Get(holdID string) (*domain.Hold, error)
+	GetByAccount(accountID string) ([]*domain.Hold, error)
+	Expired(at time.Time) ([]*domain.Hold, error)
+}
+
+// Authorize reserves amount, in the currency of accountID unless it names one, of what accountID can spend until
+// expiresAt. The hold is captured into toAccountID when it's set, both accounts must be open.
+func (service *Service) Authorize(ctx context.Context, accountID, toAccountID string, amount domain.Amount, expiresAt time.Time) (*domain.Hold, error) {
+	if accountID == "" {
+		return nil, invalidAccountID
+	}
+
+	unlock, err := service.lockAccounts(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	uow, err := service.unitOfWorkFactory.Begin(ctx)
+	if err != nil {
+		return nil, errors.Join(failedToBeginUnitOfWork, err)
+	}
+	defer uow.Rollback()
+
+	acc, err := getAccount(uow, accountID, "account_id")
+	if err != nil {
+		return nil, err
+	}
+	if toAccountID != "" && toAccountID != accountID {
+		if _, err := getAccount(uow, toAccountID, "to_account"); err != nil {
+			return nil, err
+		}
+	}
+
+	held, err := amount.In(acc.Balance.Currency)
+	if err != nil {
+		return nil, errors.Join(failedToPlaceHold, err)
+	}
+
+	hold, err := acc.PlaceHold(held, toAccountID, expiresAt, service.clock.Now())
+	if err != nil {
+		return nil, errors.Join(failedToPlaceHold, err)
+	}
+
+	if err := uow.UpdateAccount(acc); err != nil {
+		return nil, errors.Join(failedToPlaceHold, err)
+	}
+	if err := uow.InsertHold(hold); err != nil {
+		return nil, errors.Join(failedToInsertHold, err)
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, errors.Join(failedToCommit, err)
+	}
+
+	return hold, nil
+}
+
+// Capture pays amount of the hold, the whole held amount when it's nil, and releases the rest. amount is in the
+// currency of the hold unless it names one. The payment is a transfer into the account the hold names and a
+// withdrawal otherwise, fees are charged as for any other.
+func (service *Service) Capture(ctx context.Context, holdID string, amount domain.Amount) (*domain.Hold, *domain.Transaction, error) {
+	var transaction *domain.Transaction
+	hold, err := service.release(ctx, holdID, func(uow repository.UnitOfWork, acc *domain.Account, hold *domain.Hold) error {
+		captured := hold.Amount
+		var err error
+		if amount != nil {
+			if captured, err = amount.In(hold.Amount.Currency); err != nil {
+				return errors.Join(failedToReleaseHold, err)
+			}
+		}
+
+		now := service.clock.Now()
+		if hold.ToAccountID != "" {
+			transaction, err = domain.NewTransfer(hold.AccountID, hold.ToAccountID, captured, now)
+		} else {
+			transaction, err = domain.NewWithdrawal(hold.AccountID, captured, now)
+		}
+		if err != nil {
+			return errors.Join(failedToCreateTransaction, err)
+		}
+
+		if err := acc.CaptureHold(hold, captured, transaction.ID, now); err != nil {
+			return errors.Join(failedToReleaseHold, err)
+		}
+		if err := uow.UpdateAccount(acc); err != nil {
+			return errors.Join(failedToReleaseHold, err)
+		}
+
+		// the captured funds are only released now, paying them is like any other debit of the account
+		return service.stage(uow, transaction)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hold, transaction, nil
+}
+
+// Void releases the hold without paying anything
+func (service *Service) Void(ctx context.Context, holdID string) (*domain.Hold, error) {
+	return service.release(ctx, holdID, func(uow repository.UnitOfWork, acc *domain.Account, hold *domain.Hold) error {
+		if err := acc.VoidHold(hold, service.clock.Now()); err != nil {
+			return errors.Join(failedToReleaseHold, err)
+		}
+		if err := uow.UpdateAccount(acc); err != nil {
+			return errors.Join(failedToReleaseHold, err)
+		}
+
+		return nil
+	})
+}
+
+// ReleaseExpiredHolds gives back the funds of every hold that expired before it was captured or voided. It returns
+// how many holds were released, the ones that failed are left for the next call.
+func (service *Service) ReleaseExpiredHolds(ctx context.Context) (int, error) {
+	now := service.clock.Now()
+
+	holds, err := service.holdRepository.Expired(now)
+	if err != nil {
+		return 0, errors.Join(failedToGetHolds, err)
+	}
+
+	released := 0
+	var errs []error
+	for _, expired := range holds {
+		_, err := service.release(ctx, expired.ID, func(uow repository.UnitOfWork, acc *domain.Account, hold *domain.Hold) error {
+			if err := acc.ExpireHold(hold, now); err != nil {
+				return errors.Join(failedToReleaseHold, err)
+			}
+			if err := uow.UpdateAccount(acc); err != nil {
+				return errors.Join(failedToReleaseHold, err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hold %s: %w", expired.ID, err))
+			continue
+		}
+		released++
+	}
+
+	return released, errors.Join(errs...)
+}
+
+// release runs apply on the stored hold and its account while both accounts of the hold are locked, the changes
+// apply stages are committed with the hold
+func (service *Service) release(
+	ctx context.Context,
+	holdID string,
+	apply func(uow repository.UnitOfWork, acc *domain.Account, hold *domain.Hold) error,
+) (*domain.Hold, error) {
+	hold, err := service.GetHold(holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIDs := []string{hold.AccountID}
+	if hold.ToAccountID != "" {
+		accountIDs = append(accountIDs, hold.ToAccountID)
+	}
+
+	unlock, err := service.lockAccounts(ctx, accountIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	uow, err := service.unitOfWorkFactory.Begin(ctx)
+	if err != nil {
+		return nil, errors.Join(failedToBeginUnitOfWork, err)
+	}
+	defer uow.Rollback()
+
+	// the hold is read again as it may have been released since
+	if hold, err = uow.GetHold(holdID); err != nil {
+		return nil, errors.Join(failedToGetHolds, err)
+	}
+
+	// the accounts of a deleted user are closed with their holds, voiding or expiring them still gives the funds back
+	acc, err := uow.GetAccount(hold.AccountID)
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
+
+	if err := apply(uow, acc, hold); err != nil {
+		return nil, err
+	}
+
+	if err := uow.UpdateHold(hold); err != nil {
+		return nil, errors.Join(failedToUpdateHold, err)
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, errors.Join(failedToCommit, err)
+	}
+
+	return hold, nil
+}
+
+func (service *Service) GetHold(holdID string) (*domain.Hold, error) {
+	if holdID == "" {
+		return nil, invalidHoldID
+	}
+
+	hold, err := service.holdRepository.Get(holdID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(holdNotFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetHolds, err)
+	}
+
+	return hold, nil
+}
+
+// GetAccountHolds lists every hold placed on accountID, released ones included, oldest first
+func (service *Service) GetAccountHolds(accountID string) ([]*domain.Hold, error) {
+	if _, err := service.getHistoryAccount(accountID); err != nil {
+		return nil, err
+	}
+
+	holds, err := service.holdRepository.GetByAccount(accountID)
+	if err != nil {
+		return nil, errors.Join(failedToGetHolds, err)
+	}
+
+	return holds, nil
+}
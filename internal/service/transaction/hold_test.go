@@ -0,0 +1,221 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/lock"
+	"http/internal/service/servicetest"
+)
+
+func TestService_Holds(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	captured := eur(2500)
+
+	tests := []struct {
+		name         string
+		toAccountID  string
+		release      func(service *Service, manual *clock.Manual, holdID string) error
+		wantStatus   domain.HoldStatus
+		wantErr      error
+		wantBalances map[string]int64
+		wantHeld     int64
+		// wantTransactions counts the ones of account 1
+		wantTransactions int
+	}{
+		{
+			name:        "capture part of a hold into another account",
+			toAccountID: "2",
+			release: func(service *Service, _ *clock.Manual, holdID string) error {
+				_, _, err := service.Capture(context.Background(), holdID, captured)
+				return err
+			},
+			wantStatus:       domain.HoldCaptured,
+			wantBalances:     map[string]int64{"1": 7500, "2": 2500},
+			wantTransactions: 1,
+		},
+		{
+			name: "capture the whole hold as a withdrawal",
+			release: func(service *Service, _ *clock.Manual, holdID string) error {
+				_, _, err := service.Capture(context.Background(), holdID, nil)
+				return err
+			},
+			wantStatus:       domain.HoldCaptured,
+			wantBalances:     map[string]int64{"1": 4000},
+			wantTransactions: 1,
+		},
+		{
+			name: "void",
+			release: func(service *Service, _ *clock.Manual, holdID string) error {
+				_, err := service.Void(context.Background(), holdID)
+				return err
+			},
+			wantStatus:   domain.HoldVoided,
+			wantBalances: map[string]int64{"1": 10000},
+		},
+		{
+			name: "withdrawal of the held funds, want failedAddBalance",
+			release: func(service *Service, _ *clock.Manual, _ string) error {
+				_, err := service.Withdraw(context.Background(), "1", eur(5000))
+				return err
+			},
+			wantStatus:   domain.HoldActive,
+			wantErr:      failedAddBalance,
+			wantBalances: map[string]int64{"1": 10000},
+			wantHeld:     6000,
+		},
+		{
+			name: "capture once expired, want failedToReleaseHold",
+			release: func(service *Service, manual *clock.Manual, holdID string) error {
+				manual.Advance(time.Hour)
+				_, _, err := service.Capture(context.Background(), holdID, nil)
+				return err
+			},
+			wantStatus:   domain.HoldActive,
+			wantErr:      failedToReleaseHold,
+			wantBalances: map[string]int64{"1": 10000},
+			wantHeld:     6000,
+		},
+		{
+			name: "capture above the held amount, want failedToReleaseHold",
+			release: func(service *Service, _ *clock.Manual, holdID string) error {
+				above := eur(6001)
+				_, _, err := service.Capture(context.Background(), holdID, above)
+				return err
+			},
+			wantStatus:   domain.HoldActive,
+			wantErr:      failedToReleaseHold,
+			wantBalances: map[string]int64{"1": 10000},
+			wantHeld:     6000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(10000)},
+				domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+			)
+			manual := clock.NewManual(now)
+			service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, manual)
+
+			hold, err := service.Authorize(context.Background(), "1", tt.toAccountID, eur(6000), now.Add(time.Hour))
+			if err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+
+			if err := tt.release(service, manual, hold.ID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("release error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, err := service.GetHold(hold.ID)
+			if err != nil {
+				t.Fatalf("GetHold() error = %v", err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("hold status got = %v, want %v", got.Status, tt.wantStatus)
+			}
+
+			acc, _ := bank.Accounts.Get("1")
+			if acc.Held != eur(tt.wantHeld) {
+				t.Errorf("held got = %v, want %v", acc.Held, eur(tt.wantHeld))
+			}
+			bank.assertBooks(t, tt.wantBalances, tt.wantTransactions)
+		})
+	}
+}
+
+func TestService_Authorize(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	closedAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		accountID   string
+		toAccountID string
+		amount      domain.Money
+		wantErr     error
+	}{
+		{
+			name:      "beyond the available balance, want failedToPlaceHold",
+			accountID: "1",
+			amount:    eur(10001),
+			wantErr:   failedToPlaceHold,
+		},
+		{
+			name:        "captured into a closed account, want failedToGetAccount",
+			accountID:   "1",
+			toAccountID: "closed",
+			amount:      eur(100),
+			wantErr:     failedToGetAccount,
+		},
+		{
+			name:      "unknown account, want failedToGetAccount",
+			accountID: "unknown",
+			amount:    eur(100),
+			wantErr:   failedToGetAccount,
+		},
+		{
+			name:    "empty account ID, want invalidAccountID",
+			amount:  eur(100),
+			wantErr: invalidAccountID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(10000)},
+				domain.Account{ID: "closed", UserID: "1", Balance: eur(0), DeletedAt: &closedAt},
+			)
+			service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.NewManual(now))
+
+			if _, err := service.Authorize(context.Background(), tt.accountID, tt.toAccountID, tt.amount, now.Add(time.Hour)); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			holds, _ := bank.Holds.GetByAccount("1")
+			acc, _ := bank.Accounts.Get("1")
+			if len(holds) != 0 || acc.Held != (domain.Money{}) {
+				t.Errorf("got %v holds and %v held, want none", len(holds), acc.Held)
+			}
+		})
+	}
+}
+
+func TestService_ReleaseExpiredHolds(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	bank := newTestBank(domain.Account{ID: "1", UserID: "1", Balance: eur(10000)})
+	manual := clock.NewManual(now)
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, manual)
+
+	soon, err := service.Authorize(context.Background(), "1", "", eur(1000), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	later, err := service.Authorize(context.Background(), "1", "", eur(2000), now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	manual.Advance(time.Hour)
+	released, err := service.ReleaseExpiredHolds(context.Background())
+	if err != nil || released != 1 {
+		t.Fatalf("ReleaseExpiredHolds() = %v, %v, want 1 hold released", released, err)
+	}
+
+	for holdID, want := range map[string]domain.HoldStatus{soon.ID: domain.HoldExpired, later.ID: domain.HoldActive} {
+		if got, _ := service.GetHold(holdID); got.Status != want {
+			t.Errorf("hold %s status got = %v, want %v", holdID, got.Status, want)
+		}
+	}
+	if acc, _ := bank.Accounts.Get("1"); acc.Held != eur(2000) || acc.Balance != eur(10000) {
+		t.Errorf("account got %v held and %v balance, want %v held and the balance untouched", acc.Held, acc.Balance, eur(2000))
+	}
+
+	if released, err := service.ReleaseExpiredHolds(context.Background()); err != nil || released != 0 {
+		t.Errorf("ReleaseExpiredHolds() again = %v, %v, want nothing released", released, err)
+	}
+}
@@ -0,0 +1,116 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+
+	"http/internal/domain"
+	"http/internal/repository"
+)
+
+// Reverse refunds amount of a transaction, whatever is left to refund of it when amount is nil. amount is in the
+// currency the transaction credited unless it names one. The reversal moves the money back between the accounts of
+// the transaction, its fee isn't refunded.
+func (service *Service) Reverse(ctx context.Context, transactionID string, amount domain.Amount) (*domain.Transaction, error) {
+	original, err := service.getTransaction(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var accountIDs []string
+	for _, accountID := range []*string{original.FromAccountID, original.ToAccountID} {
+		if accountID != nil {
+			accountIDs = append(accountIDs, *accountID)
+		}
+	}
+
+	unlock, err := service.lockAccounts(ctx, accountIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// every reversal of the transaction locks the same accounts, none can be made while the earlier ones are read
+	reversals, err := service.getReversals(original.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refund, err := original.Refundable(reversals)
+	if err != nil {
+		return nil, errors.Join(failedToReverse, err)
+	}
+	if amount != nil {
+		if refund, err = amount.In(refund.Currency); err != nil {
+			return nil, errors.Join(failedToReverse, err)
+		}
+	}
+
+	reversal, err := domain.NewReversal(original, reversals, refund, service.clock.Now())
+	if err != nil {
+		return nil, errors.Join(failedToReverse, err)
+	}
+
+	if _, err := service.commit(ctx, reversal); err != nil {
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// GetTransaction finds a transaction with the fee it was charged and the reversals made of it, oldest first
+func (service *Service) GetTransaction(transactionID string) (*domain.Transaction, []domain.Transaction, error) {
+	transaction, err := service.getTransaction(transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	children, err := service.transactionRepository.GetByParent(transaction.ID)
+	if err != nil {
+		return nil, nil, errors.Join(failedToGetTransactions, err)
+	}
+
+	reversals := make([]domain.Transaction, 0)
+	for _, child := range children {
+		switch child.Type {
+		case domain.Fee:
+			transaction.Fee = &child
+		case domain.Reversal:
+			reversals = append(reversals, child)
+		}
+	}
+
+	return transaction, reversals, nil
+}
+
+func (service *Service) getTransaction(transactionID string) (*domain.Transaction, error) {
+	if transactionID == "" {
+		return nil, invalidTransactionID
+	}
+
+	transaction, err := service.transactionRepository.Get(transactionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(transactionNotFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetTransactions, err)
+	}
+
+	return transaction, nil
+}
+
+func (service *Service) getReversals(transactionID string) ([]domain.Transaction, error) {
+	children, err := service.transactionRepository.GetByParent(transactionID)
+	if err != nil {
+		return nil, errors.Join(failedToGetTransactions, err)
+	}
+
+	var reversals []domain.Transaction
+	for _, child := range children {
+		if child.Type == domain.Reversal {
+			reversals = append(reversals, child)
+		}
+	}
+
+	return reversals, nil
+}
@@ -0,0 +1,263 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/clock"
+	"http/internal/domain"
+	"http/internal/lock"
+	"http/internal/service/servicetest"
+)
+
+func TestService_Reverse(t *testing.T) {
+	tests := []struct {
+		name         string
+		reverse      func(service *Service, original *domain.Transaction) error
+		wantErr      error
+		wantBalances map[string]int64
+		// wantTransactions counts the ones of account 1
+		wantTransactions int
+	}{
+		{
+			name: "whole transfer",
+			reverse: func(service *Service, original *domain.Transaction) error {
+				_, err := service.Reverse(context.Background(), original.ID, nil)
+				return err
+			},
+			wantBalances:     map[string]int64{"1": 10000, "2": 0},
+			wantTransactions: 2,
+		},
+		{
+			name: "partial refunds, then the rest",
+			reverse: func(service *Service, original *domain.Transaction) error {
+				refund := eur(1000)
+				for range 2 {
+					if _, err := service.Reverse(context.Background(), original.ID, refund); err != nil {
+						return err
+					}
+				}
+				_, err := service.Reverse(context.Background(), original.ID, nil)
+				return err
+			},
+			wantBalances:     map[string]int64{"1": 10000, "2": 0},
+			wantTransactions: 4,
+		},
+		{
+			name: "more than what's left to refund, want failedToReverse",
+			reverse: func(service *Service, original *domain.Transaction) error {
+				refund := eur(5000)
+				if _, err := service.Reverse(context.Background(), original.ID, refund); err != nil {
+					return err
+				}
+				_, err := service.Reverse(context.Background(), original.ID, refund)
+				return err
+			},
+			wantErr:          failedToReverse,
+			wantBalances:     map[string]int64{"1": 9000, "2": 1000},
+			wantTransactions: 2,
+		},
+		{
+			name: "refunded in full already, want failedToReverse",
+			reverse: func(service *Service, original *domain.Transaction) error {
+				if _, err := service.Reverse(context.Background(), original.ID, nil); err != nil {
+					return err
+				}
+				_, err := service.Reverse(context.Background(), original.ID, nil)
+				return err
+			},
+			wantErr:          failedToReverse,
+			wantBalances:     map[string]int64{"1": 10000, "2": 0},
+			wantTransactions: 2,
+		},
+		{
+			name: "money already spent by the payee, want failedAddBalance",
+			reverse: func(service *Service, original *domain.Transaction) error {
+				if _, err := service.Withdraw(context.Background(), "2", eur(5000)); err != nil {
+					return err
+				}
+				_, err := service.Reverse(context.Background(), original.ID, nil)
+				return err
+			},
+			wantErr:          failedAddBalance,
+			wantBalances:     map[string]int64{"1": 4000, "2": 1000},
+			wantTransactions: 1,
+		},
+		{
+			name: "reversal of a reversal, want failedToReverse",
+			reverse: func(service *Service, original *domain.Transaction) error {
+				reversal, err := service.Reverse(context.Background(), original.ID, nil)
+				if err != nil {
+					return err
+				}
+				_, err = service.Reverse(context.Background(), reversal.ID, nil)
+				return err
+			},
+			wantErr:          failedToReverse,
+			wantBalances:     map[string]int64{"1": 10000, "2": 0},
+			wantTransactions: 2,
+		},
+		{
+			name: "unknown transaction, want transactionNotFound",
+			reverse: func(service *Service, _ *domain.Transaction) error {
+				_, err := service.Reverse(context.Background(), "unknown", nil)
+				return err
+			},
+			wantErr:          transactionNotFound,
+			wantBalances:     map[string]int64{"1": 4000, "2": 6000},
+			wantTransactions: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(10000)},
+				domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+			)
+			service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+			original, err := service.Transfer(context.Background(), "1", "2", eur(6000))
+			if err != nil {
+				t.Fatalf("Transfer() error = %v", err)
+			}
+
+			if err := tt.reverse(service, original); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("reverse error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			bank.assertBooks(t, tt.wantBalances, tt.wantTransactions)
+		})
+	}
+}
+
+func TestService_ReverseDepositAndWithdrawal(t *testing.T) {
+	bank := newTestBank(domain.Account{ID: "1", UserID: "1", Balance: eur(10000)})
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+	deposit, err := service.Deposit(context.Background(), "1", eur(3000))
+	if err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	withdrawal, err := service.Withdraw(context.Background(), "1", eur(2000))
+	if err != nil {
+		t.Fatalf("Withdraw() error = %v", err)
+	}
+
+	// the cash was paid out already, reversing the withdrawal would pay it to the account a second time
+	for _, original := range []*domain.Transaction{deposit, withdrawal} {
+		if _, err := service.Reverse(context.Background(), original.ID, nil); !errors.Is(err, failedToReverse) {
+			t.Errorf("Reverse() of the %s error = %v, wantErr %v", original.Type, err, failedToReverse)
+		}
+	}
+
+	bank.assertBooks(t, map[string]int64{"1": 11000}, 2)
+}
+
+func TestService_ReverseConverted(t *testing.T) {
+	rates := fixedRates{{From: domain.EUR, To: domain.USD, Rate: "1.10", UpdatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}}
+	bank := newTestBank(
+		domain.Account{ID: "eur", UserID: "1", Balance: eur(1000)},
+		domain.Account{ID: "usd", UserID: "2", Balance: domain.NewMoney(0, domain.USD)},
+	)
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), rates, servicetest.NoFees{}, clock.System{})
+
+	original, err := service.Transfer(context.Background(), "eur", "usd", eur(1000))
+	if err != nil {
+		t.Fatalf("Transfer() error = %v", err)
+	}
+
+	// the refunds are shared out at the rate of the transfer, the last one gives back what's left
+	refund := domain.NewMoney(333, domain.USD)
+	for _, amount := range []domain.Amount{refund, refund, nil} {
+		if _, err := service.Reverse(context.Background(), original.ID, amount); err != nil {
+			t.Fatalf("Reverse() error = %v", err)
+		}
+	}
+
+	for accountID, want := range map[string]domain.Money{"eur": eur(1000), "usd": domain.NewMoney(0, domain.USD)} {
+		if acc, _ := bank.Accounts.Get(accountID); acc.Balance != want {
+			t.Errorf("account %s balance got = %v, want %v", accountID, acc.Balance, want)
+		}
+	}
+
+	totals, _ := bank.Ledger.GetTotals()
+	for _, total := range totals {
+		if total.AccountID == domain.FXPositionAccountID {
+			if balance, _ := total.Balance(); !balance.IsZero() {
+				t.Errorf("fx position in %s got = %v, want it back to zero", total.Currency, balance)
+			}
+		}
+	}
+}
+
+func TestService_ReverseConcurrently(t *testing.T) {
+	bank := newTestBank(
+		domain.Account{ID: "1", UserID: "1", Balance: eur(10000)},
+		domain.Account{ID: "2", UserID: "2", Balance: eur(10000)},
+	)
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+	original, err := service.Transfer(context.Background(), "1", "2", eur(6000))
+	if err != nil {
+		t.Fatalf("Transfer() error = %v", err)
+	}
+
+	// the payee can afford every refund, only what's left to refund of the transfer stops them
+	var wg sync.WaitGroup
+	refund := eur(1000)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service.Reverse(context.Background(), original.ID, refund)
+		}()
+	}
+	wg.Wait()
+
+	bank.assertBooks(t, map[string]int64{"1": 10000, "2": 10000}, 7)
+}
+
+func TestService_GetTransaction(t *testing.T) {
+	bank := newTestBank(
+		domain.Account{ID: "1", UserID: "1", Balance: eur(10000)},
+		domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+	)
+	fees := fixedFees{mustFeeRule(t, domain.Transfer, 100, "", 0, 0)}
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, fees, clock.System{})
+
+	original, err := service.Transfer(context.Background(), "1", "2", eur(6000))
+	if err != nil {
+		t.Fatalf("Transfer() error = %v", err)
+	}
+	refund := eur(2500)
+	first, err := service.Reverse(context.Background(), original.ID, refund)
+	if err != nil {
+		t.Fatalf("Reverse() error = %v", err)
+	}
+	second, err := service.Reverse(context.Background(), original.ID, refund)
+	if err != nil {
+		t.Fatalf("Reverse() error = %v", err)
+	}
+
+	got, reversals, err := service.GetTransaction(original.ID)
+	if err != nil {
+		t.Fatalf("GetTransaction() error = %v", err)
+	}
+	if diff := cmp.Diff(original, got); diff != "" {
+		t.Errorf("GetTransaction() (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]domain.Transaction{*first, *second}, reversals); diff != "" {
+		t.Errorf("GetTransaction() reversals (-want +got):\n%s", diff)
+	}
+
+	// the fee is kept, only the transfer is refunded
+	bank.assertBooks(t, map[string]int64{"1": 8900, "2": 1000}, 4)
+
+	if _, _, err := service.GetTransaction(""); !errors.Is(err, invalidTransactionID) {
+		t.Errorf("GetTransaction() error = %v, wantErr %v", err, invalidTransactionID)
+	}
+}
@@ -1,149 +1,807 @@
 package transaction
 
 import (
+	"context"
 	"errors"
-	"sync"
+	"fmt"
+	"sort"
 	"time"
 
+	"github.com/lithammer/shortuuid/v4"
+	"http/internal/clock"
 	"http/internal/domain"
+	"http/internal/pagination"
+	"http/internal/repository"
+	"http/internal/tberrors"
 )
 
-//go:generate go run github.com/vektra/mockery/v2 --name=accountService --structname=AccountService --output=mocks/
-type accountService interface {
-	Get(accountID string) (*domain.Account, error)
-	AddBalance(accountID string, balance int) (*domain.Account, error)
+// lockTimeout bounds how long an operation waits for its accounts to be free
+const lockTimeout = 5 * time.Second
+
+// historyBatchSize is how many transactions are read at a time when replaying a whole history
+const historyBatchSize = 500
+
+type unitOfWorkFactory interface {
+	Begin(ctx context.Context) (repository.UnitOfWork, error)
+}
+
+type accountRepository interface {
+	Get(accID string) (*domain.Account, error)
 }
 
 type transactionRepository interface {
-	Insert(transaction *domain.Transaction) (*domain.Transaction, error)
-	GetAccountTransactions(accountID string, fromDate time.Time, toDate time.Time) ([]domain.Transaction, error)
+	Get(transactionID string) (*domain.Transaction, error)
+	GetByParent(parentID string) ([]domain.Transaction, error)
+	GetAccountTransactions(accountID string, filter repository.TransactionFilter, page repository.Page[repository.TransactionCursor]) ([]domain.Transaction, error)
+}
+
+// statementWriter encodes a statement as it's being read
+type statementWriter interface {
+	Begin(statement domain.Statement) error
+	Entry(entry domain.StatementEntry) error
+	End() error
+}
+
+type accountLocker interface {
+	Lock(ctx context.Context, keys ...string) (func(), error)
+}
+
+type exchangeRates interface {
+	Rate(from, to domain.Currency) (domain.ExchangeRate, error)
+}
+
+type feeSchedule interface {
+	Rule(transactionType domain.TransactionType, currency domain.Currency) (domain.FeeRule, bool)
 }
 
 type Service struct {
-	accountService        accountService
+	unitOfWorkFactory     unitOfWorkFactory
+	accountRepository     accountRepository
 	transactionRepository transactionRepository
-
-	// TODO isolate this in it's own package
-	mapAccessMutex sync.Mutex
-	mutexMap       map[string]*sync.Mutex
+	holdRepository        holdRepository
+	accountLocker         accountLocker
+	exchangeRates         exchangeRates
+	fees                  feeSchedule
+	clock                 clock.Clock
 }
 
-func NewService(accountService accountService, transactionRepository transactionRepository) *Service {
+func NewService(
+	unitOfWorkFactory unitOfWorkFactory,
+	accountRepository accountRepository,
+	transactionRepository transactionRepository,
+	holdRepository holdRepository,
+	accountLocker accountLocker,
+	exchangeRates exchangeRates,
+	fees feeSchedule,
+	clock clock.Clock,
+) *Service {
 	return &Service{
-		accountService:        accountService,
+		unitOfWorkFactory:     unitOfWorkFactory,
+		accountRepository:     accountRepository,
 		transactionRepository: transactionRepository,
-		mapAccessMutex:        sync.Mutex{},
-		mutexMap:              make(map[string]*sync.Mutex),
+		holdRepository:        holdRepository,
+		accountLocker:         accountLocker,
+		exchangeRates:         exchangeRates,
+		fees:                  fees,
+		clock:                 clock,
 	}
 }
 
-func (service *Service) Transfer(fromAccountID, toAccountID string, amount int) (*domain.Transaction, error) {
-	service.mapAccessMutex.Lock()
+// Transfer moves amount, in the currency of fromAccountID unless it names one, to toAccountID
+func (service *Service) Transfer(ctx context.Context, fromAccountID, toAccountID string, amount domain.Amount) (*domain.Transaction, error) {
+	return service.TransferWith(ctx, fromAccountID, toAccountID, amount, nil)
+}
 
-	if service.mutexMap[fromAccountID] == nil {
-		service.mutexMap[fromAccountID] = &sync.Mutex{}
+// TransferWith makes a transfer as Transfer does, stage is given the unit of work of the transfer to stage the records
+// of the caller that must be stored along with it: they're committed together or not at all
+func (service *Service) TransferWith(ctx context.Context, fromAccountID, toAccountID string, amount domain.Amount,
+	stage func(uow repository.UnitOfWork, transaction *domain.Transaction) error) (*domain.Transaction, error) {
+	unlock, err := service.lockAccounts(ctx, fromAccountID, toAccountID)
+	if err != nil {
+		return nil, err
 	}
-	service.mutexMap[fromAccountID].Lock()
-	defer service.mutexMap[fromAccountID].Unlock()
+	defer unlock()
 
-	if service.mutexMap[toAccountID] == nil {
-		service.mutexMap[toAccountID] = &sync.Mutex{}
+	money, err := service.amountIn(fromAccountID, "from_account", amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := domain.NewTransfer(fromAccountID, toAccountID, money, service.clock.Now())
+	if err != nil {
+		return nil, errors.Join(failedToCreateTransaction, err)
 	}
-	service.mutexMap[toAccountID].Lock()
-	defer service.mutexMap[toAccountID].Unlock()
 
-	service.mapAccessMutex.Unlock()
+	if _, err := service.commitWith(ctx, stage, transaction); err != nil {
+		return nil, err
+	}
 
-	fromAccount, err := service.accountService.AddBalance(fromAccountID, -amount)
+	return transaction, nil
+}
+
+// QuoteTransfer goes through a transfer as Transfer would, converting it and charging its fee, without making it. The
+// transaction returned and its fee were never stored.
+func (service *Service) QuoteTransfer(ctx context.Context, fromAccountID, toAccountID string, amount domain.Amount) (*domain.Transaction, error) {
+	unlock, err := service.lockAccounts(ctx, fromAccountID, toAccountID)
 	if err != nil {
-		return nil, errors.Join(failedAddBalance, err)
+		return nil, err
 	}
+	defer unlock()
 
-	toAccount, err := service.accountService.AddBalance(toAccountID, amount)
+	money, err := service.amountIn(fromAccountID, "from_account", amount)
 	if err != nil {
-		return nil, errors.Join(failedAddBalance, err)
+		return nil, err
 	}
 
-	transaction, err := domain.NewTransfer(fromAccount.ID, toAccount.ID, amount)
+	transaction, err := domain.NewTransfer(fromAccountID, toAccountID, money, service.clock.Now())
 	if err != nil {
 		return nil, errors.Join(failedToCreateTransaction, err)
 	}
 
-	newTransaction, err := service.transactionRepository.Insert(transaction)
+	uow, err := service.unitOfWorkFactory.Begin(ctx)
 	if err != nil {
-		return nil, errors.Join(failedToInsertTransaction, err)
+		return nil, errors.Join(failedToBeginUnitOfWork, err)
+	}
+	// nothing staged is ever committed
+	defer uow.Rollback()
+
+	if err := service.stage(uow, transaction); err != nil {
+		return nil, err
 	}
 
-	return newTransaction, nil
+	return transaction, nil
 }
 
-func (service *Service) Deposit(toAccountID string, amount int) (*domain.Transaction, error) {
-	service.mapAccessMutex.Lock()
+// TransferOrder is one of the transfers TransferAll makes
+type TransferOrder struct {
+	ToAccountID string
+	Amount      domain.Money
+}
+
+// TransferError tells which transfer of TransferAll failed, none of them was made
+type TransferError struct {
+	Index int
+	Err   error
+}
+
+func (err TransferError) Error() string {
+	return fmt.Sprintf("transfer %d: %s", err.Index, err.Err)
+}
+
+func (err TransferError) Unwrap() error {
+	return err.Err
+}
 
-	if service.mutexMap[toAccountID] == nil {
-		service.mutexMap[toAccountID] = &sync.Mutex{}
+// TransferAll makes every transfer out of fromAccountID in a single unit of work, either all of them are made or
+// none is. A transfer that can't be made is reported as a TransferError.
+func (service *Service) TransferAll(ctx context.Context, fromAccountID string, orders []TransferOrder) ([]*domain.Transaction, error) {
+	accountIDs := []string{fromAccountID}
+	for _, order := range orders {
+		accountIDs = append(accountIDs, order.ToAccountID)
 	}
-	service.mutexMap[toAccountID].Lock()
-	defer service.mutexMap[toAccountID].Unlock()
 
-	service.mapAccessMutex.Unlock()
+	unlock, err := service.lockAccounts(ctx, accountIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	now := service.clock.Now()
+	transactions := make([]*domain.Transaction, len(orders))
+	for i, order := range orders {
+		if transactions[i], err = domain.NewTransfer(fromAccountID, order.ToAccountID, order.Amount, now); err != nil {
+			return nil, TransferError{Index: i, Err: errors.Join(failedToCreateTransaction, err)}
+		}
+	}
 
-	toAccount, err := service.accountService.AddBalance(toAccountID, amount)
+	index, err := service.commit(ctx, transactions...)
+	if err != nil && index >= 0 {
+		return nil, TransferError{Index: index, Err: err}
+	}
 	if err != nil {
-		return nil, errors.Join(failedAddBalance, err)
+		return nil, err
 	}
 
-	transaction, err := domain.NewDeposit(toAccount.ID, amount)
+	return transactions, nil
+}
+
+// Deposit pays amount, in the currency of toAccountID unless it names one, into toAccountID
+func (service *Service) Deposit(ctx context.Context, toAccountID string, amount domain.Amount) (*domain.Transaction, error) {
+	unlock, err := service.lockAccounts(ctx, toAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	money, err := service.amountIn(toAccountID, "account_id", amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := domain.NewDeposit(toAccountID, money, service.clock.Now())
 	if err != nil {
 		return nil, errors.Join(failedToCreateTransaction, err)
 	}
 
-	newTransaction, err := service.transactionRepository.Insert(transaction)
+	if _, err := service.commit(ctx, transaction); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// Withdraw takes amount, in the currency of fromAccountID unless it names one, out of fromAccountID
+func (service *Service) Withdraw(ctx context.Context, fromAccountID string, amount domain.Amount) (*domain.Transaction, error) {
+	unlock, err := service.lockAccounts(ctx, fromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	money, err := service.amountIn(fromAccountID, "account_id", amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := domain.NewWithdrawal(fromAccountID, money, service.clock.Now())
 	if err != nil {
-		return nil, errors.Join(failedToInsertTransaction, err)
+		return nil, errors.Join(failedToCreateTransaction, err)
+	}
+
+	if _, err := service.commit(ctx, transaction); err != nil {
+		return nil, err
 	}
 
-	return newTransaction, nil
+	return transaction, nil
+}
+
+// AmountRange bounds the amounts a history lists, both bounds are optional and in the account's currency unless
+// they name one
+type AmountRange struct {
+	Min domain.Amount
+	Max domain.Amount
 }
 
-func (service *Service) Withdraw(fromAccountID string, amount int) (*domain.Transaction, error) {
-	service.mapAccessMutex.Lock()
+// GetAccountTransactionHistory lists the transactions of an account that match filter, with the amount bounds of
+// amounts, which must be in the account's currency
+func (service *Service) GetAccountTransactionHistory(
+	accountID string,
+	filter repository.TransactionFilter,
+	amounts AmountRange,
+	pageRequest pagination.Request,
+) (pagination.Page[domain.Transaction], error) {
+	acc, err := service.getHistoryAccount(accountID)
+	if err != nil {
+		return pagination.Page[domain.Transaction]{}, err
+	}
 
-	if service.mutexMap[fromAccountID] == nil {
-		service.mutexMap[fromAccountID] = &sync.Mutex{}
+	if filter.MinAmount, err = boundIn(amounts.Min, acc.Balance.Currency, "min-amount"); err != nil {
+		return pagination.Page[domain.Transaction]{}, err
+	}
+	if filter.MaxAmount, err = boundIn(amounts.Max, acc.Balance.Currency, "max-amount"); err != nil {
+		return pagination.Page[domain.Transaction]{}, err
 	}
-	service.mutexMap[fromAccountID].Lock()
-	defer service.mutexMap[fromAccountID].Unlock()
 
-	service.mapAccessMutex.Unlock()
+	if err := validateFilter(filter, acc.Balance.Currency); err != nil {
+		return pagination.Page[domain.Transaction]{}, err
+	}
 
-	fromAccount, err := service.accountService.AddBalance(fromAccountID, -amount)
+	after, err := pagination.Decode[repository.TransactionCursor](pageRequest.Cursor)
 	if err != nil {
-		return nil, errors.Join(failedAddBalance, err)
+		return pagination.Page[domain.Transaction]{}, err
 	}
 
-	transaction, err := domain.NewWithdrawal(fromAccount.ID, amount)
+	transactions, err := service.transactionRepository.GetAccountTransactions(accountID, filter,
+		repository.Page[repository.TransactionCursor]{After: after, Limit: pageRequest.FetchLimit()})
 	if err != nil {
-		return nil, errors.Join(failedToCreateTransaction, err)
+		return pagination.Page[domain.Transaction]{}, err
 	}
 
-	newTransaction, err := service.transactionRepository.Insert(transaction)
+	return pagination.NewPage(transactions, pageRequest.Limit, repository.CursorOf)
+}
+
+// GetAccountBalance replays the transactions of an account up to asOf, included, to find what its balance was then
+func (service *Service) GetAccountBalance(accountID string, asOf time.Time) (domain.Money, error) {
+	acc, err := service.getHistoryAccount(accountID)
 	if err != nil {
-		return nil, errors.Join(failedToInsertTransaction, err)
+		return domain.Money{}, err
 	}
 
-	return newTransaction, nil
+	return service.balanceAsOf(acc, asOf)
 }
 
-func (service *Service) GetAccountTransactionHistory(accountID string, fromDate time.Time, toDate time.Time) ([]domain.Transaction, error) {
-	if accountID == "" {
-		return nil, errors.New("invalid empty account ID")
+// GetEndOfDayBalances replays the history of an account once to find the balance it ended every day with, from the
+// day of from through the day of to. Days are UTC days, transactions count on the day they're valued at, so interest
+// posted later counts on the last day it was accrued for.
+func (service *Service) GetEndOfDayBalances(accountID string, from, to time.Time) ([]domain.Money, error) {
+	acc, err := service.getHistoryAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if from.After(to) {
+		return nil, invalidDateRange
+	}
+
+	// every day ends when the next one starts
+	var ends []time.Time
+	last := to.UTC().Truncate(24 * time.Hour)
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(last); day = day.AddDate(0, 0, 1) {
+		ends = append(ends, day.AddDate(0, 0, 1))
+	}
+
+	// the history is in the order transactions were made, which isn't the order they're valued in, so every day
+	// totals its own changes and the balances add them up once it's replayed
+	changes := make([]domain.Money, len(ends))
+	for i := range changes {
+		changes[i] = domain.NewMoney(0, acc.Balance.Currency)
+	}
+	err = service.replayHistory(acc.ID, repository.TransactionFilter{}, func(transaction domain.Transaction) (bool, error) {
+		day := sort.Search(len(ends), func(i int) bool { return transaction.ValuedAt().Before(ends[i]) })
+		if day == len(ends) {
+			return true, nil
+		}
+
+		change, err := transaction.BalanceChange(acc.ID)
+		if err != nil {
+			return false, err
+		}
+
+		changes[day], err = changes[day].Add(change)
+		return true, err
+	})
+	if err != nil {
+		return nil, errors.Join(failedToComputeBalance, err)
+	}
+
+	balances := make([]domain.Money, len(ends))
+	balance := domain.NewMoney(0, acc.Balance.Currency)
+	for i, change := range changes {
+		if balance, err = balance.Add(change); err != nil {
+			return nil, errors.Join(failedToComputeBalance, err)
+		}
+		balances[i] = balance
 	}
 
-	transactions, err := service.transactionRepository.GetAccountTransactions(accountID, fromDate, toDate)
+	return balances, nil
+}
+
+func (service *Service) balanceAsOf(acc *domain.Account, asOf time.Time) (domain.Money, error) {
+	balance := domain.NewMoney(0, acc.Balance.Currency)
+	err := service.replayHistory(acc.ID, repository.TransactionFilter{ToDate: asOf}, func(transaction domain.Transaction) (bool, error) {
+		change, err := transaction.BalanceChange(acc.ID)
+		if err != nil {
+			return false, err
+		}
+
+		balance, err = balance.Add(change)
+		return true, err
+	})
+	if err != nil {
+		return domain.Money{}, errors.Join(failedToComputeBalance, err)
+	}
+
+	return balance, nil
+}
+
+// WriteStatement hands writer the statement of an account between from and to, both included, then its entries one
+// at a time. Both balances are replayed from the history before the first entry is written.
+func (service *Service) WriteStatement(accountID string, from, to time.Time, writer statementWriter) error {
+	acc, err := service.getHistoryAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if from.After(to) {
+		return invalidDateRange
+	}
+
+	opening, err := service.balanceAsOf(acc, from.Add(-time.Nanosecond))
+	if err != nil {
+		return err
+	}
+	closing, err := service.balanceAsOf(acc, to)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Begin(domain.Statement{
+		ID:             shortuuid.New(),
+		AccountID:      acc.ID,
+		AccountType:    acc.Type,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		ClosingBalance: closing,
+		CreatedAt:      service.clock.Now(),
+	})
+	if err != nil {
+		return errors.Join(failedToWriteStatement, err)
+	}
+
+	balance := opening
+	err = service.replayHistory(acc.ID, repository.TransactionFilter{FromDate: from, ToDate: to}, func(transaction domain.Transaction) (bool, error) {
+		change, err := transaction.BalanceChange(acc.ID)
+		if err != nil {
+			return false, errors.Join(failedToComputeBalance, err)
+		}
+		if balance, err = balance.Add(change); err != nil {
+			return false, errors.Join(failedToComputeBalance, err)
+		}
+
+		entry := domain.StatementEntry{
+			Transaction:  transaction,
+			Amount:       change,
+			BalanceAfter: balance,
+			Counterparty: transaction.Counterparty(acc.ID),
+		}
+		if err := writer.Entry(entry); err != nil {
+			return false, errors.Join(failedToWriteStatement, err)
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.End(); err != nil {
+		return errors.Join(failedToWriteStatement, err)
+	}
+
+	return nil
+}
+
+// GetBalancesAfter replays the history of an account up to the newest of transactions and returns the balance right
+// after each of them, in the same order
+func (service *Service) GetBalancesAfter(accountID string, transactions []domain.Transaction) ([]domain.Money, error) {
+	acc, err := service.getHistoryAccount(accountID)
 	if err != nil {
 		return nil, err
 	}
+	if len(transactions) == 0 {
+		return []domain.Money{}, nil
+	}
 
-	return transactions, nil
+	positions := make(map[string]int, len(transactions))
+	newest := repository.CursorOf(transactions[0])
+	for i, transaction := range transactions {
+		positions[transaction.ID] = i
+		if cursor := repository.CursorOf(transaction); cursor.Compare(newest) > 0 {
+			newest = cursor
+		}
+	}
+
+	balances := make([]domain.Money, len(transactions))
+	balance := domain.NewMoney(0, acc.Balance.Currency)
+	err = service.replayHistory(accountID, repository.TransactionFilter{ToDate: newest.CreatedAt}, func(transaction domain.Transaction) (bool, error) {
+		change, err := transaction.BalanceChange(accountID)
+		if err != nil {
+			return false, err
+		}
+		if balance, err = balance.Add(change); err != nil {
+			return false, err
+		}
+
+		if i, ok := positions[transaction.ID]; ok {
+			balances[i] = balance
+		}
+
+		return repository.CursorOf(transaction).Compare(newest) < 0, nil
+	})
+	if err != nil {
+		return nil, errors.Join(failedToComputeBalance, err)
+	}
+
+	return balances, nil
+}
+
+// replayHistory calls fn with every transaction of an account matching filter, oldest first, until fn returns false
+// or fails. The history is read in batches so it never has to fit in memory at once.
+func (service *Service) replayHistory(accountID string, filter repository.TransactionFilter, fn func(domain.Transaction) (bool, error)) error {
+	page := repository.Page[repository.TransactionCursor]{Limit: historyBatchSize}
+	for {
+		transactions, err := service.transactionRepository.GetAccountTransactions(accountID, filter, page)
+		if err != nil {
+			return errors.Join(failedToGetTransactions, err)
+		}
+
+		for _, transaction := range transactions {
+			if next, err := fn(transaction); err != nil || !next {
+				return err
+			}
+		}
+
+		if len(transactions) < historyBatchSize {
+			return nil
+		}
+		after := repository.CursorOf(transactions[len(transactions)-1])
+		page.After = &after
+	}
+}
+
+// getHistoryAccount finds an account whose history is read, closed accounts keep theirs
+func (service *Service) getHistoryAccount(accountID string) (*domain.Account, error) {
+	if accountID == "" {
+		return nil, invalidAccountID
+	}
+
+	acc, err := service.accountRepository.Get(accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound := tberrors.NewNotFoundError("account_not_found", "account not found", "account_id")
+		return nil, errors.Join(failedToGetAccount, notFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
+
+	return acc, nil
+}
+
+// boundIn reads an optional amount bound of a history in currency, parameter names the bound in the request
+func boundIn(bound domain.Amount, currency domain.Currency, parameter string) (*domain.Money, error) {
+	if bound == nil {
+		return nil, nil
+	}
+
+	amount, err := bound.In(currency)
+	if err != nil {
+		message := fmt.Sprintf("invalid %s parameter: %s", parameter, err)
+		return nil, tberrors.NewValidationError("invalid_parameter", message, parameter)
+	}
+
+	return &amount, nil
+}
+
+func validateFilter(filter repository.TransactionFilter, currency domain.Currency) error {
+	var errs []error
+	if !filter.FromDate.IsZero() && !filter.ToDate.IsZero() && filter.FromDate.After(filter.ToDate) {
+		errs = append(errs, invalidDateRange)
+	}
+
+	for _, amount := range []*domain.Money{filter.MinAmount, filter.MaxAmount} {
+		if amount != nil && amount.Currency != currency {
+			errs = append(errs, filterCurrencyMismatch)
+			break
+		}
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && filter.MinAmount.Amount > filter.MaxAmount.Amount {
+		errs = append(errs, invalidAmountRange)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (service *Service) lockAccounts(ctx context.Context, accountIDs ...string) (func(), error) {
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+
+	unlock, err := service.accountLocker.Lock(ctx, accountIDs...)
+	if err != nil {
+		return nil, errors.Join(failedToLockAccounts, err)
+	}
+
+	return unlock, nil
+}
+
+type balanceChange struct {
+	account *domain.Account
+	// debit takes the amount out of the account instead of adding it
+	debit bool
+}
+
+// commit stages every transaction together with its balance changes and journal entry in a single unit of work,
+// either all of them are persisted or none is. When a transaction can't be staged its index is returned with the
+// error, otherwise the index is -1.
+func (service *Service) commit(ctx context.Context, transactions ...*domain.Transaction) (int, error) {
+	return service.commitWith(ctx, nil, transactions...)
+}
+
+// commitWith commits transactions as commit does, along with what stage adds to the unit of work for the first one
+func (service *Service) commitWith(ctx context.Context, stage func(repository.UnitOfWork, *domain.Transaction) error,
+	transactions ...*domain.Transaction) (int, error) {
+	uow, err := service.unitOfWorkFactory.Begin(ctx)
+	if err != nil {
+		return -1, errors.Join(failedToBeginUnitOfWork, err)
+	}
+	defer uow.Rollback()
+
+	for i, transaction := range transactions {
+		if err := service.stage(uow, transaction); err != nil {
+			return i, err
+		}
+	}
+
+	if stage != nil {
+		if err := stage(uow, transactions[0]); err != nil {
+			return -1, err
+		}
+	}
+
+	if err := uow.Commit(); err != nil {
+		return -1, errors.Join(failedToCommit, err)
+	}
+
+	return -1, nil
+}
+
+// stage changes the balances of the accounts of transaction and inserts it with its journal entry, followed by its
+// fee when it has one
+func (service *Service) stage(uow repository.UnitOfWork, transaction *domain.Transaction) error {
+	var err error
+	var changes []balanceChange
+	var from, to *domain.Account
+	if transaction.FromAccountID != nil {
+		if from, err = getAccount(uow, *transaction.FromAccountID, accountField(transaction, "from_account")); err != nil {
+			return err
+		}
+		changes = append(changes, balanceChange{account: from, debit: true})
+	}
+	if transaction.ToAccountID != nil {
+		if to, err = getAccount(uow, *transaction.ToAccountID, accountField(transaction, "to_account")); err != nil {
+			return err
+		}
+		changes = append(changes, balanceChange{account: to})
+	}
+
+	// reversals of converted transfers come with the rate the transfer was made at
+	if from != nil && to != nil && from.Balance.Currency != to.Balance.Currency && transaction.Conversion == nil {
+		if err := service.convert(transaction, from.Balance.Currency, to.Balance.Currency); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range changes {
+		amount := transaction.DestinationAmount()
+		if change.debit {
+			if amount, err = transaction.Amount.Neg(); err != nil {
+				return errors.Join(failedAddBalance, err)
+			}
+		}
+
+		if err := change.account.AddBalance(amount); err != nil {
+			return errors.Join(failedAddBalance, err)
+		}
+
+		if err := uow.UpdateAccount(change.account); err != nil {
+			return errors.Join(failedAddBalance, err)
+		}
+	}
+
+	if err := uow.InsertTransaction(transaction); err != nil {
+		return errors.Join(failedToInsertTransaction, err)
+	}
+
+	entry, err := domain.NewJournalEntry(transaction)
+	if err != nil {
+		return errors.Join(failedToCreateJournalEntry, err)
+	}
+
+	if err := uow.InsertJournalEntry(entry); err != nil {
+		return errors.Join(failedToInsertJournalEntry, err)
+	}
+
+	payer := from
+	if payer == nil {
+		payer = to
+	}
+
+	return service.chargeFee(uow, transaction, payer)
+}
+
+// chargeFee stages the fee the schedule has for transaction, paid by payer in its currency. The fee is charged on the
+// amount that left the source account and, like any other debit, has to fit in the balance or overdraft of payer.
+func (service *Service) chargeFee(uow repository.UnitOfWork, transaction *domain.Transaction, payer *domain.Account) error {
+	rule, ok := service.fees.Rule(transaction.Type, transaction.Amount.Currency)
+	if !ok {
+		return nil
+	}
+
+	charge, err := rule.Charge(transaction.Amount)
+	if err != nil {
+		return errors.Join(failedToChargeFee, err)
+	}
+	if charge.IsZero() {
+		return nil
+	}
+
+	fee, err := domain.NewFee(payer.ID, charge, transaction)
+	if err != nil {
+		return errors.Join(failedToChargeFee, err)
+	}
+
+	debit, err := charge.Neg()
+	if err != nil {
+		return errors.Join(failedToChargeFee, err)
+	}
+	if err := payer.AddBalance(debit); err != nil {
+		return errors.Join(failedToChargeFee, err)
+	}
+
+	if err := uow.UpdateAccount(payer); err != nil {
+		return errors.Join(failedAddBalance, err)
+	}
+
+	if err := uow.InsertTransaction(fee); err != nil {
+		return errors.Join(failedToInsertTransaction, err)
+	}
+
+	entry, err := domain.NewJournalEntry(fee)
+	if err != nil {
+		return errors.Join(failedToCreateJournalEntry, err)
+	}
+
+	if err := uow.InsertJournalEntry(entry); err != nil {
+		return errors.Join(failedToInsertJournalEntry, err)
+	}
+
+	transaction.Fee = fee
+
+	return nil
+}
+
+// convert applies the current rate to a transfer between accounts in different currencies, it's rejected when the
+// table has no rate for the pair
+func (service *Service) convert(transaction *domain.Transaction, from, to domain.Currency) error {
+	rate, err := service.exchangeRates.Rate(from, to)
+	if err != nil {
+		return errors.Join(failedToConvert, err)
+	}
+
+	if err := transaction.ApplyRate(rate); err != nil {
+		return errors.Join(failedToConvert, err)
+	}
+
+	return nil
+}
+
+// amountIn reads amount in the currency of the account it's taken from or paid into, field names the account in the
+// request. The account is read again, and checked, once the transaction is staged.
+func (service *Service) amountIn(accountID, field string, amount domain.Amount) (domain.Money, error) {
+	if accountID == "" {
+		missing := tberrors.NewValidationError("missing_account_id", "invalid empty account ID", field)
+		return domain.Money{}, errors.Join(failedToCreateTransaction, missing)
+	}
+
+	acc, err := service.accountRepository.Get(accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound := tberrors.NewNotFoundError("account_not_found", "account not found", field)
+		return domain.Money{}, errors.Join(failedToGetAccount, notFound, err)
+	}
+	if err != nil {
+		return domain.Money{}, errors.Join(failedToGetAccount, err)
+	}
+
+	money, err := amount.In(acc.Balance.Currency)
+	if err != nil {
+		return domain.Money{}, errors.Join(failedToCreateTransaction, err)
+	}
+
+	return money, nil
+}
+
+func getAccount(uow repository.UnitOfWork, accountID, field string) (*domain.Account, error) {
+	acc, err := uow.GetAccount(accountID)
+	if errors.Is(err, repository.ErrNotFound) {
+		notFound := tberrors.NewNotFoundError("account_not_found", "account not found", field)
+		return nil, errors.Join(failedToGetAccount, notFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetAccount, err)
+	}
+
+	// closed accounts keep their history but never move money again
+	if acc.Closed() {
+		closed := tberrors.NewForbiddenError("account_closed", "account is closed", field)
+		return nil, errors.Join(failedToGetAccount, closed)
+	}
+
+	return acc, nil
+}
+
+// accountField names the request field holding an account, deposits and withdrawals take it from the path
+func accountField(transaction *domain.Transaction, transferField string) string {
+	if transaction.Type == domain.Transfer {
+		return transferField
+	}
+
+	return "account_id"
 }
@@ -1,91 +1,104 @@
 package transaction
 
 import (
+	"context"
 	"errors"
+	"math/rand"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/lithammer/shortuuid/v4"
+	"http/internal/clock"
 	"http/internal/domain"
-	"http/internal/repository/memory"
-	"http/internal/service/transaction/mocks"
+	"http/internal/lock"
+	"http/internal/pagination"
+	"http/internal/repository"
+	"http/internal/service/servicetest"
+	"http/internal/tberrors"
 )
 
 func TestService_Transfer(t *testing.T) {
-	transactionRepository := memory.NewTransactionRepository()
-	fromAccount := &domain.Account{
-		ID:      "1",
-		UserID:  "1",
-		Balance: 100,
-	}
-
-	toAccount := &domain.Account{
-		ID:      "2",
-		UserID:  "2",
-		Balance: 0,
-	}
+	fromAccountID := "1"
+	toAccountID := "2"
 
-	type fields struct {
-		accountService func() accountService
-	}
 	type args struct {
 		fromAccountID string
 		toAccountID   string
-		amount        int
+		amount        int64
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    *domain.Transaction
-		wantErr error
+		name             string
+		args             args
+		want             *domain.Transaction
+		wantErr          error
+		wantFromBalance  int64
+		wantToBalance    int64
+		wantTransactions int
 	}{
 		{
 			name: "successful transfer",
-			fields: fields{
-				accountService: func() accountService {
-					accServiceMock := mocks.NewAccountService(t)
-					accServiceMock.On("AddBalance", fromAccount.ID, -100).Return(fromAccount, nil)
-					accServiceMock.On("AddBalance", toAccount.ID, 100).Return(toAccount, nil)
-					return accServiceMock
-				},
-			},
 			args: args{
-				fromAccountID: fromAccount.ID,
-				toAccountID:   toAccount.ID,
+				fromAccountID: fromAccountID,
+				toAccountID:   toAccountID,
 				amount:        100,
 			},
 			want: &domain.Transaction{
-				FromAccountID: &fromAccount.ID,
-				ToAccountID:   &toAccount.ID,
-				Amount:        100,
+				FromAccountID: &fromAccountID,
+				ToAccountID:   &toAccountID,
+				Amount:        eur(100),
 				Type:          domain.Transfer,
 			},
+			wantFromBalance:  0,
+			wantToBalance:    100,
+			wantTransactions: 1,
 		},
 		{
 			name: "failed transfer, fromAccount without enough balance, return failedAddBalance",
-			fields: fields{
-				accountService: func() accountService {
-					accServiceMock := mocks.NewAccountService(t)
-					accServiceMock.On("AddBalance", fromAccount.ID, -1000).Return(nil, errors.New("not enough balance"))
-					return accServiceMock
-				},
-			},
 			args: args{
-				fromAccountID: fromAccount.ID,
-				toAccountID:   toAccount.ID,
+				fromAccountID: fromAccountID,
+				toAccountID:   toAccountID,
 				amount:        1000,
 			},
-			wantErr: failedAddBalance,
+			wantErr:         failedAddBalance,
+			wantFromBalance: 100,
+			wantToBalance:   0,
+		},
+		{
+			name: "failed transfer, toAccount does not exist, return failedToGetAccount",
+			args: args{
+				fromAccountID: fromAccountID,
+				toAccountID:   "invalid",
+				amount:        100,
+			},
+			wantErr:         failedToGetAccount,
+			wantFromBalance: 100,
+			wantToBalance:   0,
+		},
+		{
+			name: "failed transfer, invalid amount, return failedToCreateTransaction",
+			args: args{
+				fromAccountID: fromAccountID,
+				toAccountID:   toAccountID,
+				amount:        -100,
+			},
+			wantErr:         failedToCreateTransaction,
+			wantFromBalance: 100,
+			wantToBalance:   0,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			service := NewService(tt.fields.accountService(), transactionRepository)
+			bank := newTestBank(
+				domain.Account{ID: fromAccountID, UserID: "1", Balance: eur(100)},
+				domain.Account{ID: toAccountID, UserID: "2", Balance: eur(0)},
+			)
+			service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
 
-			got, err := service.Transfer(tt.args.fromAccountID, tt.args.toAccountID, tt.args.amount)
+			got, err := service.Transfer(context.Background(), tt.args.fromAccountID, tt.args.toAccountID, eur(tt.args.amount))
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("Transfer() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -94,12 +107,485 @@ func TestService_Transfer(t *testing.T) {
 			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(domain.Transaction{}, "ID", "CreatedAt")); diff != "" {
 				t.Errorf("Transfer() (-want +got):\n%s", diff)
 			}
+
+			bank.assertBooks(t, map[string]int64{
+				fromAccountID: tt.wantFromBalance,
+				toAccountID:   tt.wantToBalance,
+			}, tt.wantTransactions)
+		})
+	}
+}
+
+func TestService_TransferConverted(t *testing.T) {
+	rateAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	rates := fixedRates{
+		{From: domain.EUR, To: domain.USD, Rate: "1.10", UpdatedAt: rateAt},
+	}
+
+	tests := []struct {
+		name           string
+		fromAccountID  string
+		toAccountID    string
+		amount         domain.Money
+		wantConversion *domain.FXConversion
+		wantErr        error
+		wantEUR        int64
+		wantUSD        int64
+	}{
+		{
+			name:          "successful transfer, converted with the published rate",
+			fromAccountID: "eur",
+			toAccountID:   "usd",
+			amount:        eur(1000),
+			wantConversion: &domain.FXConversion{
+				DestinationAmount: domain.NewMoney(1100, domain.USD),
+				Rate:              "1.10",
+				RateTimestamp:     rateAt,
+			},
+			wantEUR: 0,
+			wantUSD: 1100,
+		},
+		{
+			name:          "failed transfer, no rate published, return failedToConvert",
+			fromAccountID: "usd",
+			toAccountID:   "eur",
+			amount:        domain.NewMoney(100, domain.USD),
+			wantErr:       failedToConvert,
+			wantEUR:       1000,
+			wantUSD:       0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "eur", UserID: "1", Balance: eur(1000)},
+				domain.Account{ID: "usd", UserID: "2", Balance: domain.NewMoney(0, domain.USD)},
+			)
+			service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), rates, servicetest.NoFees{}, clock.System{})
+
+			got, err := service.Transfer(context.Background(), tt.fromAccountID, tt.toAccountID, tt.amount)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transfer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if diff := cmp.Diff(tt.wantConversion, got.Conversion); diff != "" {
+					t.Errorf("Transfer() conversion (-want +got):\n%s", diff)
+				}
+			}
+
+			wantBalances := map[string]domain.Money{
+				"eur": eur(tt.wantEUR),
+				"usd": domain.NewMoney(tt.wantUSD, domain.USD),
+			}
+			for accountID, want := range wantBalances {
+				acc, _ := bank.Accounts.Get(accountID)
+				if acc.Balance != want {
+					t.Errorf("account %s balance got = %v, want %v", accountID, acc.Balance, want)
+				}
+
+				total, _ := bank.Ledger.GetAccountTotal(accountID, want.Currency)
+				if ledgerBalance, _ := total.Balance(); ledgerBalance != want {
+					t.Errorf("account %s ledger balance got = %v, want %v", accountID, ledgerBalance, want)
+				}
+			}
+
+			trialBalance, _ := bank.Ledger.GetTotals()
+			for _, total := range trialBalance {
+				if total.AccountID == domain.FXPositionAccountID && tt.wantErr == nil {
+					if balance, _ := total.Balance(); balance.IsZero() {
+						t.Errorf("fx position in %s got = 0, want the converted amount", total.Currency)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestService_Fees(t *testing.T) {
+	fees := fixedFees{
+		mustFeeRule(t, domain.Transfer, 0, "1", 50, 200),
+		mustFeeRule(t, domain.Withdrawal, 100, "", 0, 0),
+	}
+
+	tests := []struct {
+		name         string
+		move         func(service *Service) (*domain.Transaction, error)
+		wantFee      int64
+		wantErr      error
+		wantBalances map[string]int64
+		wantRevenue  int64
+	}{
+		{
+			name: "transfer pays a percentage kept above the minimum",
+			move: func(service *Service) (*domain.Transaction, error) {
+				return service.Transfer(context.Background(), "1", "2", eur(1000))
+			},
+			wantFee:      50,
+			wantBalances: map[string]int64{"1": 8950, "2": 1000},
+			wantRevenue:  50,
+		},
+		{
+			name: "withdrawal pays a flat fee",
+			move: func(service *Service) (*domain.Transaction, error) {
+				return service.Withdraw(context.Background(), "1", eur(1000))
+			},
+			wantFee:      100,
+			wantBalances: map[string]int64{"1": 8900},
+			wantRevenue:  100,
+		},
+		{
+			name: "deposit without a fee rule is free",
+			move: func(service *Service) (*domain.Transaction, error) {
+				return service.Deposit(context.Background(), "1", eur(1000))
+			},
+			wantBalances: map[string]int64{"1": 11000},
+		},
+		{
+			name: "fee beyond the funds left, nothing moves",
+			move: func(service *Service) (*domain.Transaction, error) {
+				return service.Transfer(context.Background(), "1", "2", eur(9950))
+			},
+			wantErr:      failedToChargeFee,
+			wantBalances: map[string]int64{"1": 10000, "2": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(10000)},
+				domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+			)
+			service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, fees, clock.System{})
+
+			got, err := tt.move(service)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("move error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			wantTransactions := 0
+			if err == nil {
+				wantTransactions = 1
+				if tt.wantFee == 0 && got.Fee != nil {
+					t.Errorf("fee got = %v, want none", got.Fee)
+				}
+				if tt.wantFee != 0 {
+					wantTransactions = 2
+					if got.Fee == nil || got.Fee.Amount != eur(tt.wantFee) || got.Fee.ParentID != got.ID || *got.Fee.FromAccountID != "1" {
+						t.Errorf("fee got = %+v, want %v paid by account 1 for %s", got.Fee, eur(tt.wantFee), got.ID)
+					}
+				}
+			}
+			bank.assertBooks(t, tt.wantBalances, wantTransactions)
+
+			total, _ := bank.Ledger.GetAccountTotal(domain.FeeRevenueAccountID, domain.EUR)
+			if revenue, _ := total.Balance(); revenue != eur(tt.wantRevenue) {
+				t.Errorf("fee revenue got = %v, want %v", revenue, eur(tt.wantRevenue))
+			}
 		})
 	}
 }
 
+func TestService_QuoteTransfer(t *testing.T) {
+	bank := newTestBank(
+		domain.Account{ID: "1", UserID: "1", Balance: eur(10000)},
+		domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+	)
+	fees := fixedFees{mustFeeRule(t, domain.Transfer, 0, "1", 50, 200)}
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, fees, clock.System{})
+
+	got, err := service.QuoteTransfer(context.Background(), "1", "2", eur(8000))
+	if err != nil {
+		t.Fatalf("QuoteTransfer() error = %v", err)
+	}
+	if got.Fee == nil || got.Fee.Amount != eur(80) {
+		t.Errorf("QuoteTransfer() fee got = %+v, want %v", got.Fee, eur(80))
+	}
+	bank.assertBooks(t, map[string]int64{"1": 10000, "2": 0}, 0)
+
+	if _, err := service.QuoteTransfer(context.Background(), "1", "2", eur(9950)); !errors.Is(err, failedToChargeFee) {
+		t.Errorf("QuoteTransfer() beyond the funds error = %v, wantErr %v", err, failedToChargeFee)
+	}
+}
+
+func TestService_AmountInAccountCurrency(t *testing.T) {
+	bank := newTestBank(
+		domain.Account{ID: "usd", UserID: "1", Balance: domain.NewMoney(0, domain.USD)},
+		domain.Account{ID: "jpy", UserID: "2", Balance: domain.NewMoney(1000, domain.JPY)},
+	)
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+	deposit, err := service.Deposit(context.Background(), "usd", domain.DecimalAmount("12.34"))
+	if err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	if want := domain.NewMoney(1234, domain.USD); deposit.Amount != want {
+		t.Errorf("Deposit() amount got = %v, want %v", deposit.Amount, want)
+	}
+	if acc, _ := bank.Accounts.Get("usd"); acc.Balance != domain.NewMoney(1234, domain.USD) {
+		t.Errorf("account usd balance got = %v, want %v", acc.Balance, domain.NewMoney(1234, domain.USD))
+	}
+
+	// the currency of the account decides how many decimal places the amount can have
+	if _, err := service.Withdraw(context.Background(), "jpy", domain.DecimalAmount("1.5")); !errors.Is(err, failedToCreateTransaction) {
+		t.Errorf("Withdraw() error = %v, wantErr %v", err, failedToCreateTransaction)
+	}
+}
+
+func TestService_ClosedAccount(t *testing.T) {
+	closedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		move      func(service *Service) (*domain.Transaction, error)
+		wantField string
+	}{
+		{
+			name: "deposit to closed account",
+			move: func(service *Service) (*domain.Transaction, error) {
+				return service.Deposit(context.Background(), "closed", eur(10))
+			},
+			wantField: "account_id",
+		},
+		{
+			name: "withdraw from closed account",
+			move: func(service *Service) (*domain.Transaction, error) {
+				return service.Withdraw(context.Background(), "closed", eur(10))
+			},
+			wantField: "account_id",
+		},
+		{
+			name: "transfer from closed account",
+			move: func(service *Service) (*domain.Transaction, error) {
+				return service.Transfer(context.Background(), "closed", "1", eur(10))
+			},
+			wantField: "from_account",
+		},
+		{
+			name: "transfer to closed account",
+			move: func(service *Service) (*domain.Transaction, error) {
+				return service.Transfer(context.Background(), "1", "closed", eur(10))
+			},
+			wantField: "to_account",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(100)},
+				domain.Account{ID: "closed", UserID: "1", Balance: eur(100), DeletedAt: &closedAt},
+			)
+			service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+			_, err := tt.move(service)
+
+			var forbidden tberrors.ForbiddenError
+			if !errors.As(err, &forbidden) || forbidden.Code() != "account_closed" || forbidden.Field() != tt.wantField {
+				t.Fatalf("error = %v, want account_closed forbidden error on %s", err, tt.wantField)
+			}
+
+			bank.assertBooks(t, map[string]int64{"1": 100, "closed": 100}, 0)
+		})
+	}
+}
+
+func TestService_TransferFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		failOn     string
+		failOnCall int
+		wantErr    error
+	}{
+		{
+			name:       "fail to begin unit of work, return failedToBeginUnitOfWork",
+			failOn:     "Begin",
+			failOnCall: 1,
+			wantErr:    failedToBeginUnitOfWork,
+		},
+		{
+			name:       "fail to get debited account, return failedToGetAccount",
+			failOn:     "GetAccount",
+			failOnCall: 1,
+			wantErr:    failedToGetAccount,
+		},
+		{
+			name:       "fail to get credited account, return failedToGetAccount",
+			failOn:     "GetAccount",
+			failOnCall: 2,
+			wantErr:    failedToGetAccount,
+		},
+		{
+			name:       "fail to update debited account, return failedAddBalance",
+			failOn:     "UpdateAccount",
+			failOnCall: 1,
+			wantErr:    failedAddBalance,
+		},
+		{
+			name:       "fail to update credited account, return failedAddBalance",
+			failOn:     "UpdateAccount",
+			failOnCall: 2,
+			wantErr:    failedAddBalance,
+		},
+		{
+			name:       "fail to insert transaction, return failedToInsertTransaction",
+			failOn:     "InsertTransaction",
+			failOnCall: 1,
+			wantErr:    failedToInsertTransaction,
+		},
+		{
+			name:       "fail to insert journal entry, return failedToInsertJournalEntry",
+			failOn:     "InsertJournalEntry",
+			failOnCall: 1,
+			wantErr:    failedToInsertJournalEntry,
+		},
+		{
+			name:       "fail to commit, return failedToCommit",
+			failOn:     "Commit",
+			failOnCall: 1,
+			wantErr:    failedToCommit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(100)},
+				domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+			)
+			factory := &failingUnitOfWorkFactory{
+				factory:    bank.UnitOfWorkFactory,
+				failOn:     tt.failOn,
+				failOnCall: tt.failOnCall,
+				calls:      make(map[string]int),
+			}
+			service := NewService(factory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+			if _, err := service.Transfer(context.Background(), "1", "2", eur(100)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Transfer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			bank.assertBooks(t, map[string]int64{"1": 100, "2": 0}, 0)
+		})
+	}
+}
+
+func TestService_TransferAll(t *testing.T) {
+	tests := []struct {
+		name         string
+		orders       []TransferOrder
+		failOn       string
+		wantErr      error
+		wantIndex    int
+		wantBalances map[string]int64
+	}{
+		{
+			name:         "every transfer is made",
+			orders:       []TransferOrder{{ToAccountID: "2", Amount: eur(30)}, {ToAccountID: "3", Amount: eur(50)}, {ToAccountID: "2", Amount: eur(20)}},
+			wantIndex:    -1,
+			wantBalances: map[string]int64{"1": 0, "2": 50, "3": 50},
+		},
+		{
+			name:         "a transfer overdraws, want its index and nothing made",
+			orders:       []TransferOrder{{ToAccountID: "2", Amount: eur(60)}, {ToAccountID: "3", Amount: eur(60)}},
+			wantErr:      failedAddBalance,
+			wantIndex:    1,
+			wantBalances: map[string]int64{"1": 100, "2": 0, "3": 0},
+		},
+		{
+			name:         "an invalid transfer, want its index and nothing made",
+			orders:       []TransferOrder{{ToAccountID: "2", Amount: eur(10)}, {ToAccountID: "1", Amount: eur(10)}},
+			wantErr:      failedToCreateTransaction,
+			wantIndex:    1,
+			wantBalances: map[string]int64{"1": 100, "2": 0, "3": 0},
+		},
+		{
+			name:         "an unknown account, want its index and nothing made",
+			orders:       []TransferOrder{{ToAccountID: "unknown", Amount: eur(10)}, {ToAccountID: "2", Amount: eur(10)}},
+			wantErr:      repository.ErrNotFound,
+			wantIndex:    0,
+			wantBalances: map[string]int64{"1": 100, "2": 0, "3": 0},
+		},
+		{
+			name:         "fail to commit, want no index and nothing made",
+			orders:       []TransferOrder{{ToAccountID: "2", Amount: eur(10)}, {ToAccountID: "3", Amount: eur(10)}},
+			failOn:       "Commit",
+			wantErr:      failedToCommit,
+			wantIndex:    -1,
+			wantBalances: map[string]int64{"1": 100, "2": 0, "3": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank := newTestBank(
+				domain.Account{ID: "1", UserID: "1", Balance: eur(100)},
+				domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+				domain.Account{ID: "3", UserID: "3", Balance: eur(0)},
+			)
+			factory := &failingUnitOfWorkFactory{
+				factory:    bank.UnitOfWorkFactory,
+				failOn:     tt.failOn,
+				failOnCall: 1,
+				calls:      make(map[string]int),
+			}
+			service := NewService(factory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+			transactions, err := service.TransferAll(context.Background(), "1", tt.orders)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TransferAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			index := -1
+			var transferErr TransferError
+			if errors.As(err, &transferErr) {
+				index = transferErr.Index
+			}
+			if index != tt.wantIndex {
+				t.Errorf("TransferAll() failed index = %d, want %d", index, tt.wantIndex)
+			}
+
+			wantTransactions := 0
+			if tt.wantErr == nil {
+				wantTransactions = len(tt.orders)
+			}
+			if len(transactions) != wantTransactions {
+				t.Errorf("TransferAll() made %d transactions, want %d", len(transactions), wantTransactions)
+			}
+			bank.assertBooks(t, tt.wantBalances, wantTransactions)
+		})
+	}
+}
+
+func TestService_TransferOpposingDirections(t *testing.T) {
+	bank := newTestBank(
+		domain.Account{ID: "1", UserID: "1", Balance: eur(1000)},
+		domain.Account{ID: "2", UserID: "2", Balance: eur(1000)},
+	)
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 500; i++ {
+		fromAccountID, toAccountID := "1", "2"
+		if i%2 == 0 {
+			fromAccountID, toAccountID = "2", "1"
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := service.Transfer(context.Background(), fromAccountID, toAccountID, eur(1)); err != nil {
+				t.Errorf("Transfer() unexpected error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	bank.assertBooks(t, map[string]int64{"1": 1000, "2": 1000}, 500)
+}
+
 func TestService_GetAccountTransactionHistory(t *testing.T) {
-	transactionRepository := memory.NewTransactionRepository()
+	bank := newTestBank(
+		domain.Account{ID: "1", UserID: "1", Balance: eur(0)},
+		domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+	)
 	now := time.Now()
 	fourDaysAgo := now.Add(-24 * time.Hour * 4)
 	fiveDaysAgo := now.Add(-24 * time.Hour * 5)
@@ -107,87 +593,176 @@ func TestService_GetAccountTransactionHistory(t *testing.T) {
 	accountID1 := "1"
 	accountID2 := "2"
 
-	t1, _ := transactionRepository.Insert(&domain.Transaction{
+	t1, _ := bank.Transactions.Insert(&domain.Transaction{
 		ID:            shortuuid.New(),
 		FromAccountID: &accountID1,
 		ToAccountID:   &accountID2,
-		Amount:        100,
+		Amount:        eur(100),
+		Type:          domain.Transfer,
 		CreatedAt:     now,
 	})
-	t2, _ := transactionRepository.Insert(&domain.Transaction{
+	t2, _ := bank.Transactions.Insert(&domain.Transaction{
 		ID:            shortuuid.New(),
 		FromAccountID: &accountID2,
 		ToAccountID:   &accountID1,
-		Amount:        100,
+		Amount:        eur(100),
+		Type:          domain.Transfer,
 		CreatedAt:     now.AddDate(0, 0, -1),
 	})
-	t3, _ := transactionRepository.Insert(&domain.Transaction{
+	t3, _ := bank.Transactions.Insert(&domain.Transaction{
 		ID:            shortuuid.New(),
 		FromAccountID: &accountID1,
 		ToAccountID:   &accountID2,
-		Amount:        100,
+		Amount:        eur(100),
+		Type:          domain.Transfer,
 		CreatedAt:     now.AddDate(0, 0, -2),
 	})
-	t4, _ := transactionRepository.Insert(&domain.Transaction{
+	t4, _ := bank.Transactions.Insert(&domain.Transaction{
 		ID:            shortuuid.New(),
 		FromAccountID: &accountID2,
 		ToAccountID:   &accountID1,
-		Amount:        100,
+		Amount:        eur(100),
+		Type:          domain.Transfer,
 		CreatedAt:     now.AddDate(0, 0, -3),
 	})
+	t5, _ := bank.Transactions.Insert(&domain.Transaction{
+		ID:          shortuuid.New(),
+		ToAccountID: &accountID1,
+		Amount:      eur(2500),
+		Type:        domain.Deposit,
+		CreatedAt:   now.AddDate(0, 0, -3).Add(time.Hour),
+	})
+
+	cursorAfter := func(transaction *domain.Transaction) string {
+		cursor, _ := pagination.Encode(repository.CursorOf(*transaction))
+		return cursor
+	}
+	lastFourDays := repository.TransactionFilter{
+		FromDate: time.Date(fourDaysAgo.Year(), fourDaysAgo.Month(), fourDaysAgo.Day(), 0, 0, 0, 0, time.UTC),
+		ToDate:   time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, time.UTC),
+	}
+	usd := domain.NewMoney(100, domain.USD)
+	minAmount, maxAmount := eur(1000), eur(100)
 
 	type args struct {
 		accountID string
-		fromDate  time.Time
-		toDate    time.Time
-		limit     int
-		offset    int
+		filter    repository.TransactionFilter
+		amounts   AmountRange
+		page      pagination.Request
 	}
 	tests := []struct {
 		name    string
 		args    args
-		want    []domain.Transaction
+		want    pagination.Page[domain.Transaction]
 		wantErr error
 	}{
 		{
 			name: "transaction history with today's transactions",
 			args: args{
 				accountID: "1",
-				fromDate:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
-				toDate:    time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, time.UTC),
-				limit:     10,
-				offset:    0,
-			},
-			want: []domain.Transaction{
-				*t1,
+				filter: repository.TransactionFilter{
+					FromDate: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+					ToDate:   time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, time.UTC),
+				},
+				page: pagination.Request{Limit: 10},
 			},
+			want: pagination.Page[domain.Transaction]{Items: []domain.Transaction{*t1}},
 		},
 		{
 			name: "transaction history with last 4 days' transactions",
+			args: args{accountID: "1", filter: lastFourDays},
+			want: pagination.Page[domain.Transaction]{Items: []domain.Transaction{*t4, *t5, *t3, *t2, *t1}},
+		},
+		{
+			name: "first page of last 4 days' transactions, want next cursor",
+			args: args{accountID: "1", filter: lastFourDays, page: pagination.Request{Limit: 2}},
+			want: pagination.Page[domain.Transaction]{Items: []domain.Transaction{*t4, *t5}, NextCursor: cursorAfter(t5)},
+		},
+		{
+			name: "last page of last 4 days' transactions",
+			args: args{accountID: "1", filter: lastFourDays, page: pagination.Request{Cursor: cursorAfter(t3), Limit: 2}},
+			want: pagination.Page[domain.Transaction]{Items: []domain.Transaction{*t2, *t1}},
+		},
+		{
+			name: "newest first, want next cursor",
 			args: args{
 				accountID: "1",
-				fromDate:  time.Date(fourDaysAgo.Year(), fourDaysAgo.Month(), fourDaysAgo.Day(), 0, 0, 0, 0, time.UTC),
-				toDate:    time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, time.UTC),
+				filter:    repository.TransactionFilter{FromDate: lastFourDays.FromDate, ToDate: lastFourDays.ToDate, Descending: true},
+				page:      pagination.Request{Limit: 2},
 			},
-			want: []domain.Transaction{
-				*t4, *t3, *t2, *t1,
+			want: pagination.Page[domain.Transaction]{Items: []domain.Transaction{*t1, *t2}, NextCursor: cursorAfter(t2)},
+		},
+		{
+			name: "transfers with account 2",
+			args: args{
+				accountID: "1",
+				filter: repository.TransactionFilter{
+					FromDate:       lastFourDays.FromDate,
+					ToDate:         lastFourDays.ToDate,
+					Types:          []domain.TransactionType{domain.Transfer},
+					CounterpartyID: "2",
+				},
+				amounts: AmountRange{Max: maxAmount},
 			},
+			want: pagination.Page[domain.Transaction]{Items: []domain.Transaction{*t4, *t3, *t2, *t1}},
+		},
+		{
+			name: "deposits above 10 EUR",
+			args: args{
+				accountID: "1",
+				filter:    repository.TransactionFilter{FromDate: lastFourDays.FromDate, ToDate: lastFourDays.ToDate},
+				amounts:   AmountRange{Min: minAmount},
+			},
+			want: pagination.Page[domain.Transaction]{Items: []domain.Transaction{*t5}},
 		},
 		{
 			name: "transaction history from 5 days ago to 4 days ago, empty list",
 			args: args{
 				accountID: "1",
-				fromDate:  time.Date(fiveDaysAgo.Year(), fiveDaysAgo.Month(), fiveDaysAgo.Day(), 0, 0, 0, 0, time.UTC),
-				toDate:    time.Date(fourDaysAgo.Year(), fourDaysAgo.Month(), fourDaysAgo.Day(), 23, 59, 59, 0, time.UTC),
+				filter: repository.TransactionFilter{
+					FromDate: time.Date(fiveDaysAgo.Year(), fiveDaysAgo.Month(), fiveDaysAgo.Day(), 0, 0, 0, 0, time.UTC),
+					ToDate:   time.Date(fourDaysAgo.Year(), fourDaysAgo.Month(), fourDaysAgo.Day(), 23, 59, 59, 0, time.UTC),
+				},
+				page: pagination.Request{Limit: 10},
 			},
-			want: []domain.Transaction{},
+			want: pagination.Page[domain.Transaction]{Items: []domain.Transaction{}},
+		},
+		{
+			name:    "empty account id, want invalidAccountID",
+			args:    args{page: pagination.Request{Limit: 10}},
+			wantErr: invalidAccountID,
+		},
+		{
+			name:    "unknown account, want ErrNotFound",
+			args:    args{accountID: "unknown"},
+			wantErr: repository.ErrNotFound,
+		},
+		{
+			name:    "from date after to date, want invalidDateRange",
+			args:    args{accountID: "1", filter: repository.TransactionFilter{FromDate: now, ToDate: now.Add(-time.Nanosecond)}},
+			wantErr: invalidDateRange,
+		},
+		{
+			name:    "min amount above max amount, want invalidAmountRange",
+			args:    args{accountID: "1", amounts: AmountRange{Min: minAmount, Max: maxAmount}},
+			wantErr: invalidAmountRange,
+		},
+		{
+			name:    "amount in another currency, want filterCurrencyMismatch",
+			args:    args{accountID: "1", amounts: AmountRange{Min: usd}},
+			wantErr: filterCurrencyMismatch,
+		},
+		{
+			name:    "amount without a currency has more decimal places than the account's, want invalid_parameter",
+			args:    args{accountID: "1", amounts: AmountRange{Min: domain.DecimalAmount("10.001")}},
+			wantErr: tberrors.NewValidationError("invalid_parameter", "invalid min-amount parameter: amount has more decimal places than its currency allows", "min-amount"),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			service := NewService(nil, transactionRepository)
+			service := NewService(nil, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
 
-			got, err := service.GetAccountTransactionHistory(tt.args.accountID, tt.args.fromDate, tt.args.toDate)
+			got, err := service.GetAccountTransactionHistory(tt.args.accountID, tt.args.filter, tt.args.amounts, tt.args.page)
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("GetAccountTransactionHistory() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -199,3 +774,418 @@ func TestService_GetAccountTransactionHistory(t *testing.T) {
 		})
 	}
 }
+
+// TestService_BalancesFromHistory replays random operations and checks balances rebuilt from the transactions agree
+// with the live balance after every one of them
+func TestService_BalancesFromHistory(t *testing.T) {
+	bank := newTestBank(
+		domain.Account{ID: "1", UserID: "1", Balance: eur(0)},
+		domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+		domain.Account{ID: "usd", UserID: "3", Balance: domain.NewMoney(0, domain.USD)},
+	)
+	rates := fixedRates{
+		{From: domain.EUR, To: domain.USD, Rate: "1.0845"},
+		{From: domain.USD, To: domain.EUR, Rate: "0.9221"},
+	}
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), rates, servicetest.NoFees{}, clock.System{})
+	ctx := context.Background()
+	accountIDs := []string{"1", "2", "usd"}
+
+	// live balances of every account right after each transaction
+	balancesAfter := make(map[string]map[string]domain.Money)
+	random := rand.New(rand.NewSource(1))
+	for range 300 {
+		accountID := accountIDs[random.Intn(len(accountIDs))]
+		acc, _ := bank.Accounts.Get(accountID)
+		amount := domain.NewMoney(1+random.Int63n(5000), acc.Balance.Currency)
+
+		var transaction *domain.Transaction
+		var err error
+		switch random.Intn(3) {
+		case 0:
+			transaction, err = service.Deposit(ctx, accountID, amount)
+		case 1:
+			transaction, err = service.Withdraw(ctx, accountID, amount)
+		default:
+			transaction, err = service.Transfer(ctx, accountID, accountIDs[random.Intn(len(accountIDs))], amount)
+		}
+		if err != nil {
+			// insufficient funds or a transfer to the same account, nothing was recorded
+			continue
+		}
+
+		balancesAfter[transaction.ID] = make(map[string]domain.Money)
+		for _, id := range accountIDs {
+			acc, _ := bank.Accounts.Get(id)
+			balancesAfter[transaction.ID][id] = acc.Balance
+
+			got, err := service.GetAccountBalance(id, transaction.CreatedAt)
+			if err != nil {
+				t.Fatalf("GetAccountBalance() error = %v", err)
+			}
+			if got != acc.Balance {
+				t.Fatalf("GetAccountBalance(%s) as of transaction %s = %v, want the live balance %v", id, transaction.ID, got, acc.Balance)
+			}
+		}
+	}
+
+	for _, id := range accountIDs {
+		acc, _ := bank.Accounts.Get(id)
+		got, err := service.GetAccountBalance(id, time.Now())
+		if err != nil {
+			t.Fatalf("GetAccountBalance() error = %v", err)
+		}
+		if got != acc.Balance {
+			t.Errorf("GetAccountBalance(%s) now = %v, want the live balance %v", id, got, acc.Balance)
+		}
+
+		// every page of every listing, including filtered and descending ones, has the balance after each line
+		filters := []repository.TransactionFilter{
+			{},
+			{Descending: true},
+			{Types: []domain.TransactionType{domain.Withdrawal}},
+			{Types: []domain.TransactionType{domain.Transfer}, Descending: true},
+		}
+		for _, filter := range filters {
+			pageRequest := pagination.Request{Limit: 7}
+			for {
+				page, err := service.GetAccountTransactionHistory(id, filter, AmountRange{}, pageRequest)
+				if err != nil {
+					t.Fatalf("GetAccountTransactionHistory() error = %v", err)
+				}
+
+				balances, err := service.GetBalancesAfter(id, page.Items)
+				if err != nil {
+					t.Fatalf("GetBalancesAfter() error = %v", err)
+				}
+				for i, transaction := range page.Items {
+					if want := balancesAfter[transaction.ID][id]; balances[i] != want {
+						t.Errorf("GetBalancesAfter(%s) %+v transaction %s = %v, want %v", id, filter, transaction.ID, balances[i], want)
+					}
+				}
+
+				if page.NextCursor == "" {
+					break
+				}
+				pageRequest.Cursor = page.NextCursor
+			}
+		}
+	}
+}
+
+func TestService_GetAccountBalance(t *testing.T) {
+	bank := newTestBank(domain.Account{ID: "1", UserID: "1", Balance: eur(0)})
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+	deposit, _ := service.Deposit(context.Background(), "1", eur(1000))
+	withdrawal, _ := service.Withdraw(context.Background(), "1", eur(300))
+
+	tests := []struct {
+		name      string
+		accountID string
+		asOf      time.Time
+		want      domain.Money
+		wantErr   error
+	}{
+		{name: "before the first transaction", accountID: "1", asOf: deposit.CreatedAt.Add(-time.Nanosecond), want: eur(0)},
+		{name: "as of a transaction, want it included", accountID: "1", asOf: deposit.CreatedAt, want: eur(1000)},
+		{name: "after every transaction", accountID: "1", asOf: withdrawal.CreatedAt.Add(time.Hour), want: eur(700)},
+		{name: "unknown account, want ErrNotFound", accountID: "unknown", asOf: time.Now(), wantErr: repository.ErrNotFound},
+		{name: "empty account id, want invalidAccountID", asOf: time.Now(), wantErr: invalidAccountID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.GetAccountBalance(tt.accountID, tt.asOf)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetAccountBalance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GetAccountBalance() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_GetEndOfDayBalances(t *testing.T) {
+	bank := newTestBank(domain.Account{ID: "1", UserID: "1", Balance: eur(0)})
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, clock.System{})
+
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	accountID := "1"
+	for _, transaction := range []*domain.Transaction{
+		{ID: "deposit", CreatedAt: day(2).Add(10 * time.Hour), ToAccountID: &accountID, Amount: eur(1000), Type: domain.Deposit},
+		{ID: "last-of-day", CreatedAt: day(3).Add(-time.Nanosecond), FromAccountID: &accountID, Amount: eur(300), Type: domain.Withdrawal},
+		{ID: "midnight", CreatedAt: day(4), FromAccountID: &accountID, Amount: eur(900), Type: domain.OverdraftInterest},
+	} {
+		bank.Transactions.Insert(transaction)
+	}
+
+	tests := []struct {
+		name      string
+		accountID string
+		from, to  time.Time
+		want      []domain.Money
+		wantErr   error
+	}{
+		{name: "every day of the history", accountID: "1", from: day(1), to: day(5), want: []domain.Money{eur(0), eur(700), eur(700), eur(-200), eur(-200)}},
+		{name: "times pick their day", accountID: "1", from: day(2).Add(23 * time.Hour), to: day(3).Add(time.Hour), want: []domain.Money{eur(700), eur(700)}},
+		{name: "from after to, want invalidDateRange", accountID: "1", from: day(3), to: day(2), wantErr: invalidDateRange},
+		{name: "unknown account, want ErrNotFound", accountID: "unknown", from: day(1), to: day(2), wantErr: repository.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.GetEndOfDayBalances(tt.accountID, tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetEndOfDayBalances() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GetEndOfDayBalances() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_WriteStatement(t *testing.T) {
+	bank := newTestBank(
+		domain.Account{ID: "1", UserID: "1", Type: domain.Checking, Balance: eur(0)},
+		domain.Account{ID: "2", UserID: "2", Balance: eur(0)},
+	)
+	manual := clock.NewManual(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+	service := NewService(bank.UnitOfWorkFactory, bank.Accounts, bank.Transactions, bank.Holds, lock.NewManager(), servicetest.NoRates{}, servicetest.NoFees{}, manual)
+	ctx := context.Background()
+
+	service.Deposit(ctx, "1", eur(1000))
+	manual.Advance(time.Hour)
+	withdrawal, _ := service.Withdraw(ctx, "1", eur(300))
+	manual.Advance(time.Hour)
+	transfer, _ := service.Transfer(ctx, "1", "2", eur(200))
+	manual.Advance(time.Hour)
+	service.Deposit(ctx, "1", eur(50))
+	manual.Advance(time.Hour)
+
+	t.Run("entries take the opening balance to the closing balance", func(t *testing.T) {
+		var writer recordingStatementWriter
+		if err := service.WriteStatement("1", withdrawal.CreatedAt, transfer.CreatedAt, &writer); err != nil {
+			t.Fatalf("WriteStatement() error = %v", err)
+		}
+
+		wantStatement := domain.Statement{
+			AccountID:      "1",
+			AccountType:    domain.Checking,
+			From:           withdrawal.CreatedAt,
+			To:             transfer.CreatedAt,
+			OpeningBalance: eur(1000),
+			ClosingBalance: eur(500),
+			CreatedAt:      manual.Now(),
+		}
+		if diff := cmp.Diff(wantStatement, writer.statement, cmpopts.IgnoreFields(domain.Statement{}, "ID")); diff != "" {
+			t.Errorf("WriteStatement() statement (-want +got):\n%s", diff)
+		}
+
+		wantEntries := []domain.StatementEntry{
+			{Transaction: *withdrawal, Amount: eur(-300), BalanceAfter: eur(700)},
+			{Transaction: *transfer, Amount: eur(-200), BalanceAfter: eur(500), Counterparty: "2"},
+		}
+		if diff := cmp.Diff(wantEntries, writer.entries); diff != "" {
+			t.Errorf("WriteStatement() entries (-want +got):\n%s", diff)
+		}
+		if !writer.ended {
+			t.Error("WriteStatement() didn't end the statement")
+		}
+	})
+
+	t.Run("from after to, want invalidDateRange and nothing written", func(t *testing.T) {
+		var writer recordingStatementWriter
+		err := service.WriteStatement("1", transfer.CreatedAt, withdrawal.CreatedAt, &writer)
+		if !errors.Is(err, invalidDateRange) {
+			t.Fatalf("WriteStatement() error = %v, wantErr %v", err, invalidDateRange)
+		}
+		if writer.began {
+			t.Error("WriteStatement() began a statement")
+		}
+	})
+}
+
+type recordingStatementWriter struct {
+	began     bool
+	ended     bool
+	statement domain.Statement
+	entries   []domain.StatementEntry
+}
+
+func (writer *recordingStatementWriter) Begin(statement domain.Statement) error {
+	writer.began = true
+	writer.statement = statement
+	return nil
+}
+
+func (writer *recordingStatementWriter) Entry(entry domain.StatementEntry) error {
+	writer.entries = append(writer.entries, entry)
+	return nil
+}
+
+func (writer *recordingStatementWriter) End() error {
+	writer.ended = true
+	return nil
+}
+
+type testBank struct {
+	*servicetest.Bank
+}
+
+func newTestBank(accounts ...domain.Account) *testBank {
+	return &testBank{Bank: servicetest.NewBank(accounts...)}
+}
+
+// assertBooks checks account balances against the expected ones and the ledger, and that account 1 took part in no
+// transaction beyond the expected ones besides its opening deposit
+func (bank *testBank) assertBooks(t *testing.T, wantBalances map[string]int64, wantTransactions int) {
+	t.Helper()
+
+	for accountID, wantBalance := range wantBalances {
+		acc, err := bank.Accounts.Get(accountID)
+		if err != nil {
+			t.Fatalf("failed to get account %s: %v", accountID, err)
+		}
+		if acc.Balance != eur(wantBalance) {
+			t.Errorf("account %s balance got = %v, want %v", accountID, acc.Balance, eur(wantBalance))
+		}
+
+		total, _ := bank.Ledger.GetAccountTotal(accountID, domain.EUR)
+		if ledgerBalance, _ := total.Balance(); ledgerBalance != eur(wantBalance) {
+			t.Errorf("account %s ledger balance got = %v, want %v", accountID, ledgerBalance, eur(wantBalance))
+		}
+	}
+
+	totals, _ := bank.Ledger.GetTotals()
+	var debits, credits int64
+	for _, total := range totals {
+		debits += total.Debits.Amount
+		credits += total.Credits.Amount
+	}
+	if debits != credits {
+		t.Errorf("ledger debits = %v and credits = %v, want them equal", debits, credits)
+	}
+
+	transactions, _ := bank.Transactions.GetAccountTransactions("1", repository.TransactionFilter{}, repository.Page[repository.TransactionCursor]{})
+	if opening, ok := bank.Openings["1"]; ok {
+		transactions = slices.DeleteFunc(transactions, func(transaction domain.Transaction) bool {
+			return transaction.ID == opening.ID
+		})
+	}
+	if len(transactions) != wantTransactions {
+		t.Errorf("recorded transactions got = %v, want %v", len(transactions), wantTransactions)
+	}
+}
+
+var injectedError = errors.New("injected error")
+
+// failingUnitOfWorkFactory wraps a real unit of work and fails the failOnCall-th call to the failOn step
+type failingUnitOfWorkFactory struct {
+	factory    repository.UnitOfWorkFactory
+	failOn     string
+	failOnCall int
+	calls      map[string]int
+}
+
+func (factory *failingUnitOfWorkFactory) fail(step string) bool {
+	factory.calls[step]++
+	return step == factory.failOn && factory.calls[step] == factory.failOnCall
+}
+
+func (factory *failingUnitOfWorkFactory) Begin(ctx context.Context) (repository.UnitOfWork, error) {
+	if factory.fail("Begin") {
+		return nil, injectedError
+	}
+
+	uow, err := factory.factory.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &failingUnitOfWork{UnitOfWork: uow, factory: factory}, nil
+}
+
+type failingUnitOfWork struct {
+	repository.UnitOfWork
+	factory *failingUnitOfWorkFactory
+}
+
+func (uow *failingUnitOfWork) GetAccount(accID string) (*domain.Account, error) {
+	if uow.factory.fail("GetAccount") {
+		return nil, injectedError
+	}
+	return uow.UnitOfWork.GetAccount(accID)
+}
+
+func (uow *failingUnitOfWork) UpdateAccount(acc *domain.Account) error {
+	if uow.factory.fail("UpdateAccount") {
+		return injectedError
+	}
+	return uow.UnitOfWork.UpdateAccount(acc)
+}
+
+func (uow *failingUnitOfWork) InsertTransaction(transaction *domain.Transaction) error {
+	if uow.factory.fail("InsertTransaction") {
+		return injectedError
+	}
+	return uow.UnitOfWork.InsertTransaction(transaction)
+}
+
+func (uow *failingUnitOfWork) InsertJournalEntry(entry *domain.JournalEntry) error {
+	if uow.factory.fail("InsertJournalEntry") {
+		return injectedError
+	}
+	return uow.UnitOfWork.InsertJournalEntry(entry)
+}
+
+func (uow *failingUnitOfWork) Commit() error {
+	if uow.factory.fail("Commit") {
+		uow.UnitOfWork.Rollback()
+		return injectedError
+	}
+	return uow.UnitOfWork.Commit()
+}
+
+// fixedFees is a fee schedule with the given rules
+type fixedFees []domain.FeeRule
+
+func (fees fixedFees) Rule(transactionType domain.TransactionType, currency domain.Currency) (domain.FeeRule, bool) {
+	for _, rule := range fees {
+		if rule.Type == transactionType && rule.Currency == currency {
+			return rule, true
+		}
+	}
+
+	return domain.FeeRule{}, false
+}
+
+func mustFeeRule(t *testing.T, transactionType domain.TransactionType, flat int64, percentage string, min, max int64) domain.FeeRule {
+	t.Helper()
+
+	rule, err := domain.NewFeeRule(transactionType, domain.EUR, eur(flat), percentage, eur(min), eur(max))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rule
+}
+
+// fixedRates is an exchange rate table with the given rates
+type fixedRates []domain.ExchangeRate
+
+func (rates fixedRates) Rate(from, to domain.Currency) (domain.ExchangeRate, error) {
+	for _, rate := range rates {
+		if rate.From == from && rate.To == to {
+			return rate, nil
+		}
+	}
+
+	return domain.ExchangeRate{}, errors.New("no rate")
+}
+
+func eur(amount int64) domain.Money {
+	return domain.NewMoney(amount, domain.EUR)
+}
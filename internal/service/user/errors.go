@@ -1,6 +1,10 @@
 package user
 
-import "errors"
+import (
+	"errors"
+
+	"http/internal/tberrors"
+)
 
 var failedToCreateUser = errors.New("failed to create user")
 var failedToCreateAccount = errors.New("failed to create account")
@@ -8,3 +12,11 @@ var failedToPersistUser = errors.New("failed to persist user")
 var failedToGetUser = errors.New("failed to get user")
 var failedToUpdateUser = errors.New("failed to update user")
 var failedToGetUserAccounts = errors.New("failed to get user accounts")
+var failedToDeleteUserAccounts = errors.New("failed to delete user accounts")
+var failedToRestoreUser = errors.New("failed to restore user")
+var failedToRestoreUserAccounts = errors.New("failed to restore user accounts")
+var failedToGetUsers = errors.New("failed to get users")
+var failedToGetAuditTrail = errors.New("failed to get audit trail")
+var failedToRecordAudit = tberrors.NewInternalError("storage_failure", "failed to record audit event")
+var userAlreadyDeleted = tberrors.NewConflictError("user_deleted", "user is already deleted", "user_id")
+var userNotFound = tberrors.NewNotFoundError("user_not_found", "user not found", "user_id")
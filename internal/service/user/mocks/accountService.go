@@ -3,9 +3,13 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "http/internal/domain"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // AccountService is an autogenerated mock type for the accountService type
@@ -13,17 +17,17 @@ type AccountService struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: userID
-func (_m *AccountService) Create(userID string) error {
-	ret := _m.Called(userID)
+// Create provides a mock function with given fields: userID, currency
+func (_m *AccountService) Create(userID string, currency domain.Currency) error {
+	ret := _m.Called(userID, currency)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(string, domain.Currency) error); ok {
+		r0 = rf(userID, currency)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -31,14 +35,39 @@ func (_m *AccountService) Create(userID string) error {
 	return r0
 }
 
-// DeleteUserAccounts provides a mock function with given fields: userID
-func (_m *AccountService) DeleteUserAccounts(userID string) {
-	_m.Called(userID)
+// DeleteUserAccounts provides a mock function with given fields: ctx, userID, deletedAt
+func (_m *AccountService) DeleteUserAccounts(ctx context.Context, userID string, deletedAt time.Time) ([]string, error) {
+	ret := _m.Called(ctx, userID, deletedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserAccounts")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]string, error)); ok {
+		return rf(ctx, userID, deletedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) []string); ok {
+		r0 = rf(ctx, userID, deletedAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, userID, deletedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// GetUserAccounts provides a mock function with given fields: userID
-func (_m *AccountService) GetUserAccounts(userID string) ([]domain.Account, error) {
-	ret := _m.Called(userID)
+// GetUserAccounts provides a mock function with given fields: userID, returnDeleted
+func (_m *AccountService) GetUserAccounts(userID string, returnDeleted bool) ([]domain.Account, error) {
+	ret := _m.Called(userID, returnDeleted)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserAccounts")
@@ -46,19 +75,49 @@ func (_m *AccountService) GetUserAccounts(userID string) ([]domain.Account, erro
 
 	var r0 []domain.Account
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]domain.Account, error)); ok {
-		return rf(userID)
+	if rf, ok := ret.Get(0).(func(string, bool) ([]domain.Account, error)); ok {
+		return rf(userID, returnDeleted)
 	}
-	if rf, ok := ret.Get(0).(func(string) []domain.Account); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(string, bool) []domain.Account); ok {
+		r0 = rf(userID, returnDeleted)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Account)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(userID)
+	if rf, ok := ret.Get(1).(func(string, bool) error); ok {
+		r1 = rf(userID, returnDeleted)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreUserAccounts provides a mock function with given fields: ctx, userID, deletedAt
+func (_m *AccountService) RestoreUserAccounts(ctx context.Context, userID string, deletedAt time.Time) ([]string, error) {
+	ret := _m.Called(ctx, userID, deletedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreUserAccounts")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]string, error)); ok {
+		return rf(ctx, userID, deletedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) []string); ok {
+		r0 = rf(ctx, userID, deletedAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, userID, deletedAt)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1,45 +1,60 @@
 package user
 
 import (
+	"context"
 	"errors"
 	"time"
 
+	"http/internal/clock"
 	"http/internal/domain"
+	"http/internal/pagination"
+	"http/internal/repository"
 )
 
 type userRepository interface {
 	Insert(user *domain.User) (*domain.User, error)
 	Get(userID string) (*domain.User, error)
 	Update(user *domain.User) (*domain.User, error)
-	GetAll(returnDeleted bool) ([]domain.User, error)
+	GetAll(returnDeleted bool, page repository.Page[string]) ([]domain.User, error)
 }
 
 //go:generate go run github.com/vektra/mockery/v2 --name=accountService --structname=AccountService --output=mocks/
 type accountService interface {
-	Create(userID string) error
-	GetUserAccounts(userID string) ([]domain.Account, error)
-	DeleteUserAccounts(userID string)
+	Create(userID string, currency domain.Currency) error
+	GetUserAccounts(userID string, returnDeleted bool) ([]domain.Account, error)
+	DeleteUserAccounts(ctx context.Context, userID string, deletedAt time.Time) ([]string, error)
+	RestoreUserAccounts(ctx context.Context, userID string, deletedAt time.Time) ([]string, error)
+}
+
+type auditRepository interface {
+	Insert(event *domain.AuditEvent) (*domain.AuditEvent, error)
+	GetUserEvents(userID string) ([]domain.AuditEvent, error)
 }
 
 type Service struct {
-	userRepository userRepository
-	accountService accountService
+	userRepository  userRepository
+	accountService  accountService
+	auditRepository auditRepository
+	clock           clock.Clock
 }
 
-func NewService(userRepository userRepository, accountService accountService) *Service {
+func NewService(userRepository userRepository, accountService accountService, auditRepository auditRepository, clock clock.Clock) *Service {
 	return &Service{
-		userRepository: userRepository,
-		accountService: accountService,
+		userRepository:  userRepository,
+		accountService:  accountService,
+		auditRepository: auditRepository,
+		clock:           clock,
 	}
 }
 
-func (service Service) CreateUser(name string) (*domain.User, error) {
+// CreateUser creates the user together with their first account, held in currency
+func (service Service) CreateUser(name string, currency domain.Currency) (*domain.User, error) {
 	u, err := domain.NewUser(name)
 	if err != nil {
 		return nil, errors.Join(failedToCreateUser, err)
 	}
 
-	if err = service.accountService.Create(u.ID); err != nil {
+	if err = service.accountService.Create(u.ID, currency); err != nil {
 		return nil, errors.Join(failedToCreateAccount, err)
 	}
 
@@ -51,25 +66,144 @@ func (service Service) CreateUser(name string) (*domain.User, error) {
 	return u, nil
 }
 
-func (service Service) DeleteUser(userID string) error {
-	u, err := service.userRepository.Get(userID)
+// DeleteUser soft deletes the user and closes their open accounts, both keep the same deletion time so
+// RestoreUser reopens exactly those accounts. The deletion is undone when the user or its audit event fails to be
+// stored.
+func (service Service) DeleteUser(ctx context.Context, userID string) error {
+	u, err := service.getUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if u.DeletedAt != nil {
+		return userAlreadyDeleted
+	}
+
+	now := service.clock.Now()
+	accountIDs, err := service.accountService.DeleteUserAccounts(ctx, u.ID, now)
+	if err != nil {
+		return errors.Join(failedToDeleteUserAccounts, err)
+	}
+
+	deleted := *u
+	deleted.DeletedAt = &now
+
+	if _, err := service.userRepository.Update(&deleted); err != nil {
+		return errors.Join(failedToUpdateUser, err, service.restoreAccounts(ctx, u.ID, now))
+	}
+
+	if err := service.audit(u.ID, domain.UserDeleted, accountIDs, now); err != nil {
+		if _, undoErr := service.userRepository.Update(u); undoErr != nil {
+			return errors.Join(err, failedToUpdateUser, undoErr)
+		}
+		return errors.Join(err, service.restoreAccounts(ctx, u.ID, now))
+	}
+
+	return nil
+}
+
+// RestoreUser reactivates a deleted user together with the accounts closed by the deletion, accounts the user
+// closed before stay closed. The restoration is undone when the user or its audit event fails to be stored.
+func (service Service) RestoreUser(ctx context.Context, userID string) (*domain.User, error) {
+	u, err := service.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the stored user is only changed once its accounts were reopened
+	restored := *u
+	if err := restored.Restore(); err != nil {
+		return nil, errors.Join(failedToRestoreUser, err)
+	}
+
+	deletedAt := *u.DeletedAt
+	accountIDs, err := service.accountService.RestoreUserAccounts(ctx, u.ID, deletedAt)
+	if err != nil {
+		return nil, errors.Join(failedToRestoreUserAccounts, err)
+	}
+
+	stored, err := service.userRepository.Update(&restored)
+	if err != nil {
+		return nil, errors.Join(failedToUpdateUser, err, service.deleteAccounts(ctx, u.ID, deletedAt))
+	}
+
+	if err := service.audit(u.ID, domain.UserRestored, accountIDs, service.clock.Now()); err != nil {
+		if _, undoErr := service.userRepository.Update(u); undoErr != nil {
+			return nil, errors.Join(err, failedToUpdateUser, undoErr)
+		}
+		return nil, errors.Join(err, service.deleteAccounts(ctx, u.ID, deletedAt))
+	}
+
+	return stored, nil
+}
+
+// restoreAccounts reopens the accounts a deletion that is undone closed
+func (service Service) restoreAccounts(ctx context.Context, userID string, deletedAt time.Time) error {
+	if _, err := service.accountService.RestoreUserAccounts(ctx, userID, deletedAt); err != nil {
+		return errors.Join(failedToRestoreUserAccounts, err)
+	}
+
+	return nil
+}
+
+// deleteAccounts closes again the accounts a restoration that is undone reopened
+func (service Service) deleteAccounts(ctx context.Context, userID string, deletedAt time.Time) error {
+	if _, err := service.accountService.DeleteUserAccounts(ctx, userID, deletedAt); err != nil {
+		return errors.Join(failedToDeleteUserAccounts, err)
+	}
+
+	return nil
+}
+
+// GetAuditTrail returns the deletions and restorations of the user, oldest first
+func (service Service) GetAuditTrail(userID string) ([]domain.AuditEvent, error) {
+	if _, err := service.getUser(userID); err != nil {
+		return nil, err
+	}
+
+	events, err := service.auditRepository.GetUserEvents(userID)
 	if err != nil {
-		return errors.Join(failedToGetUser, err)
+		return nil, errors.Join(failedToGetAuditTrail, err)
 	}
 
-	service.accountService.DeleteUserAccounts(u.ID)
+	return events, nil
+}
 
-	now := time.Now()
-	u.DeletedAt = &now
+func (service Service) getUser(userID string) (*domain.User, error) {
+	u, err := service.userRepository.Get(userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.Join(failedToGetUser, userNotFound, err)
+	}
+	if err != nil {
+		return nil, errors.Join(failedToGetUser, err)
+	}
 
-	_, err = service.userRepository.Update(u)
+	return u, nil
+}
+
+func (service Service) audit(userID string, action domain.AuditAction, accountIDs []string, occurredAt time.Time) error {
+	event, err := domain.NewAuditEvent(userID, action, accountIDs, occurredAt)
 	if err != nil {
-		return errors.Join(failedToUpdateUser, err)
+		return errors.Join(failedToRecordAudit, err)
+	}
+
+	if _, err := service.auditRepository.Insert(event); err != nil {
+		return errors.Join(failedToRecordAudit, err)
 	}
 
 	return nil
 }
 
-func (service Service) GetUsers(returnDeleted bool) ([]domain.User, error) {
-	return service.userRepository.GetAll(returnDeleted)
+func (service Service) GetUsers(returnDeleted bool, pageRequest pagination.Request) (pagination.Page[domain.User], error) {
+	after, err := pagination.Decode[string](pageRequest.Cursor)
+	if err != nil {
+		return pagination.Page[domain.User]{}, err
+	}
+
+	users, err := service.userRepository.GetAll(returnDeleted, repository.Page[string]{After: after, Limit: pageRequest.FetchLimit()})
+	if err != nil {
+		return pagination.Page[domain.User]{}, errors.Join(failedToGetUsers, err)
+	}
+
+	return pagination.NewPage(users, pageRequest.Limit, func(user domain.User) string { return user.ID })
 }
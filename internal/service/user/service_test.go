@@ -1,13 +1,17 @@
 package user
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/mock"
+	"http/internal/clock"
 	"http/internal/domain"
+	"http/internal/pagination"
 	"http/internal/repository/memory"
 	"http/internal/service/user/mocks"
 )
@@ -31,7 +35,7 @@ func TestService_CreateUser(t *testing.T) {
 			fields: fields{
 				accountService: func() accountService {
 					accServiceMock := mocks.NewAccountService(t)
-					accServiceMock.On("Create", mock.Anything).Return(nil)
+					accServiceMock.On("Create", mock.Anything, domain.EUR).Return(nil)
 					return accServiceMock
 				},
 			},
@@ -56,7 +60,7 @@ func TestService_CreateUser(t *testing.T) {
 			fields: fields{
 				accountService: func() accountService {
 					mockAccountService := mocks.NewAccountService(t)
-					mockAccountService.On("Create", mock.Anything).Return(errors.New("fail to create account"))
+					mockAccountService.On("Create", mock.Anything, domain.EUR).Return(errors.New("fail to create account"))
 					return mockAccountService
 				},
 			},
@@ -72,7 +76,7 @@ func TestService_CreateUser(t *testing.T) {
 				userRepository: memory.NewUserRepository(),
 				accountService: tt.fields.accountService(),
 			}
-			got, err := service.CreateUser(tt.args.name)
+			got, err := service.CreateUser(tt.args.name, domain.EUR)
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("CreateUser() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -86,36 +90,39 @@ func TestService_CreateUser(t *testing.T) {
 }
 
 func TestService_DeleteUser(t *testing.T) {
-	userRepository := memory.NewUserRepository()
-	userRepository.Insert(&domain.User{
-		ID:   "test",
-		Name: "test",
-	})
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
 
 	type fields struct {
 		accountsService func() accountService
+		failUpdate      bool
+		failAudit       bool
 	}
 	type args struct {
 		userID string
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr error
+		name      string
+		fields    fields
+		args      args
+		wantErr   error
+		wantAudit []domain.AuditEvent
 	}{
 		{
 			name: "successfully delete user",
 			fields: fields{
 				accountsService: func() accountService {
 					accServiceMock := mocks.NewAccountService(t)
-					accServiceMock.On("DeleteUserAccounts", mock.Anything).Return(nil)
+					accServiceMock.On("DeleteUserAccounts", mock.Anything, "test", now).Return([]string{"1", "2"}, nil)
 					return accServiceMock
 				},
 			},
 			args: args{
 				userID: "test",
 			},
+			wantAudit: []domain.AuditEvent{
+				{UserID: "test", Action: domain.UserDeleted, AccountIDs: []string{"1", "2"}, OccurredAt: now},
+			},
 		},
 		{
 			name: "fail to get user to delete, return failedToGetUser",
@@ -129,16 +136,332 @@ func TestService_DeleteUser(t *testing.T) {
 			},
 			wantErr: failedToGetUser,
 		},
+		{
+			name: "user already deleted, return userAlreadyDeleted",
+			fields: fields{
+				accountsService: func() accountService {
+					return mocks.NewAccountService(t)
+				},
+			},
+			args: args{
+				userID: "deleted",
+			},
+			wantErr: userAlreadyDeleted,
+		},
+		{
+			name: "fail to delete accounts, return failedToDeleteUserAccounts",
+			fields: fields{
+				accountsService: func() accountService {
+					accServiceMock := mocks.NewAccountService(t)
+					accServiceMock.On("DeleteUserAccounts", mock.Anything, "test", mock.Anything).Return(nil, errors.New("fail to delete accounts"))
+					return accServiceMock
+				},
+			},
+			args: args{
+				userID: "test",
+			},
+			wantErr: failedToDeleteUserAccounts,
+		},
+		{
+			name: "fail to update user, reopen the accounts and return failedToUpdateUser",
+			fields: fields{
+				accountsService: func() accountService {
+					accServiceMock := mocks.NewAccountService(t)
+					accServiceMock.On("DeleteUserAccounts", mock.Anything, "test", mock.Anything).Return([]string{"1", "2"}, nil)
+					accServiceMock.On("RestoreUserAccounts", mock.Anything, "test", mock.Anything).Return([]string{"1", "2"}, nil)
+					return accServiceMock
+				},
+				failUpdate: true,
+			},
+			args: args{
+				userID: "test",
+			},
+			wantErr: failedToUpdateUser,
+		},
+		{
+			name: "fail to record audit, undo the deletion and return failedToRecordAudit",
+			fields: fields{
+				accountsService: func() accountService {
+					accServiceMock := mocks.NewAccountService(t)
+					accServiceMock.On("DeleteUserAccounts", mock.Anything, "test", now).Return([]string{"1", "2"}, nil)
+					accServiceMock.On("RestoreUserAccounts", mock.Anything, "test", now).Return([]string{"1", "2"}, nil)
+					return accServiceMock
+				},
+				failAudit: true,
+			},
+			args: args{
+				userID: "test",
+			},
+			wantErr: failedToRecordAudit,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			userRepository := memory.NewUserRepository()
+			userRepository.Insert(&domain.User{ID: "test", Name: "test"})
+			userRepository.Insert(&domain.User{ID: "deleted", Name: "deleted", DeletedAt: &deletedAt})
+			auditRepository := memory.NewAuditRepository()
+
 			service := Service{
-				userRepository: userRepository,
-				accountService: tt.fields.accountsService(),
+				userRepository:  userRepository,
+				accountService:  tt.fields.accountsService(),
+				auditRepository: auditRepository,
+				clock:           clock.NewManual(now),
+			}
+			if tt.fields.failUpdate {
+				service.userRepository = failingUpdates{userRepository}
+			}
+			if tt.fields.failAudit {
+				service.auditRepository = failingAudit{auditRepository}
 			}
-			if err := service.DeleteUser(tt.args.userID); !errors.Is(err, tt.wantErr) {
+			if err := service.DeleteUser(context.Background(), tt.args.userID); !errors.Is(err, tt.wantErr) {
 				t.Errorf("DeleteUser() error = %v, wantErr %v", err, tt.wantErr)
 			}
+
+			if u, _ := userRepository.Get("test"); (u.DeletedAt != nil) != (tt.wantErr == nil) {
+				t.Errorf("DeleteUser() deleted at = %v, want deleted %v", u.DeletedAt, tt.wantErr == nil)
+			}
+
+			events, _ := auditRepository.GetUserEvents(tt.args.userID)
+			if diff := cmp.Diff(tt.wantAudit, events, cmpopts.IgnoreFields(domain.AuditEvent{}, "ID")); diff != "" {
+				t.Errorf("DeleteUser() audit (-want +got):\n%s", diff)
+			}
 		})
 	}
 }
+
+func TestService_RestoreUser(t *testing.T) {
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	type fields struct {
+		accountsService func() accountService
+		failUpdate      bool
+		failAudit       bool
+	}
+	type args struct {
+		userID string
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *domain.User
+		wantErr   error
+		wantAudit []domain.AuditEvent
+	}{
+		{
+			name: "successfully restore user",
+			fields: fields{
+				accountsService: func() accountService {
+					accServiceMock := mocks.NewAccountService(t)
+					accServiceMock.On("RestoreUserAccounts", mock.Anything, "deleted", deletedAt).Return([]string{"1"}, nil)
+					return accServiceMock
+				},
+			},
+			args: args{
+				userID: "deleted",
+			},
+			want: &domain.User{ID: "deleted", Name: "deleted"},
+			wantAudit: []domain.AuditEvent{
+				{UserID: "deleted", Action: domain.UserRestored, AccountIDs: []string{"1"}, OccurredAt: now},
+			},
+		},
+		{
+			name: "unknown user, return userNotFound",
+			fields: fields{
+				accountsService: func() accountService {
+					return mocks.NewAccountService(t)
+				},
+			},
+			args: args{
+				userID: "invalid",
+			},
+			wantErr: userNotFound,
+		},
+		{
+			name: "user not deleted, return failedToRestoreUser",
+			fields: fields{
+				accountsService: func() accountService {
+					return mocks.NewAccountService(t)
+				},
+			},
+			args: args{
+				userID: "active",
+			},
+			wantErr: failedToRestoreUser,
+		},
+		{
+			name: "fail to restore accounts, return failedToRestoreUserAccounts and keep the user deleted",
+			fields: fields{
+				accountsService: func() accountService {
+					accServiceMock := mocks.NewAccountService(t)
+					accServiceMock.On("RestoreUserAccounts", mock.Anything, "deleted", deletedAt).Return(nil, errors.New("fail to restore accounts"))
+					return accServiceMock
+				},
+			},
+			args: args{
+				userID: "deleted",
+			},
+			wantErr: failedToRestoreUserAccounts,
+		},
+		{
+			name: "fail to update user, close the accounts again and return failedToUpdateUser",
+			fields: fields{
+				accountsService: func() accountService {
+					accServiceMock := mocks.NewAccountService(t)
+					accServiceMock.On("RestoreUserAccounts", mock.Anything, "deleted", deletedAt).Return([]string{"1"}, nil)
+					accServiceMock.On("DeleteUserAccounts", mock.Anything, "deleted", deletedAt).Return([]string{"1"}, nil)
+					return accServiceMock
+				},
+				failUpdate: true,
+			},
+			args: args{
+				userID: "deleted",
+			},
+			wantErr: failedToUpdateUser,
+		},
+		{
+			name: "fail to record audit, undo the restoration and return failedToRecordAudit",
+			fields: fields{
+				accountsService: func() accountService {
+					accServiceMock := mocks.NewAccountService(t)
+					accServiceMock.On("RestoreUserAccounts", mock.Anything, "deleted", deletedAt).Return([]string{"1"}, nil)
+					accServiceMock.On("DeleteUserAccounts", mock.Anything, "deleted", deletedAt).Return([]string{"1"}, nil)
+					return accServiceMock
+				},
+				failAudit: true,
+			},
+			args: args{
+				userID: "deleted",
+			},
+			wantErr: failedToRecordAudit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepository := memory.NewUserRepository()
+			userRepository.Insert(&domain.User{ID: "active", Name: "active"})
+			userRepository.Insert(&domain.User{ID: "deleted", Name: "deleted", DeletedAt: &deletedAt})
+			auditRepository := memory.NewAuditRepository()
+
+			service := Service{
+				userRepository:  userRepository,
+				accountService:  tt.fields.accountsService(),
+				auditRepository: auditRepository,
+				clock:           clock.NewManual(now),
+			}
+			if tt.fields.failUpdate {
+				service.userRepository = failingUpdates{userRepository}
+			}
+			if tt.fields.failAudit {
+				service.auditRepository = failingAudit{auditRepository}
+			}
+			got, err := service.RestoreUser(context.Background(), tt.args.userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RestoreUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("RestoreUser() (-want +got):\n%s", diff)
+			}
+
+			if u, _ := userRepository.Get("deleted"); (u.DeletedAt == nil) != (tt.wantErr == nil) {
+				t.Errorf("RestoreUser() deleted at = %v, want restored %v", u.DeletedAt, tt.wantErr == nil)
+			}
+
+			events, _ := auditRepository.GetUserEvents(tt.args.userID)
+			if diff := cmp.Diff(tt.wantAudit, events, cmpopts.IgnoreFields(domain.AuditEvent{}, "ID")); diff != "" {
+				t.Errorf("RestoreUser() audit (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestService_GetUsers(t *testing.T) {
+	userRepository := memory.NewUserRepository()
+	deletedAt := time.Now()
+	for _, user := range []domain.User{
+		{ID: "a", Name: "a"},
+		{ID: "b", Name: "b", DeletedAt: &deletedAt},
+		{ID: "c", Name: "c"},
+		{ID: "d", Name: "d"},
+	} {
+		userRepository.Insert(&user)
+	}
+	cursorAfter := func(id string) string {
+		cursor, _ := pagination.Encode(id)
+		return cursor
+	}
+
+	tests := []struct {
+		name          string
+		returnDeleted bool
+		page          pagination.Request
+		wantIDs       []string
+		wantCursor    string
+		wantErr       error
+	}{
+		{
+			name:       "first page, want next cursor",
+			page:       pagination.Request{Limit: 2},
+			wantIDs:    []string{"a", "c"},
+			wantCursor: cursorAfter("c"),
+		},
+		{
+			name:    "last page",
+			page:    pagination.Request{Cursor: cursorAfter("c"), Limit: 2},
+			wantIDs: []string{"d"},
+		},
+		{
+			name:          "page with deleted users",
+			returnDeleted: true,
+			page:          pagination.Request{Limit: 2},
+			wantIDs:       []string{"a", "b"},
+			wantCursor:    cursorAfter("b"),
+		},
+		{
+			name:    "exactly one page, want no next cursor",
+			page:    pagination.Request{Limit: 3},
+			wantIDs: []string{"a", "c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(userRepository, mocks.NewAccountService(t), memory.NewAuditRepository(), clock.System{})
+
+			got, err := service.GetUsers(tt.returnDeleted, tt.page)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetUsers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var gotIDs []string
+			for _, user := range got.Items {
+				gotIDs = append(gotIDs, user.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("GetUsers() ids (-want +got):\n%s", diff)
+			}
+			if got.NextCursor != tt.wantCursor {
+				t.Errorf("GetUsers() next cursor = %q, want %q", got.NextCursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+// failingUpdates is a user repository that fails to store any change to a user
+type failingUpdates struct {
+	*memory.UserRepository
+}
+
+func (failingUpdates) Update(*domain.User) (*domain.User, error) {
+	return nil, errors.New("fail to update user")
+}
+
+// failingAudit is an audit repository that fails to record any event
+type failingAudit struct {
+	*memory.AuditRepository
+}
+
+func (failingAudit) Insert(*domain.AuditEvent) (*domain.AuditEvent, error) {
+	return nil, errors.New("fail to record audit event")
+}
@@ -1,19 +1,133 @@
 package tberrors
 
-import "fmt"
+import "errors"
+
+// Every error type carries a stable machine readable code clients can rely on, and the request field that caused
+// it when there is one.
 
 type ValidationError struct {
+	code    string
 	message string
 	field   string
 }
 
-func NewValidationError(message, field string) error {
+func NewValidationError(code, message, field string) error {
 	return ValidationError{
+		code:    code,
 		message: message,
 		field:   field,
 	}
 }
 
 func (validationError ValidationError) Error() string {
-	return fmt.Sprintf("%s\n", validationError.message)
+	return validationError.message
+}
+
+func (validationError ValidationError) Code() string {
+	return validationError.code
+}
+
+func (validationError ValidationError) Field() string {
+	return validationError.field
+}
+
+// Nested places every validation error found in err under parent, such as lines[2].amount for the amount of the
+// second line of a file. Other errors are kept as they are.
+func Nested(parent string, err error) error {
+	switch e := err.(type) {
+	case ValidationError:
+		field := parent
+		if e.field != "" {
+			field = parent + "." + e.field
+		}
+		return ValidationError{code: e.code, message: parent + ": " + e.message, field: field}
+	case interface{ Unwrap() []error }:
+		var nested []error
+		for _, inner := range e.Unwrap() {
+			nested = append(nested, Nested(parent, inner))
+		}
+		return errors.Join(nested...)
+	default:
+		return err
+	}
+}
+
+type baseError struct {
+	code    string
+	message string
+	field   string
+}
+
+func (err baseError) Error() string {
+	return err.message
+}
+
+func (err baseError) Code() string {
+	return err.code
+}
+
+func (err baseError) Field() string {
+	return err.field
+}
+
+// NotFoundError is returned when a referenced resource does not exist.
+type NotFoundError struct {
+	baseError
+}
+
+func NewNotFoundError(code, message, field string) error {
+	return NotFoundError{baseError{code: code, message: message, field: field}}
+}
+
+// ConflictError is returned when the request clashes with the current state, retrying later may succeed.
+type ConflictError struct {
+	baseError
+}
+
+func NewConflictError(code, message, field string) error {
+	return ConflictError{baseError{code: code, message: message, field: field}}
+}
+
+// InsufficientFundsError is returned when an account can't cover a debit.
+type InsufficientFundsError struct {
+	baseError
+}
+
+func NewInsufficientFundsError(code, message, field string) error {
+	return InsufficientFundsError{baseError{code: code, message: message, field: field}}
+}
+
+// ForbiddenError is returned when an operation is not allowed on the resource.
+type ForbiddenError struct {
+	baseError
+}
+
+func NewForbiddenError(code, message, field string) error {
+	return ForbiddenError{baseError{code: code, message: message, field: field}}
+}
+
+// InternalError is returned when the bank failed on its own side, its details are not meant for clients.
+type InternalError struct {
+	baseError
+}
+
+func NewInternalError(code, message string) error {
+	return InternalError{baseError{code: code, message: message}}
+}
+
+// InternalErrorCode describes errors that carry none of the types above
+const InternalErrorCode = "internal_error"
+
+// Describe returns the code and message of the first tberrors type found in err, errors without one are described as
+// internal with their details hidden
+func Describe(err error) (code, message string) {
+	var coded interface {
+		error
+		Code() string
+	}
+	if errors.As(err, &coded) {
+		return coded.Code(), coded.Error()
+	}
+
+	return InternalErrorCode, "internal error"
 }
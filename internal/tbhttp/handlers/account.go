@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"encoding/json"
 	"log/slog"
 	"net/http"
 
 	"http/internal/service/account"
+	"http/internal/tbhttp/handlers/request"
 	"http/internal/tbhttp/handlers/response"
 )
 
@@ -13,6 +15,15 @@ func RegisterAccountHandler(mux *http.ServeMux, logger *slog.Logger, accountSvc
 
 	logger.Debug("registering GET /users/{id}/accounts")
 	mux.Handle("GET /users/{id}/accounts", handleGetUserAccounts(logger, accountSvc))
+
+	logger.Debug("registering POST /users/{id}/accounts")
+	mux.Handle("POST /users/{id}/accounts", handlePostUserAccounts(logger, accountSvc))
+
+	logger.Debug("registering GET /account/{id}")
+	mux.Handle("GET /account/{id}", handleGetAccount(logger, accountSvc))
+
+	logger.Debug("registering DELETE /account/{id}")
+	mux.Handle("DELETE /account/{id}", handleDeleteAccount(logger, accountSvc))
 }
 
 func handleGetUserAccounts(logger *slog.Logger, accountSvc *account.Service) http.Handler {
@@ -20,16 +31,19 @@ func handleGetUserAccounts(logger *slog.Logger, accountSvc *account.Service) htt
 		func(w http.ResponseWriter, r *http.Request) {
 			userID := r.PathValue("id")
 			if userID == "" {
-				logger.InfoContext(r.Context(), "invalid path")
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid path", Details: "{id} is empty"})
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			returnDeleted, err := parseReturnDeleted(r)
+			if err != nil {
+				writeBadRequest(logger, w, r, "invalid return-deleted parameter", "invalid_parameter", err.Error())
 				return
 			}
 
-			accs, err := accountSvc.GetUserAccounts(userID)
+			accs, err := accountSvc.GetUserAccounts(userID, returnDeleted)
 			if err != nil {
-				// TODO unwrap validation error and change http status accordingly
-				logger.ErrorContext(r.Context(), "failed to create user", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "failed to create user", Details: err.Error()})
+				writeError(logger, w, r, "failed to get user accounts", err)
 				return
 			}
 
@@ -37,3 +51,80 @@ func handleGetUserAccounts(logger *slog.Logger, accountSvc *account.Service) htt
 		},
 	)
 }
+
+func handlePostUserAccounts(logger *slog.Logger, accountSvc *account.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			userID := r.PathValue("id")
+			if userID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			var openAccountRequest request.OpenAccount
+			if err := json.NewDecoder(r.Body).Decode(&openAccountRequest); err != nil {
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
+				return
+			}
+
+			accountType, err := openAccountRequest.AccountType()
+			if err != nil {
+				writeError(logger, w, r, "invalid account type", err)
+				return
+			}
+
+			currency, err := openAccountRequest.AccountCurrency()
+			if err != nil {
+				writeError(logger, w, r, "invalid currency", err)
+				return
+			}
+
+			acc, err := accountSvc.Open(userID, currency, accountType, openAccountRequest.Nickname)
+			if err != nil {
+				writeError(logger, w, r, "failed to open account", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusCreated, response.AccountResponseFromDomain(acc))
+		},
+	)
+}
+
+func handleGetAccount(logger *slog.Logger, accountSvc *account.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accountID := r.PathValue("id")
+			if accountID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			acc, err := accountSvc.Get(accountID)
+			if err != nil {
+				writeError(logger, w, r, "failed to get account", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.AccountResponseFromDomain(acc))
+		},
+	)
+}
+
+func handleDeleteAccount(logger *slog.Logger, accountSvc *account.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accountID := r.PathValue("id")
+			if accountID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			if err := accountSvc.Close(r.Context(), accountID, r.URL.Query().Get("sweep-account")); err != nil {
+				writeError(logger, w, r, "failed to close account", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusNoContent, nil)
+		},
+	)
+}
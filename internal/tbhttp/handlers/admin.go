@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"http/internal/service/account"
+	"http/internal/service/interest"
+	"http/internal/tberrors"
+	"http/internal/tbhttp/handlers/request"
+	"http/internal/tbhttp/handlers/response"
+)
+
+var notAnAdmin = tberrors.NewForbiddenError("not_an_admin", "admin endpoints need the admin token as a bearer token", "")
+
+// RegisterAdminHandler registers the endpoints meant for bank staff, they are refused to every request when
+// adminToken is empty
+func RegisterAdminHandler(mux *http.ServeMux, logger *slog.Logger, accountSvc *account.Service, interestSvc *interest.Service, adminToken string) {
+	logger.Debug("registering admin endpoints")
+
+	logger.Debug("registering PUT /admin/account/{id}/overdraft")
+	mux.Handle("PUT /admin/account/{id}/overdraft", requireAdmin(logger, adminToken, handlePutOverdraft(logger, accountSvc)))
+
+	logger.Debug("registering PUT /admin/account/{id}/rate-plan")
+	mux.Handle("PUT /admin/account/{id}/rate-plan", requireAdmin(logger, adminToken, handlePutRatePlan(logger, interestSvc)))
+}
+
+func requireAdmin(logger *slog.Logger, adminToken string, next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if adminToken == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+				writeError(logger, w, r, "admin token required", notAnAdmin)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+func handlePutOverdraft(logger *slog.Logger, accountSvc *account.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accountID := r.PathValue("id")
+			if accountID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			var overdraftRequest request.Overdraft
+			if err := json.NewDecoder(r.Body).Decode(&overdraftRequest); err != nil {
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
+				return
+			}
+
+			limit, err := overdraftRequest.Money()
+			if err != nil {
+				writeError(logger, w, r, "invalid limit", err)
+				return
+			}
+
+			acc, err := accountSvc.SetOverdraft(r.Context(), accountID, limit, overdraftRequest.InterestRate)
+			if err != nil {
+				writeError(logger, w, r, "failed to set overdraft", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.AccountResponseFromDomain(acc))
+		},
+	)
+}
+
+func handlePutRatePlan(logger *slog.Logger, interestSvc *interest.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accountID := r.PathValue("id")
+			if accountID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			var ratePlanRequest request.RatePlan
+			if err := json.NewDecoder(r.Body).Decode(&ratePlanRequest); err != nil {
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
+				return
+			}
+
+			acc, err := interestSvc.SetRatePlan(r.Context(), accountID, ratePlanRequest.Plan)
+			if err != nil {
+				writeError(logger, w, r, "failed to set rate plan", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.AccountResponseFromDomain(acc))
+		},
+	)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"http/internal/domain"
+	"http/internal/idempotency"
+	"http/internal/service/batch"
+	"http/internal/tbhttp/handlers/request"
+	"http/internal/tbhttp/handlers/response"
+)
+
+// maxBatchFileSize bounds the body of a batch upload, a full batch of pain.001 transfers fits comfortably
+const maxBatchFileSize = 10 << 20
+
+func RegisterBatchHandler(mux *http.ServeMux, logger *slog.Logger, batchSvc *batch.Service, idempotencyStore idempotency.Store) {
+	logger.Debug("registering batch endpoints")
+
+	logger.Debug("registering POST /account/{id}/batches")
+	mux.Handle("POST /account/{id}/batches", withIdempotency(logger, idempotencyStore, handlePostBatch(logger, batchSvc)))
+
+	logger.Debug("registering GET /batches/{id}")
+	mux.Handle("GET /batches/{id}", handleGetBatch(logger, batchSvc))
+}
+
+func handlePostBatch(logger *slog.Logger, batchSvc *batch.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accountID := r.PathValue("id")
+			if accountID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			format, ok := request.BatchFormatOf(mediaType)
+			if !ok {
+				writeAPIError(logger, w, r, apiError{
+					status:  http.StatusUnsupportedMediaType,
+					message: "unsupported batch file",
+					code:    "unsupported_media_type",
+					detail:  "Content-Type must be text/csv or application/xml",
+				})
+				return
+			}
+
+			params := newQueryParams(r)
+			mode := queryParam(params, "mode", domain.ParseBatchMode, domain.AllOrNothing)
+			if err := params.Err(); err != nil {
+				writeError(logger, w, r, "invalid batch parameters", err)
+				return
+			}
+
+			lines, err := request.ReadBatch(format, http.MaxBytesReader(w, r.Body, maxBatchFileSize), accountID)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(logger, w, r, apiError{
+					status:  http.StatusRequestEntityTooLarge,
+					message: "batch file too large",
+					code:    "file_too_large",
+					detail:  tooLarge.Error(),
+				})
+				return
+			}
+			if err != nil {
+				writeError(logger, w, r, "invalid batch file", err)
+				return
+			}
+
+			submitted, err := batchSvc.Submit(r.Context(), accountID, mode, lines)
+			if err != nil && submitted == nil {
+				writeError(logger, w, r, "failed to submit batch", err)
+				return
+			}
+			if err != nil {
+				// its transfers were made, answering with the batch keeps a retry of the request from making them again
+				logger.ErrorContext(r.Context(), "failed to store submitted batch", "batch_id", submitted.ID, "error", err)
+			}
+
+			w.Header().Set("Location", "/batches/"+submitted.ID)
+			writeResponseJson(r.Context(), logger, w, http.StatusCreated, response.BatchFromDomain(submitted))
+		},
+	)
+}
+
+func handleGetBatch(logger *slog.Logger, batchSvc *batch.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			batchID := r.PathValue("id")
+			if batchID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			found, err := batchSvc.Get(batchID)
+			if err != nil {
+				writeError(logger, w, r, "failed to get batch", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.BatchFromDomain(found))
+		},
+	)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"http/internal/tberrors"
+	"http/internal/tbhttp/handlers/response"
+)
+
+const problemContentType = "application/problem+json"
+
+// codedError is implemented by every tberrors type
+type codedError interface {
+	error
+	Code() string
+	Field() string
+}
+
+// apiError is the wire independent form of an error response, rendered either as a legacy response.Error or as a
+// response.Problem depending on what the client accepts.
+type apiError struct {
+	status  int
+	message string
+	code    string
+	field   string
+	detail  string
+	fields  []response.ProblemField
+}
+
+// writeError is the single place turning service errors into responses. The status code comes from the first
+// tberrors type found in the chain, errors without one are treated as internal and their details are hidden.
+func writeError(logger *slog.Logger, w http.ResponseWriter, r *http.Request, message string, err error) {
+	statusCode, coded := classifyError(err)
+
+	if statusCode >= http.StatusInternalServerError {
+		logger.ErrorContext(r.Context(), message, "error", err)
+		code := "internal_error"
+		if coded != nil {
+			code = coded.Code()
+		}
+		writeAPIError(logger, w, r, apiError{status: statusCode, message: message, code: code, detail: "internal error"})
+		return
+	}
+
+	logger.InfoContext(r.Context(), message, "error", err)
+	writeAPIError(logger, w, r, apiError{
+		status:  statusCode,
+		message: message,
+		code:    coded.Code(),
+		field:   coded.Field(),
+		detail:  coded.Error(),
+		fields:  fieldErrors(err),
+	})
+}
+
+// writeBadRequest is used for requests rejected before reaching a service, such as malformed paths or bodies.
+func writeBadRequest(logger *slog.Logger, w http.ResponseWriter, r *http.Request, message, code, detail string) {
+	logger.InfoContext(r.Context(), message, "details", detail)
+	writeAPIError(logger, w, r, apiError{status: http.StatusBadRequest, message: message, code: code, detail: detail})
+}
+
+func writeAPIError(logger *slog.Logger, w http.ResponseWriter, r *http.Request, apiErr apiError) {
+	if !acceptsProblem(r) {
+		writeResponseJson(r.Context(), logger, w, apiErr.status, response.Error{
+			Message: apiErr.message,
+			Code:    apiErr.code,
+			Field:   apiErr.field,
+			Details: apiErr.detail,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(apiErr.status)
+	err := json.NewEncoder(w).Encode(response.Problem{
+		Type:     "urn:tiny-bank:problem:" + apiErr.code,
+		Title:    apiErr.message,
+		Status:   apiErr.status,
+		Detail:   apiErr.detail,
+		Instance: r.URL.Path,
+		Code:     apiErr.code,
+		Errors:   apiErr.fields,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+// acceptsProblem reports whether the client explicitly asked for problem+json, anything else keeps the legacy body
+// so older clients are not broken.
+func acceptsProblem(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil || mediaType != problemContentType {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			if err != nil || weight <= 0 {
+				continue
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func classifyError(err error) (int, codedError) {
+	var notFoundErr tberrors.NotFoundError
+	var insufficientFundsErr tberrors.InsufficientFundsError
+	var forbiddenErr tberrors.ForbiddenError
+	var conflictErr tberrors.ConflictError
+	var validationErr tberrors.ValidationError
+	var internalErr tberrors.InternalError
+
+	switch {
+	case errors.As(err, &notFoundErr):
+		return http.StatusNotFound, notFoundErr
+	case errors.As(err, &insufficientFundsErr):
+		return http.StatusUnprocessableEntity, insufficientFundsErr
+	case errors.As(err, &forbiddenErr):
+		return http.StatusForbidden, forbiddenErr
+	case errors.As(err, &conflictErr):
+		return http.StatusConflict, conflictErr
+	case errors.As(err, &validationErr):
+		return http.StatusUnprocessableEntity, validationErr
+	case errors.As(err, &internalErr):
+		return http.StatusInternalServerError, internalErr
+	default:
+		return http.StatusInternalServerError, nil
+	}
+}
+
+// fieldErrors walks the whole error tree, errors.As would stop at the first validation error and joined validation
+// errors are how the domain reports several invalid fields at once.
+func fieldErrors(err error) []response.ProblemField {
+	var fields []response.ProblemField
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+			return
+		case tberrors.ValidationError:
+			if e.Field() != "" {
+				fields = append(fields, response.ProblemField{Field: e.Field(), Code: e.Code(), Detail: e.Error()})
+			}
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+
+	return fields
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/tberrors"
+	"http/internal/tbhttp/handlers/response"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatusCode int
+		wantBody       response.Error
+	}{
+		{
+			name:           "validation error, want 422",
+			err:            errors.Join(errors.New("failed"), tberrors.NewValidationError("invalid_amount", "invalid amount", "amount")),
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantBody:       response.Error{Message: "message", Code: "invalid_amount", Field: "amount", Details: "invalid amount"},
+		},
+		{
+			name:           "not found error, want 404",
+			err:            fmt.Errorf("wrapped: %w", tberrors.NewNotFoundError("account_not_found", "account not found", "from")),
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       response.Error{Message: "message", Code: "account_not_found", Field: "from", Details: "account not found"},
+		},
+		{
+			name:           "conflict error, want 409",
+			err:            tberrors.NewConflictError("account_busy", "account busy", ""),
+			wantStatusCode: http.StatusConflict,
+			wantBody:       response.Error{Message: "message", Code: "account_busy", Details: "account busy"},
+		},
+		{
+			name:           "insufficient funds error, want 422",
+			err:            tberrors.NewInsufficientFundsError("insufficient_funds", "insufficient funds", "from"),
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantBody:       response.Error{Message: "message", Code: "insufficient_funds", Field: "from", Details: "insufficient funds"},
+		},
+		{
+			name:           "forbidden error, want 403",
+			err:            tberrors.NewForbiddenError("account_closed", "account closed", ""),
+			wantStatusCode: http.StatusForbidden,
+			wantBody:       response.Error{Message: "message", Code: "account_closed", Details: "account closed"},
+		},
+		{
+			name:           "internal error hides details, want 500",
+			err:            tberrors.NewInternalError("storage_failure", "disk on fire"),
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       response.Error{Message: "message", Code: "storage_failure", Details: "internal error"},
+		},
+		{
+			name:           "unknown error, want 500",
+			err:            errors.New("boom"),
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody:       response.Error{Message: "message", Code: "internal_error", Details: "internal error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			recorder := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/transaction", nil)
+
+			writeError(logger, recorder, r, "message", tt.err)
+
+			if recorder.Code != tt.wantStatusCode {
+				t.Errorf("writeError() status code = %d, want %d", recorder.Code, tt.wantStatusCode)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+				t.Errorf("writeError() content type = %s, want legacy json", got)
+			}
+			var got response.Error
+			if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantBody, got); diff != "" {
+				t.Errorf("writeError() body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteError_Problem(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		err      error
+		wantBody response.Problem
+	}{
+		{
+			name:   "every invalid field is listed",
+			accept: "application/problem+json",
+			err: errors.Join(
+				tberrors.NewValidationError("invalid_amount", "amount must be greater than zero", "amount"),
+				tberrors.NewValidationError("missing_to_account", "to account ID is required", "to_account"),
+			),
+			wantBody: response.Problem{
+				Type:     "urn:tiny-bank:problem:invalid_amount",
+				Title:    "message",
+				Status:   http.StatusUnprocessableEntity,
+				Detail:   "amount must be greater than zero",
+				Instance: "/transaction",
+				Code:     "invalid_amount",
+				Errors: []response.ProblemField{
+					{Field: "amount", Code: "invalid_amount", Detail: "amount must be greater than zero"},
+					{Field: "to_account", Code: "missing_to_account", Detail: "to account ID is required"},
+				},
+			},
+		},
+		{
+			name:   "problem among other media types",
+			accept: "application/json;q=0.5, application/problem+json",
+			err:    tberrors.NewNotFoundError("account_not_found", "account not found", "from"),
+			wantBody: response.Problem{
+				Type:     "urn:tiny-bank:problem:account_not_found",
+				Title:    "message",
+				Status:   http.StatusNotFound,
+				Detail:   "account not found",
+				Instance: "/transaction",
+				Code:     "account_not_found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			recorder := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/transaction", nil)
+			r.Header.Set("Accept", tt.accept)
+
+			writeError(logger, recorder, r, "message", tt.err)
+
+			if got := recorder.Header().Get("Content-Type"); got != problemContentType {
+				t.Errorf("writeError() content type = %s, want %s", got, problemContentType)
+			}
+			var got response.Problem
+			if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantBody, got); diff != "" {
+				t.Errorf("writeError() body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAcceptsProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   bool
+	}{
+		{name: "no accept header", accept: "", want: false},
+		{name: "any media type", accept: "*/*", want: false},
+		{name: "plain json", accept: "application/json", want: false},
+		{name: "problem json", accept: "application/problem+json", want: true},
+		{name: "problem json refused with q=0", accept: "application/problem+json;q=0, application/json", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+			if got := acceptsProblem(r); got != tt.want {
+				t.Errorf("acceptsProblem() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
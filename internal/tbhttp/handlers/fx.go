@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"http/internal/fx"
+	"http/internal/tbhttp/handlers/response"
+)
+
+func RegisterFXHandler(mux *http.ServeMux, logger *slog.Logger, rates *fx.Table) {
+	logger.Debug("registering fx endpoints")
+
+	logger.Debug("registering GET /fx/rates")
+	mux.Handle("GET /fx/rates", handleGetRates(logger, rates))
+}
+
+func handleGetRates(logger *slog.Logger, rates *fx.Table) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.ExchangeRatesFromDomain(rates.Rates()))
+		},
+	)
+}
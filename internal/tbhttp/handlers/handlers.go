@@ -3,8 +3,14 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+
+	"http/internal/pagination"
+	"http/internal/tberrors"
 )
 
 // missing fallback, log error for now
@@ -16,3 +22,41 @@ func writeResponseJson(ctx context.Context, logger *slog.Logger, w http.Response
 		logger.ErrorContext(ctx, "failed to encode response", "error", err)
 	}
 }
+
+// parseReturnDeleted reads the optional return-deleted query parameter shared by the listing endpoints
+func parseReturnDeleted(r *http.Request) (bool, error) {
+	returnDeletedParam := r.URL.Query().Get("return-deleted")
+	if returnDeletedParam == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(returnDeletedParam)
+}
+
+var invalidLimitParameter = tberrors.NewValidationError("invalid_limit", "limit must be a whole number", "limit")
+
+// parsePageRequest reads the optional limit and cursor query parameters shared by the paginated listings
+func parsePageRequest(r *http.Request) (pagination.Request, error) {
+	var limit int
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return pagination.Request{}, invalidLimitParameter
+		}
+	}
+
+	return pagination.NewRequest(r.URL.Query().Get("cursor"), limit)
+}
+
+// setNextLink points the Link header at the page after the one being served, it keeps every other query parameter
+func setNextLink(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+
+	query := r.URL.Query()
+	query.Set("cursor", nextCursor)
+	next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"http/internal/idempotency"
+	"http/internal/service/transaction"
+	"http/internal/tbhttp/handlers/request"
+	"http/internal/tbhttp/handlers/response"
+)
+
+func RegisterHoldHandler(mux *http.ServeMux, logger *slog.Logger, transactionSvc *transaction.Service, idempotencyStore idempotency.Store) {
+	logger.Debug("registering hold endpoints")
+
+	logger.Debug("registering POST /account/{id}/holds")
+	mux.Handle("POST /account/{id}/holds", withIdempotency(logger, idempotencyStore, handlePostHold(logger, transactionSvc)))
+
+	logger.Debug("registering GET /account/{id}/holds")
+	mux.Handle("GET /account/{id}/holds", handleGetAccountHolds(logger, transactionSvc))
+
+	logger.Debug("registering GET /holds/{id}")
+	mux.Handle("GET /holds/{id}", handleGetHold(logger, transactionSvc))
+
+	logger.Debug("registering POST /holds/{id}/capture")
+	mux.Handle("POST /holds/{id}/capture", withIdempotency(logger, idempotencyStore, handleCaptureHold(logger, transactionSvc)))
+
+	logger.Debug("registering POST /holds/{id}/void")
+	mux.Handle("POST /holds/{id}/void", withIdempotency(logger, idempotencyStore, handleVoidHold(logger, transactionSvc)))
+}
+
+func handlePostHold(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accountID := r.PathValue("id")
+			if accountID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			var holdRequest request.Hold
+			if err := json.NewDecoder(r.Body).Decode(&holdRequest); err != nil {
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
+				return
+			}
+
+			amount, err := holdRequest.Money()
+			if err != nil {
+				writeError(logger, w, r, "invalid amount", err)
+				return
+			}
+
+			hold, err := transactionSvc.Authorize(r.Context(), accountID, holdRequest.ToAccount, amount, holdRequest.ExpiresAt)
+			if err != nil {
+				writeError(logger, w, r, "failed to place hold", err)
+				return
+			}
+
+			w.Header().Set("Location", "/holds/"+hold.ID)
+			writeResponseJson(r.Context(), logger, w, http.StatusCreated, response.HoldFromDomain(hold))
+		},
+	)
+}
+
+func handleGetAccountHolds(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accountID := r.PathValue("id")
+			if accountID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			holds, err := transactionSvc.GetAccountHolds(accountID)
+			if err != nil {
+				writeError(logger, w, r, "failed to get holds", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.HoldsFromDomain(holds))
+		},
+	)
+}
+
+func handleGetHold(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			holdID := r.PathValue("id")
+			if holdID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			hold, err := transactionSvc.GetHold(holdID)
+			if err != nil {
+				writeError(logger, w, r, "failed to get hold", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.HoldFromDomain(hold))
+		},
+	)
+}
+
+func handleCaptureHold(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			holdID := r.PathValue("id")
+			if holdID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			// the body is optional, without it the whole hold is captured
+			var captureRequest request.Capture
+			if err := json.NewDecoder(r.Body).Decode(&captureRequest); err != nil && !errors.Is(err, io.EOF) {
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
+				return
+			}
+
+			amount, err := captureRequest.Money()
+			if err != nil {
+				writeError(logger, w, r, "invalid amount", err)
+				return
+			}
+
+			hold, made, err := transactionSvc.Capture(r.Context(), holdID, amount)
+			if err != nil {
+				writeError(logger, w, r, "failed to capture hold", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.CaptureFromDomain(hold, made))
+		},
+	)
+}
+
+func handleVoidHold(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			holdID := r.PathValue("id")
+			if holdID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			hold, err := transactionSvc.Void(r.Context(), holdID)
+			if err != nil {
+				writeError(logger, w, r, "failed to void hold", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.HoldFromDomain(hold))
+		},
+	)
+}
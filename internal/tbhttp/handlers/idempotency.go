@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"http/internal/idempotency"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// withIdempotency stores the first response for every Idempotency-Key and replays it for retries of the same
+// request. Requests without the header go straight to next.
+func withIdempotency(logger *slog.Logger, store idempotency.Store, next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeBadRequest(logger, w, r, "invalid body", "invalid_body", err.Error())
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			requestHash := hashRequest(r, body)
+
+			record, err := store.Begin(key, requestHash)
+			if err != nil {
+				writeError(logger, w, r, "failed to check idempotency key", err)
+				return
+			}
+
+			if record != nil {
+				logger.DebugContext(r.Context(), "replaying response", "key", key)
+				w.Header().Set("Content-Type", record.ContentType)
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.StatusCode)
+				w.Write(record.Body)
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			completed := false
+			defer func() {
+				if !completed {
+					store.Abort(key)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			store.Complete(key, idempotency.Record{
+				RequestHash: requestHash,
+				StatusCode:  recorder.statusCode,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Body:        recorder.body.Bytes(),
+			})
+			completed = true
+		},
+	)
+}
+
+// hashRequest identifies a request by method, path, query and body, so a key reused on another account or for a dry
+// run of the same request is detected
+func hashRequest(r *http.Request, body []byte) string {
+	target := r.URL.Path
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + target + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder writes through to the client while keeping a copy of the response
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (recorder *responseRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *responseRecorder) Write(b []byte) (int, error) {
+	recorder.body.Write(b)
+	return recorder.ResponseWriter.Write(b)
+}
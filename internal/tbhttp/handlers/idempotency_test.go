@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"http/internal/idempotency"
+)
+
+func TestWithIdempotency(t *testing.T) {
+	type call struct {
+		key            string
+		target         string
+		body           string
+		wantStatusCode int
+		wantBody       string
+	}
+	tests := []struct {
+		name      string
+		calls     []call
+		wantCalls int
+	}{
+		{
+			name: "retry is replayed",
+			calls: []call{
+				{key: "key", body: `{"amount":1}`, wantStatusCode: http.StatusCreated, wantBody: "1"},
+				{key: "key", body: `{"amount":1}`, wantStatusCode: http.StatusCreated, wantBody: "1"},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "reused key with different body, want 422",
+			calls: []call{
+				{key: "key", body: `{"amount":1}`, wantStatusCode: http.StatusCreated, wantBody: "1"},
+				{key: "key", body: `{"amount":2}`, wantStatusCode: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "reused key for a dry run of the same request, want 422",
+			calls: []call{
+				{key: "key", target: "/transaction?dry-run=true", body: `{"amount":1}`, wantStatusCode: http.StatusCreated, wantBody: "1"},
+				{key: "key", body: `{"amount":1}`, wantStatusCode: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "requests without key are always executed",
+			calls: []call{
+				{body: `{"amount":1}`, wantStatusCode: http.StatusCreated, wantBody: "1"},
+				{body: `{"amount":1}`, wantStatusCode: http.StatusCreated, wantBody: "2"},
+			},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.ReadAll(r.Body)
+				calls++
+				w.WriteHeader(http.StatusCreated)
+				io.WriteString(w, strconv.Itoa(calls))
+			})
+			handler := withIdempotency(slog.New(slog.NewTextHandler(io.Discard, nil)), idempotency.NewMemoryStore(time.Hour), next)
+
+			for i, c := range tt.calls {
+				target := c.target
+				if target == "" {
+					target = "/transaction"
+				}
+				r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(c.body))
+				if c.key != "" {
+					r.Header.Set(idempotencyKeyHeader, c.key)
+				}
+				w := httptest.NewRecorder()
+
+				handler.ServeHTTP(w, r)
+
+				if w.Code != c.wantStatusCode {
+					t.Errorf("call %d status got = %v, want %v", i, w.Code, c.wantStatusCode)
+				}
+				if c.wantBody != "" && w.Body.String() != c.wantBody {
+					t.Errorf("call %d body got = %v, want %v", i, w.Body.String(), c.wantBody)
+				}
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler calls got = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
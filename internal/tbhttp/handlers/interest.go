@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"http/internal/rateplan"
+	"http/internal/tbhttp/handlers/response"
+)
+
+func RegisterInterestHandler(mux *http.ServeMux, logger *slog.Logger, plans *rateplan.Table) {
+	logger.Debug("registering interest endpoints")
+
+	logger.Debug("registering GET /interest/plans")
+	mux.Handle("GET /interest/plans", handleGetRatePlans(logger, plans))
+}
+
+func handleGetRatePlans(logger *slog.Logger, plans *rateplan.Table) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.RatePlansFromDomain(plans.Plans()))
+		},
+	)
+}
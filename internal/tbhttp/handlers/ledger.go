@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"http/internal/service/ledger"
+	"http/internal/tbhttp/handlers/response"
+)
+
+func RegisterLedgerHandler(mux *http.ServeMux, logger *slog.Logger, ledgerSvc *ledger.Service) {
+	logger.Debug("registering ledger endpoints")
+
+	logger.Debug("registering GET /ledger/trial-balance")
+	mux.Handle("GET /ledger/trial-balance", handleGetTrialBalance(logger, ledgerSvc))
+}
+
+func handleGetTrialBalance(logger *slog.Logger, ledgerSvc *ledger.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			trialBalance, err := ledgerSvc.TrialBalance(r.Context())
+			if err != nil {
+				writeError(logger, w, r, "failed to get trial balance", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.TrialBalanceFromDomain(trialBalance))
+		},
+	)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	// the tz parameter takes IANA names, the table is embedded so it doesn't depend on the host's
+	_ "time/tzdata"
+
+	"http/internal/tberrors"
+)
+
+// queryParams reads typed query parameters, every parameter failing to parse is remembered so a handler checks
+// once and reports all of them together
+type queryParams struct {
+	values url.Values
+	errs   []error
+}
+
+func newQueryParams(r *http.Request) *queryParams {
+	return &queryParams{values: r.URL.Query()}
+}
+
+// Err describes every parameter that failed to parse as a validation error naming it
+func (params *queryParams) Err() error {
+	return errors.Join(params.errs...)
+}
+
+func (params *queryParams) fail(name string, err error) {
+	message := fmt.Sprintf("invalid %s parameter: %s", name, err)
+	params.errs = append(params.errs, tberrors.NewValidationError("invalid_parameter", message, name))
+}
+
+// queryParam parses the named parameter, fallback is returned when it's absent or empty
+func queryParam[T any](params *queryParams, name string, parse func(string) (T, error), fallback T) T {
+	value := params.values.Get(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := parse(value)
+	if err != nil {
+		params.fail(name, err)
+		return fallback
+	}
+
+	return parsed
+}
+
+// queryList parses the comma separated values of the named parameter, the parameter may also be repeated
+func queryList[T any](params *queryParams, name string, parse func(string) (T, error)) []T {
+	var parsed []T
+	for _, value := range params.values[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+
+			v, err := parse(item)
+			if err != nil {
+				params.fail(name, err)
+				continue
+			}
+			parsed = append(parsed, v)
+		}
+	}
+
+	return parsed
+}
+
+// queryOptional parses the named parameter, nil is returned when it's absent or empty
+func queryOptional[T any](params *queryParams, name string, parse func(string) (T, error)) *T {
+	if params.values.Get(name) == "" {
+		return nil
+	}
+
+	parsed := queryParam(params, name, parse, *new(T))
+	return &parsed
+}
+
+const dateLayout = "2006-01-02"
+
+// parseTime reads an RFC 3339 timestamp or a date, a date is the start of that day in location. With endOfDay
+// a date is the last instant of that day instead.
+func parseTime(location *time.Location, endOfDay bool) func(string) (time.Time, error) {
+	return func(value string) (time.Time, error) {
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t, nil
+		}
+
+		day, err := time.ParseInLocation(dateLayout, value, location)
+		if err != nil {
+			return time.Time{}, errors.New("must be a date such as 2025-01-31 or an RFC 3339 timestamp")
+		}
+		if endOfDay {
+			return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+		}
+
+		return day, nil
+	}
+}
+
+// parseSort reads asc or desc, it tells whether the listing is descending
+func parseSort(value string) (bool, error) {
+	switch value {
+	case "asc":
+		return false, nil
+	case "desc":
+		return true, nil
+	default:
+		return false, errors.New("must be asc or desc")
+	}
+}
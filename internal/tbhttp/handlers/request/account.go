@@ -1,5 +1,55 @@
 package request
 
+import (
+	"encoding/json"
+
+	"http/internal/domain"
+	"http/internal/tberrors"
+)
+
 type AccountBalance struct {
-	Balance int `json:"balance"`
+	Balance  json.Number `json:"balance"`
+	Currency string      `json:"currency"`
+}
+
+func (b AccountBalance) Money() (domain.Amount, error) {
+	return parseAmount(b.Balance, b.Currency)
+}
+
+type OpenAccount struct {
+	// Type is checking or savings, defaults to checking
+	Type     string `json:"type"`
+	Nickname string `json:"nickname"`
+	// Currency defaults to domain.DefaultCurrency
+	Currency string `json:"currency"`
+}
+
+func (a OpenAccount) AccountType() (domain.AccountType, error) {
+	return domain.ParseAccountType(a.Type)
+}
+
+func (a OpenAccount) AccountCurrency() (domain.Currency, error) {
+	return parseAccountCurrency(a.Currency)
+}
+
+type Overdraft struct {
+	// Limit is how far below zero the balance may go, zero removes the overdraft
+	Limit    json.Number `json:"limit"`
+	Currency string      `json:"currency"`
+	// InterestRate is the yearly debit interest as a fraction, such as 0.12 for 12%
+	InterestRate string `json:"interest_rate"`
+}
+
+func (o Overdraft) Money() (domain.Amount, error) {
+	limit, err := parseAmount(o.Limit, o.Currency)
+	if err != nil {
+		return nil, tberrors.Nested("limit", err)
+	}
+
+	return limit, nil
+}
+
+type RatePlan struct {
+	// Plan names a loaded rate plan, empty takes the account off its plan
+	Plan string `json:"plan"`
 }
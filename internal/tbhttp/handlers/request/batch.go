@@ -0,0 +1,250 @@
+package request
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"http/internal/domain"
+	"http/internal/tberrors"
+)
+
+// Batch files hold transfers out of the account in the path, one per line. Every line that fails to parse is
+// reported at once under its number, lines[3].amount for the amount of the third transfer.
+
+// BatchFormat is one of the file formats batches are uploaded as
+type BatchFormat string
+
+const (
+	BatchCSV     BatchFormat = "csv"
+	BatchPain001 BatchFormat = "pain.001"
+)
+
+// BatchFormatOf tells the format of a file from its media type, ok is false for unsupported ones
+func BatchFormatOf(mediaType string) (BatchFormat, bool) {
+	switch mediaType {
+	case "text/csv":
+		return BatchCSV, true
+	case "application/xml", "text/xml":
+		return BatchPain001, true
+	default:
+		return "", false
+	}
+}
+
+// ReadBatch reads the lines of a file in format, the transfers are out of accountID. Amounts of lines without a
+// currency are in the one of the account.
+func ReadBatch(format BatchFormat, r io.Reader, accountID string) ([]domain.BatchTransfer, error) {
+	if format == BatchPain001 {
+		return readPain001(r, accountID)
+	}
+
+	return readBatchCSV(r)
+}
+
+// invalidBatchFile keeps err so readers failing on their own, such as a body over its size limit, can be told apart
+func invalidBatchFile(err error) error {
+	return errors.Join(tberrors.NewValidationError("invalid_file", fmt.Sprintf("invalid batch file: %s", err), "file"), err)
+}
+
+var missingToAccountColumn = tberrors.NewValidationError("missing_column", "file has no to_account column", "file")
+var missingAmountColumn = tberrors.NewValidationError("missing_column", "file has no amount column", "file")
+var missingLineToAccount = tberrors.NewValidationError("missing_to_account", "to account ID is required", "to_account")
+var debtorAccountMismatch = tberrors.NewValidationError("debtor_mismatch", "debtor account is not the account of the batch", "file")
+var numberOfTransactionsMismatch = tberrors.NewValidationError("number_of_transactions_mismatch", "NbOfTxs doesn't match the transfers in the file", "file")
+var controlSumMismatch = tberrors.NewValidationError("control_sum_mismatch", "CtrlSum doesn't match the amounts in the file", "file")
+
+// readBatchCSV reads a header naming the columns then one transfer per record. to_account and amount are
+// required, currency and reference are optional, other columns are ignored.
+func readBatchCSV(r io.Reader) ([]domain.BatchTransfer, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, invalidBatchFile(err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var errs []error
+	if _, ok := columns["to_account"]; !ok {
+		errs = append(errs, missingToAccountColumn)
+	}
+	if _, ok := columns["amount"]; !ok {
+		errs = append(errs, missingAmountColumn)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	column := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var transfers []domain.BatchTransfer
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, invalidBatchFile(err)
+		}
+
+		transfer, err := parseBatchLine(column(record, "to_account"), column(record, "amount"), column(record, "currency"), column(record, "reference"))
+		if err != nil {
+			errs = append(errs, tberrors.Nested(domain.LineField(len(transfers)+1), err))
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, errors.Join(errs...)
+}
+
+// pain001Document is the part of an ISO 20022 customer credit transfer initiation, pain.001.001.03 and later,
+// a batch is made of. Account ids are read from Othr>Id, IBAN is accepted for the ones without.
+type pain001Document struct {
+	GroupHeader struct {
+		NumberOfTransactions string `xml:"NbOfTxs"`
+		ControlSum           string `xml:"CtrlSum"`
+	} `xml:"CstmrCdtTrfInitn>GrpHdr"`
+	Payments []pain001Payment `xml:"CstmrCdtTrfInitn>PmtInf"`
+}
+
+type pain001Payment struct {
+	DebtorAccount pain001Account    `xml:"DbtrAcct"`
+	Transfers     []pain001Transfer `xml:"CdtTrfTxInf"`
+}
+
+type pain001Account struct {
+	IBAN  string `xml:"Id>IBAN"`
+	Other string `xml:"Id>Othr>Id"`
+}
+
+func (account pain001Account) id() string {
+	if account.Other != "" {
+		return strings.TrimSpace(account.Other)
+	}
+
+	return strings.TrimSpace(account.IBAN)
+}
+
+type pain001Transfer struct {
+	EndToEndID      string         `xml:"PmtId>EndToEndId"`
+	Amount          pain001Amount  `xml:"Amt>InstdAmt"`
+	CreditorAccount pain001Account `xml:"CdtrAcct"`
+}
+
+type pain001Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// readPain001 reads every credit transfer of every payment, the debtor account of a payment must be accountID when
+// it's given. The number of transactions and the control sum of the group header are checked when present.
+func readPain001(r io.Reader, accountID string) ([]domain.BatchTransfer, error) {
+	var document pain001Document
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, invalidBatchFile(err)
+	}
+
+	var transfers []domain.BatchTransfer
+	var errs []error
+	for _, payment := range document.Payments {
+		if debtor := payment.DebtorAccount.id(); debtor != "" && debtor != accountID {
+			errs = append(errs, debtorAccountMismatch)
+		}
+
+		for _, transfer := range payment.Transfers {
+			line, err := parseBatchLine(transfer.CreditorAccount.id(), strings.TrimSpace(transfer.Amount.Value), transfer.Amount.Currency,
+				strings.TrimSpace(transfer.EndToEndID))
+			if err != nil {
+				errs = append(errs, tberrors.Nested(domain.LineField(len(transfers)+1), err))
+			}
+			transfers = append(transfers, line)
+		}
+	}
+
+	header := document.GroupHeader
+	if count := strings.TrimSpace(header.NumberOfTransactions); count != "" && count != strconv.Itoa(len(transfers)) {
+		errs = append(errs, numberOfTransactionsMismatch)
+	}
+	if sum := strings.TrimSpace(header.ControlSum); sum != "" && len(errs) == 0 && !matchesControlSum(sum, transfers) {
+		errs = append(errs, controlSumMismatch)
+	}
+
+	return transfers, errors.Join(errs...)
+}
+
+// matchesControlSum tells whether sum is the total of the amounts of transfers, files mixing currencies have no total
+// to check against. Amounts without a currency are added up as the decimals they were written as.
+func matchesControlSum(sum string, transfers []domain.BatchTransfer) bool {
+	controlSum, ok := decimal(sum)
+	if !ok {
+		return false
+	}
+
+	total := new(big.Rat)
+	var currency domain.Currency
+	for _, transfer := range transfers {
+		var amount string
+		switch a := transfer.Amount.(type) {
+		case domain.Money:
+			if currency != "" && a.Currency != currency {
+				return true
+			}
+			currency, amount = a.Currency, a.String()
+		case domain.DecimalAmount:
+			amount = string(a)
+		}
+
+		value, ok := decimal(amount)
+		if !ok {
+			return true
+		}
+		total.Add(total, value)
+	}
+
+	return controlSum.Cmp(total) == 0
+}
+
+// decimal reads a decimal amount such as "12.34" as an exact number
+func decimal(amount string) (*big.Rat, bool) {
+	if _, err := domain.ParseDecimalAmount(amount); err != nil {
+		return nil, false
+	}
+
+	return new(big.Rat).SetString(amount)
+}
+
+func parseBatchLine(toAccount, amount, currencyCode, reference string) (domain.BatchTransfer, error) {
+	transfer := domain.BatchTransfer{ToAccountID: toAccount, Reference: reference}
+
+	var errs []error
+	if toAccount == "" {
+		errs = append(errs, missingLineToAccount)
+	}
+
+	var err error
+	if transfer.Amount, err = parseAmount(json.Number(amount), currencyCode); err != nil {
+		errs = append(errs, err)
+	}
+
+	return transfer, errors.Join(errs...)
+}
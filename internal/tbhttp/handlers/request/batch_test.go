@@ -0,0 +1,152 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+	"http/internal/tberrors"
+)
+
+const pain001 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>PAYROLL-2025-01</MsgId>
+      <CreDtTm>2025-01-25T09:00:00</CreDtTm>
+      <NbOfTxs>%d</NbOfTxs>
+      <CtrlSum>%s</CtrlSum>
+      <InitgPty><Nm>ACME</Nm></InitgPty>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PAYROLL-2025-01-1</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt>2025-01-27</ReqdExctnDt>
+      <Dbtr><Nm>ACME</Nm></Dbtr>
+      <DbtrAcct><Id><Othr><Id>%s</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>SALARY-ADA</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">2500.00</InstdAmt></Amt>
+        <Cdtr><Nm>Ada</Nm></Cdtr>
+        <CdtrAcct><Id><Othr><Id>acc-ada</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>SALARY-ALAN</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">%s</InstdAmt></Amt>
+        <Cdtr><Nm>Alan</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>%s</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+func TestReadBatch(t *testing.T) {
+	eur := func(amount int64) domain.Money {
+		return domain.NewMoney(amount, domain.EUR)
+	}
+	pain := func(count int, controlSum, debtor, secondAmount, secondAccount string) string {
+		return fmt.Sprintf(pain001, count, controlSum, debtor, secondAmount, secondAccount)
+	}
+
+	tests := []struct {
+		name       string
+		format     BatchFormat
+		file       string
+		want       []domain.BatchTransfer
+		wantFields []string
+	}{
+		{
+			name:   "csv with columns in any order",
+			format: BatchCSV,
+			file:   "reference,amount,to_account,currency,note\nsalary-ada,2500,acc-ada,EUR,january\n, 10.5 ,acc-alan,,\n",
+			want: []domain.BatchTransfer{
+				{ToAccountID: "acc-ada", Amount: eur(250000), Reference: "salary-ada"},
+				{ToAccountID: "acc-alan", Amount: domain.DecimalAmount("10.5")},
+			},
+		},
+		{
+			name:       "csv without the required columns",
+			format:     BatchCSV,
+			file:       "account,value\nacc-ada,1\n",
+			wantFields: []string{"file", "file"},
+		},
+		{
+			name:       "csv lines are reported under their number",
+			format:     BatchCSV,
+			file:       "to_account,amount,currency\nacc-ada,1,EUR\n,ten,EUR\nacc-alan,1,XYZ\n",
+			wantFields: []string{"lines[2].to_account", "lines[2].amount", "lines[3].currency"},
+		},
+		{
+			name:       "csv with a short record",
+			format:     BatchCSV,
+			file:       "to_account,amount\nacc-ada\n",
+			wantFields: []string{"file"},
+		},
+		{
+			name:   "pain.001",
+			format: BatchPain001,
+			file:   pain(2, "4300.50", "acc-1", "1800.50", "acc-alan"),
+			want: []domain.BatchTransfer{
+				{ToAccountID: "acc-ada", Amount: eur(250000), Reference: "SALARY-ADA"},
+				{ToAccountID: "acc-alan", Amount: eur(180050), Reference: "SALARY-ALAN"},
+			},
+		},
+		{
+			name:       "pain.001 of another debtor with a wrong number of transactions",
+			format:     BatchPain001,
+			file:       pain(3, "4300.50", "acc-2", "1800.50", "acc-alan"),
+			wantFields: []string{"file", "file"},
+		},
+		{
+			name:       "pain.001 with a wrong control sum",
+			format:     BatchPain001,
+			file:       pain(2, "4300.00", "acc-1", "1800.50", "acc-alan"),
+			wantFields: []string{"file"},
+		},
+		{
+			name:       "pain.001 lines are reported under their number",
+			format:     BatchPain001,
+			file:       pain(2, "4300.50", "acc-1", "twelve", ""),
+			wantFields: []string{"lines[2].to_account", "lines[2].amount"},
+		},
+		{
+			name:       "malformed pain.001",
+			format:     BatchPain001,
+			file:       "<Document><CstmrCdtTrfInitn>",
+			wantFields: []string{"file"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadBatch(tt.format, strings.NewReader(tt.file), "acc-1")
+
+			if diff := cmp.Diff(tt.wantFields, validationFields(err)); diff != "" {
+				t.Fatalf("ReadBatch() error = %v, fields (-want +got):\n%s", err, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ReadBatch() (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// validationFields lists the field of every validation error joined in err
+func validationFields(err error) []string {
+	var fields []string
+	switch e := err.(type) {
+	case tberrors.ValidationError:
+		fields = append(fields, e.Field())
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			fields = append(fields, validationFields(inner)...)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,34 @@
+package request
+
+import (
+	"encoding/json"
+	"time"
+
+	"http/internal/domain"
+)
+
+type Hold struct {
+	// ToAccount is paid when the hold is captured, captures without it are withdrawals
+	ToAccount string      `json:"to_account"`
+	Amount    json.Number `json:"amount"`
+	Currency  string      `json:"currency"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+func (h Hold) Money() (domain.Amount, error) {
+	return parseAmount(h.Amount, h.Currency)
+}
+
+// Capture takes the whole held amount when Amount is empty
+type Capture struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+func (c Capture) Money() (domain.Amount, error) {
+	if c.Amount == "" {
+		return nil, nil
+	}
+
+	return parseAmount(c.Amount, c.Currency)
+}
@@ -0,0 +1,63 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/tberrors"
+)
+
+type StandingOrder struct {
+	ToAccount string      `json:"to_account"`
+	Amount    json.Number `json:"amount"`
+	Currency  string      `json:"currency"`
+	Reference string      `json:"reference"`
+	// Schedule is a cron expression such as "0 9 1 * *" or an RRULE such as "FREQ=MONTHLY;BYMONTHDAY=1"
+	Schedule string `json:"schedule"`
+	// TimeZone is the IANA time zone the schedule is in, UTC by default
+	TimeZone string     `json:"time_zone"`
+	StartAt  *time.Time `json:"start_at"`
+	EndAt    *time.Time `json:"end_at"`
+	// Retries is how many more times a payment failing for lack of funds is attempted, RetryInterval apart
+	Retries       int    `json:"retries"`
+	RetryInterval string `json:"retry_interval"`
+	// OnFailure is skip, the default, or suspend
+	OnFailure string `json:"on_failure"`
+}
+
+var invalidRetryInterval = tberrors.NewValidationError("invalid_retry_interval", "retry interval must be a duration such as 30m or 2h", "retry_interval")
+
+// Terms reads the terms of the order, every invalid field is reported at once
+func (o StandingOrder) Terms() (domain.StandingOrderTerms, error) {
+	terms := domain.StandingOrderTerms{
+		ToAccountID: o.ToAccount,
+		Reference:   o.Reference,
+		Schedule:    o.Schedule,
+		TimeZone:    o.TimeZone,
+		EndAt:       o.EndAt,
+		Policy:      domain.FailurePolicy{Retries: o.Retries},
+	}
+	if o.StartAt != nil {
+		terms.StartAt = *o.StartAt
+	}
+
+	var errs []error
+	var err error
+	if terms.Amount, err = parseAmount(o.Amount, o.Currency); err != nil {
+		errs = append(errs, err)
+	}
+	if o.RetryInterval != "" {
+		if terms.Policy.RetryInterval, err = time.ParseDuration(o.RetryInterval); err != nil {
+			errs = append(errs, invalidRetryInterval)
+		}
+	}
+	if o.OnFailure != "" {
+		if terms.Policy.OnFailure, err = domain.ParseFailureAction(o.OnFailure); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return terms, errors.Join(errs...)
+}
@@ -1,15 +1,77 @@
 package request
 
+import (
+	"encoding/json"
+
+	"http/internal/domain"
+)
+
+// Amounts are decimal strings such as "12.34", plain JSON numbers are accepted too. When Currency is omitted the
+// amount is in the currency of the account it moves, which only the service knows.
+
 type Transaction struct {
-	FromAccount string `json:"from_account"`
-	ToAccount   string `json:"to_account"`
-	Amount      int    `json:"amount"`
+	FromAccount string      `json:"from_account"`
+	ToAccount   string      `json:"to_account"`
+	Amount      json.Number `json:"amount"`
+	Currency    string      `json:"currency"`
+}
+
+func (t Transaction) Money() (domain.Amount, error) {
+	return parseAmount(t.Amount, t.Currency)
 }
 
 type Withdraw struct {
-	Amount int `json:"amount"`
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+func (w Withdraw) Money() (domain.Amount, error) {
+	return parseAmount(w.Amount, w.Currency)
 }
 
 type Deposit struct {
-	Amount int `json:"amount"`
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+func (d Deposit) Money() (domain.Amount, error) {
+	return parseAmount(d.Amount, d.Currency)
+}
+
+// Reversal refunds whatever is left to refund of the transaction when Amount is empty. Amount is in the currency
+// the transaction credited.
+type Reversal struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+func (r Reversal) Money() (domain.Amount, error) {
+	if r.Amount == "" {
+		return nil, nil
+	}
+
+	return parseAmount(r.Amount, r.Currency)
+}
+
+// parseAmount reads an amount in the currency named, without one it's read once the currency of its account is known
+func parseAmount(amount json.Number, currencyCode string) (domain.Amount, error) {
+	if currencyCode == "" {
+		return domain.ParseDecimalAmount(amount.String())
+	}
+
+	currency, err := domain.ParseCurrency(currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.ParseMoney(amount.String(), currency)
+}
+
+// parseAccountCurrency reads the currency of an account being opened, domain.DefaultCurrency when omitted
+func parseAccountCurrency(currencyCode string) (domain.Currency, error) {
+	if currencyCode == "" {
+		return domain.DefaultCurrency, nil
+	}
+
+	return domain.ParseCurrency(currencyCode)
 }
@@ -1,5 +1,13 @@
 package request
 
+import "http/internal/domain"
+
 type UserRequest struct {
 	Name string `json:"name"`
+	// Currency of the account opened with the user, defaults to domain.DefaultCurrency
+	Currency string `json:"currency"`
+}
+
+func (u UserRequest) AccountCurrency() (domain.Currency, error) {
+	return parseAccountCurrency(u.Currency)
 }
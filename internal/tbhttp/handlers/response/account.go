@@ -7,17 +7,43 @@ import (
 )
 
 type AccountResponse struct {
-	ID        string     `json:"id"`
-	UserID    string     `json:"user_id"`
-	Balance   int        `json:"balance"`
-	DeletedAt *time.Time `json:"deleted_at"`
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	// Balance is the ledger balance, AvailableBalance adds the overdraft limit to it and takes out what active holds
+	// reserve
+	Balance               domain.Money  `json:"balance"`
+	AvailableBalance      domain.Money  `json:"available_balance"`
+	Held                  *domain.Money `json:"held,omitempty"`
+	Currency              string        `json:"currency"`
+	Type                  string        `json:"type"`
+	Nickname              string        `json:"nickname,omitempty"`
+	OverdraftLimit        *domain.Money `json:"overdraft_limit,omitempty"`
+	OverdraftInterestRate string        `json:"overdraft_interest_rate,omitempty"`
+	InterestPlan          string        `json:"interest_plan,omitempty"`
+	DeletedAt             *time.Time    `json:"deleted_at"`
 }
 
 func AccountResponseFromDomain(account *domain.Account) AccountResponse {
-	return AccountResponse{
-		ID:        account.ID,
-		UserID:    account.UserID,
-		Balance:   account.Balance,
-		DeletedAt: account.DeletedAt,
+	accountResponse := AccountResponse{
+		ID:               account.ID,
+		UserID:           account.UserID,
+		Balance:          account.Balance,
+		AvailableBalance: account.AvailableBalance(),
+		Currency:         account.Balance.Currency.String(),
+		Type:             account.Type.String(),
+		Nickname:         account.Nickname,
+		InterestPlan:     account.Interest.Plan,
+		DeletedAt:        account.DeletedAt,
 	}
+
+	if limit := account.Overdraft.Limit; limit.IsPositive() {
+		accountResponse.OverdraftLimit = &limit
+		accountResponse.OverdraftInterestRate = account.Overdraft.Rate
+	}
+
+	if held := account.Held; held.IsPositive() {
+		accountResponse.Held = &held
+	}
+
+	return accountResponse
 }
@@ -0,0 +1,34 @@
+package response
+
+import (
+	"time"
+
+	"http/internal/domain"
+)
+
+type AuditEventResponse struct {
+	ID         string    `json:"id"`
+	Action     string    `json:"action"`
+	AccountIDs []string  `json:"account_ids"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func AuditEventsFromDomain(events []domain.AuditEvent) []AuditEventResponse {
+	auditEvents := make([]AuditEventResponse, len(events))
+
+	for i, event := range events {
+		accountIDs := event.AccountIDs
+		if accountIDs == nil {
+			accountIDs = []string{}
+		}
+
+		auditEvents[i] = AuditEventResponse{
+			ID:         event.ID,
+			Action:     event.Action.String(),
+			AccountIDs: accountIDs,
+			OccurredAt: event.OccurredAt,
+		}
+	}
+
+	return auditEvents
+}
@@ -0,0 +1,56 @@
+package response
+
+import (
+	"time"
+
+	"http/internal/domain"
+)
+
+type Batch struct {
+	ID          string      `json:"id"`
+	AccountID   string      `json:"account_id"`
+	Mode        string      `json:"mode"`
+	Status      string      `json:"status"`
+	CreatedAt   time.Time   `json:"created_at"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+	Lines       []BatchLine `json:"lines"`
+}
+
+type BatchLine struct {
+	Number        int          `json:"number"`
+	ToAccount     string       `json:"to_account"`
+	Amount        domain.Money `json:"amount"`
+	Currency      string       `json:"currency"`
+	Reference     string       `json:"reference,omitempty"`
+	Status        string       `json:"status"`
+	TransactionID string       `json:"transaction_id,omitempty"`
+	FailureCode   string       `json:"failure_code,omitempty"`
+	FailureReason string       `json:"failure_reason,omitempty"`
+}
+
+func BatchFromDomain(batch *domain.Batch) Batch {
+	lines := make([]BatchLine, len(batch.Lines))
+	for i, line := range batch.Lines {
+		lines[i] = BatchLine{
+			Number:        line.Number,
+			ToAccount:     line.ToAccountID,
+			Amount:        line.Amount,
+			Currency:      line.Amount.Currency.String(),
+			Reference:     line.Reference,
+			Status:        line.Status.String(),
+			TransactionID: line.TransactionID,
+			FailureCode:   line.FailureCode,
+			FailureReason: line.FailureReason,
+		}
+	}
+
+	return Batch{
+		ID:          batch.ID,
+		AccountID:   batch.AccountID,
+		Mode:        batch.Mode.String(),
+		Status:      batch.Status.String(),
+		CreatedAt:   batch.CreatedAt,
+		CompletedAt: batch.CompletedAt,
+		Lines:       lines,
+	}
+}
@@ -2,5 +2,7 @@ package response
 
 type Error struct {
 	Message string `json:"message"`
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
 	Details string `json:"details"`
 }
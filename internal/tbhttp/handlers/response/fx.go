@@ -0,0 +1,28 @@
+package response
+
+import (
+	"time"
+
+	"http/internal/domain"
+)
+
+type ExchangeRate struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ExchangeRatesFromDomain(rates []domain.ExchangeRate) []ExchangeRate {
+	exchangeRates := make([]ExchangeRate, len(rates))
+	for i, rate := range rates {
+		exchangeRates[i] = ExchangeRate{
+			From:      rate.From.String(),
+			To:        rate.To.String(),
+			Rate:      rate.Rate,
+			UpdatedAt: rate.UpdatedAt,
+		}
+	}
+
+	return exchangeRates
+}
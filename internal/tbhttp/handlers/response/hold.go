@@ -0,0 +1,62 @@
+package response
+
+import (
+	"time"
+
+	"http/internal/domain"
+)
+
+type Hold struct {
+	ID        string       `json:"id"`
+	AccountID string       `json:"account_id"`
+	ToAccount string       `json:"to_account,omitempty"`
+	Amount    domain.Money `json:"amount"`
+	Currency  string       `json:"currency"`
+	Status    string       `json:"status"`
+	// Captured and TransactionID are set once the hold was captured
+	Captured      *domain.Money `json:"captured,omitempty"`
+	TransactionID string        `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	ExpiresAt     time.Time     `json:"expires_at"`
+	ReleasedAt    *time.Time    `json:"released_at,omitempty"`
+	// Transaction is the payment a capture made, it's only set in capture responses
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
+
+func HoldFromDomain(hold *domain.Hold) Hold {
+	response := Hold{
+		ID:            hold.ID,
+		AccountID:     hold.AccountID,
+		ToAccount:     hold.ToAccountID,
+		Amount:        hold.Amount,
+		Currency:      hold.Amount.Currency.String(),
+		Status:        hold.Status.String(),
+		TransactionID: hold.TransactionID,
+		CreatedAt:     hold.CreatedAt,
+		ExpiresAt:     hold.ExpiresAt,
+		ReleasedAt:    hold.ReleasedAt,
+	}
+	if hold.Status == domain.HoldCaptured {
+		captured := hold.Captured
+		response.Captured = &captured
+	}
+
+	return response
+}
+
+func HoldsFromDomain(holds []*domain.Hold) []Hold {
+	response := make([]Hold, len(holds))
+	for i, hold := range holds {
+		response[i] = HoldFromDomain(hold)
+	}
+
+	return response
+}
+
+func CaptureFromDomain(hold *domain.Hold, transaction *domain.Transaction) Hold {
+	response := HoldFromDomain(hold)
+	made := TransactionFromDomain(transaction)
+	response.Transaction = &made
+
+	return response
+}
@@ -0,0 +1,34 @@
+package response
+
+import (
+	"http/internal/domain"
+)
+
+type RatePlan struct {
+	Name     string     `json:"name"`
+	Currency string     `json:"currency"`
+	Tiers    []RateTier `json:"tiers"`
+}
+
+type RateTier struct {
+	From domain.Money `json:"from"`
+	Rate string       `json:"rate"`
+}
+
+func RatePlansFromDomain(plans []domain.RatePlan) []RatePlan {
+	ratePlans := make([]RatePlan, len(plans))
+	for i, plan := range plans {
+		tiers := make([]RateTier, len(plan.Tiers))
+		for j, tier := range plan.Tiers {
+			tiers[j] = RateTier{From: tier.From, Rate: tier.Rate}
+		}
+
+		ratePlans[i] = RatePlan{
+			Name:     plan.Name,
+			Currency: plan.Currency.String(),
+			Tiers:    tiers,
+		}
+	}
+
+	return ratePlans
+}
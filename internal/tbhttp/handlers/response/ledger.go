@@ -0,0 +1,68 @@
+package response
+
+import "http/internal/domain"
+
+type LedgerAccount struct {
+	AccountID string       `json:"account_id"`
+	Currency  string       `json:"currency"`
+	Debits    domain.Money `json:"debits"`
+	Credits   domain.Money `json:"credits"`
+}
+
+type CurrencyTotal struct {
+	Currency string       `json:"currency"`
+	Debits   domain.Money `json:"debits"`
+	Credits  domain.Money `json:"credits"`
+}
+
+type BalanceMismatch struct {
+	AccountID      string       `json:"account_id"`
+	Currency       string       `json:"currency"`
+	LedgerBalance  domain.Money `json:"ledger_balance"`
+	AccountBalance domain.Money `json:"account_balance"`
+}
+
+type TrialBalance struct {
+	Accounts   []LedgerAccount   `json:"accounts"`
+	Totals     []CurrencyTotal   `json:"totals"`
+	Mismatches []BalanceMismatch `json:"mismatches"`
+	Balanced   bool              `json:"balanced"`
+}
+
+func TrialBalanceFromDomain(trialBalance *domain.TrialBalance) TrialBalance {
+	accounts := make([]LedgerAccount, len(trialBalance.Accounts))
+	for i, account := range trialBalance.Accounts {
+		accounts[i] = LedgerAccount{
+			AccountID: account.AccountID,
+			Currency:  account.Currency.String(),
+			Debits:    account.Debits,
+			Credits:   account.Credits,
+		}
+	}
+
+	totals := make([]CurrencyTotal, len(trialBalance.Totals))
+	for i, total := range trialBalance.Totals {
+		totals[i] = CurrencyTotal{
+			Currency: total.Currency.String(),
+			Debits:   total.Debits,
+			Credits:  total.Credits,
+		}
+	}
+
+	mismatches := make([]BalanceMismatch, len(trialBalance.Mismatches))
+	for i, mismatch := range trialBalance.Mismatches {
+		mismatches[i] = BalanceMismatch{
+			AccountID:      mismatch.AccountID,
+			Currency:       mismatch.AccountBalance.Currency.String(),
+			LedgerBalance:  mismatch.LedgerBalance,
+			AccountBalance: mismatch.AccountBalance,
+		}
+	}
+
+	return TrialBalance{
+		Accounts:   accounts,
+		Totals:     totals,
+		Mismatches: mismatches,
+		Balanced:   trialBalance.Balanced(),
+	}
+}
@@ -0,0 +1,94 @@
+package response
+
+import (
+	"time"
+
+	"http/internal/domain"
+)
+
+type StandingOrder struct {
+	ID            string       `json:"id"`
+	AccountID     string       `json:"account_id"`
+	ToAccount     string       `json:"to_account"`
+	Amount        domain.Money `json:"amount"`
+	Currency      string       `json:"currency"`
+	Reference     string       `json:"reference,omitempty"`
+	Schedule      string       `json:"schedule"`
+	TimeZone      string       `json:"time_zone"`
+	StartAt       time.Time    `json:"start_at"`
+	EndAt         *time.Time   `json:"end_at,omitempty"`
+	Retries       int          `json:"retries"`
+	RetryInterval string       `json:"retry_interval,omitempty"`
+	OnFailure     string       `json:"on_failure"`
+	Status        string       `json:"status"`
+	NextRunAt     *time.Time   `json:"next_run_at,omitempty"`
+	// RetryAt is when the next attempt at NextRunAt is made after a failure
+	RetryAt   *time.Time `json:"retry_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type StandingOrderExecution struct {
+	ID            string    `json:"id"`
+	ScheduledFor  time.Time `json:"scheduled_for"`
+	ExecutedAt    time.Time `json:"executed_at"`
+	Attempt       int       `json:"attempt"`
+	Status        string    `json:"status"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+	FailureCode   string    `json:"failure_code,omitempty"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+}
+
+func StandingOrderFromDomain(order *domain.StandingOrder) StandingOrder {
+	response := StandingOrder{
+		ID:        order.ID,
+		AccountID: order.AccountID,
+		ToAccount: order.ToAccountID,
+		Amount:    order.Amount,
+		Currency:  order.Amount.Currency.String(),
+		Reference: order.Reference,
+		Schedule:  order.Schedule,
+		TimeZone:  order.TimeZone,
+		StartAt:   order.StartAt,
+		EndAt:     order.EndAt,
+		Retries:   order.Policy.Retries,
+		OnFailure: order.Policy.OnFailure.String(),
+		Status:    order.Status.String(),
+		NextRunAt: order.NextRunAt,
+		CreatedAt: order.CreatedAt,
+	}
+	if order.Policy.Retries > 0 {
+		response.RetryInterval = order.Policy.RetryInterval.String()
+	}
+	if order.Attempts > 0 {
+		response.RetryAt = order.DueAt
+	}
+
+	return response
+}
+
+func StandingOrdersFromDomain(orders []*domain.StandingOrder) []StandingOrder {
+	response := make([]StandingOrder, len(orders))
+	for i, order := range orders {
+		response[i] = StandingOrderFromDomain(order)
+	}
+
+	return response
+}
+
+func StandingOrderExecutionsFromDomain(executions []*domain.StandingOrderExecution) []StandingOrderExecution {
+	response := make([]StandingOrderExecution, len(executions))
+	for i, execution := range executions {
+		response[i] = StandingOrderExecution{
+			ID:            execution.ID,
+			ScheduledFor:  execution.ScheduledFor,
+			ExecutedAt:    execution.ExecutedAt,
+			Attempt:       execution.Attempt,
+			Status:        execution.Status.String(),
+			TransactionID: execution.TransactionID,
+			FailureCode:   execution.FailureCode,
+			FailureReason: execution.FailureReason,
+		}
+	}
+
+	return response
+}
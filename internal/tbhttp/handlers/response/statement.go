@@ -0,0 +1,470 @@
+package response
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"http/internal/domain"
+	"http/internal/tberrors"
+)
+
+// StatementFormat is one of the file formats accounting tools import statements from
+type StatementFormat string
+
+const (
+	StatementCSV     StatementFormat = "csv"
+	StatementOFX     StatementFormat = "ofx"
+	StatementCAMT053 StatementFormat = "camt053"
+)
+
+var invalidStatementFormat = tberrors.NewValidationError("invalid_statement_format", "format must be csv, ofx or camt053", "format")
+
+func ParseStatementFormat(format string) (StatementFormat, error) {
+	switch f := StatementFormat(format); f {
+	case StatementCSV, StatementOFX, StatementCAMT053:
+		return f, nil
+	default:
+		return "", invalidStatementFormat
+	}
+}
+
+func (format StatementFormat) ContentType() string {
+	switch format {
+	case StatementCSV:
+		return "text/csv; charset=utf-8"
+	case StatementOFX:
+		return "application/x-ofx"
+	default:
+		return "application/xml"
+	}
+}
+
+// Extension is the usual file name extension of the format
+func (format StatementFormat) Extension() string {
+	if format == StatementCAMT053 {
+		return "xml"
+	}
+
+	return string(format)
+}
+
+// StatementWriter encodes a statement while it's being read. Begin comes first, then every entry and End last, which
+// flushes whatever is still buffered.
+type StatementWriter interface {
+	Begin(statement domain.Statement) error
+	Entry(entry domain.StatementEntry) error
+	End() error
+}
+
+func NewStatementWriter(format StatementFormat, w io.Writer) StatementWriter {
+	switch format {
+	case StatementOFX:
+		return &ofxStatementWriter{xml: newXMLWriter(w)}
+	case StatementCAMT053:
+		return &camtStatementWriter{xml: newXMLWriter(w)}
+	default:
+		return &csvStatementWriter{w: csv.NewWriter(w)}
+	}
+}
+
+// csvStatementWriter writes one record per line, the opening and closing balances are records of their own around
+// the entries
+type csvStatementWriter struct {
+	w         *csv.Writer
+	statement domain.Statement
+}
+
+var csvStatementHeader = []string{"record", "date", "transaction_id", "type", "counterparty", "amount", "currency", "balance"}
+
+func (writer *csvStatementWriter) Begin(statement domain.Statement) error {
+	writer.statement = statement
+	if err := writer.w.Write(csvStatementHeader); err != nil {
+		return err
+	}
+
+	return writer.balance("opening_balance", statement.From, statement.OpeningBalance)
+}
+
+func (writer *csvStatementWriter) Entry(entry domain.StatementEntry) error {
+	return writer.w.Write([]string{
+		"entry",
+		csvTime(entry.Transaction.CreatedAt),
+		entry.Transaction.ID,
+		entry.Transaction.Type.String(),
+		entry.Counterparty,
+		entry.Amount.String(),
+		entry.Amount.Currency.String(),
+		entry.BalanceAfter.String(),
+	})
+}
+
+func (writer *csvStatementWriter) End() error {
+	if err := writer.balance("closing_balance", writer.statement.To, writer.statement.ClosingBalance); err != nil {
+		return err
+	}
+
+	writer.w.Flush()
+	return writer.w.Error()
+}
+
+func (writer *csvStatementWriter) balance(record string, at time.Time, balance domain.Money) error {
+	return writer.w.Write([]string{record, csvTime(at), "", "", "", "", balance.Currency.String(), balance.String()})
+}
+
+func csvTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// ofxStatementWriter writes an OFX 2.2 bank statement response. OFX has no opening balance of its own, it's listed
+// in BALLIST after the closing LEDGERBAL.
+type ofxStatementWriter struct {
+	xml       *xmlWriter
+	statement domain.Statement
+}
+
+const ofxHeader = `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+// ofxBankID stands in for the routing number OFX expects, the bank has none
+const ofxBankID = "TINYBANK"
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+var ofxStatusOK = ofxStatus{Code: 0, Severity: "INFO"}
+
+type ofxSignOn struct {
+	XMLName  xml.Name  `xml:"SIGNONMSGSRSV1"`
+	Status   ofxStatus `xml:"SONRS>STATUS"`
+	Server   string    `xml:"SONRS>DTSERVER"`
+	Language string    `xml:"SONRS>LANGUAGE"`
+}
+
+type ofxAccount struct {
+	XMLName xml.Name `xml:"BANKACCTFROM"`
+	BankID  string   `xml:"BANKID"`
+	ID      string   `xml:"ACCTID"`
+	Type    string   `xml:"ACCTTYPE"`
+}
+
+type ofxTransaction struct {
+	XMLName xml.Name `xml:"STMTTRN"`
+	Type    string   `xml:"TRNTYPE"`
+	Posted  string   `xml:"DTPOSTED"`
+	Amount  string   `xml:"TRNAMT"`
+	ID      string   `xml:"FITID"`
+	Name    string   `xml:"NAME,omitempty"`
+	Memo    string   `xml:"MEMO"`
+}
+
+type ofxLedgerBalance struct {
+	XMLName xml.Name `xml:"LEDGERBAL"`
+	Amount  string   `xml:"BALAMT"`
+	AsOf    string   `xml:"DTASOF"`
+}
+
+type ofxBalanceList struct {
+	XMLName  xml.Name     `xml:"BALLIST"`
+	Balances []ofxBalance `xml:"BAL"`
+}
+
+type ofxBalance struct {
+	Name  string `xml:"NAME"`
+	Desc  string `xml:"DESC"`
+	Type  string `xml:"BALTYPE"`
+	Value string `xml:"VALUE"`
+	AsOf  string `xml:"DTASOF"`
+}
+
+func (writer *ofxStatementWriter) Begin(statement domain.Statement) error {
+	writer.statement = statement
+
+	w := writer.xml
+	w.header(ofxHeader)
+	w.start("OFX")
+	w.encode(ofxSignOn{Status: ofxStatusOK, Server: ofxTime(statement.CreatedAt), Language: "ENG"})
+	w.start("BANKMSGSRSV1", "STMTTRNRS")
+	w.element("TRNUID", statement.ID)
+	w.element("STATUS", ofxStatusOK)
+	w.start("STMTRS")
+	w.element("CURDEF", statement.OpeningBalance.Currency.String())
+	w.encode(ofxAccount{BankID: ofxBankID, ID: statement.AccountID, Type: ofxAccountType(statement.AccountType)})
+	w.start("BANKTRANLIST")
+	w.element("DTSTART", ofxTime(statement.From))
+	w.element("DTEND", ofxTime(statement.To))
+
+	return w.err
+}
+
+func (writer *ofxStatementWriter) Entry(entry domain.StatementEntry) error {
+	writer.xml.encode(ofxTransaction{
+		Type:   ofxTransactionType(entry),
+		Posted: ofxTime(entry.Transaction.CreatedAt),
+		Amount: entry.Amount.String(),
+		ID:     entry.Transaction.ID,
+		Name:   entry.Counterparty,
+		Memo:   entry.Transaction.Type.String(),
+	})
+
+	return writer.xml.err
+}
+
+func (writer *ofxStatementWriter) End() error {
+	statement, w := writer.statement, writer.xml
+	opening := ofxBalance{
+		Name:  "OPENING",
+		Desc:  "Opening balance",
+		Type:  "DOLLAR",
+		Value: statement.OpeningBalance.String(),
+		AsOf:  ofxTime(statement.From),
+	}
+
+	w.end("BANKTRANLIST")
+	w.encode(ofxLedgerBalance{Amount: statement.ClosingBalance.String(), AsOf: ofxTime(statement.To)})
+	w.encode(ofxBalanceList{Balances: []ofxBalance{opening}})
+	w.end("STMTRS", "STMTTRNRS", "BANKMSGSRSV1", "OFX")
+
+	return w.close()
+}
+
+// ofxTime formats t in UTC the way OFX dates are written
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+func ofxAccountType(accountType domain.AccountType) string {
+	if accountType == domain.Savings {
+		return "SAVINGS"
+	}
+
+	return "CHECKING"
+}
+
+func ofxTransactionType(entry domain.StatementEntry) string {
+	switch entry.Transaction.Type {
+	case domain.Deposit:
+		return "DEP"
+	case domain.Withdrawal:
+		return "CASH"
+	case domain.Transfer:
+		return "XFER"
+	case domain.OverdraftInterest, domain.Interest:
+		return "INT"
+	case domain.Fee:
+		return "FEE"
+	}
+
+	if entry.Amount.IsNegative() {
+		return "DEBIT"
+	}
+
+	return "CREDIT"
+}
+
+// camtStatementWriter writes an ISO 20022 camt.053.001.02 bank to customer statement, the opening and closing
+// balances are written before the entries as the schema orders them
+type camtStatementWriter struct {
+	xml *xmlWriter
+}
+
+const camtNamespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+type camtGroupHeader struct {
+	XMLName   xml.Name `xml:"GrpHdr"`
+	MessageID string   `xml:"MsgId"`
+	CreatedAt string   `xml:"CreDtTm"`
+}
+
+type camtPeriod struct {
+	XMLName xml.Name `xml:"FrToDt"`
+	From    string   `xml:"FrDtTm"`
+	To      string   `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	XMLName  xml.Name `xml:"Acct"`
+	ID       string   `xml:"Id>Othr>Id"`
+	Currency string   `xml:"Ccy"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtBalance struct {
+	XMLName     xml.Name   `xml:"Bal"`
+	Type        string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount      camtAmount `xml:"Amt"`
+	CreditDebit string     `xml:"CdtDbtInd"`
+	Date        string     `xml:"Dt>DtTm"`
+}
+
+type camtEntry struct {
+	XMLName           xml.Name    `xml:"Ntry"`
+	Reference         string      `xml:"NtryRef"`
+	Amount            camtAmount  `xml:"Amt"`
+	CreditDebit       string      `xml:"CdtDbtInd"`
+	Status            string      `xml:"Sts"`
+	BookingDate       string      `xml:"BookgDt>DtTm"`
+	ValueDate         string      `xml:"ValDt>DtTm"`
+	ServicerReference string      `xml:"AcctSvcrRef"`
+	TransactionCode   string      `xml:"BkTxCd>Prtry>Cd"`
+	Details           camtDetails `xml:"NtryDtls>TxDtls"`
+}
+
+type camtDetails struct {
+	ServicerReference string       `xml:"Refs>AcctSvcrRef"`
+	Parties           *camtParties `xml:"RltdPties,omitempty"`
+}
+
+// camtParties names the counterparty of a transfer, the creditor when money left the account and the debtor
+// otherwise
+type camtParties struct {
+	DebtorAccount   *camtPartyAccount `xml:"DbtrAcct,omitempty"`
+	CreditorAccount *camtPartyAccount `xml:"CdtrAcct,omitempty"`
+}
+
+type camtPartyAccount struct {
+	ID string `xml:"Id>Othr>Id"`
+}
+
+func (writer *camtStatementWriter) Begin(statement domain.Statement) error {
+	w := writer.xml
+	w.header("")
+	w.token(xml.StartElement{
+		Name: xml.Name{Local: "Document"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camtNamespace}},
+	})
+	w.start("BkToCstmrStmt")
+	w.encode(camtGroupHeader{MessageID: statement.ID, CreatedAt: camtTime(statement.CreatedAt)})
+	w.start("Stmt")
+	w.element("Id", statement.ID)
+	w.element("CreDtTm", camtTime(statement.CreatedAt))
+	w.encode(camtPeriod{From: camtTime(statement.From), To: camtTime(statement.To)})
+	w.encode(camtAccount{ID: statement.AccountID, Currency: statement.OpeningBalance.Currency.String()})
+	w.encode(newCAMTBalance("OPBD", statement.OpeningBalance, statement.From))
+	w.encode(newCAMTBalance("CLBD", statement.ClosingBalance, statement.To))
+
+	return w.err
+}
+
+func (writer *camtStatementWriter) Entry(entry domain.StatementEntry) error {
+	amount, creditDebit := camtSignedAmount(entry.Amount)
+	details := camtDetails{ServicerReference: entry.Transaction.ID}
+	if counterparty := entry.Counterparty; counterparty != "" {
+		details.Parties = &camtParties{}
+		if creditDebit == "DBIT" {
+			details.Parties.CreditorAccount = &camtPartyAccount{ID: counterparty}
+		} else {
+			details.Parties.DebtorAccount = &camtPartyAccount{ID: counterparty}
+		}
+	}
+
+	writer.xml.encode(camtEntry{
+		Reference:         entry.Transaction.ID,
+		Amount:            amount,
+		CreditDebit:       creditDebit,
+		Status:            "BOOK",
+		BookingDate:       camtTime(entry.Transaction.CreatedAt),
+		ValueDate:         camtTime(entry.Transaction.ValuedAt()),
+		ServicerReference: entry.Transaction.ID,
+		TransactionCode:   entry.Transaction.Type.String(),
+		Details:           details,
+	})
+
+	return writer.xml.err
+}
+
+func (writer *camtStatementWriter) End() error {
+	writer.xml.end("Stmt", "BkToCstmrStmt", "Document")
+
+	return writer.xml.close()
+}
+
+func newCAMTBalance(balanceType string, balance domain.Money, at time.Time) camtBalance {
+	amount, creditDebit := camtSignedAmount(balance)
+	return camtBalance{Type: balanceType, Amount: amount, CreditDebit: creditDebit, Date: camtTime(at)}
+}
+
+// camtSignedAmount splits money into the unsigned amount and credit or debit indicator camt uses, zero is a credit
+func camtSignedAmount(money domain.Money) (camtAmount, string) {
+	amount := camtAmount{Currency: money.Currency.String(), Value: strings.TrimPrefix(money.String(), "-")}
+	if money.IsNegative() {
+		return amount, "DBIT"
+	}
+
+	return amount, "CRDT"
+}
+
+func camtTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// xmlWriter stops writing at the first failure and keeps it in err, so a statement part reads as the list of its
+// elements
+type xmlWriter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+	err     error
+}
+
+func newXMLWriter(w io.Writer) *xmlWriter {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	return &xmlWriter{w: w, encoder: encoder}
+}
+
+// header writes the XML declaration followed by any processing instruction the format needs, before any element
+func (w *xmlWriter) header(instructions string) {
+	if w.err == nil {
+		_, w.err = io.WriteString(w.w, xml.Header+instructions)
+	}
+}
+
+func (w *xmlWriter) token(token xml.Token) {
+	if w.err == nil {
+		w.err = w.encoder.EncodeToken(token)
+	}
+}
+
+func (w *xmlWriter) start(names ...string) {
+	for _, name := range names {
+		w.token(xml.StartElement{Name: xml.Name{Local: name}})
+	}
+}
+
+func (w *xmlWriter) end(names ...string) {
+	for _, name := range names {
+		w.token(xml.EndElement{Name: xml.Name{Local: name}})
+	}
+}
+
+func (w *xmlWriter) element(name string, v any) {
+	if w.err == nil {
+		w.err = w.encoder.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}})
+	}
+}
+
+func (w *xmlWriter) encode(v any) {
+	if w.err == nil {
+		w.err = w.encoder.Encode(v)
+	}
+}
+
+// close checks every element was ended, flushes the encoder and ends the last line
+func (w *xmlWriter) close() error {
+	if w.err == nil {
+		w.err = w.encoder.Close()
+	}
+	if w.err == nil {
+		_, w.err = io.WriteString(w.w, "\n")
+	}
+
+	return w.err
+}
@@ -0,0 +1,108 @@
+package response
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+func TestStatementWriter(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	account, other, usdAccount := "acc-1", "acc-2", "acc-usd"
+	eur := func(amount int64) domain.Money {
+		return domain.NewMoney(amount, domain.EUR)
+	}
+
+	statement := domain.Statement{
+		ID:             "stmt-1",
+		AccountID:      account,
+		AccountType:    domain.Checking,
+		From:           from,
+		To:             from.AddDate(0, 1, 0).Add(-time.Nanosecond),
+		OpeningBalance: eur(10000),
+		ClosingBalance: eur(2922),
+		CreatedAt:      time.Date(2025, 2, 1, 8, 30, 0, 0, time.UTC),
+	}
+	entries := []domain.StatementEntry{
+		{
+			Transaction:  domain.Transaction{ID: "tx-deposit", CreatedAt: from.Add(9 * time.Hour), ToAccountID: &account, Amount: eur(5000), Type: domain.Deposit},
+			Amount:       eur(5000),
+			BalanceAfter: eur(15000),
+		},
+		{
+			Transaction:  domain.Transaction{ID: "tx-withdrawal", CreatedAt: from.AddDate(0, 0, 4), FromAccountID: &account, Amount: eur(3000), Type: domain.Withdrawal},
+			Amount:       eur(-3000),
+			BalanceAfter: eur(12000),
+		},
+		{
+			Transaction:  domain.Transaction{ID: "tx-transfer-out", CreatedAt: from.AddDate(0, 0, 9), FromAccountID: &account, ToAccountID: &other, Amount: eur(10000), Type: domain.Transfer},
+			Amount:       eur(-10000),
+			BalanceAfter: eur(2000),
+			Counterparty: other,
+		},
+		{
+			Transaction: domain.Transaction{
+				ID:            "tx-transfer-in",
+				CreatedAt:     from.AddDate(0, 0, 20).Add(123456789),
+				FromAccountID: &usdAccount,
+				ToAccountID:   &account,
+				Amount:        domain.NewMoney(1000, domain.USD),
+				Type:          domain.Transfer,
+				Conversion:    &domain.FXConversion{DestinationAmount: eur(922), Rate: "0.9221"},
+			},
+			Amount:       eur(922),
+			BalanceAfter: eur(2922),
+			Counterparty: usdAccount,
+		},
+	}
+
+	tests := []struct {
+		format StatementFormat
+		golden string
+	}{
+		{format: StatementCSV, golden: "statement.csv"},
+		{format: StatementOFX, golden: "statement.ofx"},
+		{format: StatementCAMT053, golden: "statement.camt053.xml"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := NewStatementWriter(tt.format, &buf)
+
+			if err := writer.Begin(statement); err != nil {
+				t.Fatalf("Begin() error = %v", err)
+			}
+			for _, entry := range entries {
+				if err := writer.Entry(entry); err != nil {
+					t.Fatalf("Entry() error = %v", err)
+				}
+			}
+			if err := writer.End(); err != nil {
+				t.Fatalf("End() error = %v", err)
+			}
+
+			golden := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if diff := cmp.Diff(string(want), buf.String()); diff != "" {
+				t.Errorf("statement (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
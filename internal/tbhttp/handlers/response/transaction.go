@@ -4,15 +4,70 @@ import (
 	"time"
 
 	"http/internal/domain"
+	"http/internal/pagination"
 )
 
 type Transaction struct {
-	ID          string    `json:"id"`
-	FromAccount *string   `json:"from_account,omitempty"`
-	ToAccount   *string   `json:"to_account,omitempty"`
-	Amount      int       `json:"amount"`
-	CreatedAt   time.Time `json:"created_at"`
-	Type        string    `json:"type"`
+	ID          string       `json:"id"`
+	FromAccount *string      `json:"from_account,omitempty"`
+	ToAccount   *string      `json:"to_account,omitempty"`
+	Amount      domain.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Type        string       `json:"type"`
+	FX          *Conversion  `json:"fx,omitempty"`
+	// ParentID is the transaction a fee was charged for or a reversal refunds
+	ParentID string `json:"parent_id,omitempty"`
+	// ValueDate is the last day interest was accrued for, only set on interest
+	ValueDate *time.Time `json:"value_date,omitempty"`
+	// Fee is set when the transaction was charged one
+	Fee *Fee `json:"fee,omitempty"`
+	// Reversals and Refunded are only set on a transaction read on its own once it was reversed, Refunded is in the
+	// currency the transaction credited
+	Reversals []Transaction `json:"reversals,omitempty"`
+	Refunded  *domain.Money `json:"refunded,omitempty"`
+	// BalanceAfter is only set on history entries when asked for
+	BalanceAfter *domain.Money `json:"balance_after,omitempty"`
+}
+
+// Conversion is set on transfers between accounts in different currencies
+type Conversion struct {
+	DestinationAmount   domain.Money `json:"destination_amount"`
+	DestinationCurrency string       `json:"destination_currency"`
+	Rate                string       `json:"rate"`
+	RateTimestamp       time.Time    `json:"rate_timestamp"`
+}
+
+// Fee is the fee transaction charged together with a transaction, quotes leave its ID out
+type Fee struct {
+	ID       string       `json:"id,omitempty"`
+	Amount   domain.Money `json:"amount"`
+	Currency string       `json:"currency"`
+}
+
+func feeFromDomain(fee *domain.Transaction) *Fee {
+	if fee == nil {
+		return nil
+	}
+
+	return &Fee{
+		ID:       fee.ID,
+		Amount:   fee.Amount,
+		Currency: fee.Amount.Currency.String(),
+	}
+}
+
+func conversionFromDomain(conversion *domain.FXConversion) *Conversion {
+	if conversion == nil {
+		return nil
+	}
+
+	return &Conversion{
+		DestinationAmount:   conversion.DestinationAmount,
+		DestinationCurrency: conversion.DestinationAmount.Currency.String(),
+		Rate:                conversion.Rate,
+		RateTimestamp:       conversion.RateTimestamp,
+	}
 }
 
 func TransactionFromDomain(transaction *domain.Transaction) Transaction {
@@ -21,9 +76,48 @@ func TransactionFromDomain(transaction *domain.Transaction) Transaction {
 		FromAccount: transaction.FromAccountID,
 		ToAccount:   transaction.ToAccountID,
 		Amount:      transaction.Amount,
+		Currency:    transaction.Amount.Currency.String(),
 		CreatedAt:   transaction.CreatedAt,
 		Type:        transaction.Type.String(),
+		FX:          conversionFromDomain(transaction.Conversion),
+		ParentID:    transaction.ParentID,
+		ValueDate:   transaction.ValueDate,
+		Fee:         feeFromDomain(transaction.Fee),
+	}
+}
+
+// TransactionWithReversalsFromDomain lists the reversals made of the transaction, oldest first, with what they
+// refunded in total. The total fails like any other sum of money, for reversals in another currency or beyond range.
+func TransactionWithReversalsFromDomain(transaction *domain.Transaction, reversals []domain.Transaction) (Transaction, error) {
+	response := TransactionFromDomain(transaction)
+	if len(reversals) == 0 {
+		return response, nil
 	}
+
+	refunded := domain.NewMoney(0, transaction.DestinationAmount().Currency)
+	response.Reversals = make([]Transaction, len(reversals))
+	for i := range reversals {
+		response.Reversals[i] = TransactionFromDomain(&reversals[i])
+
+		var err error
+		if refunded, err = refunded.Add(reversals[i].Amount); err != nil {
+			return Transaction{}, err
+		}
+	}
+	response.Refunded = &refunded
+
+	return response, nil
+}
+
+// QuoteFromDomain is a transfer that was only tried out, it was never stored and has no ID
+func QuoteFromDomain(transaction *domain.Transaction) Transaction {
+	quote := TransactionFromDomain(transaction)
+	quote.ID = ""
+	if quote.Fee != nil {
+		quote.Fee.ID = ""
+	}
+
+	return quote
 }
 
 func DepositFromDomain(transaction *domain.Transaction) Transaction {
@@ -31,8 +125,10 @@ func DepositFromDomain(transaction *domain.Transaction) Transaction {
 		ID:        transaction.ID,
 		ToAccount: transaction.ToAccountID,
 		Amount:    transaction.Amount,
+		Currency:  transaction.Amount.Currency.String(),
 		CreatedAt: transaction.CreatedAt,
 		Type:      transaction.Type.String(),
+		Fee:       feeFromDomain(transaction.Fee),
 	}
 }
 
@@ -41,24 +137,56 @@ func WithdrawFromDomain(transaction *domain.Transaction) Transaction {
 		ID:          transaction.ID,
 		FromAccount: transaction.FromAccountID,
 		Amount:      transaction.Amount,
+		Currency:    transaction.Amount.Currency.String(),
 		CreatedAt:   transaction.CreatedAt,
 		Type:        transaction.Type.String(),
+		Fee:         feeFromDomain(transaction.Fee),
 	}
 }
 
-func TransactionsHistoryFromDomain(transactions []domain.Transaction) []Transaction {
-	var transactionsHistory = make([]Transaction, len(transactions))
+type TransactionHistory struct {
+	Transactions []Transaction `json:"transactions"`
+	// NextCursor is left out on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// TransactionsHistoryFromDomain sets the balance after every transaction when balances holds one per transaction
+func TransactionsHistoryFromDomain(page pagination.Page[domain.Transaction], balances []domain.Money) TransactionHistory {
+	var transactionsHistory = make([]Transaction, len(page.Items))
 
-	for i, transaction := range transactions {
+	for i, transaction := range page.Items {
 		transactionsHistory[i] = Transaction{
 			ID:          transaction.ID,
 			FromAccount: transaction.FromAccountID,
 			ToAccount:   transaction.ToAccountID,
 			Amount:      transaction.Amount,
+			Currency:    transaction.Amount.Currency.String(),
 			CreatedAt:   transaction.CreatedAt,
 			Type:        transaction.Type.String(),
+			FX:          conversionFromDomain(transaction.Conversion),
+			ParentID:    transaction.ParentID,
+			ValueDate:   transaction.ValueDate,
+		}
+		if len(balances) == len(page.Items) {
+			transactionsHistory[i].BalanceAfter = &balances[i]
 		}
 	}
 
-	return transactionsHistory
+	return TransactionHistory{Transactions: transactionsHistory, NextCursor: page.NextCursor}
+}
+
+type AccountBalance struct {
+	AccountID string       `json:"account_id"`
+	Balance   domain.Money `json:"balance"`
+	Currency  string       `json:"currency"`
+	AsOf      time.Time    `json:"as_of"`
+}
+
+func AccountBalanceFromDomain(accountID string, balance domain.Money, asOf time.Time) AccountBalance {
+	return AccountBalance{
+		AccountID: accountID,
+		Balance:   balance,
+		Currency:  balance.Currency.String(),
+		AsOf:      asOf,
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"http/internal/domain"
+	"http/internal/pagination"
 )
 
 type UserResponse struct {
@@ -24,12 +25,7 @@ func AccountsResponseFromDomain(domainAccounts []domain.Account) []AccountRespon
 	accounts := make([]AccountResponse, len(domainAccounts))
 
 	for i, domainAccount := range domainAccounts {
-		accounts[i] = AccountResponse{
-			ID:        domainAccount.ID,
-			UserID:    domainAccount.UserID,
-			Balance:   domainAccount.Balance,
-			DeletedAt: domainAccount.DeletedAt,
-		}
+		accounts[i] = AccountResponseFromDomain(&domainAccount)
 	}
 
 	return accounts
@@ -37,12 +33,14 @@ func AccountsResponseFromDomain(domainAccounts []domain.Account) []AccountRespon
 
 type ListUsersResponse struct {
 	Users []UserResponse `json:"users"`
+	// NextCursor is left out on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
-func GetUsersFromDomain(users []domain.User) ListUsersResponse {
-	var listUsers = make([]UserResponse, len(users))
+func GetUsersFromDomain(page pagination.Page[domain.User]) ListUsersResponse {
+	var listUsers = make([]UserResponse, len(page.Items))
 
-	for i, user := range users {
+	for i, user := range page.Items {
 		listUsers[i] = UserResponse{
 			ID:        user.ID,
 			Name:      user.Name,
@@ -50,5 +48,5 @@ func GetUsersFromDomain(users []domain.User) ListUsersResponse {
 		}
 	}
 
-	return ListUsersResponse{Users: listUsers}
+	return ListUsersResponse{Users: listUsers, NextCursor: page.NextCursor}
 }
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"http/internal/idempotency"
+	"http/internal/service/standingorder"
+	"http/internal/tbhttp/handlers/request"
+	"http/internal/tbhttp/handlers/response"
+)
+
+func RegisterStandingOrderHandler(mux *http.ServeMux, logger *slog.Logger, standingOrderSvc *standingorder.Service, idempotencyStore idempotency.Store) {
+	logger.Debug("registering standing order endpoints")
+
+	logger.Debug("registering POST /account/{id}/standing-orders")
+	mux.Handle("POST /account/{id}/standing-orders", withIdempotency(logger, idempotencyStore, handlePostStandingOrder(logger, standingOrderSvc)))
+
+	logger.Debug("registering GET /account/{id}/standing-orders")
+	mux.Handle("GET /account/{id}/standing-orders", handleGetAccountStandingOrders(logger, standingOrderSvc))
+
+	logger.Debug("registering GET /standing-orders/{id}")
+	mux.Handle("GET /standing-orders/{id}", handleGetStandingOrder(logger, standingOrderSvc))
+
+	logger.Debug("registering DELETE /standing-orders/{id}")
+	mux.Handle("DELETE /standing-orders/{id}", handleDeleteStandingOrder(logger, standingOrderSvc))
+
+	logger.Debug("registering POST /standing-orders/{id}/resume")
+	mux.Handle("POST /standing-orders/{id}/resume", handleResumeStandingOrder(logger, standingOrderSvc))
+
+	logger.Debug("registering GET /standing-orders/{id}/executions")
+	mux.Handle("GET /standing-orders/{id}/executions", handleGetStandingOrderExecutions(logger, standingOrderSvc))
+}
+
+func handlePostStandingOrder(logger *slog.Logger, standingOrderSvc *standingorder.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accountID := r.PathValue("id")
+			if accountID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			var standingOrderRequest request.StandingOrder
+			if err := json.NewDecoder(r.Body).Decode(&standingOrderRequest); err != nil {
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
+				return
+			}
+
+			terms, err := standingOrderRequest.Terms()
+			if err != nil {
+				writeError(logger, w, r, "invalid standing order", err)
+				return
+			}
+
+			order, err := standingOrderSvc.Create(accountID, terms)
+			if err != nil {
+				writeError(logger, w, r, "failed to create standing order", err)
+				return
+			}
+
+			w.Header().Set("Location", "/standing-orders/"+order.ID)
+			writeResponseJson(r.Context(), logger, w, http.StatusCreated, response.StandingOrderFromDomain(order))
+		},
+	)
+}
+
+func handleGetAccountStandingOrders(logger *slog.Logger, standingOrderSvc *standingorder.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			accountID := r.PathValue("id")
+			if accountID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			orders, err := standingOrderSvc.GetAccountStandingOrders(accountID)
+			if err != nil {
+				writeError(logger, w, r, "failed to get standing orders", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.StandingOrdersFromDomain(orders))
+		},
+	)
+}
+
+func handleGetStandingOrder(logger *slog.Logger, standingOrderSvc *standingorder.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			orderID := r.PathValue("id")
+			if orderID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			order, err := standingOrderSvc.Get(orderID)
+			if err != nil {
+				writeError(logger, w, r, "failed to get standing order", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.StandingOrderFromDomain(order))
+		},
+	)
+}
+
+func handleDeleteStandingOrder(logger *slog.Logger, standingOrderSvc *standingorder.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			orderID := r.PathValue("id")
+			if orderID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			if _, err := standingOrderSvc.Cancel(r.Context(), orderID); err != nil {
+				writeError(logger, w, r, "failed to cancel standing order", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusNoContent, nil)
+		},
+	)
+}
+
+func handleResumeStandingOrder(logger *slog.Logger, standingOrderSvc *standingorder.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			orderID := r.PathValue("id")
+			if orderID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			order, err := standingOrderSvc.Resume(r.Context(), orderID)
+			if err != nil {
+				writeError(logger, w, r, "failed to resume standing order", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.StandingOrderFromDomain(order))
+		},
+	)
+}
+
+func handleGetStandingOrderExecutions(logger *slog.Logger, standingOrderSvc *standingorder.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			orderID := r.PathValue("id")
+			if orderID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			executions, err := standingOrderSvc.GetExecutions(orderID)
+			if err != nil {
+				writeError(logger, w, r, "failed to get standing order executions", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.StandingOrderExecutionsFromDomain(executions))
+		},
+	)
+}
@@ -2,29 +2,48 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
+	"http/internal/domain"
+	"http/internal/idempotency"
+	"http/internal/repository"
 	"http/internal/service/transaction"
 	"http/internal/tbhttp/handlers/request"
 	"http/internal/tbhttp/handlers/response"
 )
 
-func RegisterTransactionHandler(mux *http.ServeMux, logger *slog.Logger, transactionSvc *transaction.Service) {
+func RegisterTransactionHandler(mux *http.ServeMux, logger *slog.Logger, transactionSvc *transaction.Service, idempotencyStore idempotency.Store) {
 	logger.Debug("registering transaction endpoints")
 
 	logger.Debug("registering POST /transaction")
-	mux.Handle("POST /transaction", handlePostTransaction(logger, transactionSvc))
+	mux.Handle("POST /transaction", withIdempotency(logger, idempotencyStore, handlePostTransaction(logger, transactionSvc)))
 
-	logger.Debug("registering POST /account/{id}/withdraw")
-	mux.Handle("POST /account/{id}/withdraw", handlePostWithdraw(logger, transactionSvc))
+	logger.Debug("registering GET /transaction/{id}")
+	mux.Handle("GET /transaction/{id}", handleGetTransaction(logger, transactionSvc))
+
+	logger.Debug("registering POST /transaction/{id}/reverse")
+	mux.Handle("POST /transaction/{id}/reverse", withIdempotency(logger, idempotencyStore, handleReverseTransaction(logger, transactionSvc)))
 
 	logger.Debug("registering POST /account/{id}/withdraw")
-	mux.Handle("POST /account/{id}/deposit", handlePostDeposit(logger, transactionSvc))
+	mux.Handle("POST /account/{id}/withdraw", withIdempotency(logger, idempotencyStore, handlePostWithdraw(logger, transactionSvc)))
+
+	logger.Debug("registering POST /account/{id}/deposit")
+	mux.Handle("POST /account/{id}/deposit", withIdempotency(logger, idempotencyStore, handlePostDeposit(logger, transactionSvc)))
 
 	logger.Debug("registering GET /account/{id}/transactions")
 	mux.Handle("GET /account/{id}/transactions", handleGetAccountTransactions(logger, transactionSvc))
+
+	logger.Debug("registering GET /account/{id}/balance")
+	mux.Handle("GET /account/{id}/balance", handleGetAccountBalance(logger, transactionSvc))
+
+	logger.Debug("registering GET /account/{id}/statement")
+	mux.Handle("GET /account/{id}/statement", handleGetAccountStatement(logger, transactionSvc))
 }
 
 func handlePostWithdraw(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
@@ -34,22 +53,24 @@ func handlePostWithdraw(logger *slog.Logger, transactionSvc *transaction.Service
 
 			accountID := r.PathValue("id")
 			if accountID == "" {
-				logger.InfoContext(r.Context(), "invalid path")
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid path", Details: "{id} is empty"})
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
 				return
 			}
 
 			if err := json.NewDecoder(r.Body).Decode(&postWithdraw); err != nil {
-				logger.ErrorContext(r.Context(), "failed to decode json", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid json", Details: err.Error()})
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
 				return
 			}
 
-			tr, err := transactionSvc.Withdraw(accountID, postWithdraw.Amount)
+			amount, err := postWithdraw.Money()
 			if err != nil {
-				// TODO unwrap validation error and change http status accordingly
-				logger.ErrorContext(r.Context(), "failed to perform transfer", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusUnprocessableEntity, response.Error{Message: "failed to perform transfer", Details: err.Error()})
+				writeError(logger, w, r, "invalid amount", err)
+				return
+			}
+
+			tr, err := transactionSvc.Withdraw(r.Context(), accountID, amount)
+			if err != nil {
+				writeError(logger, w, r, "failed to perform transfer", err)
 				return
 			}
 
@@ -65,22 +86,24 @@ func handlePostDeposit(logger *slog.Logger, transactionSvc *transaction.Service)
 
 			accountID := r.PathValue("id")
 			if accountID == "" {
-				logger.InfoContext(r.Context(), "invalid path")
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid path", Details: "{id} is empty"})
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
 				return
 			}
 
 			if err := json.NewDecoder(r.Body).Decode(&postDeposit); err != nil {
-				logger.ErrorContext(r.Context(), "failed to decode json", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid json", Details: err.Error()})
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
 				return
 			}
 
-			tr, err := transactionSvc.Deposit(accountID, postDeposit.Amount)
+			amount, err := postDeposit.Money()
 			if err != nil {
-				// TODO unwrap validation error and change http status accordingly
-				logger.ErrorContext(r.Context(), "failed to perform transfer", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusUnprocessableEntity, response.Error{Message: "failed to perform transfer", Details: err.Error()})
+				writeError(logger, w, r, "invalid amount", err)
+				return
+			}
+
+			tr, err := transactionSvc.Deposit(r.Context(), accountID, amount)
+			if err != nil {
+				writeError(logger, w, r, "failed to perform transfer", err)
 				return
 			}
 
@@ -94,17 +117,38 @@ func handlePostTransaction(logger *slog.Logger, transactionSvc *transaction.Serv
 		func(w http.ResponseWriter, r *http.Request) {
 			var postTransaction request.Transaction
 
+			params := newQueryParams(r)
+			dryRun := queryParam(params, "dry-run", strconv.ParseBool, false)
+			if err := params.Err(); err != nil {
+				writeError(logger, w, r, "invalid dry-run parameter", err)
+				return
+			}
+
 			if err := json.NewDecoder(r.Body).Decode(&postTransaction); err != nil {
-				logger.ErrorContext(r.Context(), "failed to decode json", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid json", Details: err.Error()})
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
+				return
+			}
+
+			amount, err := postTransaction.Money()
+			if err != nil {
+				writeError(logger, w, r, "invalid amount", err)
+				return
+			}
+
+			if dryRun {
+				quote, err := transactionSvc.QuoteTransfer(r.Context(), postTransaction.FromAccount, postTransaction.ToAccount, amount)
+				if err != nil {
+					writeError(logger, w, r, "failed to quote transfer", err)
+					return
+				}
+
+				writeResponseJson(r.Context(), logger, w, http.StatusOK, response.QuoteFromDomain(quote))
 				return
 			}
 
-			tr, err := transactionSvc.Transfer(postTransaction.FromAccount, postTransaction.ToAccount, postTransaction.Amount)
+			tr, err := transactionSvc.Transfer(r.Context(), postTransaction.FromAccount, postTransaction.ToAccount, amount)
 			if err != nil {
-				// TODO unwrap validation error and change http status accordingly
-				logger.ErrorContext(r.Context(), "failed to perform transfer", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusUnprocessableEntity, response.Error{Message: "failed to perform transfer", Details: err.Error()})
+				writeError(logger, w, r, "failed to perform transfer", err)
 				return
 			}
 
@@ -113,48 +157,229 @@ func handlePostTransaction(logger *slog.Logger, transactionSvc *transaction.Serv
 	)
 }
 
-func handleGetAccountTransactions(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+func handleGetTransaction(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fromDateParam := r.URL.Query().Get("from-date")
-		toDateParam := r.URL.Query().Get("to-date")
-
-		// TODO generalize query params parsing
-		now := time.Now()
-		var fromDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-		var err error
-		if fromDateParam != "" {
-			fromDate, err = time.Parse("2006-01-02", fromDateParam)
+		transactionID := r.PathValue("id")
+		if transactionID == "" {
+			writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+			return
+		}
+
+		tr, reversals, err := transactionSvc.GetTransaction(transactionID)
+		if err != nil {
+			writeError(logger, w, r, "failed to get transaction", err)
+			return
+		}
+
+		resp, err := response.TransactionWithReversalsFromDomain(tr, reversals)
+		if err != nil {
+			// the reversals were stored, failing to add them up is never the client's fault
+			logger.ErrorContext(r.Context(), "failed to total the reversals of transaction", "transaction_id", tr.ID, "error", err)
+			writeAPIError(logger, w, r, apiError{
+				status:  http.StatusInternalServerError,
+				message: "failed to get transaction",
+				code:    "internal_error",
+				detail:  "internal error",
+			})
+			return
+		}
+
+		writeResponseJson(r.Context(), logger, w, http.StatusOK, resp)
+	})
+}
+
+func handleReverseTransaction(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			transactionID := r.PathValue("id")
+			if transactionID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			// the body is optional, without it whatever is left to refund is
+			var reversalRequest request.Reversal
+			if err := json.NewDecoder(r.Body).Decode(&reversalRequest); err != nil && !errors.Is(err, io.EOF) {
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
+				return
+			}
+
+			amount, err := reversalRequest.Money()
 			if err != nil {
-				logger.InfoContext(r.Context(), "invalid from date parameter", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid fromDate parameter", Details: err.Error()})
+				writeError(logger, w, r, "invalid amount", err)
 				return
 			}
-		}
 
-		var toDate = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, time.UTC)
-		if toDateParam != "" {
-			toDate, err = time.Parse("2006-01-02", toDateParam)
+			reversal, err := transactionSvc.Reverse(r.Context(), transactionID, amount)
 			if err != nil {
-				logger.InfoContext(r.Context(), "invalid to date parameter", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid to date parameter", Details: err.Error()})
+				writeError(logger, w, r, "failed to reverse transaction", err)
 				return
 			}
+
+			w.Header().Set("Location", "/transaction/"+reversal.ID)
+			writeResponseJson(r.Context(), logger, w, http.StatusCreated, response.TransactionFromDomain(reversal))
+		},
+	)
+}
+
+func handleGetAccountTransactions(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.PathValue("id")
+		if accountID == "" {
+			writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+			return
+		}
+
+		filter, amounts, err := parseTransactionFilter(r, time.Now())
+		if err != nil {
+			writeError(logger, w, r, "invalid filter parameters", err)
+			return
+		}
+
+		pageRequest, err := parsePageRequest(r)
+		if err != nil {
+			writeError(logger, w, r, "invalid page parameters", err)
+			return
+		}
+
+		params := newQueryParams(r)
+		withBalances := queryParam(params, "balance-after", strconv.ParseBool, false)
+		if err := params.Err(); err != nil {
+			writeError(logger, w, r, "invalid balance-after parameter", err)
+			return
+		}
+
+		page, err := transactionSvc.GetAccountTransactionHistory(accountID, filter, amounts, pageRequest)
+		if err != nil {
+			writeError(logger, w, r, "failed to get account transaction history", err)
+			return
 		}
 
+		var balances []domain.Money
+		if withBalances {
+			if balances, err = transactionSvc.GetBalancesAfter(accountID, page.Items); err != nil {
+				writeError(logger, w, r, "failed to get balances after transactions", err)
+				return
+			}
+		}
+
+		setNextLink(w, r, page.NextCursor)
+		writeResponseJson(r.Context(), logger, w, http.StatusOK, response.TransactionsHistoryFromDomain(page, balances))
+	})
+}
+
+func handleGetAccountBalance(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		accountID := r.PathValue("id")
 		if accountID == "" {
-			logger.InfoContext(r.Context(), "invalid path")
-			writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid path", Details: "{id} is empty"})
+			writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
 			return
 		}
 
-		transactions, err := transactionSvc.GetAccountTransactionHistory(accountID, fromDate, toDate)
+		// a date as-of is the end of that day
+		params := newQueryParams(r)
+		location := queryParam(params, "tz", time.LoadLocation, time.UTC)
+		asOf := queryParam(params, "as-of", parseTime(location, true), time.Now())
+		if err := params.Err(); err != nil {
+			writeError(logger, w, r, "invalid balance parameters", err)
+			return
+		}
+
+		balance, err := transactionSvc.GetAccountBalance(accountID, asOf)
 		if err != nil {
-			logger.ErrorContext(r.Context(), "failed to get account transactions", "error", err)
-			writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "failed to get account transaction history", Details: err.Error()})
+			writeError(logger, w, r, "failed to get account balance", err)
+			return
+		}
+
+		writeResponseJson(r.Context(), logger, w, http.StatusOK, response.AccountBalanceFromDomain(accountID, balance, asOf))
+	})
+}
+
+func handleGetAccountStatement(logger *slog.Logger, transactionSvc *transaction.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.PathValue("id")
+		if accountID == "" {
+			writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+			return
+		}
+
+		// the statement covers the current month up to today when from and to are left out
+		params := newQueryParams(r)
+		format := queryParam(params, "format", response.ParseStatementFormat, response.StatementCSV)
+		location := queryParam(params, "tz", time.LoadLocation, time.UTC)
+		today := time.Now().In(location)
+		startOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, location)
+		endOfToday := time.Date(today.Year(), today.Month(), today.Day()+1, 0, 0, 0, 0, location).Add(-time.Nanosecond)
+		from := queryParam(params, "from", parseTime(location, false), startOfMonth)
+		to := queryParam(params, "to", parseTime(location, true), endOfToday)
+		if err := params.Err(); err != nil {
+			writeError(logger, w, r, "invalid statement parameters", err)
+			return
+		}
+
+		body := &statementBody{w: w, format: format, filename: "statement-" + accountID}
+		err := transactionSvc.WriteStatement(accountID, from, to, response.NewStatementWriter(format, body))
+		if err == nil {
 			return
 		}
 
-		writeResponseJson(r.Context(), logger, w, http.StatusOK, response.TransactionsHistoryFromDomain(transactions))
+		// once the statement started streaming the status is sent, all that's left is cutting it short
+		if !body.started {
+			writeError(logger, w, r, "failed to write account statement", err)
+			return
+		}
+		logger.ErrorContext(r.Context(), "failed to write account statement", "error", err)
 	})
 }
+
+// statementBody sends the statement headers right before its first byte, so errors found before the statement
+// starts are still answered as problems
+type statementBody struct {
+	w        http.ResponseWriter
+	format   response.StatementFormat
+	filename string
+	started  bool
+}
+
+func (body *statementBody) Write(p []byte) (int, error) {
+	if !body.started {
+		body.started = true
+		body.w.Header().Set("Content-Type", body.format.ContentType())
+		body.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", body.filename+"."+body.format.Extension()))
+		body.w.WriteHeader(http.StatusOK)
+	}
+
+	return body.w.Write(p)
+}
+
+// parseTransactionFilter reads the history filters. Dates are days in the tz location, UTC by default, and the
+// history covers today when they're left out. Amounts are in currency, the account's when it's left out.
+func parseTransactionFilter(r *http.Request, now time.Time) (repository.TransactionFilter, transaction.AmountRange, error) {
+	params := newQueryParams(r)
+
+	location := queryParam(params, "tz", time.LoadLocation, time.UTC)
+	today := now.In(location)
+	startOfToday := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, location)
+
+	currency := queryParam(params, "currency", domain.ParseCurrency, "")
+	parseAmount := func(amount string) (domain.Amount, error) {
+		if currency == "" {
+			return domain.ParseDecimalAmount(amount)
+		}
+		return domain.ParseMoney(amount, currency)
+	}
+
+	filter := repository.TransactionFilter{
+		FromDate: queryParam(params, "from-date", parseTime(location, false), startOfToday),
+		ToDate:   queryParam(params, "to-date", parseTime(location, true), startOfToday.AddDate(0, 0, 1).Add(-time.Nanosecond)),
+		Types:    queryList(params, "type", domain.ParseTransactionType),
+	}
+	amounts := transaction.AmountRange{
+		Min: queryParam(params, "min-amount", parseAmount, nil),
+		Max: queryParam(params, "max-amount", parseAmount, nil),
+	}
+	filter.CounterpartyID = params.values.Get("counterparty")
+	filter.Descending = queryParam(params, "sort", parseSort, false)
+
+	return filter, amounts, params.Err()
+}
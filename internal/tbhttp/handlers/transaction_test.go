@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"http/internal/domain"
+	"http/internal/repository"
+	"http/internal/service/transaction"
+)
+
+func TestParseTransactionFilter(t *testing.T) {
+	now := time.Date(2025, 3, 10, 23, 30, 0, 0, time.UTC)
+	lisbon, _ := time.LoadLocation("Europe/Lisbon")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	tests := []struct {
+		name        string
+		query       string
+		want        repository.TransactionFilter
+		wantAmounts transaction.AmountRange
+		wantFields  []string
+	}{
+		{
+			name:  "no parameters, want today in UTC",
+			query: "",
+			want: repository.TransactionFilter{
+				FromDate: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+				ToDate:   time.Date(2025, 3, 10, 23, 59, 59, 999999999, time.UTC),
+			},
+		},
+		{
+			name:  "no dates, want today in tz",
+			query: "tz=Asia/Tokyo",
+			want: repository.TransactionFilter{
+				FromDate: time.Date(2025, 3, 11, 0, 0, 0, 0, tokyo),
+				ToDate:   time.Date(2025, 3, 11, 23, 59, 59, 999999999, tokyo),
+			},
+		},
+		{
+			name:  "dates are whole days in tz",
+			query: "from-date=2025-01-01&to-date=2025-01-31&tz=Europe/Lisbon",
+			want: repository.TransactionFilter{
+				FromDate: time.Date(2025, 1, 1, 0, 0, 0, 0, lisbon),
+				ToDate:   time.Date(2025, 1, 31, 23, 59, 59, 999999999, lisbon),
+			},
+		},
+		{
+			name:  "RFC 3339 timestamps are exact",
+			query: "from-date=2025-01-01T10:00:00%2B01:00&to-date=2025-01-01T12:30:00.5Z&tz=Asia/Tokyo",
+			want: repository.TransactionFilter{
+				FromDate: time.Date(2025, 1, 1, 10, 0, 0, 0, time.FixedZone("", 3600)),
+				ToDate:   time.Date(2025, 1, 1, 12, 30, 0, 500000000, time.UTC),
+			},
+		},
+		{
+			name:  "every filter",
+			query: "from-date=2025-01-01&to-date=2025-01-02&type=deposit,transfer&type=withdrawal&min-amount=10&max-amount=99.99&currency=USD&counterparty=acc-2&sort=desc",
+			want: repository.TransactionFilter{
+				FromDate:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				ToDate:         time.Date(2025, 1, 2, 23, 59, 59, 999999999, time.UTC),
+				Types:          []domain.TransactionType{domain.Deposit, domain.Transfer, domain.Withdrawal},
+				CounterpartyID: "acc-2",
+				Descending:     true,
+			},
+			wantAmounts: transaction.AmountRange{Min: domain.NewMoney(1000, domain.USD), Max: domain.NewMoney(9999, domain.USD)},
+		},
+		{
+			name:  "amounts without a currency are left to the account",
+			query: "from-date=2025-01-01&min-amount=0.5&sort=asc",
+			want: repository.TransactionFilter{
+				FromDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				ToDate:   time.Date(2025, 3, 10, 23, 59, 59, 999999999, time.UTC),
+			},
+			wantAmounts: transaction.AmountRange{Min: domain.DecimalAmount("0.5")},
+		},
+		{
+			name:       "amount with more decimal places than the currency named",
+			query:      "min-amount=1.001&currency=EUR",
+			wantFields: []string{"min-amount"},
+		},
+		{
+			name:       "every invalid parameter is reported",
+			query:      "from-date=01/01/2025&to-date=tomorrow&tz=Mars/Olympus&type=deposit,refund&min-amount=ten&max-amount=1.0.1&sort=up",
+			wantFields: []string{"tz", "from-date", "to-date", "type", "min-amount", "max-amount", "sort"},
+		},
+		{
+			name:       "unsupported currency",
+			query:      "currency=XYZ&min-amount=1",
+			wantFields: []string{"currency"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/account/1/transactions?"+tt.query, nil)
+
+			got, gotAmounts, err := parseTransactionFilter(r, now)
+
+			var gotFields []string
+			for _, field := range fieldErrors(err) {
+				gotFields = append(gotFields, field.Field)
+			}
+			if diff := cmp.Diff(tt.wantFields, gotFields); diff != "" {
+				t.Fatalf("parseTransactionFilter() error = %v, fields (-want +got):\n%s", err, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseTransactionFilter() (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantAmounts, gotAmounts); diff != "" {
+				t.Errorf("parseTransactionFilter() amounts (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
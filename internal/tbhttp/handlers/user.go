@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
-	"strconv"
 
 	"http/internal/service/user"
 	"http/internal/tbhttp/handlers/request"
@@ -22,6 +21,12 @@ func RegisterUserHandler(mux *http.ServeMux, logger *slog.Logger, userSvc *user.
 
 	logger.Debug("registering DELETE /user/{id}")
 	mux.Handle("DELETE /user/{id}", handleDeleteUser(logger, userSvc))
+
+	logger.Debug("registering POST /users/{id}/restore")
+	mux.Handle("POST /users/{id}/restore", handleRestoreUser(logger, userSvc))
+
+	logger.Debug("registering GET /users/{id}/audit")
+	mux.Handle("GET /users/{id}/audit", handleGetUserAudit(logger, userSvc))
 }
 
 func handlePostUsers(logger *slog.Logger, userSvc *user.Service) http.Handler {
@@ -30,16 +35,19 @@ func handlePostUsers(logger *slog.Logger, userSvc *user.Service) http.Handler {
 			var newUserRequest request.UserRequest
 
 			if err := json.NewDecoder(r.Body).Decode(&newUserRequest); err != nil {
-				logger.ErrorContext(r.Context(), "failed to decode json", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid json", Details: err.Error()})
+				writeBadRequest(logger, w, r, "invalid json", "invalid_json", err.Error())
+				return
+			}
+
+			currency, err := newUserRequest.AccountCurrency()
+			if err != nil {
+				writeError(logger, w, r, "invalid currency", err)
 				return
 			}
 
-			user, err := userSvc.CreateUser(newUserRequest.Name)
+			user, err := userSvc.CreateUser(newUserRequest.Name, currency)
 			if err != nil {
-				// TODO unwrap validation error and change http status accordingly
-				logger.ErrorContext(r.Context(), "failed to create user", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusUnprocessableEntity, response.Error{Message: "failed to create user", Details: err.Error()})
+				writeError(logger, w, r, "failed to create user", err)
 				return
 			}
 
@@ -51,27 +59,26 @@ func handlePostUsers(logger *slog.Logger, userSvc *user.Service) http.Handler {
 func handleGetUsers(logger *slog.Logger, userSvc *user.Service) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
-			returnDeletedParam := r.URL.Query().Get("return-deleted")
-
-			var returnDeleted bool
-			var err error
-			if returnDeletedParam != "" {
-				returnDeleted, err = strconv.ParseBool(returnDeletedParam)
-				if err != nil {
-					logger.InfoContext(r.Context(), "invalid return-deleted parameter", "error", err)
-					writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid return-deleted parameter", Details: err.Error()})
-					return
-				}
+			returnDeleted, err := parseReturnDeleted(r)
+			if err != nil {
+				writeBadRequest(logger, w, r, "invalid return-deleted parameter", "invalid_parameter", err.Error())
+				return
 			}
 
-			usrs, err := userSvc.GetUsers(returnDeleted)
+			pageRequest, err := parsePageRequest(r)
 			if err != nil {
-				logger.ErrorContext(r.Context(), "failed to get users", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusUnprocessableEntity, response.Error{Message: "failed to get users", Details: err.Error()})
+				writeError(logger, w, r, "invalid page parameters", err)
 				return
 			}
 
-			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.GetUsersFromDomain(usrs))
+			page, err := userSvc.GetUsers(returnDeleted, pageRequest)
+			if err != nil {
+				writeError(logger, w, r, "failed to get users", err)
+				return
+			}
+
+			setNextLink(w, r, page.NextCursor)
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.GetUsersFromDomain(page))
 		},
 	)
 }
@@ -81,16 +88,13 @@ func handleDeleteUser(logger *slog.Logger, userSvc *user.Service) http.Handler {
 		func(w http.ResponseWriter, r *http.Request) {
 			userID := r.PathValue("id")
 			if userID == "" {
-				logger.InfoContext(r.Context(), "invalid path")
-				writeResponseJson(r.Context(), logger, w, http.StatusBadRequest, response.Error{Message: "invalid path", Details: "{id} is empty"})
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
 				return
 			}
 
-			err := userSvc.DeleteUser(userID)
+			err := userSvc.DeleteUser(r.Context(), userID)
 			if err != nil {
-				// TODO unwrap validation error and change http status accordingly
-				logger.ErrorContext(r.Context(), "failed to create user", "error", err)
-				writeResponseJson(r.Context(), logger, w, http.StatusUnprocessableEntity, response.Error{Message: "failed to create user", Details: err.Error()})
+				writeError(logger, w, r, "failed to delete user", err)
 				return
 			}
 
@@ -98,3 +102,43 @@ func handleDeleteUser(logger *slog.Logger, userSvc *user.Service) http.Handler {
 		},
 	)
 }
+
+func handleRestoreUser(logger *slog.Logger, userSvc *user.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			userID := r.PathValue("id")
+			if userID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			u, err := userSvc.RestoreUser(r.Context(), userID)
+			if err != nil {
+				writeError(logger, w, r, "failed to restore user", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.UserResponseFromDomain(u))
+		},
+	)
+}
+
+func handleGetUserAudit(logger *slog.Logger, userSvc *user.Service) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			userID := r.PathValue("id")
+			if userID == "" {
+				writeBadRequest(logger, w, r, "invalid path", "invalid_path", "{id} is empty")
+				return
+			}
+
+			events, err := userSvc.GetAuditTrail(userID)
+			if err != nil {
+				writeError(logger, w, r, "failed to get audit trail", err)
+				return
+			}
+
+			writeResponseJson(r.Context(), logger, w, http.StatusOK, response.AuditEventsFromDomain(events))
+		},
+	)
+}
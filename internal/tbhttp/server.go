@@ -5,7 +5,14 @@ import (
 	"log/slog"
 	"net/http"
 
+	"http/internal/fx"
+	"http/internal/idempotency"
+	"http/internal/rateplan"
 	"http/internal/service/account"
+	"http/internal/service/batch"
+	"http/internal/service/interest"
+	"http/internal/service/ledger"
+	"http/internal/service/standingorder"
 	"http/internal/service/transaction"
 	"http/internal/service/user"
 	"http/internal/tbhttp/handlers"
@@ -17,10 +24,25 @@ func NewServer(
 	userService *user.Service,
 	accountService *account.Service,
 	transactionService *transaction.Service,
+	ledgerService *ledger.Service,
+	batchService *batch.Service,
+	standingOrderService *standingorder.Service,
+	interestService *interest.Service,
+	idempotencyStore idempotency.Store,
+	exchangeRates *fx.Table,
+	ratePlans *rateplan.Table,
+	adminToken string,
 ) http.Handler {
 	mux := http.NewServeMux()
 	handlers.RegisterUserHandler(mux, logger, userService)
 	handlers.RegisterAccountHandler(mux, logger, accountService)
-	handlers.RegisterTransactionHandler(mux, logger, transactionService)
+	handlers.RegisterTransactionHandler(mux, logger, transactionService, idempotencyStore)
+	handlers.RegisterHoldHandler(mux, logger, transactionService, idempotencyStore)
+	handlers.RegisterLedgerHandler(mux, logger, ledgerService)
+	handlers.RegisterBatchHandler(mux, logger, batchService, idempotencyStore)
+	handlers.RegisterStandingOrderHandler(mux, logger, standingOrderService, idempotencyStore)
+	handlers.RegisterFXHandler(mux, logger, exchangeRates)
+	handlers.RegisterInterestHandler(mux, logger, ratePlans)
+	handlers.RegisterAdminHandler(mux, logger, accountService, interestService, adminToken)
 	return mux
 }
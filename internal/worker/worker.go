@@ -0,0 +1,46 @@
+// Package worker runs the jobs the bank does on its own in the background, such as executing standing orders or
+// accruing interest.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Job does one run of a background job and returns how many items it handled, the ones a failing run left behind
+// are expected to be handled by the next one
+type Job func(ctx context.Context) (int, error)
+
+// Run runs job once when it starts and then every interval until ctx is done, failing runs are logged under name. A
+// job without a positive interval never runs.
+func Run(ctx context.Context, logger *slog.Logger, name string, interval time.Duration, job Job) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runOnce(ctx, logger, name, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runOnce(ctx context.Context, logger *slog.Logger, name string, job Job) {
+	handled, err := job(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "background job failed", "job", name, "error", err, "handled", handled)
+		return
+	}
+
+	if handled > 0 {
+		logger.InfoContext(ctx, "background job ran", "job", name, "handled", handled)
+	}
+}
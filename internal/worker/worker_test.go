@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	runs := make(chan struct{}, 10)
+	job := func(ctx context.Context) (int, error) {
+		select {
+		case runs <- struct{}{}:
+		default:
+		}
+		return 0, errors.New("storage down")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		Run(ctx, logger, "test job", time.Millisecond, job)
+	}()
+
+	// a failing run doesn't stop the next ones
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("job ran %d times, want at least 2", i)
+		}
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run() didn't return once its context was done")
+	}
+}
+
+func TestRun_WithoutInterval(t *testing.T) {
+	ran := false
+	Run(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)), "test job", 0, func(ctx context.Context) (int, error) {
+		ran = true
+		return 0, nil
+	})
+
+	if ran {
+		t.Error("Run() without an interval ran the job, want it never run")
+	}
+}